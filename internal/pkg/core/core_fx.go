@@ -1,7 +1,17 @@
 package core
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/encryption"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/protobuf"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
 	"go.uber.org/fx"
 )
 
@@ -11,8 +21,76 @@ var Module = fx.Module(
 	"corefx",
 	fx.Provide(
 		json.NewDefaultJsonSerializer,
-		json.NewDefaultEventJsonSerializer,
-		json.NewDefaultMessageJsonSerializer,
+		serializer.NewUpcasterRegistry,
+		newEventSerializer,
 		json.NewDefaultMetadataJsonSerializer,
+		encryption.ProvideConfig,
+		schema.NewSchemaRegistry,
 	),
+	// el key provider es opcional, las aplicaciones pueden registrar uno propio (por ejemplo un KMS) en lugar del de las opciones
+	fx.Provide(fx.Annotate(
+		newMessageSerializer,
+		fx.ParamTags(``, ``, `optional:"true"`),
+	)),
+	fx.Provide(fx.Annotate(
+		circuitbreaker.NewCircuitBreakerRegistry,
+		fx.ParamTags(``, `optional:"true"`),
+	)),
+	fx.Provide(fx.Annotate(
+		circuitbreaker.NewCircuitBreakersHealthChecker,
+		fx.As(new(contracts.Health)),
+		fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
+	)),
+	fx.Invoke(registerCircuitBreakersHook),
+	fx.Invoke(validateTypeRegistry),
 )
+
+// validateTypeRegistry falla el arranque si dos tipos registraron el mismo nombre logico en el typeregistry
+func validateTypeRegistry() error {
+	return typeregistry.Validate()
+}
+
+// registerCircuitBreakersHook cancela las pruebas de los circuitos abiertos al apagar la aplicacion
+func registerCircuitBreakersHook(lc fx.Lifecycle, registry circuitbreaker.CircuitBreakerRegistry) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			registry.Stop()
+
+			return nil
+		},
+	})
+}
+
+// newMessageSerializer json es el formato por defecto, los mensajes que implementan `serializer.ContentTypeMessage` se publican con protobuf
+// y los consumers eligen el serializer por el content type del mensaje. Con el cifrado habilitado el payload se cifra y firma despues de serializarlo
+func newMessageSerializer(
+	s serializer.Serializer,
+	encryptionOptions *encryption.EncryptionOptions,
+	keyProvider encryption.KeyProvider,
+) (serializer.MessageSerializer, error) {
+	messageSerializer := serializer.NewContentTypeMessageSerializer(
+		json.NewDefaultMessageJsonSerializer(s),
+		protobuf.NewProtobufMessageSerializer(s),
+	)
+	if encryptionOptions == nil || !encryptionOptions.Enabled {
+		return messageSerializer, nil
+	}
+
+	if keyProvider == nil {
+		var err error
+		keyProvider, err = encryption.NewKeyProvider(encryptionOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encryption.NewEncryptedMessageSerializer(messageSerializer, keyProvider, encryptionOptions.Strict), nil
+}
+
+// newEventSerializer los eventos json pasan por las transformaciones del registry, las aplicaciones registran sus upcasters con un `fx.Invoke`
+func newEventSerializer(s serializer.Serializer, registry serializer.UpcasterRegistry) serializer.EventSerializer {
+	return serializer.NewContentTypeEventSerializer(
+		serializer.NewUpcastingEventSerializer(json.NewDefaultEventJsonSerializer(s), registry),
+		protobuf.NewProtobufEventSerializer(s),
+	)
+}
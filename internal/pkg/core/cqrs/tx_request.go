@@ -7,3 +7,29 @@ type TxRequest interface {
 
 	isTxRequest()
 }
+
+type txCommand struct {
+	Command
+}
+
+// TxCommand es un Command que se ejecuta dentro de la transacción abierta por el pipeline transaccional del mediator
+type TxCommand interface {
+	Command
+
+	isTxRequest()
+}
+
+func NewTxCommandByT[T any]() TxCommand {
+	return &txCommand{Command: NewCommandByT[T]()}
+}
+
+func (c *txCommand) isTxRequest() {
+}
+
+func IsTxRequest(obj interface{}) bool {
+	if _, ok := obj.(TxRequest); ok {
+		return true
+	}
+
+	return false
+}
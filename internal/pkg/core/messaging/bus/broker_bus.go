@@ -0,0 +1,206 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+)
+
+// BrokerBus tiene la logica comun de los buses de los brokers: el registro de consumers por tipo de mensaje,
+// su arranque y parada, las notificaciones y la publicacion con el producer del broker.
+// Los buses de cada broker lo embeben y agregan la creacion de consumers con su configuracion
+type BrokerBus struct {
+	messageTypeConsumers    map[reflect.Type][]consumer2.Consumer
+	producer                producer.Producer
+	logger                  logger.Logger
+	isConsumedNotifications []func(message types.IMessage)
+	isProducedNotifications []func(message types.IMessage)
+}
+
+func NewBrokerBus(logger logger.Logger) *BrokerBus {
+	return &BrokerBus{
+		logger:               logger,
+		messageTypeConsumers: map[reflect.Type][]consumer2.Consumer{},
+	}
+}
+
+// SetProducer sets the producer of the bus, the producer is created after the bus because it notifies to the bus
+func (b *BrokerBus) SetProducer(producer producer.Producer) {
+	b.producer = producer
+}
+
+// IsConsumed adds a notification function to the bus
+func (b *BrokerBus) IsConsumed(h func(message types.IMessage)) {
+	b.isConsumedNotifications = append(b.isConsumedNotifications, h)
+}
+
+// IsProduced adds a notification function to the bus
+func (b *BrokerBus) IsProduced(h func(message types.IMessage)) {
+	b.isProducedNotifications = append(b.isProducedNotifications, h)
+}
+
+// NotifyConsumed calls the consumed notifications, the consumers of the bus are created with it
+func (b *BrokerBus) NotifyConsumed(message types.IMessage) {
+	for _, notification := range b.isConsumedNotifications {
+		if notification != nil {
+			notification(message)
+		}
+	}
+}
+
+// NotifyProduced calls the produced notifications, the producer of the bus is created with it
+func (b *BrokerBus) NotifyProduced(message types.IMessage) {
+	for _, notification := range b.isProducedNotifications {
+		if notification != nil {
+			notification(message)
+		}
+	}
+}
+
+// AddConsumer adds a consumer for the message type
+func (b *BrokerBus) AddConsumer(messageType reflect.Type, consumer consumer2.Consumer) {
+	b.messageTypeConsumers[messageType] = append(b.messageTypeConsumers[messageType], consumer)
+}
+
+// ConnectConsumer adds a consumer to the bus
+func (b *BrokerBus) ConnectConsumer(messageType types.IMessage, consumer consumer2.Consumer) error {
+	b.AddConsumer(utils.GetMessageBaseReflectType(messageType), consumer)
+
+	return nil
+}
+
+// ConnectHandlerToConsumers adds the handler to the existing consumers of the message type,
+// returns false if there is no consumer for the message type
+func (b *BrokerBus) ConnectHandlerToConsumers(
+	messageType types.IMessage,
+	consumerHandler consumer2.ConsumerHandler,
+) bool {
+	consumersForType := b.messageTypeConsumers[utils.GetMessageBaseReflectType(messageType)]
+	if consumersForType == nil {
+		return false
+	}
+
+	for _, c := range consumersForType {
+		c.ConnectionHandler(consumerHandler)
+	}
+
+	return true
+}
+
+// Start starts the consumers, if a consumer fails the started consumers are stopped
+func (b *BrokerBus) Start(ctx context.Context) error {
+	for _, consumers := range b.messageTypeConsumers {
+		for _, c := range consumers {
+			if err := c.Start(ctx); err != nil {
+				b.logger.Errorf("error in consumer %s, with err: %v", c.GetName(), err)
+
+				if stopErr := b.Stop(); stopErr != nil {
+					return errors.WrapIf(err, stopErr.Error())
+				}
+
+				return err
+			}
+			b.logger.Info(fmt.Sprintf("consumer %s, started", c.GetName()))
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the consumers concurrently, each consumer drains its in-flight messages
+func (b *BrokerBus) Stop() error {
+	waitGroup := sync.WaitGroup{}
+
+	for _, consumers := range b.messageTypeConsumers {
+		for _, c := range consumers {
+			waitGroup.Add(1)
+
+			go func(c consumer2.Consumer) {
+				defer waitGroup.Done()
+
+				if err := c.Stop(); err != nil {
+					b.logger.Errorf("error in stopping consumer %s: %v", c.GetName(), err)
+				}
+			}(c)
+		}
+	}
+	waitGroup.Wait()
+
+	return nil
+}
+
+// DrainStates returns the drain state of the consumers, the consumers that don't drain are reported as running
+func (b *BrokerBus) DrainStates() map[string]consumer2.DrainState {
+	states := map[string]consumer2.DrainState{}
+
+	for _, consumers := range b.messageTypeConsumers {
+		for _, c := range consumers {
+			name := c.GetName()
+			for i := 2; ; i++ {
+				if _, ok := states[name]; !ok {
+					break
+				}
+				name = fmt.Sprintf("%s_%d", c.GetName(), i)
+			}
+
+			state := consumer2.DrainStateRunning
+			if reporter, ok := c.(consumer2.DrainStateReporter); ok {
+				state = reporter.DrainState()
+			}
+			states[name] = state
+		}
+	}
+
+	return states
+}
+
+func (b *BrokerBus) PublishMessage(ctx context.Context, message types.IMessage) error {
+	return b.producer.PublishMessage(ctx, message)
+}
+
+func (b *BrokerBus) PublishMessageWithTopicName(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	return b.producer.PublishMessageWithTopicName(ctx, message, meta, topicName)
+}
+
+func (b *BrokerBus) PublishMessageWithDelay(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return b.producer.PublishMessageWithDelay(ctx, message, meta, delay)
+}
+
+func (b *BrokerBus) ScheduleMessage(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	return b.producer.ScheduleMessage(ctx, message, meta, at)
+}
+
+func (b *BrokerBus) PublishMessages(
+	ctx context.Context,
+	messages []types.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	return b.producer.PublishMessages(ctx, messages, meta, topicName)
+}
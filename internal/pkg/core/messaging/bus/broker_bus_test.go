@@ -0,0 +1,116 @@
+package bus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	*types.Message
+	OrderId string
+}
+
+// fakeConsumer registra las llamadas del bus, falla al iniciar con `startErr` si no es nil
+type fakeConsumer struct {
+	name     string
+	startErr error
+	started  bool
+	stopped  bool
+	handlers []consumer.ConsumerHandler
+}
+
+func (c *fakeConsumer) Start(context.Context) error {
+	c.started = c.startErr == nil
+
+	return c.startErr
+}
+
+func (c *fakeConsumer) Stop() error {
+	c.stopped = true
+
+	return nil
+}
+
+func (c *fakeConsumer) ConnectionHandler(handler consumer.ConsumerHandler) {
+	c.handlers = append(c.handlers, handler)
+}
+
+func (c *fakeConsumer) IsConsumed(func(message types.IMessage)) {}
+
+func (c *fakeConsumer) GetName() string { return c.name }
+
+func (c *fakeConsumer) DrainState() consumer.DrainState {
+	if c.stopped {
+		return consumer.DrainStateDrained
+	}
+
+	return consumer.DrainStateRunning
+}
+
+type noopHandler struct{}
+
+func (noopHandler) Handle(context.Context, types.MessageConsumeContext) error { return nil }
+
+func Test_ConnectHandlerToConsumers(t *testing.T) {
+	bus := NewBrokerBus(defaultlogger.GetLogger())
+
+	assert.False(t, bus.ConnectHandlerToConsumers(&orderCreated{}, noopHandler{}))
+
+	first, second := &fakeConsumer{name: "orders"}, &fakeConsumer{name: "orders"}
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, first))
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, second))
+
+	assert.True(t, bus.ConnectHandlerToConsumers(&orderCreated{}, noopHandler{}))
+	assert.Len(t, first.handlers, 1)
+	assert.Len(t, second.handlers, 1)
+}
+
+func Test_Start_Stops_The_Consumers_When_A_Consumer_Fails(t *testing.T) {
+	bus := NewBrokerBus(defaultlogger.GetLogger())
+	failing := &fakeConsumer{name: "orders", startErr: errors.New("broker not available")}
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, failing))
+
+	err := bus.Start(context.Background())
+
+	assert.ErrorIs(t, err, failing.startErr)
+	assert.True(t, failing.stopped)
+}
+
+func Test_DrainStates_Reports_Each_Consumer(t *testing.T) {
+	bus := NewBrokerBus(defaultlogger.GetLogger())
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, &fakeConsumer{name: "orders"}))
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, &fakeConsumer{name: "orders"}))
+
+	assert.Equal(t, map[string]consumer.DrainState{
+		"orders":   consumer.DrainStateRunning,
+		"orders_2": consumer.DrainStateRunning,
+	}, bus.DrainStates())
+
+	require.NoError(t, bus.Stop())
+
+	assert.Equal(t, map[string]consumer.DrainState{
+		"orders":   consumer.DrainStateDrained,
+		"orders_2": consumer.DrainStateDrained,
+	}, bus.DrainStates())
+}
+
+func Test_Notifications_Are_Called_In_Order(t *testing.T) {
+	bus := NewBrokerBus(defaultlogger.GetLogger())
+	var calls []string
+	bus.IsConsumed(func(types.IMessage) { calls = append(calls, "consumed-1") })
+	bus.IsConsumed(func(types.IMessage) { calls = append(calls, "consumed-2") })
+	bus.IsProduced(func(types.IMessage) { calls = append(calls, "produced") })
+
+	bus.NotifyConsumed(&orderCreated{})
+	bus.NotifyProduced(&orderCreated{})
+
+	assert.Equal(t, []string{"consumed-1", "consumed-2", "produced"}, calls)
+}
@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/samber/lo"
+)
+
+// BrokerConfigurationBuilder junta las configuraciones de producers y consumers de un broker,
+// los builders de configuracion de cada broker lo usan con sus tipos de configuracion
+type BrokerConfigurationBuilder[TProducer any, TConsumer any] struct {
+	producerBuilds []func() TProducer
+	consumerBuilds []func() TConsumer
+}
+
+// AddProducer registers the message type and adds the build function of its producer configuration
+func (b *BrokerConfigurationBuilder[TProducer, TConsumer]) AddProducer(
+	producerMessageType types.IMessage,
+	build func() TProducer,
+) {
+	registerMessageType(producerMessageType)
+	b.producerBuilds = append(b.producerBuilds, build)
+}
+
+// AddConsumer registers the message type and adds the build function of its consumer configuration
+func (b *BrokerConfigurationBuilder[TProducer, TConsumer]) AddConsumer(
+	consumerMessageType types.IMessage,
+	build func() TConsumer,
+) {
+	registerMessageType(consumerMessageType)
+	b.consumerBuilds = append(b.consumerBuilds, build)
+}
+
+// Build builds the producers and consumers configurations in the order they were added
+func (b *BrokerConfigurationBuilder[TProducer, TConsumer]) Build() ([]TProducer, []TConsumer) {
+	producers := lo.Map(b.producerBuilds, func(build func() TProducer, _ int) TProducer {
+		return build()
+	})
+	consumers := lo.Map(b.consumerBuilds, func(build func() TConsumer, _ int) TConsumer {
+		return build()
+	})
+
+	return producers, consumers
+}
+
+// registerMessageType registra el tipo puntero del mensaje en el typemapper para poder deserializarlo por nombre
+func registerMessageType(message types.IMessage) {
+	typemapper.RegisterType(reflect.PointerTo(utils.GetMessageBaseReflectType(message)))
+}
@@ -0,0 +1,374 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+type InMemoryConsumerConnector interface {
+	consumer.ConsumerConnector
+	// ConnectInMemoryConsumer Add a new in-memory consumer to the message type consumers
+	ConnectInMemoryConsumer(
+		messageType types.IMessage,
+		consumerBuilderFunc InMemoryConsumerConfigurationBuilderFuc,
+	) error
+}
+
+// InMemoryBus es un bus sin broker para pruebas y ejecuciones locales, los mensajes se serializan
+// y se entregan a los consumers cuyo exchange y routing key coinciden con los del mensaje
+type InMemoryBus interface {
+	bus.Bus
+	InMemoryConsumerConnector
+}
+
+// InMemoryBusConfigurationFunc configura los consumers del bus antes de iniciarlo
+type InMemoryBusConfigurationFunc func(connector InMemoryConsumerConnector)
+
+type inMemoryBus struct {
+	logger                  logger.Logger
+	messageSerializer       serializer.MessageSerializer
+	messageTypeConsumers    map[reflect.Type][]consumer.Consumer
+	inMemoryConsumers       []*inMemoryConsumer
+	consumersLock           sync.RWMutex
+	scheduledMessages       map[*time.Timer]struct{}
+	scheduledLock           sync.Mutex
+	isConsumedNotifications []func(message types.IMessage)
+	isProducedNotifications []func(message types.IMessage)
+}
+
+func NewInMemoryBus(
+	logger logger.Logger,
+	messageSerializer serializer.MessageSerializer,
+	configurationFunc InMemoryBusConfigurationFunc,
+) (InMemoryBus, error) {
+	inMemoryBus := &inMemoryBus{
+		logger:               logger,
+		messageSerializer:    messageSerializer,
+		messageTypeConsumers: map[reflect.Type][]consumer.Consumer{},
+		scheduledMessages:    map[*time.Timer]struct{}{},
+	}
+
+	if configurationFunc != nil {
+		configurationFunc(inMemoryBus)
+	}
+
+	return inMemoryBus, nil
+}
+
+func (b *inMemoryBus) IsConsumed(h func(message types.IMessage)) {
+	b.isConsumedNotifications = append(b.isConsumedNotifications, h)
+}
+
+func (b *inMemoryBus) IsProduced(h func(message types.IMessage)) {
+	b.isProducedNotifications = append(b.isProducedNotifications, h)
+}
+
+// ConnectConsumer adds an in-memory consumer to the bus, the bus only delivers the messages to its own consumers
+// so the consumers of other transports are rejected instead of never receiving a message
+func (b *inMemoryBus) ConnectConsumer(messageType types.IMessage, consumer consumer.Consumer) error {
+	c, ok := consumer.(*inMemoryConsumer)
+	if !ok {
+		return errors.Errorf(
+			"the in-memory bus can't deliver messages to the consumer `%s` of type %T, use ConnectInMemoryConsumer or ConnectConsumerHandler",
+			consumer.GetName(),
+			consumer,
+		)
+	}
+
+	b.registerInMemoryConsumer(messageType, c)
+
+	return nil
+}
+
+func (b *inMemoryBus) ConnectInMemoryConsumer(
+	messageType types.IMessage,
+	consumerBuilderFunc InMemoryConsumerConfigurationBuilderFuc,
+) error {
+	builder := NewInMemoryConsumerConfigurationBuilder(messageType)
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+
+	b.addInMemoryConsumer(messageType, builder.Build())
+
+	return nil
+}
+
+// ConnectConsumerHandler Add handler to existing in-memory consumers. creates new consumer if not exist
+func (b *inMemoryBus) ConnectConsumerHandler(
+	messageType types.IMessage,
+	consumerHandler consumer.ConsumerHandler,
+) error {
+	typeName := utils.GetMessageBaseReflectType(messageType)
+
+	b.consumersLock.RLock()
+	var consumersForType []*inMemoryConsumer
+	for _, c := range b.inMemoryConsumers {
+		if c.configuration.ConsumerMessageType == typeName {
+			consumersForType = append(consumersForType, c)
+		}
+	}
+	b.consumersLock.RUnlock()
+
+	if len(consumersForType) > 0 {
+		for _, c := range consumersForType {
+			c.ConnectionHandler(consumerHandler)
+		}
+
+		return nil
+	}
+
+	configuration := NewDefaultInMemoryConsumerConfiguration(messageType)
+	configuration.Handlers = []consumer.ConsumerHandler{consumerHandler}
+	b.addInMemoryConsumer(messageType, configuration)
+
+	return nil
+}
+
+// ConnectReplyConsumerHandler creates a consumer bound to the `replyTo` exchange
+func (b *inMemoryBus) ConnectReplyConsumerHandler(
+	messageType types.IMessage,
+	replyTo string,
+	consumerHandler consumer.ConsumerHandler,
+) error {
+	configuration := NewDefaultInMemoryConsumerConfiguration(messageType)
+	configuration.Name = fmt.Sprintf("%s_reply_consumer", utils.GetMessageName(messageType))
+	configuration.ExchangeName = replyTo
+	configuration.Handlers = []consumer.ConsumerHandler{consumerHandler}
+	b.addInMemoryConsumer(messageType, configuration)
+
+	return nil
+}
+
+func (b *inMemoryBus) addInMemoryConsumer(
+	messageType types.IMessage,
+	configuration *InMemoryConsumerConfiguration,
+) {
+	c := newInMemoryConsumer(
+		configuration,
+		b.messageSerializer,
+		b.logger,
+		// IsConsumed Notification
+		func(message types.IMessage) {
+			for _, notification := range b.isConsumedNotifications {
+				if notification != nil {
+					notification(message)
+				}
+			}
+		},
+	)
+
+	b.registerInMemoryConsumer(messageType, c)
+}
+
+func (b *inMemoryBus) registerInMemoryConsumer(messageType types.IMessage, c *inMemoryConsumer) {
+	b.consumersLock.Lock()
+	defer b.consumersLock.Unlock()
+
+	typeName := utils.GetMessageBaseReflectType(messageType)
+	b.messageTypeConsumers[typeName] = append(b.messageTypeConsumers[typeName], c)
+	b.inMemoryConsumers = append(b.inMemoryConsumers, c)
+}
+
+func (b *inMemoryBus) Start(ctx context.Context) error {
+	b.consumersLock.RLock()
+	defer b.consumersLock.RUnlock()
+
+	for _, consumers := range b.messageTypeConsumers {
+		for _, c := range consumers {
+			if err := c.Start(ctx); err != nil {
+				return err
+			}
+			b.logger.Infof("in-memory consumer %s, started", c.GetName())
+		}
+	}
+
+	return nil
+}
+
+// Stop cancels the scheduled messages that are not delivered yet and stops the consumers
+func (b *inMemoryBus) Stop() error {
+	b.scheduledLock.Lock()
+	for timer := range b.scheduledMessages {
+		timer.Stop()
+	}
+	b.scheduledMessages = map[*time.Timer]struct{}{}
+	b.scheduledLock.Unlock()
+
+	b.consumersLock.RLock()
+	defer b.consumersLock.RUnlock()
+
+	for _, consumers := range b.messageTypeConsumers {
+		for _, c := range consumers {
+			if err := c.Stop(); err != nil {
+				b.logger.Errorf("error in stopping in-memory consumer %s: %v", c.GetName(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *inMemoryBus) PublishMessage(ctx context.Context, message types.IMessage) error {
+	return b.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+func (b *inMemoryBus) PublishMessageWithTopicName(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+) error {
+	exchange := topicOrExchangeName
+	if exchange == "" {
+		exchange = utils.GetTopicOrExchangeName(message)
+	}
+	routingKey := utils.GetRoutingKey(message)
+
+	meta = b.getMetadata(message, meta)
+
+	serializedObj, err := b.messageSerializer.Serialize(message)
+	if err != nil {
+		return err
+	}
+	messageHeader.SetMessageContentType(meta, serializedObj.ContentType)
+	// the serializer headers, like the key id of an encrypted payload, travel with the message
+	for key, value := range serializedObj.Metadata {
+		meta.Set(key, value)
+	}
+
+	b.consumersLock.RLock()
+	consumers := b.inMemoryConsumers
+	b.consumersLock.RUnlock()
+
+	for _, c := range consumers {
+		if !c.matches(exchange, routingKey) {
+			continue
+		}
+
+		// each consumer gets its own copy of the metadata, like a queue bound to the exchange
+		d := &delivery{
+			body:          serializedObj.Data,
+			contentType:   serializedObj.ContentType,
+			messageType:   typeregistry.GetTypeName(message),
+			messageId:     message.GeMessageId(),
+			correlationId: messageHeader.GetCorrelationId(meta),
+			created:       message.GetCreated(),
+			meta:          copyMetadata(meta),
+		}
+		if err := c.enqueue(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	for _, notification := range b.isProducedNotifications {
+		if notification != nil {
+			notification(message)
+		}
+	}
+
+	return nil
+}
+
+// PublishMessages publishes the messages in order, it stops on the first failed publish
+func (b *inMemoryBus) PublishMessages(
+	ctx context.Context,
+	messages []types.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+) error {
+	for _, message := range messages {
+		if err := b.PublishMessageWithTopicName(ctx, message, meta, topicOrExchangeName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *inMemoryBus) PublishMessageWithDelay(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return b.ScheduleMessage(ctx, message, meta, time.Now().Add(delay))
+}
+
+// ScheduleMessage publishes the message when the scheduled time arrives, the pending messages are lost when the bus stops
+func (b *inMemoryBus) ScheduleMessage(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	if meta == nil {
+		meta = metadata.New()
+	}
+	messageHeader.SetMessageScheduled(meta, at.UTC())
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return b.PublishMessageWithTopicName(ctx, message, meta, "")
+	}
+
+	b.scheduledLock.Lock()
+	defer b.scheduledLock.Unlock()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		b.scheduledLock.Lock()
+		delete(b.scheduledMessages, timer)
+		b.scheduledLock.Unlock()
+
+		// the publish context could be canceled before the scheduled time
+		if err := b.PublishMessageWithTopicName(context.Background(), message, meta, ""); err != nil {
+			b.logger.Errorf("error in publishing scheduled message with id `%s`: %v", message.GeMessageId(), err)
+		}
+	})
+	b.scheduledMessages[timer] = struct{}{}
+
+	return nil
+}
+
+func (b *inMemoryBus) getMetadata(message types.IMessage, meta metadata.Metadata) metadata.Metadata {
+	meta = copyMetadata(meta)
+
+	messageHeader.SetMessageType(meta, typeregistry.GetTypeName(message))
+	messageHeader.SetMessageContentType(meta, b.messageSerializer.ContentType())
+	messageHeader.SetMessageId(meta, message.GeMessageId())
+	messageHeader.SetMessageCreated(meta, message.GetCreated())
+	messageHeader.SetMessageName(meta, utils.GetMessageName(message))
+
+	if strings.TrimSpace(messageHeader.GetCorrelationId(meta)) == "" {
+		messageHeader.SetCorrelationId(meta, uuid.NewV4().String())
+	}
+
+	return meta
+}
+
+func copyMetadata(meta metadata.Metadata) metadata.Metadata {
+	result := metadata.New()
+	for key, value := range meta {
+		result.Set(key, value)
+	}
+
+	return result
+}
@@ -0,0 +1,125 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	*types.Message
+	OrderId string
+}
+
+func newOrderCreated(orderId string) *orderCreated {
+	return &orderCreated{Message: types.NewMessage(uuid.NewV4().String()), OrderId: orderId}
+}
+
+// recordingHandler envia al canal los mensajes que recibe
+type recordingHandler struct {
+	received chan types.IMessage
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{received: make(chan types.IMessage, 10)}
+}
+
+func (h *recordingHandler) Handle(_ context.Context, consumeContext types.MessageConsumeContext) error {
+	h.received <- consumeContext.Message()
+
+	return nil
+}
+
+func (h *recordingHandler) next(t *testing.T) types.IMessage {
+	t.Helper()
+
+	select {
+	case message := <-h.received:
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("the message was not delivered")
+
+		return nil
+	}
+}
+
+// foreignConsumer es un consumer de otro transporte, el bus en memoria no puede entregarle mensajes
+type foreignConsumer struct{}
+
+func (foreignConsumer) Start(context.Context) error                { return nil }
+func (foreignConsumer) Stop() error                                { return nil }
+func (foreignConsumer) ConnectionHandler(consumer.ConsumerHandler) {}
+func (foreignConsumer) IsConsumed(func(message types.IMessage))    {}
+func (foreignConsumer) GetName() string                            { return "foreign_consumer" }
+
+func newTestBus(t *testing.T) InMemoryBus {
+	t.Helper()
+
+	bus, err := NewInMemoryBus(
+		defaultlogger.GetLogger(),
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		nil,
+	)
+	require.NoError(t, err)
+
+	return bus
+}
+
+func Test_PublishMessage_Delivers_To_Consumer_Handler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := newTestBus(t)
+	handler := newRecordingHandler()
+	require.NoError(t, bus.ConnectConsumerHandler(&orderCreated{}, handler))
+	require.NoError(t, bus.Start(ctx))
+	defer bus.Stop()
+
+	require.NoError(t, bus.PublishMessage(ctx, newOrderCreated("order-1")))
+
+	message, ok := handler.next(t).(*orderCreated)
+	require.True(t, ok)
+	assert.Equal(t, "order-1", message.OrderId)
+}
+
+func Test_ConnectConsumer_Delivers_To_Connected_InMemory_Consumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := newTestBus(t)
+	handler := newRecordingHandler()
+	configuration := NewDefaultInMemoryConsumerConfiguration(&orderCreated{})
+	configuration.Handlers = []consumer.ConsumerHandler{handler}
+	c := newInMemoryConsumer(
+		configuration,
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		defaultlogger.GetLogger(),
+	)
+
+	require.NoError(t, bus.ConnectConsumer(&orderCreated{}, c))
+	require.NoError(t, bus.Start(ctx))
+	defer bus.Stop()
+
+	require.NoError(t, bus.PublishMessage(ctx, newOrderCreated("order-2")))
+
+	message, ok := handler.next(t).(*orderCreated)
+	require.True(t, ok)
+	assert.Equal(t, "order-2", message.OrderId)
+}
+
+func Test_ConnectConsumer_Rejects_Consumers_Of_Other_Transports(t *testing.T) {
+	bus := newTestBus(t)
+
+	err := bus.ConnectConsumer(&orderCreated{}, foreignConsumer{})
+
+	assert.ErrorContains(t, err, "foreign_consumer")
+}
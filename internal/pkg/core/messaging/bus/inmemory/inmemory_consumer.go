@@ -0,0 +1,233 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+)
+
+// delivery is a serialized message waiting in the buffer of a consumer
+type delivery struct {
+	body          []byte
+	contentType   string
+	messageType   string
+	messageId     string
+	correlationId string
+	created       time.Time
+	meta          metadata.Metadata
+}
+
+type inMemoryConsumer struct {
+	configuration           *InMemoryConsumerConfiguration
+	messageSerializer       serializer.MessageSerializer
+	logger                  logger.Logger
+	deliveries              chan *delivery
+	handlers                []consumer.ConsumerHandler
+	handlersLock            sync.RWMutex
+	isConsumedNotifications []func(message types.IMessage)
+	deliveryTag             uint64
+	cancel                  context.CancelFunc
+	wg                      sync.WaitGroup
+}
+
+func newInMemoryConsumer(
+	configuration *InMemoryConsumerConfiguration,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+	isConsumedNotifications ...func(message types.IMessage),
+) *inMemoryConsumer {
+	if configuration.ConcurrencyLimit <= 0 {
+		configuration.ConcurrencyLimit = defaultConcurrencyLimit
+	}
+	if configuration.BufferSize <= 0 {
+		configuration.BufferSize = defaultBufferSize
+	}
+
+	// the message is deserialized with the consumer message type like the rabbitmq consumers
+	typemapper.RegisterType(reflect.PointerTo(configuration.ConsumerMessageType))
+
+	return &inMemoryConsumer{
+		configuration:           configuration,
+		messageSerializer:       messageSerializer,
+		logger:                  logger,
+		deliveries:              make(chan *delivery, configuration.BufferSize),
+		handlers:                configuration.Handlers,
+		isConsumedNotifications: isConsumedNotifications,
+	}
+}
+
+func (c *inMemoryConsumer) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	for i := 0; i < c.configuration.ConcurrencyLimit; i++ {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-c.deliveries:
+					c.handle(ctx, d)
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (c *inMemoryConsumer) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+
+	return nil
+}
+
+func (c *inMemoryConsumer) ConnectionHandler(handler consumer.ConsumerHandler) {
+	c.handlersLock.Lock()
+	defer c.handlersLock.Unlock()
+
+	c.handlers = append(c.handlers, handler)
+}
+
+func (c *inMemoryConsumer) IsConsumed(h func(message types.IMessage)) {
+	c.isConsumedNotifications = append(c.isConsumedNotifications, h)
+}
+
+func (c *inMemoryConsumer) GetName() string {
+	return c.configuration.Name
+}
+
+// matches checks if the consumer is bound to the exchange with a routing key that matches the topic pattern of the consumer
+func (c *inMemoryConsumer) matches(exchange string, routingKey string) bool {
+	if c.configuration.ExchangeName != exchange {
+		return false
+	}
+
+	return matchRoutingKey(
+		strings.Split(c.configuration.RoutingKey, "."),
+		strings.Split(routingKey, "."),
+	)
+}
+
+// enqueue adds the delivery to the buffer of the consumer, it blocks while the buffer is full
+func (c *inMemoryConsumer) enqueue(ctx context.Context, d *delivery) error {
+	select {
+	case c.deliveries <- d:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *inMemoryConsumer) handle(ctx context.Context, d *delivery) {
+	messageTypeName := typemapper.GetTypeNameByType(reflect.PointerTo(c.configuration.ConsumerMessageType))
+	message, err := c.messageSerializer.Deserialize(d.body, messageTypeName, d.contentType)
+	if err != nil {
+		c.logger.Errorf("[inMemoryConsumer.handle] error in deserializing message with id `%s`: %v", d.messageId, err)
+		return
+	}
+
+	consumeContext := types.NewMessageConsumeContext(
+		message,
+		d.meta,
+		d.contentType,
+		d.messageType,
+		d.created,
+		atomic.AddUint64(&c.deliveryTag, 1),
+		d.messageId,
+		d.correlationId,
+		d.body,
+	)
+
+	c.handlersLock.RLock()
+	handlers := c.handlers
+	c.handlersLock.RUnlock()
+
+	for _, handler := range handlers {
+		ctx := consumer.ContextWithConsumeInfo(
+			ctx,
+			consumer.ConsumeInfo{ConsumerName: c.configuration.Name, HandlerName: consumer.GetHandlerName(handler)},
+		)
+		if err := c.runHandler(ctx, handler, consumeContext); err != nil {
+			c.logger.Errorf(
+				"[inMemoryConsumer.handle] error in handling message with id `%s` in consumer `%s`: %v",
+				d.messageId,
+				c.configuration.Name,
+				err,
+			)
+			return
+		}
+	}
+
+	for _, notification := range c.isConsumedNotifications {
+		if notification != nil {
+			notification(message)
+		}
+	}
+}
+
+// runHandler runs the handler wrapped by the pipelines, the first pipeline is the outermost
+func (c *inMemoryConsumer) runHandler(
+	ctx context.Context,
+	handler consumer.ConsumerHandler,
+	consumeContext types.MessageConsumeContext,
+) error {
+	var next pipeline.ConsumerHandlerFunc = func(ctx context.Context) error {
+		return handler.Handle(ctx, consumeContext)
+	}
+
+	for i := len(c.configuration.Pipelines) - 1; i >= 0; i-- {
+		pipe := c.configuration.Pipelines[i]
+		nextValue := next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, consumeContext, nextValue)
+		}
+	}
+
+	if err := next(ctx); err != nil {
+		return errors.Wrap(err, "error handling consumer handlers pipeline")
+	}
+
+	return nil
+}
+
+// matchRoutingKey matches the words of a routing key with the words of a topic pattern,
+// `*` matches exactly one word and `#` matches zero or more words
+func matchRoutingKey(pattern []string, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchRoutingKey(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+
+		return false
+	case "*":
+		return len(words) > 0 && matchRoutingKey(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && words[0] == pattern[0] && matchRoutingKey(pattern[1:], words[1:])
+	}
+}
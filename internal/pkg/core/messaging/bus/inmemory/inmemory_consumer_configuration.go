@@ -0,0 +1,136 @@
+package inmemory
+
+import (
+	"fmt"
+	"reflect"
+
+	messageConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+)
+
+const (
+	defaultConcurrencyLimit = 1
+	defaultBufferSize       = 256
+)
+
+// InMemoryConsumerConfiguration usa las mismas convenciones de exchange y routing key que los consumers de rabbitmq
+type InMemoryConsumerConfiguration struct {
+	Name                string
+	ConsumerMessageType reflect.Type
+	ExchangeName        string
+	RoutingKey          string // admite los comodines `*` y `#` de los topic exchanges
+	ConcurrencyLimit    int    // numero de goroutines que procesan los mensajes del consumer
+	BufferSize          int    // numero de mensajes pendientes antes de bloquear al producer
+	Pipelines           []pipeline.ConsumerPipeline
+	Handlers            []messageConsumer.ConsumerHandler
+}
+
+type InMemoryConsumerConfigurationBuilderFuc func(builder InMemoryConsumerConfigurationBuilder)
+
+type InMemoryConsumerConfigurationBuilder interface {
+	WithHandlers(
+		consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc,
+	) InMemoryConsumerConfigurationBuilder
+	WithPipelines(
+		pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc,
+	) InMemoryConsumerConfigurationBuilder
+	WithName(name string) InMemoryConsumerConfigurationBuilder
+	WithExchangeName(exchangeName string) InMemoryConsumerConfigurationBuilder
+	WithRoutingKey(routingKey string) InMemoryConsumerConfigurationBuilder
+	WithConcurrencyLimit(limit int) InMemoryConsumerConfigurationBuilder
+	WithBufferSize(size int) InMemoryConsumerConfigurationBuilder
+	Build() *InMemoryConsumerConfiguration
+}
+
+type inMemoryConsumerConfigurationBuilder struct {
+	consumerConfiguration *InMemoryConsumerConfiguration
+	pipelinesBuilder      pipeline.ConsumerPipelineConfigurationBuilder
+	handlersBuilder       messageConsumer.ConsumerHandlerConfigurationBuilder
+}
+
+func NewDefaultInMemoryConsumerConfiguration(messageType types.IMessage) *InMemoryConsumerConfiguration {
+	return &InMemoryConsumerConfiguration{
+		Name:                fmt.Sprintf("%s_consumer", utils.GetMessageName(messageType)),
+		ConsumerMessageType: utils.GetMessageBaseReflectType(messageType),
+		ExchangeName:        utils.GetTopicOrExchangeName(messageType),
+		RoutingKey:          utils.GetRoutingKey(messageType),
+		ConcurrencyLimit:    defaultConcurrencyLimit,
+		BufferSize:          defaultBufferSize,
+	}
+}
+
+func NewInMemoryConsumerConfigurationBuilder(messageType types.IMessage) InMemoryConsumerConfigurationBuilder {
+	return &inMemoryConsumerConfigurationBuilder{
+		consumerConfiguration: NewDefaultInMemoryConsumerConfiguration(messageType),
+	}
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithHandlers(
+	consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc,
+) InMemoryConsumerConfigurationBuilder {
+	builder := messageConsumer.NewConsumerHandlersConfigurationBuilder()
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+	b.handlersBuilder = builder
+
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc,
+) InMemoryConsumerConfigurationBuilder {
+	builder := pipeline.NewConsumerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.pipelinesBuilder = builder
+
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithName(name string) InMemoryConsumerConfigurationBuilder {
+	b.consumerConfiguration.Name = name
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithExchangeName(
+	exchangeName string,
+) InMemoryConsumerConfigurationBuilder {
+	b.consumerConfiguration.ExchangeName = exchangeName
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithRoutingKey(
+	routingKey string,
+) InMemoryConsumerConfigurationBuilder {
+	b.consumerConfiguration.RoutingKey = routingKey
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithConcurrencyLimit(
+	limit int,
+) InMemoryConsumerConfigurationBuilder {
+	b.consumerConfiguration.ConcurrencyLimit = limit
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) WithBufferSize(
+	size int,
+) InMemoryConsumerConfigurationBuilder {
+	b.consumerConfiguration.BufferSize = size
+	return b
+}
+
+func (b *inMemoryConsumerConfigurationBuilder) Build() *InMemoryConsumerConfiguration {
+	if b.pipelinesBuilder != nil {
+		b.consumerConfiguration.Pipelines = b.pipelinesBuilder.Build().Pipelines
+	}
+	if b.handlersBuilder != nil {
+		b.consumerConfiguration.Handlers = b.handlersBuilder.Build().Handlers
+	}
+
+	return b.consumerConfiguration
+}
@@ -0,0 +1,59 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// Module provided to fxlog, it replaces `rabbitmq.ModuleFunc` in tests and local runs without a broker
+	// https://uber-go.github.io/fx/modules.html
+	Module = fx.Module( //nolint:gochecknoglobals
+		"inmemorybusfx",
+		inMemoryBusProviders,
+		inMemoryBusInvokes,
+	)
+
+	inMemoryBusProviders = fx.Options( //nolint:gochecknoglobals
+		fx.Provide(fx.Annotate(
+			NewInMemoryBus,
+			fx.ParamTags(``, ``, `optional:"true"`),
+			fx.As(new(producer.Producer)),
+			fx.As(new(bus.Bus)),
+			fx.As(new(InMemoryBus)),
+		)),
+	)
+
+	inMemoryBusInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
+)
+
+func registerHooks(
+	lc fx.Lifecycle,
+	inMemoryBus InMemoryBus,
+	logger logger.Logger,
+) {
+	lifetimeCtx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// the OnStart ctx has a short timeout, the consumers need a context which is alive during the whole app
+			if err := inMemoryBus.Start(lifetimeCtx); err != nil {
+				logger.Errorf("(inMemoryBus.Start) error in running in-memory bus: {%v}", err)
+				return err
+			}
+			logger.Info("in-memory bus is listening.")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			return inMemoryBus.Stop()
+		},
+	})
+}
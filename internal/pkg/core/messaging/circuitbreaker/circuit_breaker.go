@@ -0,0 +1,226 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// ErrCircuitOpen lo devuelve el pipeline a los mensajes que llegan con el circuito abierto, ej: los que el consumer
+// recibio antes de pausarse
+var ErrCircuitOpen = errors.Sentinel("circuit breaker is open")
+
+// IsCircuitOpen indica que el mensaje fallo por el circuito abierto, los consumers no deben reintentarlo en el `RetryIf`
+// porque cada reintento falla igual hasta que el circuito se cierre
+func IsCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
+
+// circuitBreaker abre el circuito despues de `FailureThreshold` fallos consecutivos y pausa los consumers que lo usan,
+// despues de `OpenDuration` prueba la dependencia y reanuda los consumers si la prueba es exitosa.
+// Sin `Probe` el circuito queda medio abierto y solo un mensaje a la vez prueba la dependencia, los demas mensajes
+// que el consumer recibe con su prefetch esperan el resultado de la prueba.
+// Los consumers que no se pueden pausar siguen recibiendo mensajes y fallan con `ErrCircuitOpen` mientras el circuito esta abierto
+type circuitBreaker struct {
+	name      string
+	options   *CircuitBreakerOptions
+	logger    logger.Logger
+	lock      sync.Mutex
+	state     State
+	failures  int
+	consumers []consumer.PausableConsumer
+	timer     *time.Timer
+	trialDone chan struct{} // not nil while the half open trial message is in flight, it is closed when the trial ends
+}
+
+func newCircuitBreaker(name string, options *CircuitBreakerOptions, logger logger.Logger) *circuitBreaker {
+	return &circuitBreaker{
+		name:    name,
+		options: options.withDefaults(),
+		logger:  logger,
+		state:   StateClosed,
+	}
+}
+
+func (c *circuitBreaker) Handle(
+	ctx context.Context,
+	consumerContext types.MessageConsumeContext,
+	next pipeline.ConsumerHandlerFunc,
+) error {
+	info, _ := consumer.ConsumeInfoFromContext(ctx)
+
+	trial, err := c.allow(ctx, info.Consumer)
+	if err != nil {
+		return errors.WithMessagef(err, "message with id `%s` of consumer `%s`", consumerContext.MessageId(), c.name)
+	}
+
+	err = next(ctx)
+
+	c.record(err, trial)
+
+	return err
+}
+
+func (c *circuitBreaker) State() State {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.state
+}
+
+// allow registra el consumer del mensaje para poder pausarlo, y rechaza el mensaje si el circuito esta abierto.
+// Con el circuito medio abierto el primer mensaje es la prueba y los demas esperan a que termine, `trial` indica si
+// el mensaje es la prueba
+func (c *circuitBreaker) allow(ctx context.Context, pausable consumer.PausableConsumer) (trial bool, err error) {
+	c.lock.Lock()
+
+	if pausable != nil && !c.hasConsumer(pausable) {
+		c.consumers = append(c.consumers, pausable)
+	}
+
+	for {
+		switch {
+		case c.state == StateOpen:
+			c.lock.Unlock()
+			return false, ErrCircuitOpen
+		case c.state == StateHalfOpen && c.trialDone == nil:
+			c.trialDone = make(chan struct{})
+			c.lock.Unlock()
+			return true, nil
+		case c.state == StateHalfOpen:
+			trialDone := c.trialDone
+			c.lock.Unlock()
+
+			select {
+			case <-trialDone:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+
+			c.lock.Lock()
+		default:
+			c.lock.Unlock()
+			return false, nil
+		}
+	}
+}
+
+func (c *circuitBreaker) record(err error, trial bool) {
+	c.lock.Lock()
+
+	// the messages waiting for the trial check the new state when the lock is released
+	if trial && c.trialDone != nil {
+		close(c.trialDone)
+		c.trialDone = nil
+	}
+
+	if err != nil && c.options.IsFailure(err) {
+		c.failures++
+		// in half open the first failure opens the circuit again
+		if c.state == StateOpen || (c.state == StateClosed && c.failures < c.options.FailureThreshold) {
+			c.lock.Unlock()
+			return
+		}
+
+		c.state = StateOpen
+		c.failures = 0
+		c.timer = time.AfterFunc(c.options.OpenDuration, c.probe)
+		consumers := c.consumers
+		c.lock.Unlock()
+
+		c.logger.Errorf("circuit breaker of consumer `%s` is open, the consumer is paused: %v", c.name, err)
+		for _, pausable := range consumers {
+			if err := pausable.Pause(); err != nil {
+				c.logger.Errorf("error in pausing consumer `%s`: %v", c.name, err)
+			}
+		}
+
+		return
+	}
+
+	// the errors that are not a failure of the dependency don't reset the consecutive failures
+	if err == nil {
+		c.failures = 0
+		if c.state == StateHalfOpen {
+			c.state = StateClosed
+			c.logger.Infof("circuit breaker of consumer `%s` is closed", c.name)
+		}
+	}
+
+	c.lock.Unlock()
+}
+
+// probe prueba la dependencia con el circuito abierto, si falla espera `OpenDuration` para volver a probarla
+func (c *circuitBreaker) probe() {
+	next := StateHalfOpen
+	if c.options.Probe != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.options.OpenDuration)
+		err := c.options.Probe(ctx)
+		cancel()
+
+		if err != nil {
+			c.logger.Warnf("circuit breaker probe of consumer `%s` failed: %v", c.name, err)
+
+			c.lock.Lock()
+			c.timer = time.AfterFunc(c.options.OpenDuration, c.probe)
+			c.lock.Unlock()
+
+			return
+		}
+
+		// the successful probe is the half open trial, so the circuit is closed
+		next = StateClosed
+	}
+
+	c.lock.Lock()
+	if c.state != StateOpen {
+		c.lock.Unlock()
+		return
+	}
+	c.state = next
+	c.timer = nil
+	consumers := c.consumers
+	c.lock.Unlock()
+
+	c.logger.Infof("circuit breaker of consumer `%s` is %s, the consumer is resumed", c.name, next)
+	for _, pausable := range consumers {
+		if err := pausable.Resume(); err != nil {
+			c.logger.Errorf("error in resuming consumer `%s`: %v", c.name, err)
+		}
+	}
+}
+
+func (c *circuitBreaker) stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
+func (c *circuitBreaker) hasConsumer(pausable consumer.PausableConsumer) bool {
+	for _, registered := range c.consumers {
+		if registered == pausable {
+			return true
+		}
+	}
+
+	return false
+}
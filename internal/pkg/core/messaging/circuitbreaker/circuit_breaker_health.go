@@ -0,0 +1,43 @@
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+)
+
+// circuitBreakersHealthChecker reporta el estado del circuit breaker de cada consumer, un circuito abierto o
+// semiabierto deja al servicio degradado (falla readiness) pero no lo marca como caido
+type circuitBreakersHealthChecker struct {
+	registry CircuitBreakerRegistry
+}
+
+func NewCircuitBreakersHealthChecker(registry CircuitBreakerRegistry) contracts.Health {
+	return &circuitBreakersHealthChecker{registry: registry}
+}
+
+// CheckHealth no falla con los circuitos abiertos, el estado de cada breaker se reporta en CheckStatuses
+func (c *circuitBreakersHealthChecker) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+func (c *circuitBreakersHealthChecker) CheckStatuses(ctx context.Context) contracts.Check {
+	check := contracts.Check{}
+
+	for name, state := range c.registry.States() {
+		switch state {
+		case StateOpen:
+			check[name] = contracts.Status{Status: contracts.StatusOpen}
+		case StateHalfOpen:
+			check[name] = contracts.Status{Status: contracts.StatusHalfOpen}
+		default:
+			check[name] = contracts.Status{Status: contracts.StatusUp}
+		}
+	}
+
+	return check
+}
+
+func (c *circuitBreakersHealthChecker) GetHealthName() string {
+	return "circuit_breakers"
+}
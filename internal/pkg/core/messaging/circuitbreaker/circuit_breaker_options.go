@@ -0,0 +1,54 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+)
+
+// CircuitBreakerOptions son los umbrales del circuit breaker de un consumer
+type CircuitBreakerOptions struct {
+	// FailureThreshold es el numero de fallos consecutivos que abren el circuito
+	FailureThreshold int `mapstructure:"failureThreshold"`
+	// OpenDuration es la espera con el circuito abierto antes de probar la dependencia, y entre las pruebas fallidas
+	OpenDuration time.Duration `mapstructure:"openDuration"`
+	// Probe comprueba la dependencia antes de reanudar el consumer, sin probe el siguiente mensaje es la prueba
+	// y los demas mensajes esperan su resultado
+	Probe func(ctx context.Context) error `mapstructure:"-"`
+	// IsFailure decide que errores de los handlers cuentan como una caida de la dependencia,
+	// por defecto todos menos los errores de validacion que no dependen de ella
+	IsFailure func(err error) bool `mapstructure:"-"`
+}
+
+func NewDefaultCircuitBreakerOptions() *CircuitBreakerOptions {
+	return &CircuitBreakerOptions{
+		FailureThreshold: defaultFailureThreshold,
+		OpenDuration:     defaultOpenDuration,
+		IsFailure:        isDependencyFailure,
+	}
+}
+
+func (o *CircuitBreakerOptions) withDefaults() *CircuitBreakerOptions {
+	options := *o
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = defaultFailureThreshold
+	}
+	if options.OpenDuration <= 0 {
+		options.OpenDuration = defaultOpenDuration
+	}
+	if options.IsFailure == nil {
+		options.IsFailure = isDependencyFailure
+	}
+
+	return &options
+}
+
+func isDependencyFailure(err error) bool {
+	return err != nil && !customErrors.IsValidationError(err)
+}
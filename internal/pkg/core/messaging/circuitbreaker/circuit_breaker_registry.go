@@ -0,0 +1,113 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/constants/telemetrytags"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/metrics"
+
+	"emperror.dev/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CircuitBreakerRegistry crea los circuit breakers de los consumers y expone su estado para el health check y las metricas
+type CircuitBreakerRegistry interface {
+	// NewCircuitBreakerPipeline devuelve el pipeline del circuit breaker del consumer `consumerName`,
+	// los consumers con el mismo nombre comparten el circuit breaker
+	NewCircuitBreakerPipeline(consumerName string, options *CircuitBreakerOptions) pipeline.ConsumerPipeline
+	States() map[string]State
+	// Stop cancela las pruebas pendientes de los circuitos abiertos
+	Stop()
+}
+
+type circuitBreakerRegistry struct {
+	logger   logger.Logger
+	lock     sync.RWMutex
+	breakers map[string]*circuitBreaker
+}
+
+// NewCircuitBreakerRegistry registra la metrica del estado de los circuit breakers si `appMetrics` no es nil
+func NewCircuitBreakerRegistry(logger logger.Logger, appMetrics metrics.AppMetrics) (CircuitBreakerRegistry, error) {
+	registry := &circuitBreakerRegistry{
+		logger:   logger,
+		breakers: make(map[string]*circuitBreaker),
+	}
+
+	if appMetrics == nil {
+		return registry, nil
+	}
+
+	_, err := appMetrics.Int64ObservableGauge(
+		"messaging.consumer.circuit_breaker.state",
+		metric.WithUnit("state"),
+		metric.WithDescription("The state of the consumer circuit breakers, 0 closed, 1 half open and 2 open"),
+		metric.WithInt64Callback(registry.observeStates),
+	)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in creating the circuit breaker state gauge")
+	}
+
+	return registry, nil
+}
+
+func (r *circuitBreakerRegistry) NewCircuitBreakerPipeline(
+	consumerName string,
+	options *CircuitBreakerOptions,
+) pipeline.ConsumerPipeline {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if breaker, ok := r.breakers[consumerName]; ok {
+		return breaker
+	}
+
+	if options == nil {
+		options = NewDefaultCircuitBreakerOptions()
+	}
+
+	breaker := newCircuitBreaker(consumerName, options, r.logger)
+	r.breakers[consumerName] = breaker
+
+	return breaker
+}
+
+func (r *circuitBreakerRegistry) States() map[string]State {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	states := make(map[string]State, len(r.breakers))
+	for name, breaker := range r.breakers {
+		states[name] = breaker.State()
+	}
+
+	return states
+}
+
+func (r *circuitBreakerRegistry) Stop() {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, breaker := range r.breakers {
+		breaker.stop()
+	}
+}
+
+func (r *circuitBreakerRegistry) observeStates(_ context.Context, observer metric.Int64Observer) error {
+	for name, state := range r.States() {
+		var value int64
+		switch state {
+		case StateHalfOpen:
+			value = 1
+		case StateOpen:
+			value = 2
+		}
+
+		observer.Observe(value, metric.WithAttributes(attribute.String(telemetrytags.App.Consumer, name)))
+	}
+
+	return nil
+}
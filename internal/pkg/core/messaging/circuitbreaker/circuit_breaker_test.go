@@ -0,0 +1,190 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConsumeContext() types.MessageConsumeContext {
+	message := types.NewMessage(uuid.NewV4().String())
+
+	return types.NewMessageConsumeContext(
+		message,
+		metadata.Metadata{},
+		"application/json",
+		"testMessage",
+		time.Now(),
+		1,
+		message.GeMessageId(),
+		"",
+		nil,
+	)
+}
+
+// openCircuit hace fallar el handler hasta abrir el circuito, la prueba de la dependencia queda lejos en el tiempo
+func openCircuit(t *testing.T, registry CircuitBreakerRegistry, consumerName string) *circuitBreaker {
+	t.Helper()
+
+	breaker := registry.NewCircuitBreakerPipeline(
+		consumerName,
+		&CircuitBreakerOptions{FailureThreshold: 2, OpenDuration: time.Hour},
+	).(*circuitBreaker)
+	t.Cleanup(registry.Stop)
+
+	failing := func(context.Context) error { return errors.New("dependency is down") }
+	for i := 0; i < 2; i++ {
+		require.Error(t, breaker.Handle(context.Background(), newTestConsumeContext(), failing))
+	}
+	require.Equal(t, StateOpen, breaker.State())
+
+	return breaker
+}
+
+func Test_Handle_Rejects_Messages_With_The_Circuit_Open(t *testing.T) {
+	registry, err := NewCircuitBreakerRegistry(defaultlogger.GetLogger(), nil)
+	require.NoError(t, err)
+	breaker := openCircuit(t, registry, "products_consumer")
+
+	called := false
+	err = breaker.Handle(context.Background(), newTestConsumeContext(), func(context.Context) error {
+		called = true
+
+		return nil
+	})
+
+	assert.False(t, called)
+	assert.True(t, IsCircuitOpen(err))
+}
+
+func Test_IsCircuitOpen(t *testing.T) {
+	assert.True(t, IsCircuitOpen(errors.WithMessage(ErrCircuitOpen, "message of consumer")))
+	assert.False(t, IsCircuitOpen(errors.New("dependency is down")))
+	assert.False(t, IsCircuitOpen(nil))
+}
+
+func Test_HealthChecker_Reports_Open_Circuit_As_Degraded(t *testing.T) {
+	registry, err := NewCircuitBreakerRegistry(defaultlogger.GetLogger(), nil)
+	require.NoError(t, err)
+	registry.NewCircuitBreakerPipeline("orders_consumer", nil)
+	openCircuit(t, registry, "products_consumer")
+
+	checker := NewCircuitBreakersHealthChecker(registry).(contracts.StatusesHealth)
+	check := checker.CheckStatuses(context.Background())
+
+	assert.Equal(t, contracts.Check{
+		"orders_consumer":   contracts.Status{Status: contracts.StatusUp},
+		"products_consumer": contracts.Status{Status: contracts.StatusOpen},
+	}, check)
+	// el circuito abierto falla readiness pero no liveness
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+	assert.False(t, check.AllUp())
+	assert.True(t, check.AllAlive())
+}
+
+// halfOpenCircuit abre el circuito sin probe y espera a que pase a medio abierto
+func halfOpenCircuit(t *testing.T) *circuitBreaker {
+	t.Helper()
+
+	registry, err := NewCircuitBreakerRegistry(defaultlogger.GetLogger(), nil)
+	require.NoError(t, err)
+	t.Cleanup(registry.Stop)
+
+	breaker := registry.NewCircuitBreakerPipeline(
+		"products_consumer",
+		&CircuitBreakerOptions{FailureThreshold: 1, OpenDuration: 10 * time.Millisecond},
+	).(*circuitBreaker)
+
+	failing := func(context.Context) error { return errors.New("dependency is down") }
+	require.Error(t, breaker.Handle(context.Background(), newTestConsumeContext(), failing))
+	require.Eventually(t, func() bool { return breaker.State() == StateHalfOpen }, time.Second, time.Millisecond)
+
+	return breaker
+}
+
+// startTrial empieza el mensaje de prueba del circuito medio abierto, el mensaje termina con el error que recibe `result`
+func startTrial(t *testing.T, breaker *circuitBreaker) (result chan error, done chan error) {
+	t.Helper()
+
+	started := make(chan struct{})
+	result = make(chan error)
+	done = make(chan error, 1)
+	go func() {
+		done <- breaker.Handle(context.Background(), newTestConsumeContext(), func(context.Context) error {
+			close(started)
+
+			return <-result
+		})
+	}()
+	<-started
+
+	return result, done
+}
+
+func Test_Handle_Allows_A_Single_Trial_Message_In_Half_Open(t *testing.T) {
+	breaker := halfOpenCircuit(t)
+	trialResult, trialDone := startTrial(t, breaker)
+
+	var waitingCalled atomic.Bool
+	waitingDone := make(chan error, 1)
+	go func() {
+		waitingDone <- breaker.Handle(context.Background(), newTestConsumeContext(), func(context.Context) error {
+			waitingCalled.Store(true)
+
+			return nil
+		})
+	}()
+
+	// the other messages wait while the trial is in flight
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, waitingCalled.Load())
+
+	trialResult <- nil
+	require.NoError(t, <-trialDone)
+	require.NoError(t, <-waitingDone)
+
+	assert.True(t, waitingCalled.Load())
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func Test_Handle_Rejects_The_Waiting_Messages_When_The_Trial_Fails(t *testing.T) {
+	breaker := halfOpenCircuit(t)
+	trialResult, trialDone := startTrial(t, breaker)
+
+	waitingDone := make(chan error, 1)
+	go func() {
+		waitingDone <- breaker.Handle(context.Background(), newTestConsumeContext(), func(context.Context) error {
+			return nil
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	trialResult <- errors.New("dependency is still down")
+	require.Error(t, <-trialDone)
+
+	assert.True(t, IsCircuitOpen(<-waitingDone))
+}
+
+func Test_Handle_Stops_Waiting_For_The_Trial_When_The_Context_Is_Done(t *testing.T) {
+	breaker := halfOpenCircuit(t)
+	trialResult, trialDone := startTrial(t, breaker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := breaker.Handle(ctx, newTestConsumeContext(), func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	trialResult <- nil
+	require.NoError(t, <-trialDone)
+}
@@ -0,0 +1,35 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+)
+
+type consumeInfoKey struct{}
+
+// ConsumeInfo describe la ejecucion actual del handler, la agregan los consumers al contexto de los pipelines
+type ConsumeInfo struct {
+	ConsumerName string
+	// HandlerName es el nombre del tipo del handler que se ejecuta, distingue a los handlers de un mismo consumer
+	HandlerName string
+	// Attempt es el numero de intentos anteriores del mensaje, cero en la primera entrega
+	Attempt uint
+	// Consumer permite pausar el consumer que ejecuta el handler, es nil si el consumer no se puede pausar
+	Consumer PausableConsumer
+}
+
+func ContextWithConsumeInfo(ctx context.Context, info ConsumeInfo) context.Context {
+	return context.WithValue(ctx, consumeInfoKey{}, info)
+}
+
+func ConsumeInfoFromContext(ctx context.Context) (ConsumeInfo, bool) {
+	info, ok := ctx.Value(consumeInfoKey{}).(ConsumeInfo)
+
+	return info, ok
+}
+
+// GetHandlerName devuelve el nombre con el que se identifica al handler en el `ConsumeInfo`
+func GetHandlerName(handler ConsumerHandler) string {
+	return typemapper.GetFullTypeName(handler)
+}
@@ -8,3 +8,10 @@ type ConsumerConnector interface {
 	// ConnectConsumer Add a new consumer to existing message type consumers. if there is no consumer, will create a new consumer for the message type
 	ConnectConsumer(messageType types.IMessage, consumer Consumer) error
 }
+
+// ReplyConsumerConnector conecta handlers a una direccion de respuesta propia de la instancia, la usa request/reply
+// para que las respuestas lleguen a la instancia que envio el request
+type ReplyConsumerConnector interface {
+	// ConnectReplyConsumerHandler creates a consumer for the message type bound to the `replyTo` address, it should be called before starting the bus
+	ConnectReplyConsumerHandler(messageType types.IMessage, replyTo string, consumerHandler ConsumerHandler) error
+}
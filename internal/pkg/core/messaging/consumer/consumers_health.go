@@ -0,0 +1,58 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+
+	"emperror.dev/errors"
+)
+
+// DrainStatesReporter lo implementan los buses que reportan el drenado de cada uno de sus consumers por su nombre
+type DrainStatesReporter interface {
+	DrainStates() map[string]DrainState
+}
+
+// consumersHealthChecker reporta el drenado de cada consumer de un bus, un preStop hook puede esperar
+// hasta que ningun consumer del endpoint de health este `draining`
+type consumersHealthChecker struct {
+	reporter DrainStatesReporter
+	name     string
+}
+
+// NewConsumersHealthChecker crea el health de los consumers del bus con el nombre `name`, la conexion del transporte
+// la reporta su propio health
+func NewConsumersHealthChecker(reporter DrainStatesReporter, name string) contracts.StatusesHealth {
+	return &consumersHealthChecker{reporter: reporter, name: name}
+}
+
+func (c *consumersHealthChecker) CheckHealth(ctx context.Context) error {
+	for name, state := range c.reporter.DrainStates() {
+		if state != DrainStateRunning {
+			return errors.Errorf("consumer `%s` is %s", name, state)
+		}
+	}
+
+	return nil
+}
+
+func (c *consumersHealthChecker) CheckStatuses(ctx context.Context) contracts.Check {
+	check := contracts.Check{}
+
+	for name, state := range c.reporter.DrainStates() {
+		switch state {
+		case DrainStateDraining:
+			check[name] = contracts.Status{Status: contracts.StatusDraining}
+		case DrainStateDrained:
+			check[name] = contracts.Status{Status: contracts.StatusDrained}
+		default:
+			check[name] = contracts.Status{Status: contracts.StatusUp}
+		}
+	}
+
+	return check
+}
+
+func (c *consumersHealthChecker) GetHealthName() string {
+	return c.name
+}
@@ -0,0 +1,38 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type drainStates map[string]DrainState
+
+func (d drainStates) DrainStates() map[string]DrainState {
+	return d
+}
+
+func Test_ConsumersHealthChecker_Reports_The_Drain_State_Of_Each_Consumer(t *testing.T) {
+	checker := NewConsumersHealthChecker(drainStates{
+		"orders_consumer":   DrainStateRunning,
+		"products_consumer": DrainStateDraining,
+		"payments_consumer": DrainStateDrained,
+	}, "test_consumers")
+
+	assert.Equal(t, "test_consumers", checker.GetHealthName())
+	assert.Error(t, checker.CheckHealth(context.Background()))
+	assert.Equal(t, contracts.Check{
+		"orders_consumer":   contracts.Status{Status: contracts.StatusUp},
+		"products_consumer": contracts.Status{Status: contracts.StatusDraining},
+		"payments_consumer": contracts.Status{Status: contracts.StatusDrained},
+	}, checker.CheckStatuses(context.Background()))
+}
+
+func Test_ConsumersHealthChecker_Is_Healthy_While_All_Consumers_Run(t *testing.T) {
+	checker := NewConsumersHealthChecker(drainStates{"orders_consumer": DrainStateRunning}, "test_consumers")
+
+	assert.NoError(t, checker.CheckHealth(context.Background()))
+}
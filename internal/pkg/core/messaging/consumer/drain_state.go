@@ -0,0 +1,15 @@
+package consumer
+
+// DrainState es el estado del apagado de un consumer, los preStop hooks esperan a que todos esten `drained`
+type DrainState string
+
+const (
+	DrainStateRunning  DrainState = "running"
+	DrainStateDraining DrainState = "draining"
+	DrainStateDrained  DrainState = "drained"
+)
+
+// DrainStateReporter lo implementan los consumers que terminan sus mensajes en curso antes de detenerse
+type DrainStateReporter interface {
+	DrainState() DrainState
+}
@@ -0,0 +1,11 @@
+package consumer
+
+// PausableConsumer lo implementan los consumers que pueden dejar de recibir mensajes sin detenerse,
+// ej: mientras el circuit breaker de una dependencia esta abierto
+type PausableConsumer interface {
+	// Pause cancela la suscripcion a las queues, los mensajes en curso terminan normalmente
+	Pause() error
+	// Resume vuelve a suscribir el consumer a sus queues
+	Resume() error
+	IsPaused() bool
+}
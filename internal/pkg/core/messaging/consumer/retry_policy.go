@@ -0,0 +1,97 @@
+package consumer
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+)
+
+// RetryPolicy define cuantas veces y con que espera se reintenta un mensaje cuyo handler fallo
+type RetryPolicy struct {
+	MaxAttempts  uint          // numero total de intentos, incluyendo el primero
+	InitialDelay time.Duration // espera antes del primer reintento
+	MaxDelay     time.Duration // limite de la espera entre reintentos, 0 para no limitarla
+	Multiplier   float64       // factor por el que se multiplica la espera en cada reintento
+	Jitter       float64       // fraccion (0 a 1) de la espera que se suma o resta aleatoriamente para no sincronizar los reintentos
+	// NonRetryableErrors son los predicados de errores que no se reintentan, ej: `customErrors.IsValidationError`
+	NonRetryableErrors []func(err error) bool
+	// DelayedRedelivery re-envia el mensaje a una retry queue con TTL por cada espera en lugar de esperar en la goroutine del consumer,
+	// el jitter no aplica porque la espera la define el TTL de la queue. Solo lo soportan los transportes con retry queues, ej: rabbitmq
+	DelayedRedelivery bool
+}
+
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 300 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.1,
+		NonRetryableErrors: []func(err error) bool{
+			customErrors.IsValidationError,
+		},
+	}
+}
+
+// Delay devuelve la espera sin jitter antes del reintento `attempt`, el primer reintento es el 1
+func (p *RetryPolicy) Delay(attempt uint) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := time.Duration(float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay < 0) {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// DelayWithJitter devuelve la espera antes del reintento `attempt` con el jitter aplicado
+func (p *RetryPolicy) DelayWithJitter(attempt uint) time.Duration {
+	delay := p.Delay(attempt)
+	if p.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+
+	jitter := math.Min(p.Jitter, 1)
+	// random factor in the range [1-jitter, 1+jitter]
+	factor := 1 - jitter + rand.Float64()*2*jitter //nolint:gosec
+
+	return time.Duration(float64(delay) * factor)
+}
+
+// Delays devuelve las esperas distintas de todos los reintentos, se usan para declarar las retry queues
+func (p *RetryPolicy) Delays() []time.Duration {
+	var delays []time.Duration
+	for attempt := uint(1); attempt < p.MaxAttempts; attempt++ {
+		delay := p.Delay(attempt)
+		if len(delays) == 0 || delays[len(delays)-1] != delay {
+			delays = append(delays, delay)
+		}
+	}
+
+	return delays
+}
+
+// IsRetryable indica si el error puede reintentarse segun `NonRetryableErrors`
+func (p *RetryPolicy) IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, isNonRetryable := range p.NonRetryableErrors {
+		if isNonRetryable != nil && isNonRetryable(err) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -7,4 +7,13 @@ const (
 	Type          string = "type"
 	ContentType   string = "content-type"
 	Created       string = "created"
+	Scheduled     string = "scheduled"
+	ReplyTo       string = "reply-to"
+	PartitionKey  string = "partition-key"
+	// InReplyTo es la direccion reply-to del request que responde el mensaje
+	InReplyTo string = "in-reply-to"
+	// EncryptionKeyId es el id de la llave con la que se cifro y firmo el payload, permite rotar las llaves
+	EncryptionKeyId string = "encryption-key-id"
+	// RoutingKey reemplaza la routing key del tipo del mensaje al publicarlo, el producer no la envia como header
+	RoutingKey string = "routing-key"
 )
@@ -39,7 +39,7 @@ func SetMessageType(m metadata.Metadata, val string) {
 }
 
 func SetMessageContentType(m metadata.Metadata, val string) {
-	m.Set(Type, val)
+	m.Set(ContentType, val)
 }
 
 func GetMessageContentType(m metadata.Metadata) string {
@@ -53,3 +53,57 @@ func GetMessageCreated(m metadata.Metadata) time.Time {
 func SetMessageCreated(m metadata.Metadata, val time.Time) {
 	m.Set(Created, val)
 }
+
+// GetMessageScheduled devuelve el momento en el que el mensaje debe entregarse, es cero si el mensaje no fue programado
+func GetMessageScheduled(m metadata.Metadata) time.Time {
+	return m.GetTime(Scheduled)
+}
+
+func SetMessageScheduled(m metadata.Metadata, val time.Time) {
+	m.Set(Scheduled, val)
+}
+
+// GetReplyTo devuelve la direccion a la que se debe enviar la respuesta de un request, es vacia si el mensaje no espera respuesta
+func GetReplyTo(m metadata.Metadata) string {
+	return m.GetString(ReplyTo)
+}
+
+func SetReplyTo(m metadata.Metadata, val string) {
+	m.Set(ReplyTo, val)
+}
+
+// GetInReplyTo devuelve la direccion reply-to del request al que responde el mensaje, es vacia si el mensaje no es una respuesta
+func GetInReplyTo(m metadata.Metadata) string {
+	return m.GetString(InReplyTo)
+}
+
+func SetInReplyTo(m metadata.Metadata, val string) {
+	m.Set(InReplyTo, val)
+}
+
+// GetPartitionKey devuelve la clave que agrupa los mensajes que se deben procesar en orden, por ejemplo el id del agregado
+func GetPartitionKey(m metadata.Metadata) string {
+	return m.GetString(PartitionKey)
+}
+
+func SetPartitionKey(m metadata.Metadata, val string) {
+	m.Set(PartitionKey, val)
+}
+
+// GetEncryptionKeyId devuelve el id de la llave del payload cifrado, es vacio si el mensaje se publico sin cifrar
+func GetEncryptionKeyId(m metadata.Metadata) string {
+	return m.GetString(EncryptionKeyId)
+}
+
+func SetEncryptionKeyId(m metadata.Metadata, val string) {
+	m.Set(EncryptionKeyId, val)
+}
+
+// GetRoutingKey devuelve la routing key con la que se debe publicar el mensaje, es vacio si se usa la del tipo del mensaje
+func GetRoutingKey(m metadata.Metadata) string {
+	return m.GetString(RoutingKey)
+}
+
+func SetRoutingKey(m metadata.Metadata, val string) {
+	m.Set(RoutingKey, val)
+}
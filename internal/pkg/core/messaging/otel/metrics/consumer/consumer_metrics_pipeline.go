@@ -0,0 +1,145 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	messagingConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/constants/telemetrytags"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/metrics"
+
+	"emperror.dev/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type consumerMetricsPipeline struct {
+	consumed  metric.Int64Counter
+	succeeded metric.Int64Counter
+	failed    metric.Int64Counter
+	retried   metric.Int64Counter
+	inFlight  metric.Int64UpDownCounter
+	duration  metric.Float64Histogram
+	age       metric.Float64Histogram
+}
+
+// NewConsumerMetricsPipeline mide la ejecucion de los handlers de los consumers, cada intento del handler cuenta como
+// un mensaje consumido y los intentos despues del primero tambien cuentan como reintentos
+func NewConsumerMetricsPipeline(appMetrics metrics.AppMetrics) (pipeline.ConsumerPipeline, error) {
+	p := &consumerMetricsPipeline{}
+
+	var err error
+	if p.consumed, err = appMetrics.Int64Counter(
+		"messaging.consumer.consumed_total",
+		metric.WithUnit("count"),
+		metric.WithDescription("Measures the number of messages passed to the consumer handlers"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the consumed messages counter")
+	}
+
+	if p.succeeded, err = appMetrics.Int64Counter(
+		"messaging.consumer.succeeded_total",
+		metric.WithUnit("count"),
+		metric.WithDescription("Measures the number of messages handled successfully"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the succeeded messages counter")
+	}
+
+	if p.failed, err = appMetrics.Int64Counter(
+		"messaging.consumer.failed_total",
+		metric.WithUnit("count"),
+		metric.WithDescription("Measures the number of messages whose handler returned an error"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the failed messages counter")
+	}
+
+	if p.retried, err = appMetrics.Int64Counter(
+		"messaging.consumer.retried_total",
+		metric.WithUnit("count"),
+		metric.WithDescription("Measures the number of handler executions that are a retry of a failed attempt"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the retried messages counter")
+	}
+
+	if p.inFlight, err = appMetrics.Int64UpDownCounter(
+		"messaging.consumer.in_flight",
+		metric.WithUnit("count"),
+		metric.WithDescription("Measures the number of messages being handled"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the in-flight messages counter")
+	}
+
+	if p.duration, err = appMetrics.Float64Histogram(
+		"messaging.consumer.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the duration of the consumer handlers"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the handler duration histogram")
+	}
+
+	if p.age, err = appMetrics.Float64Histogram(
+		"messaging.consumer.message_age",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the time since the message was created until its handler starts"),
+	); err != nil {
+		return nil, errors.WrapIf(err, "error in creating the message age histogram")
+	}
+
+	return p, nil
+}
+
+func (p *consumerMetricsPipeline) Handle(
+	ctx context.Context,
+	consumerContext types.MessageConsumeContext,
+	next pipeline.ConsumerHandlerFunc,
+) error {
+	info, _ := messagingConsumer.ConsumeInfoFromContext(ctx)
+
+	messageType := consumerContext.MessageType()
+	if messageType == "" && consumerContext.Message() != nil {
+		messageType = utils.GetMessageName(consumerContext.Message())
+	}
+
+	opt := metric.WithAttributes(
+		attribute.String(telemetrytags.App.Consumer, info.ConsumerName),
+		attribute.String(telemetrytags.App.MessageType, messageType),
+	)
+
+	p.consumed.Add(ctx, 1, opt)
+	if info.Attempt > 0 {
+		p.retried.Add(ctx, 1, opt)
+	}
+
+	if created := messageCreated(consumerContext); !created.IsZero() {
+		p.age.Record(ctx, float64(time.Since(created).Milliseconds()), opt)
+	}
+
+	p.inFlight.Add(ctx, 1, opt)
+	defer p.inFlight.Add(ctx, -1, opt)
+
+	startTime := time.Now()
+
+	err := next(ctx)
+
+	p.duration.Record(ctx, float64(time.Since(startTime).Microseconds())/1000, opt)
+
+	if err != nil {
+		p.failed.Add(ctx, 1, opt)
+	} else {
+		p.succeeded.Add(ctx, 1, opt)
+	}
+
+	return err
+}
+
+// messageCreated prefers the creation time of the message, the broker timestamp is the publish time
+func messageCreated(consumerContext types.MessageConsumeContext) time.Time {
+	if message := consumerContext.Message(); message != nil && !message.GetCreated().IsZero() {
+		return message.GetCreated()
+	}
+
+	return consumerContext.Created()
+}
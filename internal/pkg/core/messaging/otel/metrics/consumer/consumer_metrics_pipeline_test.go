@@ -0,0 +1,186 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	messagingConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/constants/telemetrytags"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const (
+	testConsumerName = "orders_consumer"
+	testMessageType  = "orderCreated"
+)
+
+type testAppMetrics struct {
+	metric.Meter
+}
+
+func newTestPipeline(t *testing.T) (pipeline.ConsumerPipeline, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	consumerPipeline, err := NewConsumerMetricsPipeline(testAppMetrics{Meter: provider.Meter("consumer-metrics-test")})
+	require.NoError(t, err)
+
+	return consumerPipeline, reader
+}
+
+func newTestConsumeContext(created time.Time) types.MessageConsumeContext {
+	message := types.NewMessage(uuid.NewV4().String())
+	message.Created = created
+
+	return types.NewMessageConsumeContext(
+		message,
+		metadata.Metadata{},
+		"application/json",
+		testMessageType,
+		time.Now(),
+		1,
+		message.GeMessageId(),
+		"",
+		nil,
+	)
+}
+
+func consumeContext(attempt uint) context.Context {
+	return messagingConsumer.ContextWithConsumeInfo(
+		context.Background(),
+		messagingConsumer.ConsumeInfo{ConsumerName: testConsumerName, Attempt: attempt},
+	)
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var resourceMetrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &resourceMetrics))
+
+	aggregations := map[string]metricdata.Aggregation{}
+	for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			aggregations[m.Name] = m.Data
+		}
+	}
+
+	return aggregations
+}
+
+// sumValue devuelve el valor del contador para los atributos del consumer y del tipo de mensaje de los tests
+func sumValue(t *testing.T, aggregations map[string]metricdata.Aggregation, name string) int64 {
+	t.Helper()
+
+	sum, ok := aggregations[name].(metricdata.Sum[int64])
+	require.True(t, ok, "metric `%s` is not an int64 sum", name)
+	require.Len(t, sum.DataPoints, 1)
+	assertTestAttributes(t, sum.DataPoints[0].Attributes)
+
+	return sum.DataPoints[0].Value
+}
+
+func histogramPoint(
+	t *testing.T,
+	aggregations map[string]metricdata.Aggregation,
+	name string,
+) metricdata.HistogramDataPoint[float64] {
+	t.Helper()
+
+	histogram, ok := aggregations[name].(metricdata.Histogram[float64])
+	require.True(t, ok, "metric `%s` is not a float64 histogram", name)
+	require.Len(t, histogram.DataPoints, 1)
+	assertTestAttributes(t, histogram.DataPoints[0].Attributes)
+
+	return histogram.DataPoints[0]
+}
+
+func assertTestAttributes(t *testing.T, attributes attribute.Set) {
+	t.Helper()
+
+	consumerName, _ := attributes.Value(attribute.Key(telemetrytags.App.Consumer))
+	messageType, _ := attributes.Value(attribute.Key(telemetrytags.App.MessageType))
+
+	assert.Equal(t, testConsumerName, consumerName.AsString())
+	assert.Equal(t, testMessageType, messageType.AsString())
+}
+
+func Test_Handle_Counts_Consumed_Succeeded_Failed_And_Retried_Messages(t *testing.T) {
+	consumerPipeline, reader := newTestPipeline(t)
+
+	succeed := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("handler failed") }
+
+	require.NoError(t, consumerPipeline.Handle(consumeContext(0), newTestConsumeContext(time.Now()), succeed))
+	require.Error(t, consumerPipeline.Handle(consumeContext(0), newTestConsumeContext(time.Now()), fail))
+	// the second attempt of the failed message
+	require.NoError(t, consumerPipeline.Handle(consumeContext(1), newTestConsumeContext(time.Now()), succeed))
+
+	aggregations := collect(t, reader)
+
+	assert.Equal(t, int64(3), sumValue(t, aggregations, "messaging.consumer.consumed_total"))
+	assert.Equal(t, int64(2), sumValue(t, aggregations, "messaging.consumer.succeeded_total"))
+	assert.Equal(t, int64(1), sumValue(t, aggregations, "messaging.consumer.failed_total"))
+	assert.Equal(t, int64(1), sumValue(t, aggregations, "messaging.consumer.retried_total"))
+	assert.Equal(t, int64(0), sumValue(t, aggregations, "messaging.consumer.in_flight"))
+}
+
+func Test_Handle_Tracks_The_In_Flight_Messages(t *testing.T) {
+	consumerPipeline, reader := newTestPipeline(t)
+
+	var inFlight int64
+	err := consumerPipeline.Handle(
+		consumeContext(0),
+		newTestConsumeContext(time.Now()),
+		func(context.Context) error {
+			inFlight = sumValue(t, collect(t, reader), "messaging.consumer.in_flight")
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), inFlight)
+	assert.Equal(t, int64(0), sumValue(t, collect(t, reader), "messaging.consumer.in_flight"))
+}
+
+func Test_Handle_Records_The_Duration_And_The_Age_Of_The_Messages(t *testing.T) {
+	consumerPipeline, reader := newTestPipeline(t)
+
+	err := consumerPipeline.Handle(
+		consumeContext(0),
+		newTestConsumeContext(time.Now().Add(-2*time.Second)),
+		func(context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	aggregations := collect(t, reader)
+
+	duration := histogramPoint(t, aggregations, "messaging.consumer.duration")
+	assert.Equal(t, uint64(1), duration.Count)
+	assert.GreaterOrEqual(t, duration.Sum, float64(20))
+
+	age := histogramPoint(t, aggregations, "messaging.consumer.message_age")
+	assert.Equal(t, uint64(1), age.Count)
+	assert.GreaterOrEqual(t, age.Sum, float64(2000))
+	assert.Less(t, age.Sum, float64(2000+time.Minute.Milliseconds()))
+}
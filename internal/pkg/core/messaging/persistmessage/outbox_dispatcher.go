@@ -0,0 +1,98 @@
+package persistmessage
+
+import (
+	"context"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+)
+
+// OutboxDispatcher revisa periodicamente el outbox y publica los mensajes pendientes en el broker,
+// y en su propio intervalo elimina los mensajes procesados con `CleanupMessages`
+type OutboxDispatcher interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+type outboxDispatcher struct {
+	messagePersistenceService MessagePersistenceService
+	options                   *OutboxOptions
+	logger                    logger.Logger
+	cancel                    context.CancelFunc
+	done                      chan struct{}
+}
+
+func NewOutboxDispatcher(
+	messagePersistenceService MessagePersistenceService,
+	options *OutboxOptions,
+	l logger.Logger,
+) OutboxDispatcher {
+	return &outboxDispatcher{
+		messagePersistenceService: messagePersistenceService,
+		options:                   options,
+		logger:                    l,
+	}
+}
+
+func (d *outboxDispatcher) Start(ctx context.Context) error {
+	if d.options.Disabled {
+		d.logger.Info("outbox dispatcher is disabled")
+
+		return nil
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.options.PollingInterval)
+		defer ticker.Stop()
+
+		cleanupTicker := time.NewTicker(d.options.CleanupInterval)
+		defer cleanupTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.messagePersistenceService.ProcessAll(ctx); err != nil {
+					d.logger.Errorf("(outboxDispatcher.ProcessAll) error in processing outbox messages: %v", err)
+				}
+			case <-cleanupTicker.C:
+				if err := d.messagePersistenceService.CleanupMessages(ctx); err != nil {
+					d.logger.Errorf("(outboxDispatcher.CleanupMessages) error in cleaning up processed messages: %v", err)
+				}
+			}
+		}
+	}()
+
+	d.logger.Infof(
+		"outbox dispatcher is running with polling interval %s and cleanup interval %s",
+		d.options.PollingInterval,
+		d.options.CleanupInterval,
+	)
+
+	return nil
+}
+
+func (d *outboxDispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+
+	d.cancel()
+
+	// esperamos a que termine el lote en curso para no dejar mensajes a medio publicar
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	d.logger.Info("outbox dispatcher stopped")
+
+	return nil
+}
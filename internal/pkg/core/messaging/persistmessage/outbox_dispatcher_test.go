@@ -0,0 +1,52 @@
+package persistmessage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMessagePersistenceService solo cuenta las llamadas que hace el dispatcher
+type fakeMessagePersistenceService struct {
+	MessagePersistenceService
+	processed atomic.Int32
+	cleanups  atomic.Int32
+}
+
+func (f *fakeMessagePersistenceService) ProcessAll(context.Context) error {
+	f.processed.Add(1)
+
+	return nil
+}
+
+func (f *fakeMessagePersistenceService) CleanupMessages(context.Context) error {
+	f.cleanups.Add(1)
+
+	return nil
+}
+
+func Test_OutboxDispatcher_Cleans_Up_The_Messages_On_Its_Own_Interval(t *testing.T) {
+	service := &fakeMessagePersistenceService{}
+	dispatcher := NewOutboxDispatcher(
+		service,
+		NewOutboxOptionsWithDefaults(&OutboxOptions{
+			PollingInterval: 10 * time.Millisecond,
+			CleanupInterval: 100 * time.Millisecond,
+		}),
+		defaultlogger.GetLogger(),
+	)
+
+	require.NoError(t, dispatcher.Start(context.Background()))
+	time.Sleep(350 * time.Millisecond)
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	assert.Greater(t, service.processed.Load(), int32(10))
+	assert.GreaterOrEqual(t, service.cleanups.Load(), int32(2))
+	assert.LessOrEqual(t, service.cleanups.Load(), int32(4))
+}
@@ -0,0 +1,53 @@
+package persistmessage
+
+import "time"
+
+const (
+	defaultPollingInterval = 5 * time.Second
+	defaultBatchSize       = 100
+	defaultMaxRetryCount   = 5
+	defaultLockDuration    = 30 * time.Second
+	defaultInboxRetention  = 7 * 24 * time.Hour
+	defaultCleanupInterval = time.Hour
+)
+
+// OutboxOptions configura el dispatcher que publica los mensajes guardados en el outbox
+type OutboxOptions struct {
+	Disabled        bool          `mapstructure:"disabled"`
+	PollingInterval time.Duration `mapstructure:"pollingInterval"` // cada cuanto se revisa el outbox
+	BatchSize       int           `mapstructure:"batchSize"`       // cantidad maxima de mensajes por ciclo
+	MaxRetryCount   int           `mapstructure:"maxRetryCount"`   // al llegar a este numero de reintentos el mensaje pasa a `Failed`
+	LockDuration    time.Duration `mapstructure:"lockDuration"`    // tiempo que una instancia reserva los mensajes que esta publicando
+	// InboxRetention es el tiempo que `CleanupMessages` conserva los mensajes procesados del inbox, debe ser mayor
+	// que el tiempo en el que el broker puede volver a entregar un mensaje para que la deduplicacion siga funcionando
+	InboxRetention time.Duration `mapstructure:"inboxRetention"`
+	// CleanupInterval es cada cuanto el dispatcher llama a `CleanupMessages`
+	CleanupInterval time.Duration `mapstructure:"cleanupInterval"`
+}
+
+// NewOutboxOptionsWithDefaults completa con valores por defecto los campos que no vienen en la configuracion
+func NewOutboxOptionsWithDefaults(options *OutboxOptions) *OutboxOptions {
+	if options == nil {
+		options = &OutboxOptions{}
+	}
+	if options.PollingInterval <= 0 {
+		options.PollingInterval = defaultPollingInterval
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.MaxRetryCount <= 0 {
+		options.MaxRetryCount = defaultMaxRetryCount
+	}
+	if options.LockDuration <= 0 {
+		options.LockDuration = defaultLockDuration
+	}
+	if options.InboxRetention <= 0 {
+		options.InboxRetention = defaultInboxRetention
+	}
+	if options.CleanupInterval <= 0 {
+		options.CleanupInterval = defaultCleanupInterval
+	}
+
+	return options
+}
@@ -1,8 +1,12 @@
 package persistmessage
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -19,16 +23,22 @@ type MessageStatus int
 const (
 	Stored    MessageStatus = 1
 	Processed MessageStatus = 2
+	// Failed es el estado de los mensajes del outbox que llegaron al numero maximo de reintentos, ya no se publican
+	Failed MessageStatus = 3
 )
 
 type StoreMessage struct {
 	ID            uuid.UUID `gorm:"primaryKey"`
 	DataType      string
 	Data          string
-	CreatedAt     time.Time `gorm:"default:current_timestamp"`
+	CreatedAt     time.Time `gorm:"default:current_timestamp;index:idx_store_messages_status_delivery_created,priority:3"`
 	RetryCount    int
-	MessageStatus MessageStatus
-	DeliveryType  MessageDeliveryType
+	MessageStatus MessageStatus       `gorm:"index:idx_store_messages_status_delivery_created,priority:1"`
+	DeliveryType  MessageDeliveryType `gorm:"index:idx_store_messages_status_delivery_created,priority:2"`
+	// LockedUntil es la reserva de un mensaje del outbox mientras una instancia lo publica
+	LockedUntil *time.Time
+	// ProcessedAt es la fecha en la que el mensaje se marco como procesado, la retencion de los mensajes se cuenta desde aqui
+	ProcessedAt *time.Time
 }
 
 func NewStoreMessage(
@@ -48,8 +58,13 @@ func NewStoreMessage(
 	}
 }
 
+// ChangeState cambia el estado del mensaje, al procesarlo por primera vez se guarda la fecha de procesamiento
 func (sm *StoreMessage) ChangeState(messageStatus MessageStatus) {
 	sm.MessageStatus = messageStatus
+	if messageStatus == Processed && sm.ProcessedAt == nil {
+		processedAt := time.Now()
+		sm.ProcessedAt = &processedAt
+	}
 }
 
 func (sm *StoreMessage) IncreaseRetry() {
@@ -59,3 +74,11 @@ func (sm *StoreMessage) IncreaseRetry() {
 func (sm *StoreMessage) TableName() string {
 	return "store_messages"
 }
+
+// InboxMessageId devuelve el id de la fila del inbox de un mensaje para el consumer y el handler del contexto,
+// asi cada handler deduplica sus propias entregas y la fila no choca con la del outbox del mismo mensaje en la misma tabla
+func InboxMessageId(ctx context.Context, messageId uuid.UUID) uuid.UUID {
+	info, _ := consumer.ConsumeInfoFromContext(ctx)
+
+	return uuid.NewV5(messageId, fmt.Sprintf("inbox/%s/%s", info.ConsumerName, info.HandlerName))
+}
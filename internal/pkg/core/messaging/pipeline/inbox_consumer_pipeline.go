@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// inboxConsumerPipeline hace idempotente el consumo de mensajes, cada mensaje se guarda en el inbox
+// y si ya fue procesado antes no se vuelve a ejecutar el handler
+type inboxConsumerPipeline struct {
+	messagePersistenceService persistmessage.MessagePersistenceService
+	logger                    logger.Logger
+}
+
+func NewInboxConsumerPipeline(
+	messagePersistenceService persistmessage.MessagePersistenceService,
+	l logger.Logger,
+) ConsumerPipeline {
+	return &inboxConsumerPipeline{
+		messagePersistenceService: messagePersistenceService,
+		logger:                    l,
+	}
+}
+
+func (i *inboxConsumerPipeline) Handle(
+	ctx context.Context,
+	consumerContext types.MessageConsumeContext,
+	next ConsumerHandlerFunc,
+) error {
+	message := consumerContext.Message()
+	if message == nil {
+		return next(ctx)
+	}
+
+	messageID, err := uuid.FromString(message.GeMessageId())
+	if err != nil {
+		i.logger.Warnf(
+			"message with id `%s` has not a valid uuid, skipping inbox deduplication",
+			message.GeMessageId(),
+		)
+
+		return next(ctx)
+	}
+
+	// la fila del inbox es del mensaje para el consumer y el handler que lo procesan, asi las entregas repetidas del broker
+	// apuntan a la misma fila pero otro consumer u otro handler del mismo mensaje tiene la suya
+	inboxID := persistmessage.InboxMessageId(ctx, messageID)
+
+	storeMessage, err := i.messagePersistenceService.GetById(ctx, inboxID)
+	if err != nil && !customErrors.IsNotFoundError(err) {
+		return err
+	}
+	if storeMessage != nil && storeMessage.DeliveryType != persistmessage.Inbox {
+		storeMessage = nil
+	}
+
+	if storeMessage != nil && storeMessage.MessageStatus == persistmessage.Processed {
+		i.logger.Infof(
+			"message with id `%s` and type `%s` already processed, skipping the handler",
+			messageID,
+			consumerContext.MessageType(),
+		)
+
+		return nil
+	}
+
+	// si el mensaje ya esta en el inbox pero no fue procesado, un intento anterior fallo y se vuelve a ejecutar el handler
+	if storeMessage == nil {
+		err = i.messagePersistenceService.AddReceivedMessage(
+			*types.NewMessageEnvelope(message, consumerContext.Metadata()),
+			ctx,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := next(ctx); err != nil {
+		return err
+	}
+
+	return i.messagePersistenceService.ChangeState(ctx, inboxID, persistmessage.Processed)
+}
@@ -0,0 +1,24 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+)
+
+// ProducerHandlerFunc is a continuation for the next task to execute in the producer pipeline
+type ProducerHandlerFunc func(ctx context.Context) error
+
+// ProducerContext contiene el mensaje y su payload ya serializado antes de publicarlo
+type ProducerContext struct {
+	Message     types.IMessage
+	Metadata    metadata.Metadata
+	Body        []byte
+	ContentType string
+}
+
+// ProducerPipeline is a Pipeline for wrapping the publish of a message.
+type ProducerPipeline interface {
+	Handle(ctx context.Context, producerContext *ProducerContext, next ProducerHandlerFunc) error
+}
@@ -0,0 +1,5 @@
+package pipeline
+
+type ProducerPipelineConfiguration struct {
+	Pipelines []ProducerPipeline
+}
@@ -0,0 +1,27 @@
+package pipeline
+
+type ProducerPipelineConfigurationBuilderFunc func(ProducerPipelineConfigurationBuilder)
+
+type ProducerPipelineConfigurationBuilder interface {
+	AddPipeline(pipeline ProducerPipeline) ProducerPipelineConfigurationBuilder
+	Build() *ProducerPipelineConfiguration
+}
+
+type producerPipelineConfigurationBuilder struct {
+	pipelineConfigurations *ProducerPipelineConfiguration
+}
+
+func NewProducerPipelineConfigurationBuilder() ProducerPipelineConfigurationBuilder {
+	return &producerPipelineConfigurationBuilder{pipelineConfigurations: &ProducerPipelineConfiguration{}}
+}
+
+func (c *producerPipelineConfigurationBuilder) AddPipeline(
+	pipeline ProducerPipeline,
+) ProducerPipelineConfigurationBuilder {
+	c.pipelineConfigurations.Pipelines = append(c.pipelineConfigurations.Pipelines, pipeline)
+	return c
+}
+
+func (c *producerPipelineConfigurationBuilder) Build() *ProducerPipelineConfiguration {
+	return c.pipelineConfigurations
+}
@@ -0,0 +1,29 @@
+package producer
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+)
+
+// PublishFuture es el resultado pendiente de una publicacion asincrona, se completa cuando el broker confirma el mensaje
+type PublishFuture interface {
+	// Done se cierra cuando la publicacion se confirma o falla
+	Done() <-chan struct{}
+	// Wait espera la confirmacion de la publicacion o la cancelacion del contexto
+	Wait(ctx context.Context) error
+}
+
+// AsyncProducer publica mensajes sin esperar la confirmacion del broker, los producers que no la soportan solo implementan `Producer`
+type AsyncProducer interface {
+	Producer
+	// PublishMessageAsync publica el mensaje y devuelve su confirmacion pendiente, los callbacks se llaman al completarse
+	PublishMessageAsync(
+		ctx context.Context,
+		message types.IMessage,
+		metadata metadata.Metadata,
+		topicOrExchangeName string,
+		callbacks ...func(err error),
+	) (PublishFuture, error)
+}
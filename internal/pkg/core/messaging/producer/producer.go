@@ -2,6 +2,7 @@ package producer
 
 import (
 	"context"
+	"time"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
@@ -18,6 +19,27 @@ type Producer interface {
 		metadata metadata.Metadata,
 		totopicOrExchangeName string,
 	) error
+	// PublishMessageWithDelay publica un mensaje que se entrega a los consumers despues de `delay`
+	PublishMessageWithDelay(
+		ctx context.Context,
+		message types.IMessage,
+		metadata metadata.Metadata,
+		delay time.Duration,
+	) error
+	// ScheduleMessage publica un mensaje que se entrega a los consumers en el momento `at`
+	ScheduleMessage(
+		ctx context.Context,
+		message types.IMessage,
+		metadata metadata.Metadata,
+		at time.Time,
+	) error
+	// PublishMessages publica un lote de mensajes y espera la confirmacion de todos
+	PublishMessages(
+		ctx context.Context,
+		messages []types.IMessage,
+		metadata metadata.Metadata,
+		topicOrExchangeName string,
+	) error
 	// IsProduced verifica si un mensaje ha sido publicado
 	IsProduced(func(message types.IMessage))
 }
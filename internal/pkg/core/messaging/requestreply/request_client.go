@@ -0,0 +1,191 @@
+package requestreply
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const defaultRequestTimeout = 30 * time.Second
+
+// ErrRequestTimeout se devuelve cuando la respuesta no llega antes del timeout del request
+var ErrRequestTimeout = errors.New("request timed out waiting for the response")
+
+type RequestClientOptions struct {
+	// ReplyTo es la direccion donde esta instancia recibe las respuestas, si esta vacia se genera una unica por instancia
+	ReplyTo string
+	// Timeout es la espera maxima de una respuesta cuando el contexto del request no tiene deadline
+	Timeout time.Duration
+}
+
+// RequestClient envia requests por el bus y espera sus respuestas, las respuestas se relacionan con su request por el correlation id
+type RequestClient interface {
+	// ReplyTo devuelve la direccion donde esta instancia recibe las respuestas
+	ReplyTo() string
+	// RegisterResponseType conecta el consumer de respuestas del tipo, debe llamarse antes de iniciar el bus
+	RegisterResponseType(responseType types.IMessage) error
+	// Send envia el request y espera su respuesta hasta el timeout o la cancelacion del contexto
+	Send(ctx context.Context, request types.IMessage) (types.IMessage, error)
+}
+
+type requestClient struct {
+	producer  producer.Producer
+	connector consumer.ConsumerConnector
+	logger    logger.Logger
+	options   *RequestClientOptions
+	pending   map[string]chan types.IMessage
+	lock      sync.Mutex
+}
+
+func NewRequestClient(
+	producer producer.Producer,
+	connector consumer.ConsumerConnector,
+	logger logger.Logger,
+	options *RequestClientOptions,
+) RequestClient {
+	if options == nil {
+		options = &RequestClientOptions{}
+	}
+	if options.ReplyTo == "" {
+		options.ReplyTo = fmt.Sprintf("replies.%s", uuid.NewV4().String())
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultRequestTimeout
+	}
+
+	return &requestClient{
+		producer:  producer,
+		connector: connector,
+		logger:    logger,
+		options:   options,
+		pending:   map[string]chan types.IMessage{},
+	}
+}
+
+func (c *requestClient) ReplyTo() string {
+	return c.options.ReplyTo
+}
+
+func (c *requestClient) RegisterResponseType(responseType types.IMessage) error {
+	replyConnector, ok := c.connector.(consumer.ReplyConsumerConnector)
+	if !ok {
+		return errors.Errorf(
+			"consumer connector doesn't support reply addresses, can't register the response type `%s`",
+			utils.GetMessageName(responseType),
+		)
+	}
+
+	return replyConnector.ConnectReplyConsumerHandler(responseType, c.options.ReplyTo, &replyHandler{client: c})
+}
+
+func (c *requestClient) Send(ctx context.Context, request types.IMessage) (types.IMessage, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+
+	correlationId := uuid.NewV4().String()
+	responseChan := make(chan types.IMessage, 1)
+
+	c.lock.Lock()
+	c.pending[correlationId] = responseChan
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, correlationId)
+		c.lock.Unlock()
+	}()
+
+	meta := metadata.New()
+	messageHeader.SetCorrelationId(meta, correlationId)
+	messageHeader.SetReplyTo(meta, c.options.ReplyTo)
+
+	if err := c.producer.PublishMessageWithTopicName(ctx, request, meta, ""); err != nil {
+		return nil, errors.WrapIf(err, "error in publishing the request")
+	}
+
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, errors.WithStack(ErrRequestTimeout)
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// complete delivers the response to the waiting request, the responses of requests that already timed out
+// and the duplicated responses are discarded, so the reply consumer never blocks on a request that stopped waiting
+func (c *requestClient) complete(correlationId string, response types.IMessage) {
+	c.lock.Lock()
+	responseChan, ok := c.pending[correlationId]
+	delete(c.pending, correlationId)
+	c.lock.Unlock()
+
+	if !ok {
+		c.logger.Warnf("response with correlation id `%s` has no pending request, it is discarded", correlationId)
+		return
+	}
+
+	select {
+	case responseChan <- response:
+	default:
+	}
+}
+
+type replyHandler struct {
+	client *requestClient
+}
+
+func (h *replyHandler) Handle(ctx context.Context, consumeContext types.MessageConsumeContext) error {
+	correlationId := consumeContext.CorrelationId()
+	if correlationId == "" && consumeContext.Metadata() != nil {
+		correlationId = messageHeader.GetCorrelationId(consumeContext.Metadata())
+	}
+
+	h.client.complete(correlationId, consumeContext.Message())
+
+	return nil
+}
+
+// Request envia el request y devuelve la respuesta tipada, el tipo de la respuesta debe estar registrado con `RegisterResponseType`
+func Request[TReq types.IMessage, TRes types.IMessage](
+	ctx context.Context,
+	client RequestClient,
+	request TReq,
+) (TRes, error) {
+	var response TRes
+
+	result, err := client.Send(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	response, ok := result.(TRes)
+	if !ok {
+		return response, errors.Errorf(
+			"response of type `%s` is not of the expected type `%s`",
+			utils.GetMessageName(result),
+			typemapper.GetGenericTypeNameByT[TRes](),
+		)
+	}
+
+	return response, nil
+}
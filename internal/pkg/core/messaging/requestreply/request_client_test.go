@@ -0,0 +1,110 @@
+package requestreply
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus/inmemory"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type getPrice struct {
+	*types.Message
+	ProductId string
+}
+
+type priceResponse struct {
+	*types.Message
+	ProductId string
+	Price     float64
+}
+
+func newTestBus(t *testing.T) inmemory.InMemoryBus {
+	t.Helper()
+
+	bus, err := inmemory.NewInMemoryBus(
+		defaultlogger.GetLogger(),
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		nil,
+	)
+	require.NoError(t, err)
+
+	return bus
+}
+
+func Test_Request_Returns_The_Response_Of_The_Responder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bus := newTestBus(t)
+	client := NewRequestClient(bus, bus, defaultlogger.GetLogger(), nil)
+	require.NoError(t, client.RegisterResponseType(&priceResponse{}))
+	require.NoError(t, bus.ConnectConsumerHandler(&getPrice{}, NewResponderHandler(
+		bus,
+		func(_ context.Context, request *getPrice) (*priceResponse, error) {
+			return &priceResponse{
+				Message:   types.NewMessage(uuid.NewV4().String()),
+				ProductId: request.ProductId,
+				Price:     10.5,
+			}, nil
+		},
+	)))
+	require.NoError(t, bus.Start(ctx))
+	defer bus.Stop()
+
+	response, err := Request[*getPrice, *priceResponse](
+		ctx,
+		client,
+		&getPrice{Message: types.NewMessage(uuid.NewV4().String()), ProductId: "product-1"},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "product-1", response.ProductId)
+	assert.Equal(t, 10.5, response.Price)
+}
+
+func Test_Send_Times_Out_Without_Response(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := newTestBus(t)
+	client := NewRequestClient(bus, bus, defaultlogger.GetLogger(), &RequestClientOptions{Timeout: 50 * time.Millisecond})
+	require.NoError(t, bus.Start(ctx))
+	defer bus.Stop()
+
+	_, err := client.Send(ctx, &getPrice{Message: types.NewMessage(uuid.NewV4().String())})
+
+	assert.True(t, errors.Is(err, ErrRequestTimeout))
+	assert.Empty(t, client.(*requestClient).pending)
+}
+
+func Test_Complete_Discards_Duplicated_Responses_Without_Blocking(t *testing.T) {
+	client := NewRequestClient(nil, nil, defaultlogger.GetLogger(), nil).(*requestClient)
+	responseChan := make(chan types.IMessage, 1)
+	client.pending["correlation-id"] = responseChan
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			client.complete("correlation-id", &priceResponse{Message: types.NewMessage(uuid.NewV4().String())})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("complete blocked on a duplicated response")
+	}
+
+	assert.Len(t, responseChan, 1)
+	assert.Empty(t, client.pending)
+}
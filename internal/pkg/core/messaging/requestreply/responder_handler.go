@@ -0,0 +1,74 @@
+package requestreply
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+
+	"emperror.dev/errors"
+)
+
+// ResponderHandlerFunc procesa el request y devuelve la respuesta que se envia a la direccion reply-to del request
+type ResponderHandlerFunc[TReq types.IMessage, TRes types.IMessage] func(ctx context.Context, request TReq) (TRes, error)
+
+type responderHandler[TReq types.IMessage, TRes types.IMessage] struct {
+	producer producer.Producer
+	handle   ResponderHandlerFunc[TReq, TRes]
+}
+
+// NewResponderHandler crea un consumer handler que responde los requests, se conecta como cualquier otro handler del consumer
+func NewResponderHandler[TReq types.IMessage, TRes types.IMessage](
+	producer producer.Producer,
+	handle ResponderHandlerFunc[TReq, TRes],
+) consumer.ConsumerHandler {
+	return &responderHandler[TReq, TRes]{
+		producer: producer,
+		handle:   handle,
+	}
+}
+
+func (h *responderHandler[TReq, TRes]) Handle(
+	ctx context.Context,
+	consumeContext types.MessageConsumeContext,
+) error {
+	request, ok := consumeContext.Message().(TReq)
+	if !ok {
+		return errors.Errorf(
+			"request of type `%s` is not of the expected type",
+			utils.GetMessageName(consumeContext.Message()),
+		)
+	}
+
+	replyTo := messageHeader.GetReplyTo(consumeContext.Metadata())
+	if replyTo == "" {
+		return errors.Errorf(
+			"request with id `%s` doesn't have a reply-to address",
+			consumeContext.MessageId(),
+		)
+	}
+
+	response, err := h.handle(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	correlationId := consumeContext.CorrelationId()
+	if correlationId == "" {
+		correlationId = messageHeader.GetCorrelationId(consumeContext.Metadata())
+	}
+
+	meta := metadata.New()
+	messageHeader.SetCorrelationId(meta, correlationId)
+	messageHeader.SetInReplyTo(meta, replyTo)
+
+	if err := h.producer.PublishMessageWithTopicName(ctx, response, meta, replyTo); err != nil {
+		return errors.WrapIf(err, "error in publishing the response")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// Incompatibility es un cambio del schema que rompe a los consumers de la version anterior
+type Incompatibility struct {
+	Path   string
+	Reason string
+}
+
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("`%s`: %s", i.Path, i.Reason)
+}
+
+// FindIncompatibilities compara la version nueva de un schema con la anterior, los cambios incompatibles son
+// quitar campos, cambiar su tipo o formato y volver requerido un campo que antes era opcional.
+// Agregar campos opcionales es compatible
+func FindIncompatibilities(previous *JsonSchema, current *JsonSchema) []Incompatibility {
+	var result []Incompatibility
+	compare("$", previous, current, &result)
+
+	return result
+}
+
+// CheckCompatibility devuelve un error con todos los cambios incompatibles, esta pensado para los tests
+// que comparan el schema actual de un mensaje con el schema publicado de su version anterior
+func CheckCompatibility(previous *JsonSchema, current *JsonSchema) error {
+	incompatibilities := FindIncompatibilities(previous, current)
+	if len(incompatibilities) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(incompatibilities))
+	for _, incompatibility := range incompatibilities {
+		reasons = append(reasons, incompatibility.String())
+	}
+
+	return errors.Errorf(
+		"schema `%s` is not compatible with its previous version: %s",
+		current.Title,
+		strings.Join(reasons, "; "),
+	)
+}
+
+func compare(path string, previous *JsonSchema, current *JsonSchema, result *[]Incompatibility) {
+	if previous == nil {
+		return
+	}
+	if current == nil {
+		*result = append(*result, Incompatibility{Path: path, Reason: "the schema was removed"})
+		return
+	}
+
+	// the new version can accept more types, e.g. a field that becomes nullable, but can't stop producing the old ones
+	for _, t := range previous.Type {
+		if len(current.Type) > 0 && !contains(current.Type, t) {
+			*result = append(*result, Incompatibility{
+				Path: path,
+				Reason: fmt.Sprintf(
+					"type changed from `%s` to `%s`",
+					strings.Join(previous.Type, "|"),
+					strings.Join(current.Type, "|"),
+				),
+			})
+
+			return
+		}
+	}
+
+	if previous.Format != current.Format {
+		*result = append(*result, Incompatibility{
+			Path:   path,
+			Reason: fmt.Sprintf("format changed from `%s` to `%s`", previous.Format, current.Format),
+		})
+	}
+
+	names := make([]string, 0, len(previous.Properties))
+	for name := range previous.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := current.Properties[name]
+		if !ok {
+			*result = append(*result, Incompatibility{Path: path + "." + name, Reason: "the field was removed"})
+			continue
+		}
+		compare(path+"."+name, previous.Properties[name], property, result)
+	}
+
+	for _, required := range current.Required {
+		if !contains(previous.Required, required) {
+			*result = append(*result, Incompatibility{
+				Path:   path + "." + required,
+				Reason: "the field is required but it was optional or didn't exist in the previous version",
+			})
+		}
+	}
+
+	compare(path+"[]", previous.Items, current.Items, result)
+	compare(path+"{}", previous.AdditionalProperties, current.AdditionalProperties, result)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const productV1Schema = `{
+  "title": "productCreatedV1",
+  "type": "object",
+  "properties": {
+    "productId": {"type": "string", "format": "uuid"},
+    "name": {"type": "string"},
+    "price": {"type": "number"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "description": {"type": ["string", "null"]}
+  },
+  "required": ["productId", "name", "price"]
+}`
+
+func Test_FindIncompatibilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    []Incompatibility
+	}{
+		{
+			name:    "same schema",
+			current: productV1Schema,
+		},
+		{
+			name: "added optional field",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": ["string", "null"]},
+				"category": {"type": "string"}},
+				"required": ["productId", "name", "price"]}`,
+		},
+		{
+			name: "removed field",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}},
+				"required": ["productId", "name", "price"]}`,
+			want: []Incompatibility{{Path: "$.description", Reason: "the field was removed"}},
+		},
+		{
+			name: "changed field type",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": ["string", "null"]}},
+				"required": ["productId", "name", "price"]}`,
+			want: []Incompatibility{{Path: "$.price", Reason: "type changed from `number` to `string`"}},
+		},
+		{
+			name: "changed field format",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": ["string", "null"]}},
+				"required": ["productId", "name", "price"]}`,
+			want: []Incompatibility{{Path: "$.productId", Reason: "format changed from `uuid` to ``"}},
+		},
+		{
+			name: "optional field became required",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": ["string", "null"]}},
+				"required": ["productId", "name", "price", "description"]}`,
+			want: []Incompatibility{{
+				Path:   "$.description",
+				Reason: "the field is required but it was optional or didn't exist in the previous version",
+			}},
+		},
+		{
+			name: "changed items type",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "integer"}}, "description": {"type": ["string", "null"]}},
+				"required": ["productId", "name", "price"]}`,
+			want: []Incompatibility{{Path: "$.tags[]", Reason: "type changed from `string` to `integer`"}},
+		},
+		{
+			name: "nullable field stopped accepting null",
+			current: `{"type": "object", "properties": {
+				"productId": {"type": "string", "format": "uuid"}, "name": {"type": "string"}, "price": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": "string"}},
+				"required": ["productId", "name", "price"]}`,
+			want: []Incompatibility{{Path: "$.description", Reason: "type changed from `string|null` to `string`"}},
+		},
+	}
+
+	previous, err := ParseJsonSchema([]byte(productV1Schema))
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current, err := ParseJsonSchema([]byte(test.current))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, FindIncompatibilities(previous, current))
+		})
+	}
+}
+
+func Test_CheckCompatibility_Lists_Every_Incompatibility(t *testing.T) {
+	previous, err := ParseJsonSchema([]byte(productV1Schema))
+	require.NoError(t, err)
+	current, err := ParseJsonSchema([]byte(`{"title": "productCreatedV1", "type": "object", "properties": {
+		"productId": {"type": "integer"}, "price": {"type": "number"},
+		"tags": {"type": "array", "items": {"type": "string"}}, "description": {"type": ["string", "null"]}},
+		"required": ["productId", "price"]}`))
+	require.NoError(t, err)
+
+	err = CheckCompatibility(previous, current)
+
+	assert.ErrorContains(t, err, "`$.name`: the field was removed")
+	assert.ErrorContains(t, err, "`$.productId`: type changed from `string` to `integer`")
+}
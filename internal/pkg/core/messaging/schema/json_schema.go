@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// JsonSchema es el subconjunto de JSON Schema que describe el payload json de un mensaje
+type JsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 SchemaType             `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *JsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// SchemaType son los tipos json que admite un schema, se serializa como string cuando solo tiene un tipo
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+
+	var types []string
+	if err := json.Unmarshal(data, &types); err != nil {
+		return err
+	}
+	*t = types
+
+	return nil
+}
+
+// ParseJsonSchema carga un schema guardado, por ejemplo el schema publicado de la version anterior de un mensaje
+func ParseJsonSchema(data []byte) (*JsonSchema, error) {
+	s := &JsonSchema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.WrapIf(err, "error in parsing the json schema")
+	}
+
+	return s, nil
+}
+
+func (s *JsonSchema) String() string {
+	data, _ := json.MarshalIndent(s, "", "  ")
+
+	return string(data)
+}
+
+//nolint:gochecknoglobals
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// GenerateJsonSchema genera el schema del payload json del tipo con las mismas reglas de `encoding/json`,
+// los campos sin `omitempty` son requeridos y los campos de los structs embebidos se incluyen en el struct padre
+func GenerateJsonSchema(typ reflect.Type) *JsonSchema {
+	g := &generator{visiting: map[reflect.Type]bool{}}
+	s := g.schemaOf(typ)
+
+	s.Schema = draft
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	s.Title = typ.Name()
+
+	return s
+}
+
+type generator struct {
+	// visiting the struct types in the current path, for recursive types
+	visiting map[reflect.Type]bool
+}
+
+func (g *generator) schemaOf(typ reflect.Type) *JsonSchema {
+	nullable := false
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+		nullable = true
+	}
+
+	s := g.schemaOfType(typ)
+
+	// nil pointers, slices and maps are encoded as null
+	if len(s.Type) > 0 && (nullable || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map) {
+		s.Type = append(s.Type, "null")
+	}
+
+	return s
+}
+
+func (g *generator) schemaOfType(typ reflect.Type) *JsonSchema {
+	switch {
+	case typ == timeType:
+		return &JsonSchema{Type: SchemaType{"string"}, Format: "date-time"}
+	case typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8 && strings.EqualFold(typ.Name(), "uuid"):
+		return &JsonSchema{Type: SchemaType{"string"}, Format: "uuid"}
+	case reflect.PointerTo(typ).Implements(jsonMarshalerType) || typ.Implements(jsonMarshalerType):
+		// a custom encoding, any value is accepted
+		return &JsonSchema{}
+	case reflect.PointerTo(typ).Implements(textMarshalerType) || typ.Implements(textMarshalerType):
+		return &JsonSchema{Type: SchemaType{"string"}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &JsonSchema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JsonSchema{Type: SchemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &JsonSchema{Type: SchemaType{"number"}}
+	case reflect.String:
+		return &JsonSchema{Type: SchemaType{"string"}}
+	case reflect.Slice, reflect.Array:
+		// byte slices are encoded as base64 strings
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			return &JsonSchema{Type: SchemaType{"string"}}
+		}
+
+		return &JsonSchema{Type: SchemaType{"array"}, Items: g.schemaOf(typ.Elem())}
+	case reflect.Map:
+		return &JsonSchema{Type: SchemaType{"object"}, AdditionalProperties: g.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		return g.structSchema(typ)
+	default:
+		// interfaces and the types without a json representation accept any value
+		return &JsonSchema{}
+	}
+}
+
+func (g *generator) structSchema(typ reflect.Type) *JsonSchema {
+	if g.visiting[typ] {
+		return &JsonSchema{}
+	}
+	g.visiting[typ] = true
+	defer delete(g.visiting, typ)
+
+	s := &JsonSchema{Type: SchemaType{"object"}, Properties: map[string]*JsonSchema{}}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// the fields of the embedded structs are promoted to the parent struct
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			for embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				embedded := g.structSchema(embeddedType)
+				for propertyName, property := range embedded.Properties {
+					if _, ok := s.Properties[propertyName]; !ok {
+						s.Properties[propertyName] = property
+					}
+				}
+				s.Required = append(s.Required, embedded.Required...)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = g.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
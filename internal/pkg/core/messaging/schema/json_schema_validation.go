@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Validate valida el payload json contra el schema, devuelve un error de validacion con todos los campos invalidos
+func (s *JsonSchema) Validate(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return customErrors.NewValidationError(fmt.Sprintf("payload is not a valid json: %v", err))
+	}
+
+	var violations []string
+	s.validateValue("$", value, &violations)
+
+	if len(violations) > 0 {
+		return customErrors.NewValidationError(
+			fmt.Sprintf("payload doesn't match the schema `%s`: %s", s.Title, strings.Join(violations, "; ")),
+		)
+	}
+
+	return nil
+}
+
+func (s *JsonSchema) validateValue(path string, value any, violations *[]string) {
+	if s == nil {
+		return
+	}
+
+	if len(s.Type) > 0 && !s.allowsType(value) {
+		*violations = append(
+			*violations,
+			fmt.Sprintf("`%s` should be of type `%s` but is `%s`", path, strings.Join(s.Type, "|"), jsonTypeOf(value)),
+		)
+
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, required := range s.Required {
+			if _, ok := v[required]; !ok {
+				*violations = append(*violations, fmt.Sprintf("`%s.%s` is required", path, required))
+			}
+		}
+
+		// the properties that are not in the schema are allowed, the producers can add fields before the consumers know them
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				property.validateValue(path+"."+name, v[name], violations)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validateValue(path+"."+name, v[name], violations)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			s.Items.validateValue(fmt.Sprintf("%s[%d]", path, i), item, violations)
+		}
+	case string:
+		if !validFormat(s.Format, v) {
+			*violations = append(*violations, fmt.Sprintf("`%s` is not a valid `%s`", path, s.Format))
+		}
+	}
+}
+
+func (s *JsonSchema) allowsType(value any) bool {
+	valueType := jsonTypeOf(value)
+	for _, t := range s.Type {
+		if t == valueType {
+			return true
+		}
+
+		// the integers are numbers too
+		if t == "number" && valueType == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	case "uuid":
+		_, err := uuid.FromString(value)
+		return err == nil
+	default:
+		return true
+	}
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+)
+
+// SchemaRegistry guarda el JSON Schema de los mensajes de integracion, los schemas se identifican por el nombre del
+// mensaje y no por el tipo de go porque el producer y los consumers declaran el mensaje en paquetes distintos
+type SchemaRegistry interface {
+	// Register genera y guarda el schema del mensaje, si ya estaba registrado devuelve el schema existente
+	Register(message types.IMessage) *JsonSchema
+	// RegisterSchema guarda un schema generado por otro servicio, por ejemplo el schema publicado por el producer
+	RegisterSchema(messageName string, schema *JsonSchema)
+	GetSchema(messageName string) (*JsonSchema, error)
+	Schemas() map[string]*JsonSchema
+	// MessageNames devuelve los nombres de los mensajes registrados ordenados
+	MessageNames() []string
+	// Validate valida el payload del mensaje, los mensajes sin schema registrado no se validan
+	Validate(messageName string, data []byte) error
+}
+
+type schemaRegistry struct {
+	schemas map[string]*JsonSchema
+	lock    sync.RWMutex
+}
+
+func NewSchemaRegistry() SchemaRegistry {
+	return &schemaRegistry{schemas: map[string]*JsonSchema{}}
+}
+
+func (r *schemaRegistry) Register(message types.IMessage) *JsonSchema {
+	messageName := utils.GetMessageName(message)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if s, ok := r.schemas[messageName]; ok {
+		return s
+	}
+
+	s := GenerateJsonSchema(utils.GetMessageBaseReflectType(message))
+	r.schemas[messageName] = s
+
+	return s
+}
+
+func (r *schemaRegistry) RegisterSchema(messageName string, schema *JsonSchema) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.schemas[messageName] = schema
+}
+
+func (r *schemaRegistry) GetSchema(messageName string) (*JsonSchema, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	s, ok := r.schemas[messageName]
+	if !ok {
+		return nil, customErrors.NewNotFoundError(fmt.Sprintf("schema for message `%s` is not registered", messageName))
+	}
+
+	return s, nil
+}
+
+func (r *schemaRegistry) Schemas() map[string]*JsonSchema {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make(map[string]*JsonSchema, len(r.schemas))
+	for name, s := range r.schemas {
+		result[name] = s
+	}
+
+	return result
+}
+
+func (r *schemaRegistry) MessageNames() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *schemaRegistry) Validate(messageName string, data []byte) error {
+	r.lock.RLock()
+	s, ok := r.schemas[messageName]
+	r.lock.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return s.Validate(data)
+}
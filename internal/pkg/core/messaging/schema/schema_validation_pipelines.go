@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+)
+
+const jsonMediaType = "application/json"
+
+// the schemas describe the plain json payload, the other formats like protobuf or an encrypted payload have their own
+// contract, so only the exact json media type is validated and its parameters like the charset are ignored
+func isJsonContentType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == jsonMediaType
+}
+
+type producerSchemaValidationPipeline struct {
+	registry SchemaRegistry
+}
+
+// NewProducerSchemaValidationPipeline rechaza la publicacion de los mensajes cuyo payload no cumple su schema,
+// asi un cambio en el contrato del mensaje falla en el producer y no en los consumers
+func NewProducerSchemaValidationPipeline(registry SchemaRegistry) pipeline.ProducerPipeline {
+	return &producerSchemaValidationPipeline{registry: registry}
+}
+
+func (p *producerSchemaValidationPipeline) Handle(
+	ctx context.Context,
+	producerContext *pipeline.ProducerContext,
+	next pipeline.ProducerHandlerFunc,
+) error {
+	if !isJsonContentType(producerContext.ContentType) {
+		return next(ctx)
+	}
+
+	messageName := utils.GetMessageName(producerContext.Message)
+	if err := p.registry.Validate(messageName, producerContext.Body); err != nil {
+		return errors.WrapIff(err, "message `%s` with id `%s` is not published",
+			messageName, producerContext.Message.GeMessageId())
+	}
+
+	return next(ctx)
+}
+
+type consumerSchemaValidationPipeline struct {
+	registry SchemaRegistry
+	logger   logger.Logger
+}
+
+// NewConsumerSchemaValidationPipeline valida el payload recibido antes de ejecutar los handlers, los mensajes invalidos
+// devuelven un error de validacion y el consumer los envia al dead letter sin reintentos
+func NewConsumerSchemaValidationPipeline(registry SchemaRegistry, logger logger.Logger) pipeline.ConsumerPipeline {
+	return &consumerSchemaValidationPipeline{registry: registry, logger: logger}
+}
+
+func (p *consumerSchemaValidationPipeline) Handle(
+	ctx context.Context,
+	consumerContext types.MessageConsumeContext,
+	next pipeline.ConsumerHandlerFunc,
+) error {
+	if consumerContext.Message() == nil || !isJsonContentType(consumerContext.ContentType()) {
+		return next(ctx)
+	}
+
+	messageName := utils.GetMessageName(consumerContext.Message())
+	if err := p.registry.Validate(messageName, consumerContext.Body()); err != nil {
+		p.logger.Errorf(
+			"message `%s` with id `%s` doesn't match its schema: %v",
+			messageName,
+			consumerContext.MessageId(),
+			err,
+		)
+
+		return err
+	}
+
+	return next(ctx)
+}
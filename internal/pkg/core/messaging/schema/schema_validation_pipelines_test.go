@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type productCreated struct {
+	*types.Message
+	ProductId string  `json:"productId"`
+	Price     float64 `json:"price"`
+}
+
+func newProductCreated() *productCreated {
+	return &productCreated{Message: types.NewMessage(uuid.NewV4().String()), ProductId: "product-1", Price: 10}
+}
+
+// payload serializa un mensaje valido y aplica los cambios del caso de prueba
+func payload(t *testing.T, change func(fields map[string]any)) string {
+	t.Helper()
+
+	data, err := json.Marshal(newProductCreated())
+	require.NoError(t, err)
+
+	fields := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	if change != nil {
+		change(fields)
+	}
+
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func Test_IsJsonContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "", want: true},
+		{contentType: "application/json", want: true},
+		{contentType: "application/json; charset=utf-8", want: true},
+		{contentType: "Application/JSON", want: true},
+		{contentType: "application/vnd.encrypted", want: false},
+		{contentType: "application/vnd.encrypted+json", want: false},
+		{contentType: "application/x-protobuf", want: false},
+		{contentType: "text/jsonish", want: false},
+		{contentType: "json", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T) {
+			assert.Equal(t, test.want, isJsonContentType(test.contentType))
+		})
+	}
+}
+
+func Test_ProducerSchemaValidationPipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantErr     bool
+	}{
+		{name: "valid payload", body: payload(t, nil), contentType: "application/json"},
+		{
+			name:        "missing required field",
+			body:        payload(t, func(fields map[string]any) { delete(fields, "price") }),
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "wrong field type",
+			body:        payload(t, func(fields map[string]any) { fields["price"] = "10" }),
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{name: "payload of other format", body: "\x0a\x09product-1", contentType: "application/x-protobuf"},
+	}
+
+	registry := NewSchemaRegistry()
+	registry.Register(&productCreated{})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			published := false
+			err := NewProducerSchemaValidationPipeline(registry).Handle(
+				context.Background(),
+				&pipeline.ProducerContext{
+					Message:     newProductCreated(),
+					Metadata:    metadata.New(),
+					Body:        []byte(test.body),
+					ContentType: test.contentType,
+				},
+				func(context.Context) error {
+					published = true
+
+					return nil
+				},
+			)
+
+			if test.wantErr {
+				assert.True(t, customErrors.IsValidationError(err))
+				assert.False(t, published)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, published)
+		})
+	}
+}
+
+func Test_ConsumerSchemaValidationPipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantErr     bool
+	}{
+		{name: "valid payload", body: payload(t, nil), contentType: "application/json; charset=utf-8"},
+		{
+			name:        "missing required field",
+			body:        payload(t, func(fields map[string]any) { delete(fields, "productId") }),
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{name: "invalid json", body: `{"productId": `, contentType: "application/json", wantErr: true},
+		{name: "payload of other format", body: "ciphertext", contentType: "application/vnd.encrypted"},
+	}
+
+	registry := NewSchemaRegistry()
+	registry.Register(&productCreated{})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := newProductCreated()
+			handled := false
+			err := NewConsumerSchemaValidationPipeline(registry, defaultlogger.GetLogger()).Handle(
+				context.Background(),
+				types.NewMessageConsumeContext(
+					message,
+					metadata.New(),
+					test.contentType,
+					"productCreated",
+					time.Now(),
+					1,
+					message.GeMessageId(),
+					"",
+					[]byte(test.body),
+				),
+				func(context.Context) error {
+					handled = true
+
+					return nil
+				},
+			)
+
+			if test.wantErr {
+				assert.True(t, customErrors.IsValidationError(err))
+				assert.False(t, handled)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, handled)
+		})
+	}
+}
@@ -16,6 +16,8 @@ type MessageConsumeContext interface {
 	DeliveryTag() uint64
 	Metadata() metadata.Metadata
 	Message() IMessage
+	// Body es el payload serializado tal como llego del broker
+	Body() []byte
 }
 
 type messageConsumeContext struct {
@@ -27,6 +29,7 @@ type messageConsumeContext struct {
 	tag           uint64
 	correlationId string
 	message       IMessage
+	body          []byte
 }
 
 func NewMessageConsumeContext(
@@ -38,6 +41,7 @@ func NewMessageConsumeContext(
 	deliveryTag uint64,
 	messageId string,
 	correlationId string,
+	body []byte,
 ) MessageConsumeContext {
 	return &messageConsumeContext{
 		message:       message,
@@ -48,6 +52,7 @@ func NewMessageConsumeContext(
 		created:       created,
 		messageType:   messageType,
 		correlationId: correlationId,
+		body:          body,
 	}
 }
 
@@ -82,3 +87,7 @@ func (m *messageConsumeContext) Created() time.Time {
 func (m *messageConsumeContext) DeliveryTag() uint64 {
 	return m.tag
 }
+
+func (m *messageConsumeContext) Body() []byte {
+	return m.body
+}
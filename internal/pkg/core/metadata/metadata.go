@@ -94,10 +94,11 @@ func New() Metadata {
 	return make(Metadata)
 }
 
-// FromMetadata devuelve un tipo Metadata a partir de un tipo Metadata
+// FromMetadata devuelve una copia de los metadatos, los cambios en la copia no modifican los metadatos del llamador
 func FromMetadata(m Metadata) Metadata {
-	if m == nil {
-		return Metadata{}
+	result := make(Metadata, len(m))
+	for key, value := range m {
+		result[key] = value
 	}
-	return m
+	return result
 }
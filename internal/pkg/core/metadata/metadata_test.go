@@ -0,0 +1,19 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FromMetadata_Returns_A_Copy(t *testing.T) {
+	meta := Metadata{"key": "value"}
+
+	copied := FromMetadata(meta)
+	copied.Set("other", "value")
+	delete(copied, "key")
+
+	assert.Equal(t, Metadata{"key": "value"}, meta)
+	assert.Equal(t, Metadata{"other": "value"}, copied)
+	assert.NotNil(t, FromMetadata(nil))
+}
@@ -0,0 +1,104 @@
+package serializer
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+
+	"emperror.dev/errors"
+)
+
+// contentTypeEventSerializer serializes each event with the serializer of its content type,
+// the events implement `ContentTypeMessage` for using a content type different from the default one
+type contentTypeEventSerializer struct {
+	defaultSerializer EventSerializer
+	serializers       map[string]EventSerializer
+}
+
+func NewContentTypeEventSerializer(
+	defaultSerializer EventSerializer,
+	serializers ...EventSerializer,
+) EventSerializer {
+	contentTypeSerializers := map[string]EventSerializer{
+		MediaType(defaultSerializer.ContentType()): defaultSerializer,
+	}
+	for _, s := range serializers {
+		contentTypeSerializers[MediaType(s.ContentType())] = s
+	}
+
+	return &contentTypeEventSerializer{
+		defaultSerializer: defaultSerializer,
+		serializers:       contentTypeSerializers,
+	}
+}
+
+func (s *contentTypeEventSerializer) Serialize(event domain.IDomainEvent) (*EventSerializationResult, error) {
+	eventSerializer, err := s.eventSerializer(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventSerializer.Serialize(event)
+}
+
+func (s *contentTypeEventSerializer) SerializeObject(event interface{}) (*EventSerializationResult, error) {
+	eventSerializer, err := s.eventSerializer(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventSerializer.SerializeObject(event)
+}
+
+func (s *contentTypeEventSerializer) Deserialize(data []byte, eventType string, contentType string) (interface{}, error) {
+	eventSerializer, err := s.contentTypeSerializer(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventSerializer.Deserialize(data, eventType, eventSerializer.ContentType())
+}
+
+func (s *contentTypeEventSerializer) DeserializeType(
+	data []byte,
+	eventType reflect.Type,
+	contentType string,
+) (domain.IDomainEvent, error) {
+	eventSerializer, err := s.contentTypeSerializer(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventSerializer.DeserializeType(data, eventType, eventSerializer.ContentType())
+}
+
+func (s *contentTypeEventSerializer) ContentType() string {
+	return s.defaultSerializer.ContentType()
+}
+
+func (s *contentTypeEventSerializer) Serializer() Serializer {
+	return s.defaultSerializer.Serializer()
+}
+
+func (s *contentTypeEventSerializer) eventSerializer(event interface{}) (EventSerializer, error) {
+	contentTypeEvent, ok := event.(ContentTypeMessage)
+	if !ok {
+		return s.defaultSerializer, nil
+	}
+
+	return s.contentTypeSerializer(contentTypeEvent.MessageContentType())
+}
+
+func (s *contentTypeEventSerializer) contentTypeSerializer(contentType string) (EventSerializer, error) {
+	mediaType := MediaType(contentType)
+	if mediaType == "" {
+		return s.defaultSerializer, nil
+	}
+
+	eventSerializer, ok := s.serializers[mediaType]
+	if !ok {
+		return nil, errors.Errorf("contentType: %s is not supported", contentType)
+	}
+
+	return eventSerializer, nil
+}
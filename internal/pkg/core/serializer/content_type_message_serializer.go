@@ -0,0 +1,158 @@
+package serializer
+
+import (
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+
+	"emperror.dev/errors"
+)
+
+// ContentTypeMessage lo implementan los mensajes que se publican con un content type distinto al por defecto,
+// permite migrar el formato de cada tipo de mensaje por separado
+type ContentTypeMessage interface {
+	MessageContentType() string
+}
+
+// contentTypeMessageSerializer serializes each message with the serializer of its content type
+// and deserializes with the serializer of the content type in the message headers
+type contentTypeMessageSerializer struct {
+	defaultSerializer MessageSerializer
+	serializers       map[string]MessageSerializer
+}
+
+// NewContentTypeMessageSerializer el serializer por defecto se usa para los mensajes sin content type propio y para los envelopes del outbox
+func NewContentTypeMessageSerializer(
+	defaultSerializer MessageSerializer,
+	serializers ...MessageSerializer,
+) MessageSerializer {
+	contentTypeSerializers := map[string]MessageSerializer{
+		MediaType(defaultSerializer.ContentType()): defaultSerializer,
+	}
+	for _, s := range serializers {
+		contentTypeSerializers[MediaType(s.ContentType())] = s
+	}
+
+	return &contentTypeMessageSerializer{
+		defaultSerializer: defaultSerializer,
+		serializers:       contentTypeSerializers,
+	}
+}
+
+func (m *contentTypeMessageSerializer) Serialize(message types.IMessage) (*EventSerializationResult, error) {
+	s, err := m.messageSerializer(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Serialize(message)
+}
+
+func (m *contentTypeMessageSerializer) SerializeObject(message interface{}) (*EventSerializationResult, error) {
+	s, err := m.messageSerializer(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.SerializeObject(message)
+}
+
+// SerializeEnvelop the envelopes are stored with the default serializer, the message is serialized by its own content type when it is published
+func (m *contentTypeMessageSerializer) SerializeEnvelop(
+	messageEnvelop types.MessageEnvelope,
+) (*EventSerializationResult, error) {
+	return m.defaultSerializer.SerializeEnvelop(messageEnvelop)
+}
+
+func (m *contentTypeMessageSerializer) DeserializeEnvelop(
+	data []byte,
+	messageType string,
+	contentType string,
+) (*types.MessageEnvelope, error) {
+	return m.defaultSerializer.DeserializeEnvelop(data, messageType, MediaType(contentType))
+}
+
+func (m *contentTypeMessageSerializer) Deserialize(
+	data []byte,
+	messageType string,
+	contentType string,
+) (types.IMessage, error) {
+	s, err := m.contentTypeSerializer(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Deserialize(data, messageType, s.ContentType())
+}
+
+func (m *contentTypeMessageSerializer) DeserializeObject(
+	data []byte,
+	messageType string,
+	contentType string,
+) (interface{}, error) {
+	s, err := m.contentTypeSerializer(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.DeserializeObject(data, messageType, s.ContentType())
+}
+
+func (m *contentTypeMessageSerializer) DeserializeType(
+	data []byte,
+	messageType reflect.Type,
+	contentType string,
+) (types.IMessage, error) {
+	s, err := m.contentTypeSerializer(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.DeserializeType(data, messageType, s.ContentType())
+}
+
+func (m *contentTypeMessageSerializer) ContentType() string {
+	return m.defaultSerializer.ContentType()
+}
+
+func (m *contentTypeMessageSerializer) Serializer() Serializer {
+	return m.defaultSerializer.Serializer()
+}
+
+func (m *contentTypeMessageSerializer) messageSerializer(message interface{}) (MessageSerializer, error) {
+	contentTypeMessage, ok := message.(ContentTypeMessage)
+	if !ok {
+		return m.defaultSerializer, nil
+	}
+
+	return m.contentTypeSerializer(contentTypeMessage.MessageContentType())
+}
+
+// contentTypeSerializer returns the serializer of the media type of the content type, the parameters like the charset are ignored
+// and the messages without content type use the default serializer
+func (m *contentTypeMessageSerializer) contentTypeSerializer(contentType string) (MessageSerializer, error) {
+	mediaType := MediaType(contentType)
+	if mediaType == "" {
+		return m.defaultSerializer, nil
+	}
+
+	s, ok := m.serializers[mediaType]
+	if !ok {
+		return nil, errors.Errorf("contentType: %s is not supported", contentType)
+	}
+
+	return s, nil
+}
+
+// MediaType devuelve el media type del content type sin sus parametros, por ejemplo `application/json` para
+// `application/json; charset=utf-8`, los serializers se eligen solo por el media type
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	return mediaType
+}
@@ -0,0 +1,190 @@
+package serializer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/protobuf"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// orderPaid se publica con su contrato protobuf, el contrato es un `structpb.Struct` para no generar codigo en los tests
+type orderPaid struct {
+	*types.Message
+	OrderId string  `json:"orderId"`
+	Amount  float64 `json:"amount"`
+}
+
+func (o *orderPaid) MessageContentType() string {
+	return protobuf.ContentType
+}
+
+func (o *orderPaid) ToProto() (proto.Message, error) {
+	return structpb.NewStruct(map[string]interface{}{
+		"messageId": o.MessageId,
+		"orderId":   o.OrderId,
+		"amount":    o.Amount,
+	})
+}
+
+func (o *orderPaid) FromProto(message proto.Message) error {
+	fields := message.(*structpb.Struct).GetFields()
+
+	o.Message = types.NewMessage(fields["messageId"].GetStringValue())
+	o.OrderId = fields["orderId"].GetStringValue()
+	o.Amount = fields["amount"].GetNumberValue()
+
+	return nil
+}
+
+func (o *orderPaid) EmptyProto() proto.Message {
+	return &structpb.Struct{}
+}
+
+// orderShipped no tiene content type propio, se publica con el serializer por defecto
+type orderShipped struct {
+	*types.Message
+	OrderId string `json:"orderId"`
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&orderPaid{}))
+	typemapper.RegisterType(reflect.TypeOf(&orderShipped{}))
+}
+
+func newContentTypeSerializer() serializer.MessageSerializer {
+	jsonSerializer := json.NewDefaultJsonSerializer()
+
+	return serializer.NewContentTypeMessageSerializer(
+		json.NewDefaultMessageJsonSerializer(jsonSerializer),
+		protobuf.NewProtobufMessageSerializer(jsonSerializer),
+	)
+}
+
+func newOrderPaid() *orderPaid {
+	return &orderPaid{Message: types.NewMessage(uuid.NewV4().String()), OrderId: "order-1", Amount: 25.5}
+}
+
+func newOrderShipped() *orderShipped {
+	return &orderShipped{Message: types.NewMessage(uuid.NewV4().String()), OrderId: "order-1"}
+}
+
+func Test_ContentTypeMessageSerializer_Round_Trips_Json_Messages(t *testing.T) {
+	messageSerializer := newContentTypeSerializer()
+	message := newOrderShipped()
+
+	result, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", result.ContentType)
+
+	deserialized, err := messageSerializer.Deserialize(result.Data, typemapper.GetTypeName(message), result.ContentType)
+	require.NoError(t, err)
+
+	require.IsType(t, &orderShipped{}, deserialized)
+	assert.Equal(t, message.OrderId, deserialized.(*orderShipped).OrderId)
+	assert.Equal(t, message.MessageId, deserialized.GeMessageId())
+}
+
+func Test_ContentTypeMessageSerializer_Round_Trips_Protobuf_Messages(t *testing.T) {
+	messageSerializer := newContentTypeSerializer()
+	message := newOrderPaid()
+
+	result, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+	assert.Equal(t, protobuf.ContentType, result.ContentType)
+
+	deserialized, err := messageSerializer.Deserialize(result.Data, typemapper.GetTypeName(message), result.ContentType)
+	require.NoError(t, err)
+
+	require.IsType(t, &orderPaid{}, deserialized)
+	assert.Equal(t, message.OrderId, deserialized.(*orderPaid).OrderId)
+	assert.Equal(t, message.Amount, deserialized.(*orderPaid).Amount)
+	assert.Equal(t, message.MessageId, deserialized.GeMessageId())
+}
+
+func Test_ContentTypeMessageSerializer_Dispatches_By_The_Media_Type(t *testing.T) {
+	messageSerializer := newContentTypeSerializer()
+
+	paid := newOrderPaid()
+	paidResult, err := messageSerializer.Serialize(paid)
+	require.NoError(t, err)
+	shipped := newOrderShipped()
+	shippedResult, err := messageSerializer.Serialize(shipped)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		messageType string
+		contentType string
+		expected    types.IMessage
+	}{
+		{
+			name:        "json with charset",
+			data:        shippedResult.Data,
+			messageType: typemapper.GetTypeName(shipped),
+			contentType: "application/json; charset=utf-8",
+			expected:    shipped,
+		},
+		{
+			name:        "json without content type",
+			data:        shippedResult.Data,
+			messageType: typemapper.GetTypeName(shipped),
+			contentType: "",
+			expected:    shipped,
+		},
+		{
+			name:        "protobuf with parameters and upper case",
+			data:        paidResult.Data,
+			messageType: typemapper.GetTypeName(paid),
+			contentType: "Application/X-Protobuf; proto=orders.OrderPaid",
+			expected:    paid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deserialized, err := messageSerializer.Deserialize(test.data, test.messageType, test.contentType)
+			require.NoError(t, err)
+
+			assert.IsType(t, test.expected, deserialized)
+			assert.Equal(t, test.expected.GeMessageId(), deserialized.GeMessageId())
+		})
+	}
+}
+
+func Test_ContentTypeMessageSerializer_Rejects_Unsupported_Content_Types(t *testing.T) {
+	messageSerializer := newContentTypeSerializer()
+	message := newOrderShipped()
+	result, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+
+	_, err = messageSerializer.Deserialize(result.Data, typemapper.GetTypeName(message), "text/plain; charset=utf-8")
+
+	assert.Error(t, err)
+}
+
+func Test_ContentTypeMessageSerializer_Stores_The_Envelopes_With_The_Default_Serializer(t *testing.T) {
+	messageSerializer := newContentTypeSerializer()
+	message := newOrderPaid()
+
+	result, err := messageSerializer.SerializeEnvelop(*types.NewMessageEnvelope(message, map[string]interface{}{"key": "value"}))
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", result.ContentType)
+}
+
+func Test_MediaType(t *testing.T) {
+	assert.Equal(t, "application/json", serializer.MediaType("application/json"))
+	assert.Equal(t, "application/json", serializer.MediaType("application/json; charset=utf-8"))
+	assert.Equal(t, "application/x-protobuf", serializer.MediaType(" Application/X-Protobuf "))
+	assert.Equal(t, "", serializer.MediaType(""))
+}
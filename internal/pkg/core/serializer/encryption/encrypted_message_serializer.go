@@ -0,0 +1,274 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"reflect"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+
+	"emperror.dev/errors"
+	"github.com/goccy/go-json"
+)
+
+// EncryptedContentType es el content type de los mensajes cifrados, no es json para que los pipelines de schemas no
+// validen el envelope, el content type original viaja dentro del envelope
+const EncryptedContentType = "application/vnd.encrypted"
+
+const (
+	encryptionKeyPurpose = "message-encryption"
+	signingKeyPurpose    = "message-signing"
+)
+
+var (
+	// ErrInvalidSignature se devuelve cuando la firma del mensaje no coincide, el payload o sus headers se modificaron
+	ErrInvalidSignature = errors.Sentinel("invalid message signature")
+	// ErrUnsignedMessage se devuelve en modo estricto cuando llega un mensaje sin cifrar ni firmar
+	ErrUnsignedMessage = errors.Sentinel("message is not encrypted and signed")
+)
+
+// encryptedEnvelope es el payload publicado, los []byte se escriben en base64
+type encryptedEnvelope struct {
+	KeyId       string `json:"kid"`
+	ContentType string `json:"contentType"`
+	Nonce       []byte `json:"nonce"`
+	Data        []byte `json:"data"`
+	Signature   []byte `json:"signature"`
+}
+
+// encryptedMessageSerializer cifra con AES-GCM y firma con HMAC-SHA256 el payload del serializer interno
+type encryptedMessageSerializer struct {
+	serializer  serializer.MessageSerializer
+	keyProvider KeyProvider
+	strict      bool
+}
+
+// NewEncryptedMessageSerializer envuelve el serializer, los mensajes se publican cifrados con la llave actual del provider.
+// En modo estricto los consumers rechazan los mensajes en claro, si no se leen con el serializer interno
+func NewEncryptedMessageSerializer(
+	messageSerializer serializer.MessageSerializer,
+	keyProvider KeyProvider,
+	strict bool,
+) serializer.MessageSerializer {
+	return &encryptedMessageSerializer{
+		serializer:  messageSerializer,
+		keyProvider: keyProvider,
+		strict:      strict,
+	}
+}
+
+func (e *encryptedMessageSerializer) Serialize(message types.IMessage) (*serializer.EventSerializationResult, error) {
+	result, err := e.serializer.Serialize(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.seal(result)
+}
+
+func (e *encryptedMessageSerializer) SerializeObject(message interface{}) (*serializer.EventSerializationResult, error) {
+	result, err := e.serializer.SerializeObject(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.seal(result)
+}
+
+// SerializeEnvelop the outbox envelopes are stored with the inner serializer, the message is encrypted when it is published
+func (e *encryptedMessageSerializer) SerializeEnvelop(
+	messageEnvelop types.MessageEnvelope,
+) (*serializer.EventSerializationResult, error) {
+	return e.serializer.SerializeEnvelop(messageEnvelop)
+}
+
+func (e *encryptedMessageSerializer) DeserializeEnvelop(
+	data []byte,
+	messageType string,
+	contentType string,
+) (*types.MessageEnvelope, error) {
+	return e.serializer.DeserializeEnvelop(data, messageType, contentType)
+}
+
+func (e *encryptedMessageSerializer) Deserialize(
+	data []byte,
+	messageType string,
+	contentType string,
+) (types.IMessage, error) {
+	data, contentType, err := e.open(data, contentType)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in decrypting: `%s`", messageType)
+	}
+
+	return e.serializer.Deserialize(data, messageType, contentType)
+}
+
+func (e *encryptedMessageSerializer) DeserializeObject(
+	data []byte,
+	messageType string,
+	contentType string,
+) (interface{}, error) {
+	data, contentType, err := e.open(data, contentType)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in decrypting: `%s`", messageType)
+	}
+
+	return e.serializer.DeserializeObject(data, messageType, contentType)
+}
+
+func (e *encryptedMessageSerializer) DeserializeType(
+	data []byte,
+	messageType reflect.Type,
+	contentType string,
+) (types.IMessage, error) {
+	data, contentType, err := e.open(data, contentType)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in decrypting: `%s`", messageType)
+	}
+
+	return e.serializer.DeserializeType(data, messageType, contentType)
+}
+
+func (e *encryptedMessageSerializer) ContentType() string {
+	return EncryptedContentType
+}
+
+func (e *encryptedMessageSerializer) Serializer() serializer.Serializer {
+	return e.serializer.Serializer()
+}
+
+// seal cifra el payload con la llave actual, el id de la llave se devuelve en la metadata para que viaje en los headers
+func (e *encryptedMessageSerializer) seal(
+	result *serializer.EventSerializationResult,
+) (*serializer.EventSerializationResult, error) {
+	data, keyId, err := Seal(e.keyProvider, result.Data, result.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := metadata.FromMetadata(result.Metadata)
+	messageHeader.SetEncryptionKeyId(meta, keyId)
+
+	return &serializer.EventSerializationResult{
+		Data:        data,
+		ContentType: EncryptedContentType,
+		Metadata:    meta,
+	}, nil
+}
+
+// open verifica la firma y descifra el payload, devuelve el payload y el content type del serializer interno
+func (e *encryptedMessageSerializer) open(data []byte, contentType string) ([]byte, string, error) {
+	if contentType != EncryptedContentType {
+		if e.strict {
+			return nil, "", ErrUnsignedMessage
+		}
+
+		return data, contentType, nil
+	}
+
+	return Open(e.keyProvider, data)
+}
+
+// Seal cifra y firma los datos con la llave actual del provider, devuelve el envelope en json y el id de la llave usada
+func Seal(keyProvider KeyProvider, data []byte, contentType string) ([]byte, string, error) {
+	keyId := keyProvider.CurrentKeyId()
+	key, err := keyProvider.GetKey(keyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	envelope := &encryptedEnvelope{
+		KeyId:       keyId,
+		ContentType: contentType,
+		Nonce:       make([]byte, aead.NonceSize()),
+	}
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, "", errors.WrapIf(err, "error in generating the nonce")
+	}
+
+	// the key id and the inner content type are authenticated with the payload, they can't be swapped
+	envelope.Data = aead.Seal(nil, envelope.Nonce, data, envelope.additionalData())
+	envelope.Signature = envelope.sign(key)
+
+	sealed, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, "", errors.WrapIf(err, "error in marshaling the encrypted envelope")
+	}
+
+	return sealed, keyId, nil
+}
+
+// Open verifica la firma y descifra un envelope creado con Seal, devuelve los datos y su content type
+func Open(keyProvider KeyProvider, data []byte) ([]byte, string, error) {
+	envelope := &encryptedEnvelope{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return nil, "", errors.WrapIf(err, "error in unmarshaling the encrypted envelope")
+	}
+	if len(envelope.Signature) == 0 {
+		return nil, "", ErrUnsignedMessage
+	}
+
+	key, err := keyProvider.GetKey(envelope.KeyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !hmac.Equal(envelope.Signature, envelope.sign(key)) {
+		return nil, "", ErrInvalidSignature
+	}
+
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return nil, "", ErrInvalidSignature
+	}
+
+	plain, err := aead.Open(nil, envelope.Nonce, envelope.Data, envelope.additionalData())
+	if err != nil {
+		return nil, "", errors.WrapIf(ErrInvalidSignature, err.Error())
+	}
+
+	return plain, envelope.ContentType, nil
+}
+
+func (m *encryptedEnvelope) additionalData() []byte {
+	return []byte(m.KeyId + "\x00" + m.ContentType)
+}
+
+// sign firma todos los campos del envelope con la llave de firma
+func (m *encryptedEnvelope) sign(key []byte) []byte {
+	mac := hmac.New(sha256.New, deriveKey(key, signingKeyPurpose))
+	mac.Write(m.additionalData())
+	mac.Write([]byte{0})
+	mac.Write(m.Nonce)
+	mac.Write(m.Data)
+
+	return mac.Sum(nil)
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveKey(key, encryptionKeyPurpose))
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in creating the cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in creating the gcm cipher")
+	}
+
+	return aead, nil
+}
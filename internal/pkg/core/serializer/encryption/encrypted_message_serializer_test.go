@@ -0,0 +1,158 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type paymentCaptured struct {
+	*types.Message
+	PaymentId string  `json:"paymentId"`
+	Amount    float64 `json:"amount"`
+}
+
+func newPaymentCaptured() *paymentCaptured {
+	return &paymentCaptured{Message: types.NewMessage(uuid.NewV4().String()), PaymentId: "payment-1", Amount: 25}
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&paymentCaptured{}))
+}
+
+var messageType = typemapper.GetFullTypeName(&paymentCaptured{}) //nolint:gochecknoglobals
+
+func newTestSerializer(t *testing.T, currentKeyId string, strict bool) serializer.MessageSerializer {
+	t.Helper()
+
+	keyProvider, err := NewStaticKeyProvider(currentKeyId, map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 32),
+	})
+	require.NoError(t, err)
+
+	return NewEncryptedMessageSerializer(
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		keyProvider,
+		strict,
+	)
+}
+
+func Test_Serialize_Encrypts_The_Payload(t *testing.T) {
+	messageSerializer := newTestSerializer(t, "key-1", true)
+	message := newPaymentCaptured()
+
+	result, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+
+	assert.Equal(t, EncryptedContentType, result.ContentType)
+	assert.NotContains(t, string(result.Data), "payment-1")
+	assert.Equal(t, "key-1", messageHeader.GetEncryptionKeyId(metadata.FromMetadata(result.Metadata)))
+
+	deserialized, err := messageSerializer.Deserialize(result.Data, messageType, result.ContentType)
+	require.NoError(t, err)
+	assert.Equal(t, message.PaymentId, deserialized.(*paymentCaptured).PaymentId)
+}
+
+func Test_Deserialize_Reads_The_Messages_Of_A_Rotated_Key(t *testing.T) {
+	result, err := newTestSerializer(t, "key-1", true).Serialize(newPaymentCaptured())
+	require.NoError(t, err)
+
+	deserialized, err := newTestSerializer(t, "key-2", true).Deserialize(result.Data, messageType, result.ContentType)
+	require.NoError(t, err)
+	assert.Equal(t, "payment-1", deserialized.(*paymentCaptured).PaymentId)
+}
+
+func Test_Deserialize_Rejects_A_Modified_Payload(t *testing.T) {
+	messageSerializer := newTestSerializer(t, "key-1", true)
+	result, err := messageSerializer.Serialize(newPaymentCaptured())
+	require.NoError(t, err)
+
+	modified := bytes.Replace(result.Data, []byte(`"kid":"key-1"`), []byte(`"kid":"key-2"`), 1)
+	_, err = messageSerializer.Deserialize(modified, messageType, result.ContentType)
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func Test_Deserialize_Plain_Messages(t *testing.T) {
+	plain, err := json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()).Serialize(newPaymentCaptured())
+	require.NoError(t, err)
+
+	_, err = newTestSerializer(t, "key-1", true).Deserialize(plain.Data, messageType, plain.ContentType)
+	assert.ErrorIs(t, err, ErrUnsignedMessage)
+
+	deserialized, err := newTestSerializer(t, "key-1", false).Deserialize(plain.Data, messageType, plain.ContentType)
+	require.NoError(t, err)
+	assert.Equal(t, "payment-1", deserialized.(*paymentCaptured).PaymentId)
+}
+
+// el envelope cifrado no cumple el schema del mensaje, los pipelines de schemas no lo tienen que validar
+func Test_Encrypted_Messages_Pass_The_Schema_Validation_Pipelines(t *testing.T) {
+	registry := schema.NewSchemaRegistry()
+	registry.Register(&paymentCaptured{})
+	messageSerializer := newTestSerializer(t, "key-1", true)
+	message := newPaymentCaptured()
+
+	result, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+
+	published := false
+	err = schema.NewProducerSchemaValidationPipeline(registry).Handle(
+		context.Background(),
+		&pipeline.ProducerContext{
+			Message:     message,
+			Metadata:    metadata.FromMetadata(result.Metadata),
+			Body:        result.Data,
+			ContentType: result.ContentType,
+		},
+		func(context.Context) error {
+			published = true
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, published)
+
+	deserialized, err := messageSerializer.Deserialize(result.Data, messageType, result.ContentType)
+	require.NoError(t, err)
+
+	handled := false
+	err = schema.NewConsumerSchemaValidationPipeline(registry, defaultlogger.GetLogger()).Handle(
+		context.Background(),
+		types.NewMessageConsumeContext(
+			deserialized,
+			metadata.FromMetadata(result.Metadata),
+			result.ContentType,
+			messageType,
+			time.Now(),
+			1,
+			message.GeMessageId(),
+			"",
+			result.Data,
+		),
+		func(context.Context) error {
+			handled = true
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, handled)
+}
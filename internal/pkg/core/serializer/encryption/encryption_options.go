@@ -0,0 +1,36 @@
+package encryption
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	EnvKeyProvider  = "env"
+	FileKeyProvider = "file"
+)
+
+type EncryptionOptions struct {
+	// Enabled cifra y firma el payload de los mensajes publicados, sin esta opcion los mensajes viajan en claro
+	Enabled bool `mapstructure:"enabled"`
+	// Strict rechaza los mensajes sin cifrar ni firmar, sin esta opcion se aceptan para poder migrar los productores de a uno
+	Strict bool `mapstructure:"strict"`
+	// KeyId es la llave con la que se cifran los mensajes nuevos, las llaves anteriores se siguen usando para leer
+	KeyId string `mapstructure:"keyId"`
+	// KeyProvider es el origen de las llaves, `env` o `file`
+	KeyProvider string `mapstructure:"keyProvider" default:"env"`
+	// KeysEnvironmentVariable es la variable con las llaves en formato `id1=base64,id2=base64`
+	KeysEnvironmentVariable string `mapstructure:"keysEnvironmentVariable" default:"MESSAGE_ENCRYPTION_KEYS"`
+	// KeysFile es un archivo json con las llaves en formato `{"id1": "base64"}`
+	KeysFile string `mapstructure:"keysFile"`
+}
+
+func ProvideConfig(environment environment.Environment) (*EncryptionOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[EncryptionOptions]())
+	cfg, err := config.BindConfigKey[EncryptionOptions](optionName)
+
+	return cfg, err
+}
@@ -0,0 +1,134 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/goccy/go-json"
+)
+
+const defaultKeysEnvironmentVariable = "MESSAGE_ENCRYPTION_KEYS"
+
+// ErrKeyNotFound se devuelve cuando un mensaje se cifro con una llave que el provider no conoce, por ejemplo una llave ya retirada
+var ErrKeyNotFound = errors.Sentinel("encryption key not found")
+
+// KeyProvider entrega las llaves de cifrado por su id, la llave actual cifra los mensajes nuevos
+// y las demas solo se usan para leer los mensajes publicados antes de una rotacion
+type KeyProvider interface {
+	CurrentKeyId() string
+	GetKey(keyId string) ([]byte, error)
+}
+
+type staticKeyProvider struct {
+	currentKeyId string
+	keys         map[string][]byte
+}
+
+// NewStaticKeyProvider crea un provider con las llaves en memoria, las llaves son de 16, 24 o 32 bytes
+func NewStaticKeyProvider(currentKeyId string, keys map[string][]byte) (KeyProvider, error) {
+	if currentKeyId == "" {
+		return nil, errors.New("the current encryption key id is empty")
+	}
+	for keyId, key := range keys {
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, errors.Errorf("the encryption key `%s` must have 16, 24 or 32 bytes", keyId)
+		}
+	}
+	if _, ok := keys[currentKeyId]; !ok {
+		return nil, errors.WrapIff(ErrKeyNotFound, "current key `%s`", currentKeyId)
+	}
+
+	return &staticKeyProvider{currentKeyId: currentKeyId, keys: keys}, nil
+}
+
+// NewEnvKeyProvider lee las llaves de la variable de entorno en formato `id1=base64,id2=base64`, pensado para desarrollo local
+func NewEnvKeyProvider(currentKeyId string, variable string) (KeyProvider, error) {
+	if variable == "" {
+		variable = defaultKeysEnvironmentVariable
+	}
+
+	keys := map[string][]byte{}
+	for _, pair := range strings.Split(os.Getenv(variable), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		keyId, encoded, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid encryption key in `%s`, the format is `id=base64`", variable)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.WrapIff(err, "error in decoding the encryption key `%s`", keyId)
+		}
+		keys[keyId] = key
+	}
+
+	return NewStaticKeyProvider(currentKeyId, keys)
+}
+
+// NewFileKeyProvider lee las llaves de un archivo json en formato `{"id1": "base64"}`
+func NewFileKeyProvider(currentKeyId string, path string) (KeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in reading the encryption keys file `%s`", path)
+	}
+
+	var encodedKeys map[string]string
+	if err := json.Unmarshal(data, &encodedKeys); err != nil {
+		return nil, errors.WrapIff(err, "error in parsing the encryption keys file `%s`", path)
+	}
+
+	keys := make(map[string][]byte, len(encodedKeys))
+	for keyId, encoded := range encodedKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.WrapIff(err, "error in decoding the encryption key `%s`", keyId)
+		}
+		keys[keyId] = key
+	}
+
+	return NewStaticKeyProvider(currentKeyId, keys)
+}
+
+// NewKeyProvider crea el provider configurado en las opciones
+func NewKeyProvider(options *EncryptionOptions) (KeyProvider, error) {
+	switch options.KeyProvider {
+	case "", EnvKeyProvider:
+		return NewEnvKeyProvider(options.KeyId, options.KeysEnvironmentVariable)
+	case FileKeyProvider:
+		return NewFileKeyProvider(options.KeyId, options.KeysFile)
+	default:
+		return nil, errors.Errorf("encryption key provider `%s` is not supported", options.KeyProvider)
+	}
+}
+
+func (p *staticKeyProvider) CurrentKeyId() string {
+	return p.currentKeyId
+}
+
+func (p *staticKeyProvider) GetKey(keyId string) ([]byte, error) {
+	key, ok := p.keys[keyId]
+	if !ok {
+		return nil, errors.WrapIff(ErrKeyNotFound, "key `%s`", keyId)
+	}
+
+	return key, nil
+}
+
+// deriveKey separa la llave de cifrado y la de firma, asi una misma llave del provider no se usa para las dos cosas
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	derived := mac.Sum(nil)
+
+	return derived[:len(key)]
+}
@@ -1,7 +1,11 @@
 package serializer
 
+import "github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+
 // EventSerializationResult is a struct that contains the data and content type of an event
 type EventSerializationResult struct {
 	Data        []byte
 	ContentType string
+	// Metadata son los headers que el serializer agrega al mensaje, por ejemplo el id de la llave de un payload cifrado
+	Metadata metadata.Metadata
 }
@@ -8,6 +8,7 @@ import (
 	typeMapper "github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
 
 	"emperror.dev/errors"
+	"github.com/goccy/go-json"
 )
 
 type DefaultMessageJsonSerializer struct {
@@ -42,11 +43,56 @@ func (m *DefaultMessageJsonSerializer) SerializeObject(
 	return result, nil
 }
 
+// messageEnvelopeJson es la representacion json de un MessageEnvelope, el mensaje se guarda sin interpretar hasta conocer su tipo
+type messageEnvelopeJson struct {
+	Message json.RawMessage        `json:"message"`
+	Headers map[string]interface{} `json:"headers"`
+}
+
 func (m *DefaultMessageJsonSerializer) SerializeEnvelop(
 	messageEnvelop types.MessageEnvelope,
 ) (*serializer.EventSerializationResult, error) {
-	// TODO implement me
-	panic("implement me")
+	if messageEnvelop.Message == nil {
+		return nil, errors.New("messageEnvelop.Message is nil")
+	}
+
+	eventType := typeMapper.GetTypeName(messageEnvelop.Message)
+
+	messageData, err := m.serializer.Marshal(messageEnvelop.Message)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling: `%s`", eventType)
+	}
+
+	data, err := m.serializer.Marshal(
+		&messageEnvelopeJson{Message: messageData, Headers: messageEnvelop.Headers},
+	)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling envelope of: `%s`", eventType)
+	}
+
+	return &serializer.EventSerializationResult{Data: data, ContentType: m.ContentType()}, nil
+}
+
+func (m *DefaultMessageJsonSerializer) DeserializeEnvelop(
+	data []byte,
+	messageType string,
+	contentType string,
+) (*types.MessageEnvelope, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	envelope := &messageEnvelopeJson{}
+	if err := m.serializer.Unmarshal(data, envelope); err != nil {
+		return nil, errors.WrapIff(err, "error in Unmarshaling envelope of: `%s`", messageType)
+	}
+
+	message, err := m.Deserialize(envelope.Message, messageType, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewMessageEnvelope(message, envelope.Headers), nil
 }
 
 func (m *DefaultMessageJsonSerializer) Deserialize(
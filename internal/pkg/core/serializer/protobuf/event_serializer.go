@@ -0,0 +1,94 @@
+package protobuf
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	typeMapper "github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+)
+
+type ProtobufEventSerializer struct {
+	serializer serializer.Serializer
+}
+
+func NewProtobufEventSerializer(s serializer.Serializer) serializer.EventSerializer {
+	return &ProtobufEventSerializer{serializer: s}
+}
+
+func (s *ProtobufEventSerializer) Serialize(event domain.IDomainEvent) (*serializer.EventSerializationResult, error) {
+	return s.SerializeObject(event)
+}
+
+func (s *ProtobufEventSerializer) SerializeObject(event interface{}) (*serializer.EventSerializationResult, error) {
+	if event == nil {
+		return &serializer.EventSerializationResult{Data: nil, ContentType: s.ContentType()}, nil
+	}
+
+	data, err := marshal(event)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling: `%s`", typeMapper.GetTypeName(event))
+	}
+
+	return &serializer.EventSerializationResult{Data: data, ContentType: s.ContentType()}, nil
+}
+
+func (s *ProtobufEventSerializer) ContentType() string {
+	return ContentType
+}
+
+func (s *ProtobufEventSerializer) Deserialize(
+	data []byte,
+	eventType string,
+	contentType string,
+) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	targetEventPointer := typeMapper.EmptyInstanceByTypeNameAndImplementedInterface[domain.IDomainEvent](
+		eventType,
+	)
+
+	if targetEventPointer == nil {
+		return nil, errors.Errorf("event type `%s` is not impelemted IDomainEvent or can't be instansiated", eventType)
+	}
+
+	if contentType != s.ContentType() {
+		return nil, errors.Errorf("contentType: %s is not supported", contentType)
+	}
+
+	if err := unmarshal(data, targetEventPointer); err != nil {
+		return nil, errors.WrapIff(err, "error in Unmarshaling: `%s`", eventType)
+	}
+
+	return targetEventPointer, nil
+}
+
+func (s *ProtobufEventSerializer) DeserializeType(
+	data []byte,
+	eventType reflect.Type,
+	contentType string,
+) (domain.IDomainEvent, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	result, err := s.Deserialize(data, typeMapper.GetTypeNameByType(eventType), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	event, ok := result.(domain.IDomainEvent)
+	if !ok {
+		return nil, errors.New("result is not a domain event")
+	}
+
+	return event, nil
+}
+
+func (s *ProtobufEventSerializer) Serializer() serializer.Serializer {
+	return s.serializer
+}
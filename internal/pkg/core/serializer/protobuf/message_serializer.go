@@ -0,0 +1,165 @@
+package protobuf
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	typeMapper "github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+)
+
+type ProtobufMessageSerializer struct {
+	serializer serializer.Serializer
+}
+
+// NewProtobufMessageSerializer el serializer se usa para el envelope del outbox, el mensaje dentro del envelope va en protobuf
+func NewProtobufMessageSerializer(s serializer.Serializer) serializer.MessageSerializer {
+	return &ProtobufMessageSerializer{serializer: s}
+}
+
+func (m *ProtobufMessageSerializer) Serialize(message types.IMessage) (*serializer.EventSerializationResult, error) {
+	return m.SerializeObject(message)
+}
+
+func (m *ProtobufMessageSerializer) SerializeObject(
+	message interface{},
+) (*serializer.EventSerializationResult, error) {
+	if message == nil {
+		return &serializer.EventSerializationResult{Data: nil, ContentType: m.ContentType()}, nil
+	}
+
+	data, err := marshal(message)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling: `%s`", typeMapper.GetTypeName(message))
+	}
+
+	return &serializer.EventSerializationResult{Data: data, ContentType: m.ContentType()}, nil
+}
+
+// messageEnvelopeProto keeps the headers readable in the outbox, the message is stored with its protobuf bytes
+type messageEnvelopeProto struct {
+	Message []byte                 `json:"message"`
+	Headers map[string]interface{} `json:"headers"`
+}
+
+func (m *ProtobufMessageSerializer) SerializeEnvelop(
+	messageEnvelop types.MessageEnvelope,
+) (*serializer.EventSerializationResult, error) {
+	if messageEnvelop.Message == nil {
+		return nil, errors.New("messageEnvelop.Message is nil")
+	}
+
+	eventType := typeMapper.GetTypeName(messageEnvelop.Message)
+
+	messageData, err := marshal(messageEnvelop.Message)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling: `%s`", eventType)
+	}
+
+	data, err := m.serializer.Marshal(
+		&messageEnvelopeProto{Message: messageData, Headers: messageEnvelop.Headers},
+	)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in Marshaling envelope of: `%s`", eventType)
+	}
+
+	return &serializer.EventSerializationResult{Data: data, ContentType: m.ContentType()}, nil
+}
+
+func (m *ProtobufMessageSerializer) DeserializeEnvelop(
+	data []byte,
+	messageType string,
+	contentType string,
+) (*types.MessageEnvelope, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	envelope := &messageEnvelopeProto{}
+	if err := m.serializer.Unmarshal(data, envelope); err != nil {
+		return nil, errors.WrapIff(err, "error in Unmarshaling envelope of: `%s`", messageType)
+	}
+
+	message, err := m.Deserialize(envelope.Message, messageType, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewMessageEnvelope(message, envelope.Headers), nil
+}
+
+func (m *ProtobufMessageSerializer) Deserialize(
+	data []byte,
+	messageType string,
+	contentType string,
+) (types.IMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	targetMessagePointer := typeMapper.EmptyInstanceByTypeNameAndImplementedInterface[types.IMessage](
+		messageType,
+	)
+
+	if targetMessagePointer == nil {
+		return nil, errors.Errorf("message type `%s` is not impelemted IMessage or can't be instansiated", messageType)
+	}
+
+	if contentType != m.ContentType() {
+		return nil, errors.Errorf("contentType: %s is not supported", contentType)
+	}
+
+	if err := unmarshal(data, targetMessagePointer); err != nil {
+		return nil, errors.WrapIff(err, "error in Unmarshaling: `%s`", messageType)
+	}
+
+	return targetMessagePointer.(types.IMessage), nil
+}
+
+func (m *ProtobufMessageSerializer) DeserializeObject(
+	data []byte,
+	messageType string,
+	contentType string,
+) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	targetMessagePointer := typeMapper.InstanceByTypeName(messageType)
+
+	if targetMessagePointer == nil {
+		return nil, errors.Errorf("message type `%s` can't be instansiated", messageType)
+	}
+
+	if contentType != m.ContentType() {
+		return nil, errors.Errorf("contentType: %s is not supported", contentType)
+	}
+
+	if err := unmarshal(data, targetMessagePointer); err != nil {
+		return nil, errors.WrapIff(err, "error in Unmarshaling: `%s`", messageType)
+	}
+
+	return targetMessagePointer, nil
+}
+
+func (m *ProtobufMessageSerializer) DeserializeType(
+	data []byte,
+	messageType reflect.Type,
+	contentType string,
+) (types.IMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	return m.Deserialize(data, typeMapper.GetTypeNameByType(messageType), contentType)
+}
+
+func (m *ProtobufMessageSerializer) ContentType() string {
+	return ContentType
+}
+
+func (m *ProtobufMessageSerializer) Serializer() serializer.Serializer {
+	return m.serializer
+}
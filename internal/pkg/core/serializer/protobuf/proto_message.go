@@ -0,0 +1,54 @@
+package protobuf
+
+import (
+	"emperror.dev/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType es el content type de los mensajes serializados con protobuf
+const ContentType = "application/x-protobuf"
+
+// ProtoConvertible lo implementan los mensajes y eventos que se serializan con su contrato protobuf generado,
+// los tipos generados en `genproto` se serializan directamente porque implementan `proto.Message`
+type ProtoConvertible interface {
+	// ToProto convierte el mensaje a su contrato protobuf
+	ToProto() (proto.Message, error)
+	// FromProto carga el mensaje desde su contrato protobuf
+	FromProto(message proto.Message) error
+	// EmptyProto devuelve una instancia vacia del contrato protobuf, se usa para deserializar
+	EmptyProto() proto.Message
+}
+
+// marshal serializes the value with its protobuf contract
+func marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case proto.Message:
+		return proto.Marshal(v)
+	case ProtoConvertible:
+		protoMessage, err := v.ToProto()
+		if err != nil {
+			return nil, err
+		}
+
+		return proto.Marshal(protoMessage)
+	default:
+		return nil, errors.Errorf("type `%T` doesn't implement `proto.Message` or `ProtoConvertible`", value)
+	}
+}
+
+// unmarshal deserializes the data to the target pointer with its protobuf contract
+func unmarshal(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, t)
+	case ProtoConvertible:
+		protoMessage := t.EmptyProto()
+		if err := proto.Unmarshal(data, protoMessage); err != nil {
+			return err
+		}
+
+		return t.FromProto(protoMessage)
+	default:
+		return errors.Errorf("type `%T` doesn't implement `proto.Message` or `ProtoConvertible`", target)
+	}
+}
@@ -0,0 +1,146 @@
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"emperror.dev/errors"
+)
+
+// eventTypeVersionRegex separa el nombre y la version de los tipos de evento con el sufijo `V<n>`, por ejemplo `*OrderCreatedV1`
+var (
+	eventTypeVersionRegex   = regexp.MustCompile(`^(.+)V(\d+)$`)   //nolint:gochecknoglobals
+	logicalTypeVersionRegex = regexp.MustCompile(`^(.+)\.v(\d+)$`) //nolint:gochecknoglobals
+)
+
+// UpcastFunc transforma el json de una version de un evento a la forma de la version siguiente
+type UpcastFunc func(data []byte) ([]byte, error)
+
+// UpcasterRegistry guarda las transformaciones de cada version de los eventos, los eventos guardados con una version
+// anterior se transforman hasta la ultima version antes de deserializarlos
+type UpcasterRegistry interface {
+	// Register registra la transformacion de la version `version` del evento `eventName` a la version siguiente,
+	// `eventName` es el nombre del tipo sin el sufijo de la version, por ejemplo `OrderCreated`
+	Register(eventName string, version int, upcast UpcastFunc) error
+	// Upcast aplica la cadena de transformaciones desde la version del tipo guardado, devuelve el tipo de la ultima version y su json
+	Upcast(eventType string, data []byte) (string, []byte, error)
+}
+
+type upcasterKey struct {
+	eventName string
+	version   int
+}
+
+type upcasterRegistry struct {
+	upcasters map[upcasterKey]UpcastFunc
+	lock      sync.RWMutex
+}
+
+func NewUpcasterRegistry() UpcasterRegistry {
+	return &upcasterRegistry{upcasters: map[upcasterKey]UpcastFunc{}}
+}
+
+func (r *upcasterRegistry) Register(eventName string, version int, upcast UpcastFunc) error {
+	if upcast == nil {
+		return errors.New("upcast func is nil")
+	}
+
+	key := upcasterKey{eventName: strings.TrimPrefix(eventName, "*"), version: version}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.upcasters[key]; ok {
+		return errors.Errorf("upcaster for event `%s` version %d is already registered", key.eventName, version)
+	}
+	r.upcasters[key] = upcast
+
+	return nil
+}
+
+func (r *upcasterRegistry) Upcast(eventType string, data []byte) (string, []byte, error) {
+	pointer := strings.HasPrefix(eventType, "*")
+	eventName, version := ParseEventType(eventType)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	upcasted := false
+	for {
+		upcast, ok := r.upcasters[upcasterKey{eventName: eventName, version: version}]
+		if !ok {
+			break
+		}
+
+		var err error
+		data, err = upcast(data)
+		if err != nil {
+			return "", nil, errors.WrapIff(err, "error in upcasting event `%s` from version %d", eventName, version)
+		}
+		version++
+		upcasted = true
+	}
+
+	// the events without upcasters keep their type name, the versions are only added to the upcasted ones
+	if !upcasted {
+		return eventType, data, nil
+	}
+
+	return FormatEventType(eventName, version, pointer), data, nil
+}
+
+// ParseEventType devuelve el nombre y la version de un tipo de evento, acepta los nombres de tipo de go con el sufijo `V<n>`
+// y los nombres logicos del typeregistry con el sufijo `.v<n>`, los tipos sin sufijo son la version 1
+func ParseEventType(eventType string) (string, int) {
+	eventType = strings.TrimPrefix(eventType, "*")
+
+	matches := logicalTypeVersionRegex.FindStringSubmatch(eventType)
+	if matches == nil {
+		matches = eventTypeVersionRegex.FindStringSubmatch(eventType)
+	}
+	if matches == nil {
+		return eventType, 1
+	}
+
+	version, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return eventType, 1
+	}
+
+	return matches[1], version
+}
+
+// FormatEventType devuelve el nombre del tipo de la version del evento, con `*` para los tipos puntero como en `typemapper.GetTypeName`
+// los nombres logicos (con `.`, como `orders.order-created`) usan el sufijo `.v<n>`
+func FormatEventType(eventName string, version int, pointer bool) string {
+	if !pointer && strings.Contains(eventName, ".") {
+		return fmt.Sprintf("%s.v%d", eventName, version)
+	}
+
+	eventType := fmt.Sprintf("%sV%d", eventName, version)
+	if pointer {
+		return "*" + eventType
+	}
+
+	return eventType
+}
+
+// JsonUpcaster crea un UpcastFunc que transforma el evento como un mapa, por ejemplo para renombrar o agregar campos
+func JsonUpcaster(upcast func(event map[string]interface{}) error) UpcastFunc {
+	return func(data []byte) ([]byte, error) {
+		event := map[string]interface{}{}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, err
+		}
+
+		if err := upcast(event); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(event)
+	}
+}
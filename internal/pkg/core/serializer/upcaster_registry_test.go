@@ -0,0 +1,154 @@
+package serializer
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseEventType(t *testing.T) {
+	tests := []struct {
+		eventType string
+		name      string
+		version   int
+	}{
+		{eventType: "*OrderCreatedV1", name: "OrderCreated", version: 1},
+		{eventType: "OrderCreatedV12", name: "OrderCreated", version: 12},
+		{eventType: "*OrderCreated", name: "OrderCreated", version: 1},
+		{eventType: "orders.order-created.v1", name: "orders.order-created", version: 1},
+		{eventType: "orders.order-created.v3", name: "orders.order-created", version: 3},
+		{eventType: "orders.order-created", name: "orders.order-created", version: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.eventType, func(t *testing.T) {
+			name, version := ParseEventType(test.eventType)
+			assert.Equal(t, test.name, name)
+			assert.Equal(t, test.version, version)
+		})
+	}
+}
+
+func Test_FormatEventType(t *testing.T) {
+	assert.Equal(t, "*OrderCreatedV2", FormatEventType("OrderCreated", 2, true))
+	assert.Equal(t, "OrderCreatedV2", FormatEventType("OrderCreated", 2, false))
+	assert.Equal(t, "orders.order-created.v2", FormatEventType("orders.order-created", 2, false))
+}
+
+func Test_FormatEventType_Is_The_Inverse_Of_ParseEventType(t *testing.T) {
+	for _, eventType := range []string{"*OrderCreatedV1", "OrderCreatedV4", "orders.order-created.v1"} {
+		name, version := ParseEventType(eventType)
+		assert.Equal(t, eventType, FormatEventType(name, version, eventType[0] == '*'))
+	}
+}
+
+// renameField crea un upcaster que renombra un campo del evento
+func renameField(from string, to string) UpcastFunc {
+	return JsonUpcaster(func(event map[string]interface{}) error {
+		event[to] = event[from]
+		delete(event, from)
+
+		return nil
+	})
+}
+
+func newChainRegistry(t *testing.T, eventName string) UpcasterRegistry {
+	t.Helper()
+
+	registry := NewUpcasterRegistry()
+	require.NoError(t, registry.Register(eventName, 1, renameField("customerName", "accountEmail")))
+	require.NoError(t, registry.Register(eventName, 2, renameField("address", "deliveryAddress")))
+
+	return registry
+}
+
+func Test_Upcast_Applies_The_Chain_Up_To_The_Latest_Version(t *testing.T) {
+	tests := []struct {
+		name         string
+		eventName    string
+		eventType    string
+		upcastedType string
+	}{
+		{name: "go type name", eventName: "OrderCreated", eventType: "*OrderCreatedV1", upcastedType: "*OrderCreatedV3"},
+		{
+			name:         "logical type name",
+			eventName:    "orders.order-created",
+			eventType:    "orders.order-created.v1",
+			upcastedType: "orders.order-created.v3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := newChainRegistry(t, test.eventName)
+
+			eventType, data, err := registry.Upcast(
+				test.eventType,
+				[]byte(`{"customerName":"john@example.com","address":"street 1"}`),
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.upcastedType, eventType)
+			assert.JSONEq(t, `{"accountEmail":"john@example.com","deliveryAddress":"street 1"}`, string(data))
+		})
+	}
+}
+
+func Test_Upcast_Starts_From_The_Stored_Version(t *testing.T) {
+	registry := newChainRegistry(t, "OrderCreated")
+
+	eventType, data, err := registry.Upcast(
+		"*OrderCreatedV2",
+		[]byte(`{"accountEmail":"john@example.com","address":"street 1"}`),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "*OrderCreatedV3", eventType)
+	assert.JSONEq(t, `{"accountEmail":"john@example.com","deliveryAddress":"street 1"}`, string(data))
+}
+
+func Test_Upcast_Keeps_The_Events_Without_Upcasters(t *testing.T) {
+	registry := newChainRegistry(t, "OrderCreated")
+	payload := []byte(`{"accountEmail":"john@example.com"}`)
+
+	for _, stored := range []string{"*OrderCreatedV3", "*OrderUpdated", "orders.order-updated.v1"} {
+		eventType, data, err := registry.Upcast(stored, payload)
+		require.NoError(t, err)
+
+		assert.Equal(t, stored, eventType)
+		assert.Equal(t, payload, data)
+	}
+}
+
+func Test_Upcast_Returns_The_Upcaster_Error(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	require.NoError(t, registry.Register("OrderCreated", 1, renameField("customerName", "accountEmail")))
+	require.NoError(t, registry.Register("OrderCreated", 2, func(data []byte) ([]byte, error) {
+		return nil, errors.New("missing delivery address")
+	}))
+
+	_, _, err := registry.Upcast("*OrderCreatedV1", []byte(`{"customerName":"john@example.com"}`))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version 2")
+	assert.Contains(t, err.Error(), "missing delivery address")
+}
+
+func Test_Upcast_Returns_The_Error_Of_Invalid_Json(t *testing.T) {
+	registry := newChainRegistry(t, "OrderCreated")
+
+	_, _, err := registry.Upcast("*OrderCreatedV1", []byte(`not json`))
+
+	assert.Error(t, err)
+}
+
+func Test_Register_Rejects_Duplicated_And_Nil_Upcasters(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	require.NoError(t, registry.Register("OrderCreated", 1, renameField("customerName", "accountEmail")))
+
+	// the pointer prefix is not part of the event name
+	assert.Error(t, registry.Register("*OrderCreated", 1, renameField("customerName", "accountEmail")))
+	assert.Error(t, registry.Register("OrderCreated", 2, nil))
+}
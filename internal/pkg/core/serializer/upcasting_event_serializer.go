@@ -0,0 +1,60 @@
+package serializer
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+)
+
+// upcastingEventSerializer transforms the events stored with a previous version before deserializing them,
+// the aggregates and projections only see the latest version of each event
+type upcastingEventSerializer struct {
+	eventSerializer EventSerializer
+	registry        UpcasterRegistry
+}
+
+// NewUpcastingEventSerializer las transformaciones trabajan sobre el json, el serializer envuelto tiene que ser el de json
+func NewUpcastingEventSerializer(eventSerializer EventSerializer, registry UpcasterRegistry) EventSerializer {
+	return &upcastingEventSerializer{
+		eventSerializer: eventSerializer,
+		registry:        registry,
+	}
+}
+
+func (s *upcastingEventSerializer) Serialize(event domain.IDomainEvent) (*EventSerializationResult, error) {
+	return s.eventSerializer.Serialize(event)
+}
+
+func (s *upcastingEventSerializer) SerializeObject(event interface{}) (*EventSerializationResult, error) {
+	return s.eventSerializer.SerializeObject(event)
+}
+
+func (s *upcastingEventSerializer) Deserialize(data []byte, eventType string, contentType string) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	eventType, data, err := s.registry.Upcast(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.eventSerializer.Deserialize(data, eventType, contentType)
+}
+
+// DeserializeType the target type is already the version the caller expects, the data is deserialized without upcasting
+func (s *upcastingEventSerializer) DeserializeType(
+	data []byte,
+	eventType reflect.Type,
+	contentType string,
+) (domain.IDomainEvent, error) {
+	return s.eventSerializer.DeserializeType(data, eventType, contentType)
+}
+
+func (s *upcastingEventSerializer) ContentType() string {
+	return s.eventSerializer.ContentType()
+}
+
+func (s *upcastingEventSerializer) Serializer() Serializer {
+	return s.eventSerializer.Serializer()
+}
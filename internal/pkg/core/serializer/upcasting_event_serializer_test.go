@@ -0,0 +1,97 @@
+package serializer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// OrderPlacedV2 es la ultima version del evento, la version 1 guardaba `customerName` en lugar de `accountEmail`
+type OrderPlacedV2 struct {
+	*domain.DomainEvent
+	AccountEmail string `json:"accountEmail"`
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&OrderPlacedV2{}))
+}
+
+func newUpcastingSerializer(t *testing.T, upcast serializer.UpcastFunc) serializer.EventSerializer {
+	t.Helper()
+
+	registry := serializer.NewUpcasterRegistry()
+	require.NoError(t, registry.Register("OrderPlaced", 1, upcast))
+
+	return serializer.NewUpcastingEventSerializer(
+		json.NewDefaultEventJsonSerializer(json.NewDefaultJsonSerializer()),
+		registry,
+	)
+}
+
+var renameCustomerName = serializer.JsonUpcaster(func(event map[string]interface{}) error { //nolint:gochecknoglobals
+	event["accountEmail"] = event["customerName"]
+	delete(event, "customerName")
+
+	return nil
+})
+
+func Test_Deserialize_Upcasts_The_Stored_Version(t *testing.T) {
+	eventSerializer := newUpcastingSerializer(t, renameCustomerName)
+
+	event, err := eventSerializer.Deserialize(
+		[]byte(`{"customerName":"john@example.com"}`),
+		"*OrderPlacedV1",
+		eventSerializer.ContentType(),
+	)
+	require.NoError(t, err)
+
+	require.IsType(t, &OrderPlacedV2{}, event)
+	assert.Equal(t, "john@example.com", event.(*OrderPlacedV2).AccountEmail)
+}
+
+func Test_Deserialize_Passes_Through_The_Latest_Version(t *testing.T) {
+	eventSerializer := newUpcastingSerializer(t, renameCustomerName)
+
+	event, err := eventSerializer.Deserialize(
+		[]byte(`{"accountEmail":"john@example.com"}`),
+		"*OrderPlacedV2",
+		eventSerializer.ContentType(),
+	)
+	require.NoError(t, err)
+
+	require.IsType(t, &OrderPlacedV2{}, event)
+	assert.Equal(t, "john@example.com", event.(*OrderPlacedV2).AccountEmail)
+}
+
+func Test_Deserialize_Returns_The_Upcaster_Error(t *testing.T) {
+	eventSerializer := newUpcastingSerializer(t, func(data []byte) ([]byte, error) {
+		return nil, errors.New("unknown customer")
+	})
+
+	event, err := eventSerializer.Deserialize(
+		[]byte(`{"customerName":"john@example.com"}`),
+		"*OrderPlacedV1",
+		eventSerializer.ContentType(),
+	)
+
+	assert.Nil(t, event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown customer")
+}
+
+func Test_Deserialize_Returns_Nil_For_Nil_Data(t *testing.T) {
+	eventSerializer := newUpcastingSerializer(t, renameCustomerName)
+
+	event, err := eventSerializer.Deserialize(nil, "*OrderPlacedV1", eventSerializer.ContentType())
+
+	require.NoError(t, err)
+	assert.Nil(t, event)
+}
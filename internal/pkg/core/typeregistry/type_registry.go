@@ -0,0 +1,154 @@
+package typeregistry
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+)
+
+// el registry guarda el nombre logico estable de los eventos y mensajes, los nombres guardados en los streams, el outbox
+// y los brokers no dependen del paquete del tipo y se pueden mover los tipos sin romper la deserializacion
+var (
+	lock sync.RWMutex
+	// names es el nombre logico de cada tipo, los tipos se guardan sin puntero
+	names = map[reflect.Type]string{} //nolint:gochecknoglobals
+	// typesByName son los tipos de los nombres logicos y de sus aliases
+	typesByName = map[string]reflect.Type{} //nolint:gochecknoglobals
+	// registrationErrors son los nombres duplicados, Validate los devuelve en el arranque de la aplicacion
+	registrationErrors error //nolint:gochecknoglobals
+)
+
+// Register registra el nombre logico del tipo T, por ejemplo `orders.order-created.v1`. Los aliases son los nombres
+// con los que se guardaron los mensajes antes de registrar el tipo, por ejemplo `*OrderCreatedV1`. El nombre logico no cambia
+// los exchanges, colas y routing keys del tipo, siguen con el nombre en snake case para no dejar colas huerfanas en un despliegue
+func Register[T any](name string, aliases ...string) error {
+	return RegisterType(reflect.TypeOf((*T)(nil)).Elem(), name, aliases...)
+}
+
+// RegisterType registra el nombre logico del tipo, los nombres duplicados se guardan para fallar en Validate
+func RegisterType(typ reflect.Type, name string, aliases ...string) error {
+	typ = baseType(typ)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := register(typ, name, aliases)
+	if err != nil {
+		registrationErrors = errors.Append(registrationErrors, err)
+	}
+
+	return err
+}
+
+func register(typ reflect.Type, name string, aliases []string) error {
+	if name == "" {
+		return errors.Errorf("the logical name of type `%s` is empty", typ.String())
+	}
+
+	if registered, ok := names[typ]; ok && registered != name {
+		return errors.Errorf(
+			"type `%s` is already registered with name `%s`, it can't be registered with name `%s`",
+			typ.String(),
+			registered,
+			name,
+		)
+	}
+
+	for _, key := range append([]string{name}, aliases...) {
+		if registered, ok := typesByName[key]; ok && registered != typ {
+			return errors.Errorf(
+				"name `%s` of type `%s` is already registered by type `%s`",
+				key,
+				typ.String(),
+				registered.String(),
+			)
+		}
+	}
+
+	names[typ] = name
+	for _, key := range append([]string{name}, aliases...) {
+		typesByName[key] = typ
+		// the serializers instantiate the types with typemapper, the logical names resolve to the pointer type
+		typemapper.RegisterTypeWithKey(key, reflect.PointerTo(typ))
+	}
+	typemapper.RegisterType(reflect.PointerTo(typ))
+
+	return nil
+}
+
+// Validate devuelve los nombres duplicados de las registraciones, la aplicacion no debe arrancar con nombres duplicados
+func Validate() error {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return registrationErrors
+}
+
+// GetName devuelve el nombre logico del valor, devuelve false si su tipo no esta registrado
+func GetName(value interface{}) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+
+	return GetNameByType(reflect.TypeOf(value))
+}
+
+func GetNameByType(typ reflect.Type) (string, bool) {
+	if typ == nil {
+		return "", false
+	}
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	name, ok := names[baseType(typ)]
+
+	return name, ok
+}
+
+// TypeByName devuelve el tipo de un nombre logico o de un alias
+func TypeByName(name string) (reflect.Type, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	typ, ok := typesByName[name]
+
+	return typ, ok
+}
+
+// GetTypeName devuelve el nombre logico del valor, los tipos sin registrar usan el nombre corto de `typemapper.GetTypeName`
+func GetTypeName(value interface{}) string {
+	if name, ok := GetName(value); ok {
+		return name
+	}
+
+	return typemapper.GetTypeName(value)
+}
+
+func GetTypeNameByType(typ reflect.Type) string {
+	if name, ok := GetNameByType(typ); ok {
+		return name
+	}
+
+	return typemapper.GetTypeNameByType(typ)
+}
+
+// GetFullTypeName devuelve el nombre logico del valor, los tipos sin registrar usan el nombre con el paquete de `typemapper.GetFullTypeName`
+func GetFullTypeName(value interface{}) string {
+	if name, ok := GetName(value); ok {
+		return name
+	}
+
+	return typemapper.GetFullTypeName(value)
+}
+
+func baseType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
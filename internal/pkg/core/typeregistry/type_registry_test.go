@@ -0,0 +1,115 @@
+package typeregistry_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// el registry es global, cada test registra sus propios tipos
+
+type PaymentCapturedV1 struct {
+	PaymentId string `json:"paymentId"`
+}
+
+type RefundIssuedV1 struct {
+	RefundId string `json:"refundId"`
+}
+
+type ParcelShipped struct{}
+
+type ParcelLost struct{}
+
+type CouponRedeemed struct{}
+
+type CouponExpired struct{}
+
+type NotRegisteredEvent struct{}
+
+func Test_Register_Resolves_The_Logical_Name(t *testing.T) {
+	require.NoError(t, typeregistry.Register[PaymentCapturedV1]("payments.payment-captured.v1"))
+
+	for _, value := range []interface{}{PaymentCapturedV1{}, &PaymentCapturedV1{}} {
+		name, ok := typeregistry.GetName(value)
+		assert.True(t, ok)
+		assert.Equal(t, "payments.payment-captured.v1", name)
+		assert.Equal(t, "payments.payment-captured.v1", typeregistry.GetTypeName(value))
+		assert.Equal(t, "payments.payment-captured.v1", typeregistry.GetFullTypeName(value))
+	}
+
+	typ, ok := typeregistry.TypeByName("payments.payment-captured.v1")
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(PaymentCapturedV1{}), typ)
+
+	// the serializers instantiate the logical name through typemapper
+	assert.IsType(t, &PaymentCapturedV1{}, typemapper.InstanceByTypeName("payments.payment-captured.v1"))
+}
+
+func Test_Register_Resolves_The_Aliases_To_The_Type(t *testing.T) {
+	require.NoError(t, typeregistry.Register[RefundIssuedV1]("payments.refund-issued.v1", "*RefundIssuedV1", "RefundIssued"))
+
+	for _, alias := range []string{"*RefundIssuedV1", "RefundIssued"} {
+		typ, ok := typeregistry.TypeByName(alias)
+		assert.True(t, ok)
+		assert.Equal(t, reflect.TypeOf(RefundIssuedV1{}), typ)
+		assert.IsType(t, &RefundIssuedV1{}, typemapper.InstanceByTypeName(alias))
+	}
+
+	// the new messages are written with the logical name, not with the aliases
+	assert.Equal(t, "payments.refund-issued.v1", typeregistry.GetTypeName(&RefundIssuedV1{}))
+}
+
+func Test_Register_Keeps_The_Broker_Names_Of_The_Type(t *testing.T) {
+	require.NoError(t, typeregistry.Register[CouponExpired]("promotions.coupon-expired.v1"))
+
+	assert.Equal(t, "coupon_expired", utils.GetTopicOrExchangeName(&CouponExpired{}))
+	assert.Equal(t, "coupon_expired", utils.GetTopicOrExchangeNameFromType(reflect.TypeOf(&CouponExpired{})))
+	assert.Equal(t, "coupon_expired", utils.GetQueueName(&CouponExpired{}))
+	assert.Equal(t, "coupon_expired", utils.GetRoutingKey(&CouponExpired{}))
+}
+
+func Test_GetTypeName_Falls_Back_To_The_Type_Name(t *testing.T) {
+	_, ok := typeregistry.GetName(&NotRegisteredEvent{})
+	assert.False(t, ok)
+
+	assert.Equal(t, typemapper.GetTypeName(&NotRegisteredEvent{}), typeregistry.GetTypeName(&NotRegisteredEvent{}))
+	assert.Equal(t, typemapper.GetFullTypeName(&NotRegisteredEvent{}), typeregistry.GetFullTypeName(&NotRegisteredEvent{}))
+
+	_, ok = typeregistry.TypeByName("NotRegisteredEvent")
+	assert.False(t, ok)
+}
+
+func Test_Register_Is_Idempotent_For_The_Same_Name(t *testing.T) {
+	require.NoError(t, typeregistry.Register[CouponRedeemed]("promotions.coupon-redeemed.v1"))
+	assert.NoError(t, typeregistry.Register[CouponRedeemed]("promotions.coupon-redeemed.v1"))
+	assert.NoError(t, typeregistry.RegisterType(reflect.TypeOf(&CouponRedeemed{}), "promotions.coupon-redeemed.v1"))
+}
+
+func Test_Validate_Returns_The_Duplicated_Names(t *testing.T) {
+	require.NoError(t, typeregistry.Register[ParcelShipped]("shipping.parcel.v1", "*Parcel"))
+
+	// another type with the same name or alias
+	assert.Error(t, typeregistry.Register[ParcelLost]("shipping.parcel.v1"))
+	assert.Error(t, typeregistry.Register[ParcelLost]("shipping.parcel-lost.v1", "*Parcel"))
+	// the same type with another name
+	assert.Error(t, typeregistry.Register[ParcelShipped]("shipping.parcel-shipped.v1"))
+	assert.Error(t, typeregistry.Register[ParcelLost](""))
+
+	err := typeregistry.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "name `shipping.parcel.v1`")
+	assert.Contains(t, err.Error(), "name `*Parcel`")
+	assert.Contains(t, err.Error(), "already registered with name `shipping.parcel.v1`")
+
+	// the failed registrations don't change the registered names
+	typ, _ := typeregistry.TypeByName("shipping.parcel.v1")
+	assert.Equal(t, reflect.TypeOf(ParcelShipped{}), typ)
+	_, ok := typeregistry.GetName(&ParcelLost{})
+	assert.False(t, ok)
+}
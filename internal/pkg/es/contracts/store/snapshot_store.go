@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+)
+
+// SnapshotStore guarda el ultimo snapshot de cada stream, los snapshots anteriores no se conservan
+type SnapshotStore interface {
+	// Save guarda el snapshot, reemplaza al snapshot anterior del stream
+	Save(ctx context.Context, snapshot *models.Snapshot) error
+
+	// Load devuelve el ultimo snapshot del stream, devuelve nil si el stream no tiene snapshots
+	Load(ctx context.Context, streamName streamName.StreamName) (*models.Snapshot, error)
+}
+
+// SnapshotPolicy decide cuando se guarda un snapshot de un agregado
+type SnapshotPolicy interface {
+	// ShouldTakeSnapshot se llama despues de guardar los eventos del agregado, `category` es el tipo del agregado
+	// en el nombre del stream y las versiones son la del agregado antes y despues de guardar los eventos
+	ShouldTakeSnapshot(category string, previousVersion int64, currentVersion int64) bool
+}
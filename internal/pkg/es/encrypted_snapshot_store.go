@@ -0,0 +1,83 @@
+package es
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/encryption"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+
+	"emperror.dev/errors"
+)
+
+// snapshotStateContentType the state of the aggregates is opaque for the store, each aggregate chooses its format
+const snapshotStateContentType = "application/octet-stream"
+
+// encryptedSnapshotStore cifra el estado de los snapshots antes de guardarlo en el store interno
+type encryptedSnapshotStore struct {
+	snapshotStore store.SnapshotStore
+	keyProvider   encryption.KeyProvider
+}
+
+// NewEncryptedSnapshotStore cifra y firma el estado de los snapshots con la llave actual del provider, los snapshots
+// guardan los mismos datos personales que los mensajes cifrados y no pueden quedar en claro en su store
+func NewEncryptedSnapshotStore(
+	snapshotStore store.SnapshotStore,
+	keyProvider encryption.KeyProvider,
+) store.SnapshotStore {
+	return &encryptedSnapshotStore{snapshotStore: snapshotStore, keyProvider: keyProvider}
+}
+
+// NewSnapshotStoreWithEncryption cifra los snapshots cuando el cifrado de los mensajes esta habilitado, sin key provider
+// se crea el de las opciones igual que para el serializer de mensajes
+func NewSnapshotStoreWithEncryption(
+	snapshotStore store.SnapshotStore,
+	encryptionOptions *encryption.EncryptionOptions,
+	keyProvider encryption.KeyProvider,
+) (store.SnapshotStore, error) {
+	if encryptionOptions == nil || !encryptionOptions.Enabled {
+		return snapshotStore, nil
+	}
+
+	if keyProvider == nil {
+		var err error
+		keyProvider, err = encryption.NewKeyProvider(encryptionOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewEncryptedSnapshotStore(snapshotStore, keyProvider), nil
+}
+
+func (s *encryptedSnapshotStore) Save(ctx context.Context, snapshot *models.Snapshot) error {
+	state, _, err := encryption.Seal(s.keyProvider, snapshot.State, snapshotStateContentType)
+	if err != nil {
+		return errors.WrapIff(err, "error in encrypting the snapshot of stream `%s`", snapshot.StreamId)
+	}
+
+	encrypted := *snapshot
+	encrypted.State = state
+
+	return s.snapshotStore.Save(ctx, &encrypted)
+}
+
+// Load the snapshots saved in clear text before enabling the encryption can't be opened, the aggregate store then loads
+// the aggregate from its events and the next snapshot replaces them
+func (s *encryptedSnapshotStore) Load(ctx context.Context, streamName streamName.StreamName) (*models.Snapshot, error) {
+	snapshot, err := s.snapshotStore.Load(ctx, streamName)
+	if err != nil || snapshot == nil {
+		return snapshot, err
+	}
+
+	state, _, err := encryption.Open(s.keyProvider, snapshot.State)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in decrypting the snapshot of stream `%s`", streamName.String())
+	}
+
+	decrypted := *snapshot
+	decrypted.State = state
+
+	return &decrypted, nil
+}
@@ -0,0 +1,108 @@
+package es_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/encryption"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/inmemory"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderStream = streamName.StreamName("order-3f1a")
+
+var orderState = []byte(`{"accountEmail":"john@example.com","deliveryAddress":"street 1"}`) //nolint:gochecknoglobals
+
+func newKeyProvider(t *testing.T, currentKeyId string) encryption.KeyProvider {
+	t.Helper()
+
+	keyProvider, err := encryption.NewStaticKeyProvider(currentKeyId, map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 32),
+	})
+	require.NoError(t, err)
+
+	return keyProvider
+}
+
+func newOrderSnapshot() *models.Snapshot {
+	return &models.Snapshot{StreamId: orderStream.String(), Version: 4, SchemaVersion: 1, State: orderState}
+}
+
+func Test_EncryptedSnapshotStore_Stores_The_State_Encrypted(t *testing.T) {
+	innerStore := inmemory.NewInMemorySnapshotStore()
+	snapshotStore := es.NewEncryptedSnapshotStore(innerStore, newKeyProvider(t, "key-1"))
+
+	require.NoError(t, snapshotStore.Save(context.Background(), newOrderSnapshot()))
+
+	stored, err := innerStore.Load(context.Background(), orderStream)
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.State), "john@example.com")
+	assert.NotContains(t, string(stored.State), "street 1")
+	assert.Equal(t, int64(4), stored.Version)
+
+	loaded, err := snapshotStore.Load(context.Background(), orderStream)
+	require.NoError(t, err)
+	assert.Equal(t, orderState, loaded.State)
+	assert.Equal(t, int64(4), loaded.Version)
+	assert.Equal(t, 1, loaded.SchemaVersion)
+}
+
+func Test_EncryptedSnapshotStore_Reads_The_Snapshots_Of_The_Previous_Keys(t *testing.T) {
+	innerStore := inmemory.NewInMemorySnapshotStore()
+	require.NoError(t, es.NewEncryptedSnapshotStore(innerStore, newKeyProvider(t, "key-1")).
+		Save(context.Background(), newOrderSnapshot()))
+
+	loaded, err := es.NewEncryptedSnapshotStore(innerStore, newKeyProvider(t, "key-2")).
+		Load(context.Background(), orderStream)
+
+	require.NoError(t, err)
+	assert.Equal(t, orderState, loaded.State)
+}
+
+func Test_EncryptedSnapshotStore_Rejects_The_Snapshots_In_Clear_Text(t *testing.T) {
+	innerStore := inmemory.NewInMemorySnapshotStore()
+	require.NoError(t, innerStore.Save(context.Background(), newOrderSnapshot()))
+
+	loaded, err := es.NewEncryptedSnapshotStore(innerStore, newKeyProvider(t, "key-1")).
+		Load(context.Background(), orderStream)
+
+	// the aggregate stores log the error and load the aggregate from its events
+	assert.Error(t, err)
+	assert.Nil(t, loaded)
+}
+
+func Test_EncryptedSnapshotStore_Returns_Nil_Without_Snapshot(t *testing.T) {
+	snapshotStore := es.NewEncryptedSnapshotStore(inmemory.NewInMemorySnapshotStore(), newKeyProvider(t, "key-1"))
+
+	loaded, err := snapshotStore.Load(context.Background(), orderStream)
+
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func Test_NewSnapshotStoreWithEncryption_Encrypts_Only_When_Enabled(t *testing.T) {
+	innerStore := inmemory.NewInMemorySnapshotStore()
+
+	snapshotStore, err := es.NewSnapshotStoreWithEncryption(innerStore, &encryption.EncryptionOptions{}, nil)
+	require.NoError(t, err)
+	assert.Same(t, innerStore, snapshotStore)
+
+	snapshotStore, err = es.NewSnapshotStoreWithEncryption(
+		innerStore,
+		&encryption.EncryptionOptions{Enabled: true},
+		newKeyProvider(t, "key-1"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, snapshotStore.Save(context.Background(), newOrderSnapshot()))
+
+	stored, err := innerStore.Load(context.Background(), orderStream)
+	require.NoError(t, err)
+	assert.NotEqual(t, orderState, stored.State)
+}
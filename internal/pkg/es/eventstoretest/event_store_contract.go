@@ -0,0 +1,218 @@
+package eventstoretest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	readPosition "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/read_position"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/truncatePosition"
+	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
+	esErrors "github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/errors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	OrderStream   = streamName.StreamName("order-3f1a")
+	PaymentStream = streamName.StreamName("payment-9c2b")
+)
+
+// OrderUpdated es el evento de los tests del contrato, esta registrado en el typemapper para los event stores que
+// serializan sus eventos
+type OrderUpdated struct {
+	*domain.DomainEvent
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&OrderUpdated{}))
+}
+
+// NewEventStoreFunc crea un event store vacio para cada test del contrato
+type NewEventStoreFunc func(t *testing.T) store.EventStore
+
+func NewStreamEvents(count int) []*models.StreamEvent {
+	events := make([]*models.StreamEvent, 0, count)
+	for i := 0; i < count; i++ {
+		events = append(events, &models.StreamEvent{Event: &OrderUpdated{DomainEvent: domain.NewDomainEvent("OrderUpdated")}})
+	}
+
+	return events
+}
+
+// AppendEvents agrega `count` eventos al stream sin validar la version
+func AppendEvents(t *testing.T, eventStore store.EventStore, stream streamName.StreamName, count int) {
+	t.Helper()
+
+	_, err := eventStore.AppendEvents(stream, expectedStreamVersion.Any, NewStreamEvents(count), context.Background())
+	require.NoError(t, err)
+}
+
+func Versions(events []*models.StreamEvent) []int64 {
+	result := make([]int64, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.Version)
+	}
+
+	return result
+}
+
+// RunEventStoreContract ejecuta los tests de la semantica de eventstoredb que comparten todas las implementaciones
+// de `store.EventStore`, las posiciones globales empiezan en 1 en un event store vacio
+func RunEventStoreContract(t *testing.T, newEventStore NewEventStoreFunc) {
+	t.Helper()
+
+	t.Run("AppendEvents sets the version and the position", func(t *testing.T) {
+		eventStore := newEventStore(t)
+		AppendEvents(t, eventStore, OrderStream, 2)
+
+		result, err := eventStore.AppendEvents(PaymentStream, expectedStreamVersion.NoStream, NewStreamEvents(1), context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), result.GlobalPosition)
+		assert.Equal(t, uint64(0), result.NextExpectedVersion)
+
+		events, err := eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, []int64{0, 1}, Versions(events))
+		assert.Equal(t, int64(1), events[0].Position)
+		assert.Equal(t, int64(2), events[1].Position)
+		assert.NotEqual(t, events[0].EventID, events[1].EventID)
+
+		exists, err := eventStore.StreamExists(OrderStream, context.Background())
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("AppendEvents checks the expected version", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			existingEvents  int
+			expectedVersion expectedStreamVersion.ExpectedStreamVersion
+			wantErr         bool
+		}{
+			{name: "no stream on a new stream", expectedVersion: expectedStreamVersion.NoStream},
+			{name: "no stream on an existing stream", existingEvents: 1, expectedVersion: expectedStreamVersion.NoStream, wantErr: true},
+			{name: "stream exists on a new stream", expectedVersion: expectedStreamVersion.StreamExists, wantErr: true},
+			{name: "stream exists on an existing stream", existingEvents: 1, expectedVersion: expectedStreamVersion.StreamExists},
+			{name: "any", existingEvents: 2, expectedVersion: expectedStreamVersion.Any},
+			{name: "current version", existingEvents: 2, expectedVersion: expectedStreamVersion.FromInt64(1)},
+			{name: "stale version", existingEvents: 2, expectedVersion: expectedStreamVersion.FromInt64(0), wantErr: true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				eventStore := newEventStore(t)
+				if test.existingEvents > 0 {
+					AppendEvents(t, eventStore, OrderStream, test.existingEvents)
+				}
+
+				_, err := eventStore.AppendEvents(OrderStream, test.expectedVersion, NewStreamEvents(1), context.Background())
+
+				if test.wantErr {
+					assert.True(t, esErrors.IsAppendToStreamError(err))
+					return
+				}
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("AppendEvents rejects the second of two writers with the same expected version", func(t *testing.T) {
+		eventStore := newEventStore(t)
+		AppendEvents(t, eventStore, OrderStream, 1)
+
+		_, err := eventStore.AppendEvents(OrderStream, expectedStreamVersion.FromInt64(0), NewStreamEvents(1), context.Background())
+		require.NoError(t, err)
+		_, err = eventStore.AppendEvents(OrderStream, expectedStreamVersion.FromInt64(0), NewStreamEvents(1), context.Background())
+		assert.True(t, esErrors.IsAppendToStreamError(err))
+
+		events, err := eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{0, 1}, Versions(events))
+	})
+
+	t.Run("ReadEvents", func(t *testing.T) {
+		eventStore := newEventStore(t)
+		AppendEvents(t, eventStore, OrderStream, 4)
+
+		forwards, err := eventStore.ReadEvents(OrderStream, readPosition.FromInt64(1), 2, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, Versions(forwards))
+
+		backwards, err := eventStore.ReadEventsBackwardsFromEnd(OrderStream, 3, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2, 1}, Versions(backwards))
+
+		fromEnd, err := eventStore.ReadEvents(OrderStream, readPosition.End, 10, context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, fromEnd)
+
+		_, err = eventStore.ReadEventsFromStart(PaymentStream, 10, context.Background())
+		assert.True(t, esErrors.IsStreamNotFoundError(err))
+	})
+
+	t.Run("TruncateStream hides the events before the position", func(t *testing.T) {
+		eventStore := newEventStore(t)
+		AppendEvents(t, eventStore, OrderStream, 4)
+
+		_, err := eventStore.TruncateStream(
+			OrderStream,
+			truncatePosition.FromInt64(2),
+			expectedStreamVersion.FromInt64(0),
+			context.Background(),
+		)
+		assert.True(t, esErrors.IsTruncateStreamError(err))
+
+		_, err = eventStore.TruncateStream(OrderStream, truncatePosition.FromInt64(2), expectedStreamVersion.Any, context.Background())
+		require.NoError(t, err)
+
+		forwards, err := eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3}, Versions(forwards))
+
+		backwards, err := eventStore.ReadEventsBackwardsFromEnd(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2}, Versions(backwards))
+
+		// the new events continue the revisions of the stream
+		AppendEvents(t, eventStore, OrderStream, 1)
+		forwards, err = eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3, 4}, Versions(forwards))
+	})
+
+	t.Run("DeleteStream is a soft delete", func(t *testing.T) {
+		eventStore := newEventStore(t)
+		AppendEvents(t, eventStore, OrderStream, 2)
+
+		err := eventStore.DeleteStream(OrderStream, expectedStreamVersion.FromInt64(0), context.Background())
+		assert.True(t, esErrors.IsDeleteStreamError(err))
+
+		require.NoError(t, eventStore.DeleteStream(OrderStream, expectedStreamVersion.FromInt64(1), context.Background()))
+
+		exists, err := eventStore.StreamExists(OrderStream, context.Background())
+		require.NoError(t, err)
+		assert.False(t, exists)
+		_, err = eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		assert.True(t, esErrors.IsStreamNotFoundError(err))
+
+		// the stream is created again with the next revisions, the events before the delete are not read again
+		_, err = eventStore.AppendEvents(OrderStream, expectedStreamVersion.NoStream, NewStreamEvents(1), context.Background())
+		require.NoError(t, err)
+		events, err := eventStore.ReadEventsFromStart(OrderStream, 10, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, Versions(events))
+
+		// a deleted stream can't be deleted or truncated again
+		require.NoError(t, eventStore.DeleteStream(OrderStream, expectedStreamVersion.Any, context.Background()))
+		err = eventStore.DeleteStream(OrderStream, expectedStreamVersion.Any, context.Background())
+		assert.True(t, esErrors.IsDeleteStreamError(err))
+	})
+}
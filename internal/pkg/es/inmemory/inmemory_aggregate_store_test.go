@@ -0,0 +1,231 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/errors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/utils"
+
+	"github.com/google/uuid"
+	satoriUUID "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counterIncremented struct {
+	*domain.DomainEvent
+	Amount int
+}
+
+// counter es el agregado de los tests de snapshots, `folded` cuenta los eventos que se reprodujeron al cargarlo
+// y no se guarda en el snapshot
+type counter struct {
+	*models.EventSourcedAggregateRoot
+	total  int
+	folded int
+}
+
+type counterSnapshot struct {
+	Id    satoriUUID.UUID `json:"id"`
+	Total int             `json:"total"`
+}
+
+func (c *counter) NewEmptyAggregate() {
+	c.EventSourcedAggregateRoot = models.NewEventSourcedAggregateRoot(typemapper.GetFullTypeName(c), c.When)
+}
+
+func newCounter(id uuid.UUID) *counter {
+	c := &counter{}
+	c.NewEmptyAggregate()
+	c.SetId(utils.ConvertGoogleUUIDToSatoriUUID(id))
+
+	return c
+}
+
+func (c *counter) Increment(amount int) error {
+	return c.Apply(&counterIncremented{DomainEvent: domain.NewDomainEvent("counterIncremented"), Amount: amount}, true)
+}
+
+func (c *counter) When(event domain.IDomainEvent) error {
+	incremented, ok := event.(*counterIncremented)
+	if !ok {
+		return errors.InvalidEventTypeError
+	}
+
+	c.SetId(incremented.GetAggregateId())
+	c.total += incremented.Amount
+	c.folded++
+
+	return nil
+}
+
+func (c *counter) SnapshotSchemaVersion() int {
+	return 1
+}
+
+func (c *counter) CreateSnapshot() ([]byte, error) {
+	return json.Marshal(&counterSnapshot{Id: c.Id(), Total: c.total})
+}
+
+func (c *counter) RestoreSnapshot(state []byte) error {
+	snapshot := &counterSnapshot{}
+	if err := json.Unmarshal(state, snapshot); err != nil {
+		return err
+	}
+	c.SetId(snapshot.Id)
+	c.total = snapshot.Total
+
+	return nil
+}
+
+func newSnapshotAggregateStore(
+	snapshotStore store.SnapshotStore,
+	frequency int64,
+) store.AggregateStore[*counter] {
+	return NewInMemoryAggregateStoreWithSnapshots[*counter](
+		defaultlogger.GetLogger(),
+		NewInMemoryEventStore(),
+		snapshotStore,
+		es.NewEveryNEventsSnapshotPolicy(frequency),
+	)
+}
+
+func storeIncrements(t *testing.T, aggregateStore store.AggregateStore[*counter], c *counter, amounts ...int) {
+	t.Helper()
+
+	for _, amount := range amounts {
+		require.NoError(t, c.Increment(amount))
+	}
+	_, err := aggregateStore.Store(c, metadata.Metadata{}, context.Background())
+	require.NoError(t, err)
+}
+
+func loadSnapshot(t *testing.T, snapshotStore store.SnapshotStore, id uuid.UUID) *models.Snapshot {
+	t.Helper()
+
+	snapshot, err := snapshotStore.Load(
+		context.Background(),
+		streamName.ForID[*counter](utils.ConvertGoogleUUIDToSatoriUUID(id)),
+	)
+	require.NoError(t, err)
+
+	return snapshot
+}
+
+func load(t *testing.T, aggregateStore store.AggregateStore[*counter], id uuid.UUID) *counter {
+	t.Helper()
+
+	loaded, err := aggregateStore.Load(context.Background(), id)
+	require.NoError(t, err)
+
+	return loaded
+}
+
+func Test_Load_Restores_The_Snapshot_And_Replays_The_Events_After_Its_Version(t *testing.T) {
+	snapshotStore := NewInMemorySnapshotStore()
+	aggregateStore := newSnapshotAggregateStore(snapshotStore, 3)
+	id := uuid.New()
+
+	c := newCounter(id)
+	storeIncrements(t, aggregateStore, c, 1, 2, 3)
+
+	snapshot := loadSnapshot(t, snapshotStore, id)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, int64(2), snapshot.Version)
+
+	storeIncrements(t, aggregateStore, load(t, aggregateStore, id), 4)
+
+	loaded := load(t, aggregateStore, id)
+
+	assert.Equal(t, id.String(), loaded.Id().String())
+	assert.Equal(t, 10, loaded.total)
+	// only the event at snapshot.Version+1 is folded, the first three come from the snapshot
+	assert.Equal(t, 1, loaded.folded)
+	assert.Equal(t, int64(3), loaded.OriginalVersion())
+	assert.Equal(t, int64(3), loaded.CurrentVersion())
+}
+
+func Test_Load_Restores_A_Snapshot_Without_Later_Events(t *testing.T) {
+	snapshotStore := NewInMemorySnapshotStore()
+	aggregateStore := newSnapshotAggregateStore(snapshotStore, 2)
+	id := uuid.New()
+
+	storeIncrements(t, aggregateStore, newCounter(id), 5, 5)
+
+	loaded, err := aggregateStore.Load(context.Background(), id)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, loaded.total)
+	assert.Equal(t, 0, loaded.folded)
+	assert.Equal(t, int64(1), loaded.CurrentVersion())
+}
+
+func Test_Load_Ignores_A_Snapshot_With_Another_Schema_Version(t *testing.T) {
+	snapshotStore := NewInMemorySnapshotStore()
+	aggregateStore := newSnapshotAggregateStore(snapshotStore, 0)
+	id := uuid.New()
+
+	storeIncrements(t, aggregateStore, newCounter(id), 1, 2)
+
+	// a snapshot of a previous format of the state
+	err := snapshotStore.Save(context.Background(), &models.Snapshot{
+		StreamId:      streamName.ForID[*counter](utils.ConvertGoogleUUIDToSatoriUUID(id)).String(),
+		Version:       1,
+		SchemaVersion: 0,
+		State:         []byte(`{"total":100}`),
+	})
+	require.NoError(t, err)
+
+	loaded, err := aggregateStore.Load(context.Background(), id)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, loaded.total)
+	assert.Equal(t, 2, loaded.folded)
+	assert.Equal(t, int64(1), loaded.CurrentVersion())
+}
+
+func Test_Store_Takes_A_Snapshot_When_A_Commit_Crosses_A_Multiple_Of_The_Frequency(t *testing.T) {
+	snapshotStore := NewInMemorySnapshotStore()
+	aggregateStore := newSnapshotAggregateStore(snapshotStore, 2)
+	id := uuid.New()
+
+	storeIncrements(t, aggregateStore, newCounter(id), 1)
+	assert.Nil(t, loadSnapshot(t, snapshotStore, id))
+
+	// the commit goes from 1 to 4 events, it skips over the second event
+	storeIncrements(t, aggregateStore, load(t, aggregateStore, id), 1, 1, 1)
+	snapshot := loadSnapshot(t, snapshotStore, id)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, int64(3), snapshot.Version)
+	assert.JSONEq(t, fmt.Sprintf(`{"id":%q,"total":4}`, id.String()), string(snapshot.State))
+
+	// 5 events don't reach the next multiple
+	storeIncrements(t, aggregateStore, load(t, aggregateStore, id), 1)
+	assert.Equal(t, int64(3), loadSnapshot(t, snapshotStore, id).Version)
+}
+
+func Test_LoadWithReadPosition_Does_Not_Use_The_Snapshot(t *testing.T) {
+	snapshotStore := NewInMemorySnapshotStore()
+	aggregateStore := newSnapshotAggregateStore(snapshotStore, 1)
+	id := uuid.New()
+
+	storeIncrements(t, aggregateStore, newCounter(id), 1, 2)
+	require.NotNil(t, loadSnapshot(t, snapshotStore, id))
+
+	loaded, err := aggregateStore.LoadWithReadPosition(context.Background(), id, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, loaded.total)
+	assert.Equal(t, 2, loaded.folded)
+}
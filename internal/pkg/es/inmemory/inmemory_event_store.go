@@ -0,0 +1,326 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	appendResult "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/append_result"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	readPosition "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/read_position"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/truncatePosition"
+	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
+	esErrors "github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/errors"
+
+	"emperror.dev/errors"
+	"github.com/google/uuid"
+)
+
+// InMemoryEventStore es un `store.EventStore` en memoria con la misma semantica que eventstoredb, se usa en los tests
+// del lado de comandos sin levantar un EventStoreDB. ReadAllEvents lee los eventos de todos los streams como el stream `$all`
+type InMemoryEventStore interface {
+	store.EventStore
+
+	// ReadAllEvents devuelve los eventos de todos los streams despues de la posicion global, y un canal que se cierra
+	// en el siguiente append para esperar eventos nuevos
+	ReadAllEvents(position uint64) ([]*AllStreamEvent, <-chan struct{})
+}
+
+// AllStreamEvent es un evento del stream `$all` con el nombre de su stream
+type AllStreamEvent struct {
+	StreamName  streamName.StreamName
+	StreamEvent *models.StreamEvent
+}
+
+type inMemoryStream struct {
+	events []*models.StreamEvent
+	// truncateBefore es la primera revision que se puede leer, como el `$tb` de la metadata de eventstoredb
+	truncateBefore int64
+	// los streams borrados son soft deletes, se pueden crear de nuevo y sus revisiones continuan
+	deleted bool
+}
+
+func (s *inMemoryStream) revision() int64 {
+	return int64(len(s.events)) - 1
+}
+
+func (s *inMemoryStream) exists() bool {
+	return s != nil && !s.deleted && len(s.events) > 0
+}
+
+type inMemoryEventStore struct {
+	lock    sync.RWMutex
+	streams map[string]*inMemoryStream
+	// all es el log global, la posicion de cada evento es su indice mas uno porque el checkpoint 0 es el inicio
+	all      []*AllStreamEvent
+	appended chan struct{}
+}
+
+func NewInMemoryEventStore() InMemoryEventStore {
+	return &inMemoryEventStore{
+		streams:  make(map[string]*inMemoryStream),
+		appended: make(chan struct{}),
+	}
+}
+
+func (e *inMemoryEventStore) StreamExists(
+	streamName streamName.StreamName,
+	ctx context.Context,
+) (bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.streams[streamName.String()].exists(), nil
+}
+
+func (e *inMemoryEventStore) AppendEvents(
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	events []*models.StreamEvent,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	stream := e.streams[streamName.String()]
+	if err := checkExpectedVersion(stream, expectedVersion); err != nil {
+		return nil, errors.WithMessage(
+			esErrors.NewAppendToStreamError(err, streamName.String()),
+			"error in appending to stream",
+		)
+	}
+
+	if stream == nil {
+		stream = &inMemoryStream{}
+		e.streams[streamName.String()] = stream
+	}
+
+	for _, event := range events {
+		eventId := event.EventID
+		if eventId == uuid.Nil {
+			eventId = uuid.New()
+		}
+
+		// the stored event has the revision in the stream and the position in `$all`, like the events read from eventstoredb
+		storedEvent := &models.StreamEvent{
+			EventID:  eventId,
+			Event:    event.Event,
+			Metadata: event.Metadata,
+			Version:  stream.revision() + 1,
+			Position: int64(len(e.all)) + 1,
+		}
+		stream.events = append(stream.events, storedEvent)
+		e.all = append(e.all, &AllStreamEvent{StreamName: streamName, StreamEvent: storedEvent})
+	}
+
+	if len(events) > 0 {
+		stream.deleted = false
+
+		// wakes up the subscriptions waiting for new events
+		close(e.appended)
+		e.appended = make(chan struct{})
+	}
+
+	return appendResult.From(uint64(len(e.all)), uint64(stream.revision())), nil
+}
+
+func (e *inMemoryEventStore) AppendNewEvents(
+	streamName streamName.StreamName,
+	events []*models.StreamEvent,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	return e.AppendEvents(streamName, expectedStreamVersion.NoStream, events, ctx)
+}
+
+func (e *inMemoryEventStore) ReadEvents(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	stream, err := e.readableStream(streamName)
+	if err != nil {
+		return nil, err
+	}
+
+	// reading forwards from the end doesn't return events
+	if readPosition.IsEnd() {
+		return nil, nil
+	}
+
+	var events []*models.StreamEvent
+	for revision := max(readPosition.Value(), stream.truncateBefore); revision <= stream.revision(); revision++ {
+		if uint64(len(events)) >= count {
+			break
+		}
+		events = append(events, stream.events[revision])
+	}
+
+	return events, nil
+}
+
+func (e *inMemoryEventStore) ReadEventsWithMaxCount(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEvents(streamName, readPosition, uint64(math.MaxUint64), ctx)
+}
+
+func (e *inMemoryEventStore) ReadEventsFromStart(
+	streamName streamName.StreamName,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEvents(streamName, readPosition.Start, count, ctx)
+}
+
+func (e *inMemoryEventStore) ReadEventsBackwards(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	stream, err := e.readableStream(streamName)
+	if err != nil {
+		return nil, err
+	}
+
+	from := readPosition.Value()
+	if readPosition.IsEnd() || from > stream.revision() {
+		from = stream.revision()
+	}
+
+	var events []*models.StreamEvent
+	for revision := from; revision >= stream.truncateBefore; revision-- {
+		if uint64(len(events)) >= count {
+			break
+		}
+		events = append(events, stream.events[revision])
+	}
+
+	return events, nil
+}
+
+func (e *inMemoryEventStore) ReadEventsBackwardsWithMaxCount(
+	stream streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEventsBackwards(stream, readPosition, uint64(math.MaxUint64), ctx)
+}
+
+func (e *inMemoryEventStore) ReadEventsBackwardsFromEnd(
+	streamName streamName.StreamName,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEventsBackwards(streamName, readPosition.End, count, ctx)
+}
+
+func (e *inMemoryEventStore) TruncateStream(
+	streamName streamName.StreamName,
+	truncatePosition truncatePosition.StreamTruncatePosition,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	stream := e.streams[streamName.String()]
+	err := checkExpectedVersion(stream, expectedVersion)
+	if err == nil && !stream.exists() {
+		err = errors.Errorf("stream %s doesn't exist", streamName.String())
+	}
+	if err != nil {
+		return nil, errors.WithMessage(
+			esErrors.NewTruncateStreamError(err, streamName.String()),
+			"error in truncating stream",
+		)
+	}
+
+	stream.truncateBefore = max(stream.truncateBefore, truncatePosition.Value())
+
+	return appendResult.From(uint64(len(e.all)), uint64(stream.revision())), nil
+}
+
+func (e *inMemoryEventStore) DeleteStream(
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	ctx context.Context,
+) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	stream := e.streams[streamName.String()]
+	err := checkExpectedVersion(stream, expectedVersion)
+	if err == nil && !stream.exists() {
+		err = errors.Errorf("stream %s doesn't exist", streamName.String())
+	}
+	if err != nil {
+		return errors.WithMessage(
+			esErrors.NewDeleteStreamError(err, streamName.String()),
+			"error in deleting stream",
+		)
+	}
+
+	// the events stay in `$all` like a soft delete in eventstoredb
+	stream.deleted = true
+	stream.truncateBefore = stream.revision() + 1
+
+	return nil
+}
+
+func (e *inMemoryEventStore) ReadAllEvents(position uint64) ([]*AllStreamEvent, <-chan struct{}) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	if position >= uint64(len(e.all)) {
+		return nil, e.appended
+	}
+
+	events := make([]*AllStreamEvent, len(e.all)-int(position))
+	copy(events, e.all[position:])
+
+	return events, e.appended
+}
+
+func (e *inMemoryEventStore) readableStream(streamName streamName.StreamName) (*inMemoryStream, error) {
+	stream := e.streams[streamName.String()]
+	if stream == nil || stream.deleted {
+		return nil, errors.WrapIf(
+			esErrors.NewStreamNotFoundError(nil, streamName.String()),
+			"error in reading stream",
+		)
+	}
+
+	return stream, nil
+}
+
+// checkExpectedVersion valida la version esperada con la ultima revision del stream
+func checkExpectedVersion(
+	stream *inMemoryStream,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+) error {
+	currentVersion := expectedStreamVersion.NoStream.Value()
+	if stream.exists() {
+		currentVersion = stream.revision()
+	}
+
+	if expectedVersion.IsSatisfiedBy(currentVersion) {
+		return nil
+	}
+
+	return errors.New(
+		fmt.Sprintf("wrong expected version, expected %d but the current version is %d", expectedVersion.Value(), currentVersion),
+	)
+}
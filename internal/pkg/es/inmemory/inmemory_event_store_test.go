@@ -0,0 +1,60 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/eventstoretest"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderStream = eventstoretest.OrderStream
+
+// appendEvents agrega `count` eventos al stream sin validar la version
+func appendEvents(t *testing.T, eventStore InMemoryEventStore, stream streamName.StreamName, count int) {
+	t.Helper()
+
+	eventstoretest.AppendEvents(t, eventStore, stream, count)
+}
+
+func Test_InMemoryEventStore_Contract(t *testing.T) {
+	eventstoretest.RunEventStoreContract(t, func(t *testing.T) store.EventStore {
+		return NewInMemoryEventStore()
+	})
+}
+
+func Test_DeleteStream_Keeps_The_Events_In_All(t *testing.T) {
+	eventStore := NewInMemoryEventStore()
+	appendEvents(t, eventStore, orderStream, 2)
+
+	require.NoError(t, eventStore.DeleteStream(orderStream, expectedStreamVersion.Any, context.Background()))
+
+	all, _ := eventStore.ReadAllEvents(0)
+	assert.Len(t, all, 2)
+}
+
+func Test_ReadAllEvents_Waits_For_The_Next_Append(t *testing.T) {
+	eventStore := NewInMemoryEventStore()
+	appendEvents(t, eventStore, orderStream, 1)
+
+	events, appended := eventStore.ReadAllEvents(1)
+	assert.Empty(t, events)
+
+	select {
+	case <-appended:
+		t.Fatal("the channel is closed before the append")
+	default:
+	}
+
+	appendEvents(t, eventStore, "payment-9c2b", 1)
+	<-appended
+
+	events, _ = eventStore.ReadAllEvents(1)
+	require.Len(t, events, 1)
+	assert.Equal(t, streamName.StreamName("payment-9c2b"), events[0].StreamName)
+}
@@ -0,0 +1,93 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/projection"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// ModuleFunc provided to fxlog, it replaces `eventstroredb.ModuleFunc` in tests, the aggregate stores are provided
+	// with `NewInMemoryAggregateStore`
+	// https://uber-go.github.io/fx/modules.html
+	ModuleFunc = func(projectionBuilderConstructor interface{}) fx.Option { //nolint:gochecknoglobals
+		return fx.Module(
+			"inmemoryeventstorefx",
+			fx.Provide(projectionBuilderConstructor),
+			inMemoryEventStoreProviders,
+			inMemoryEventStoreInvokes,
+		)
+	}
+
+	inMemoryEventStoreProviders = fx.Options(fx.Provide( //nolint:gochecknoglobals
+		fx.Annotate(
+			NewInMemoryEventStore,
+			fx.As(new(store.EventStore)),
+			fx.As(new(InMemoryEventStore)),
+		),
+		es.NewInMemorySubscriptionCheckpointRepository,
+		newProjectionPublisher,
+		NewInMemorySubscriptionAllWorker,
+	))
+
+	inMemoryEventStoreInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
+)
+
+type hooksParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Worker    InMemorySubscriptionAllWorker
+	Logger    logger.Logger
+	Config    *config.EventStoreDbOptions `optional:"true"`
+}
+
+// newProjectionPublisher construye las proyecciones con el mismo ProjectionBuilderFuc de la aplicacion
+func newProjectionPublisher(projectionBuilderFunc eventstroredb.ProjectionBuilderFuc) projection.IProjectionPublisher {
+	builder := eventstroredb.NewProjectionsBuilder()
+	if projectionBuilderFunc != nil {
+		projectionBuilderFunc(builder)
+	}
+
+	return es.NewProjectionPublisher(builder.Build().Projections)
+}
+
+func registerHooks(params hooksParams) {
+	lifetimeCtx, cancel := context.WithCancel(context.Background())
+
+	// the subscription uses the same id and prefixes of the eventstoredb subscription when its config is provided
+	option := &InMemorySubscriptionToAllOptions{}
+	if params.Config != nil && params.Config.Subscription != nil {
+		option.SubscriptionId = params.Config.Subscription.SubscriptionId
+		option.Prefixes = params.Config.Subscription.Prefix
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// the OnStart ctx has a short timeout, the subscription needs a context which is alive during the whole app
+			go func() {
+				if err := params.Worker.SubscribeAll(lifetimeCtx, option); err != nil && lifetimeCtx.Err() == nil {
+					params.Logger.Errorf(
+						"(worker.SubscribeAll) error in running in-memory subscription worker: {%v}",
+						err,
+					)
+				}
+			}()
+			params.Logger.Info("in-memory subscription worker is listening.")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			return nil
+		},
+	})
+}
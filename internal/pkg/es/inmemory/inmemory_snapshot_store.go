@@ -0,0 +1,49 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+)
+
+type inMemorySnapshotStore struct {
+	snapshots map[string]models.Snapshot
+	lock      sync.RWMutex
+}
+
+// NewInMemorySnapshotStore guarda el ultimo snapshot de cada stream en memoria, se usa con `NewInMemoryAggregateStoreWithSnapshots`
+func NewInMemorySnapshotStore() store.SnapshotStore {
+	return &inMemorySnapshotStore{snapshots: map[string]models.Snapshot{}}
+}
+
+func (s *inMemorySnapshotStore) Save(_ context.Context, snapshot *models.Snapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// the snapshot is copied, the callers can't change the stored state
+	stored := *snapshot
+	stored.State = append([]byte(nil), snapshot.State...)
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	s.snapshots[snapshot.StreamId] = stored
+
+	return nil
+}
+
+func (s *inMemorySnapshotStore) Load(_ context.Context, streamName streamName.StreamName) (*models.Snapshot, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	stored, ok := s.snapshots[streamName.String()]
+	if !ok {
+		return nil, nil
+	}
+	stored.State = append([]byte(nil), stored.State...)
+
+	return &stored, nil
+}
@@ -0,0 +1,159 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/projection"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+// InMemorySubscriptionAllWorker publica los eventos del `$all` del event store en memoria a mediatr y a las proyecciones,
+// como `eventstroredb.EsdbSubscriptionAllWorker`
+type InMemorySubscriptionAllWorker interface {
+	// SubscribeAll publica los eventos desde el ultimo checkpoint y espera los eventos nuevos hasta que se cancela el contexto
+	SubscribeAll(ctx context.Context, subscriptionOption *InMemorySubscriptionToAllOptions) error
+
+	// CatchUp publica los eventos pendientes y termina, los tests lo usan para correr las proyecciones sin esperar
+	CatchUp(ctx context.Context, subscriptionOption *InMemorySubscriptionToAllOptions) error
+}
+
+type InMemorySubscriptionToAllOptions struct {
+	SubscriptionId string
+	// Prefixes filtra los streams por el prefijo de su nombre, sin prefijos se publican todos los streams
+	Prefixes []string
+}
+
+type inMemorySubscriptionAllWorker struct {
+	log                              logger.Logger
+	eventStore                       InMemoryEventStore
+	subscriptionCheckpointRepository contracts.SubscriptionCheckpointRepository
+	projectionPublisher              projection.IProjectionPublisher
+	// lock avoids publishing the same event twice when CatchUp runs with a running subscription
+	lock sync.Mutex
+}
+
+func NewInMemorySubscriptionAllWorker(
+	log logger.Logger,
+	eventStore InMemoryEventStore,
+	subscriptionRepository contracts.SubscriptionCheckpointRepository,
+	projectionPublisher projection.IProjectionPublisher,
+) InMemorySubscriptionAllWorker {
+	return &inMemorySubscriptionAllWorker{
+		log:                              log,
+		eventStore:                       eventStore,
+		subscriptionCheckpointRepository: subscriptionRepository,
+		projectionPublisher:              projectionPublisher,
+	}
+}
+
+func (s *inMemorySubscriptionAllWorker) SubscribeAll(
+	ctx context.Context,
+	subscriptionOption *InMemorySubscriptionToAllOptions,
+) error {
+	s.log.Info(fmt.Sprintf("starting in-memory subscription to all '%s'.", subscriptionId(subscriptionOption)))
+
+	for {
+		appended, err := s.catchUp(ctx, subscriptionOption)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-appended:
+		}
+	}
+}
+
+func (s *inMemorySubscriptionAllWorker) CatchUp(
+	ctx context.Context,
+	subscriptionOption *InMemorySubscriptionToAllOptions,
+) error {
+	_, err := s.catchUp(ctx, subscriptionOption)
+
+	return err
+}
+
+// catchUp publishes the events after the checkpoint, it returns the channel which is closed on the next append
+func (s *inMemorySubscriptionAllWorker) catchUp(
+	ctx context.Context,
+	subscriptionOption *InMemorySubscriptionToAllOptions,
+) (<-chan struct{}, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := subscriptionId(subscriptionOption)
+
+	checkpoint, err := s.subscriptionCheckpointRepository.Load(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events, appended := s.eventStore.ReadAllEvents(checkpoint)
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if hasPrefix(event.StreamName.String(), subscriptionOption) {
+			if err := s.handleEvent(ctx, event); err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.subscriptionCheckpointRepository.Store(id, uint64(event.StreamEvent.Position), ctx)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to store subscription checkpoint")
+		}
+	}
+
+	return appended, nil
+}
+
+func (s *inMemorySubscriptionAllWorker) handleEvent(ctx context.Context, event *AllStreamEvent) error {
+	// publish to internal event bus - for handling event and project it manually tp corresponding read model
+	err := mediatr.Publish(ctx, event.StreamEvent)
+	if err != nil {
+		return errors.WrapIf(
+			err,
+			"failed to publish stream event for the mediatr (internal event bus for handling event)",
+		)
+	}
+
+	err = s.projectionPublisher.Publish(ctx, event.StreamEvent)
+	if err != nil {
+		return errors.WrapIf(err, "failed to publish stream event in the handle event")
+	}
+
+	return nil
+}
+
+func subscriptionId(subscriptionOption *InMemorySubscriptionToAllOptions) string {
+	if subscriptionOption == nil || subscriptionOption.SubscriptionId == "" {
+		return "defaultLogger"
+	}
+
+	return subscriptionOption.SubscriptionId
+}
+
+func hasPrefix(streamName string, subscriptionOption *InMemorySubscriptionToAllOptions) bool {
+	if subscriptionOption == nil || len(subscriptionOption.Prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range subscriptionOption.Prefixes {
+		if strings.HasPrefix(streamName, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher guarda la posicion en `$all` de los eventos publicados a las proyecciones
+type recordingPublisher struct {
+	lock      sync.Mutex
+	positions []int64
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, streamEvent *models.StreamEvent) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.positions = append(p.positions, streamEvent.Position)
+
+	return nil
+}
+
+func (p *recordingPublisher) published() []int64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]int64(nil), p.positions...)
+}
+
+func newTestWorker(
+	eventStore InMemoryEventStore,
+) (InMemorySubscriptionAllWorker, *recordingPublisher, contracts.SubscriptionCheckpointRepository) {
+	publisher := &recordingPublisher{}
+	checkpoints := es.NewInMemorySubscriptionCheckpointRepository()
+
+	return NewInMemorySubscriptionAllWorker(defaultlogger.GetLogger(), eventStore, checkpoints, publisher), publisher, checkpoints
+}
+
+func Test_CatchUp_Publishes_The_Events_After_The_Checkpoint(t *testing.T) {
+	eventStore := NewInMemoryEventStore()
+	worker, publisher, checkpoints := newTestWorker(eventStore)
+	options := &InMemorySubscriptionToAllOptions{SubscriptionId: "orders-projection"}
+	appendEvents(t, eventStore, orderStream, 2)
+
+	require.NoError(t, worker.CatchUp(context.Background(), options))
+	assert.Equal(t, []int64{1, 2}, publisher.published())
+
+	checkpoint, err := checkpoints.Load("orders-projection", context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), checkpoint)
+
+	// the events before the checkpoint are not published again
+	appendEvents(t, eventStore, orderStream, 1)
+	require.NoError(t, worker.CatchUp(context.Background(), options))
+	assert.Equal(t, []int64{1, 2, 3}, publisher.published())
+}
+
+func Test_CatchUp_Filters_The_Streams_By_Prefix(t *testing.T) {
+	eventStore := NewInMemoryEventStore()
+	worker, publisher, checkpoints := newTestWorker(eventStore)
+	options := &InMemorySubscriptionToAllOptions{SubscriptionId: "orders-projection", Prefixes: []string{"order-"}}
+	appendEvents(t, eventStore, orderStream, 1)
+	appendEvents(t, eventStore, "payment-9c2b", 1)
+
+	require.NoError(t, worker.CatchUp(context.Background(), options))
+
+	assert.Equal(t, []int64{1}, publisher.published())
+	// the checkpoint moves after the filtered events too
+	checkpoint, err := checkpoints.Load("orders-projection", context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), checkpoint)
+}
+
+func Test_SubscribeAll_Delivers_The_New_Events_Until_It_Is_Canceled(t *testing.T) {
+	eventStore := NewInMemoryEventStore()
+	worker, publisher, _ := newTestWorker(eventStore)
+	appendEvents(t, eventStore, orderStream, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.SubscribeAll(ctx, &InMemorySubscriptionToAllOptions{SubscriptionId: "orders-projection"})
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.published()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	appendEvents(t, eventStore, orderStream, 2)
+	assert.Eventually(t, func() bool {
+		return len(publisher.published()) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int64{1, 2, 3}, publisher.published())
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the subscription didn't stop")
+	}
+}
@@ -54,6 +54,9 @@ type IEventSourcedAggregateRoot interface {
 
 	SetOriginalVersion(version int64)
 
+	// RestoreVersion sets the original and current version of an aggregate restored from a snapshot
+	RestoreVersion(version int64)
+
 	// CurrentVersion Gets the current version is set to original version when the aggregate is loaded from the store.
 	// It should increase for each state transition performed within the scope of the current operation.
 	CurrentVersion() int64
@@ -134,6 +137,11 @@ func (a *EventSourcedAggregateRoot) SetOriginalVersion(version int64) {
 	a.originalVersion = version
 }
 
+func (a *EventSourcedAggregateRoot) RestoreVersion(version int64) {
+	a.originalVersion = version
+	a.currentVersion = version
+}
+
 func (a *EventSourcedAggregateRoot) CurrentVersion() int64 {
 	return a.currentVersion
 }
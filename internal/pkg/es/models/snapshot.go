@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Snapshot es el estado de un agregado en una version de su stream, evita reproducir todos los eventos al cargarlo
+type Snapshot struct {
+	StreamId      string `json:"streamId"      bson:"_id"`
+	AggregateType string `json:"aggregateType" bson:"aggregateType"`
+	// Version es la version del ultimo evento incluido en el estado, la carga continua desde el evento siguiente
+	Version int64 `json:"version" bson:"version"`
+	// SchemaVersion es la version del formato del estado, los snapshots con otra version se ignoran
+	SchemaVersion int       `json:"schemaVersion" bson:"schemaVersion"`
+	State         []byte    `json:"state"         bson:"state"`
+	CreatedAt     time.Time `json:"createdAt"     bson:"createdAt"`
+}
+
+// ISnapshotAggregate lo implementan los agregados que se pueden restaurar desde un snapshot,
+// los agregados que no lo implementan siempre se cargan desde el inicio del stream
+type ISnapshotAggregate interface {
+	// SnapshotSchemaVersion se incrementa cuando cambia el formato del estado del snapshot
+	SnapshotSchemaVersion() int
+	// CreateSnapshot serializa el estado actual del agregado
+	CreateSnapshot() ([]byte, error)
+	// RestoreSnapshot restaura el estado del agregado desde un estado creado con CreateSnapshot
+	RestoreSnapshot(state []byte) error
+}
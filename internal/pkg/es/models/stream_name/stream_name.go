@@ -20,6 +20,17 @@ func (n StreamName) GetId() uuid.UUID {
 	return uuid.FromStringOrNil(id)
 }
 
+// Category devuelve el tipo del agregado del stream, es la parte del nombre antes del id
+func (n StreamName) Category() string {
+	name := n.String()
+	index := strings.Index(name, "-")
+	if index < 0 {
+		return name
+	}
+
+	return name[:index]
+}
+
 func (n StreamName) String() string {
 	return string(n)
 }
@@ -35,3 +35,18 @@ func (e ExpectedStreamVersion) IsAny() bool {
 func (e ExpectedStreamVersion) IsStreamExists() bool {
 	return e == StreamExists
 }
+
+// IsSatisfiedBy valida la version esperada con la ultima revision del stream como el `ExpectedRevision` de eventstoredb,
+// la revision de un stream que no existe o que se borro es NoStream
+func (e ExpectedStreamVersion) IsSatisfiedBy(currentVersion int64) bool {
+	switch {
+	case e.IsAny():
+		return true
+	case e.IsNoStream():
+		return currentVersion == NoStream.Value()
+	case e.IsStreamExists():
+		return currentVersion >= 0
+	default:
+		return e.Value() >= 0 && e.Value() == currentVersion
+	}
+}
@@ -0,0 +1,93 @@
+package es
+
+import (
+	"strings"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+type SnapshotOptions struct {
+	// Frequency es la cantidad de eventos entre snapshots de los agregados sin frecuencia propia, 0 no guarda snapshots
+	Frequency int64 `mapstructure:"frequency"`
+	// AggregateFrequencies es la frecuencia de cada tipo de agregado, la clave es la categoria del stream (por ejemplo `order`)
+	AggregateFrequencies map[string]int64 `mapstructure:"aggregateFrequencies"`
+}
+
+func ProvideSnapshotOptions(environment environment.Environment) (*SnapshotOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[SnapshotOptions]())
+	cfg, err := config.BindConfigKey[SnapshotOptions](optionName)
+
+	return cfg, err
+}
+
+// NewSnapshotPolicy crea la politica de las opciones, cada tipo de agregado usa su frecuencia o la frecuencia por defecto
+func NewSnapshotPolicy(options *SnapshotOptions) store.SnapshotPolicy {
+	policies := make(map[string]store.SnapshotPolicy, len(options.AggregateFrequencies))
+	for category, frequency := range options.AggregateFrequencies {
+		policies[category] = NewEveryNEventsSnapshotPolicy(frequency)
+	}
+
+	return NewAggregateTypeSnapshotPolicy(NewEveryNEventsSnapshotPolicy(options.Frequency), policies)
+}
+
+type everyNEventsSnapshotPolicy struct {
+	frequency int64
+}
+
+// NewEveryNEventsSnapshotPolicy guarda un snapshot cada `frequency` eventos del stream, con 0 o menos no guarda snapshots
+func NewEveryNEventsSnapshotPolicy(frequency int64) store.SnapshotPolicy {
+	return &everyNEventsSnapshotPolicy{frequency: frequency}
+}
+
+func (p *everyNEventsSnapshotPolicy) ShouldTakeSnapshot(
+	category string,
+	previousVersion int64,
+	currentVersion int64,
+) bool {
+	if p.frequency <= 0 {
+		return false
+	}
+
+	// the versions start at 0, a commit with several events can skip over the multiple of the frequency
+	return (currentVersion+1)/p.frequency > (previousVersion+1)/p.frequency
+}
+
+type aggregateTypeSnapshotPolicy struct {
+	defaultPolicy store.SnapshotPolicy
+	policies      map[string]store.SnapshotPolicy
+}
+
+// NewAggregateTypeSnapshotPolicy elige la politica por la categoria del stream, las categorias sin politica usan `defaultPolicy`
+func NewAggregateTypeSnapshotPolicy(
+	defaultPolicy store.SnapshotPolicy,
+	policies map[string]store.SnapshotPolicy,
+) store.SnapshotPolicy {
+	// viper lowercases the keys of the maps, the categories are compared in lowercase
+	lowerPolicies := make(map[string]store.SnapshotPolicy, len(policies))
+	for category, policy := range policies {
+		lowerPolicies[strings.ToLower(category)] = policy
+	}
+
+	return &aggregateTypeSnapshotPolicy{defaultPolicy: defaultPolicy, policies: lowerPolicies}
+}
+
+func (p *aggregateTypeSnapshotPolicy) ShouldTakeSnapshot(
+	category string,
+	previousVersion int64,
+	currentVersion int64,
+) bool {
+	policy, ok := p.policies[strings.ToLower(category)]
+	if !ok {
+		policy = p.defaultPolicy
+	}
+	if policy == nil {
+		return false
+	}
+
+	return policy.ShouldTakeSnapshot(category, previousVersion, currentVersion)
+}
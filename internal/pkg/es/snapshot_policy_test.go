@@ -0,0 +1,61 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EveryNEventsSnapshotPolicy_Takes_A_Snapshot_When_A_Commit_Crosses_A_Multiple_Of_N(t *testing.T) {
+	policy := NewEveryNEventsSnapshotPolicy(3)
+
+	// the versions start at 0, version 2 is the third event of the stream
+	tests := []struct {
+		previousVersion int64
+		currentVersion  int64
+		expected        bool
+	}{
+		{previousVersion: -1, currentVersion: 1, expected: false},
+		{previousVersion: -1, currentVersion: 2, expected: true},
+		{previousVersion: 1, currentVersion: 2, expected: true},
+		{previousVersion: 2, currentVersion: 4, expected: false},
+		{previousVersion: 4, currentVersion: 5, expected: true},
+		// a commit with several events that skips over the multiple
+		{previousVersion: 0, currentVersion: 4, expected: true},
+		{previousVersion: 1, currentVersion: 9, expected: true},
+		{previousVersion: 2, currentVersion: 3, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d to %d", test.previousVersion, test.currentVersion), func(t *testing.T) {
+			assert.Equal(t, test.expected, policy.ShouldTakeSnapshot("order", test.previousVersion, test.currentVersion))
+		})
+	}
+}
+
+func Test_EveryNEventsSnapshotPolicy_Without_Frequency_Never_Takes_Snapshots(t *testing.T) {
+	assert.False(t, NewEveryNEventsSnapshotPolicy(0).ShouldTakeSnapshot("order", -1, 100))
+	assert.False(t, NewEveryNEventsSnapshotPolicy(-1).ShouldTakeSnapshot("order", -1, 100))
+}
+
+func Test_AggregateTypeSnapshotPolicy_Uses_The_Policy_Of_The_Category(t *testing.T) {
+	policy := NewAggregateTypeSnapshotPolicy(
+		NewEveryNEventsSnapshotPolicy(10),
+		map[string]store.SnapshotPolicy{"Order": NewEveryNEventsSnapshotPolicy(2)},
+	)
+
+	assert.True(t, policy.ShouldTakeSnapshot("order", 0, 1))
+	assert.False(t, policy.ShouldTakeSnapshot("payment", 0, 1))
+	assert.True(t, policy.ShouldTakeSnapshot("payment", 8, 9))
+}
+
+func Test_NewSnapshotPolicy_Binds_The_Frequencies_Of_The_Options(t *testing.T) {
+	policy := NewSnapshotPolicy(&SnapshotOptions{AggregateFrequencies: map[string]int64{"order": 2}})
+
+	assert.True(t, policy.ShouldTakeSnapshot("order", 0, 1))
+	// the categories without frequency don't take snapshots without a default frequency
+	assert.False(t, policy.ShouldTakeSnapshot("payment", -1, 100))
+}
@@ -28,10 +28,12 @@ import (
 )
 
 type esdbAggregateStore[T models.IHaveEventSourcedAggregate] struct {
-	log        logger.Logger
-	eventStore store.EventStore
-	serializer *EsdbSerializer
-	tracer     trace.Tracer
+	log            logger.Logger
+	eventStore     store.EventStore
+	serializer     *EsdbSerializer
+	tracer         trace.Tracer
+	snapshotStore  store.SnapshotStore
+	snapshotPolicy store.SnapshotPolicy
 }
 
 func NewEventStoreAggregateStore[T models.IHaveEventSourcedAggregate](
@@ -48,6 +50,26 @@ func NewEventStoreAggregateStore[T models.IHaveEventSourcedAggregate](
 	}
 }
 
+// NewEventStoreAggregateStoreWithSnapshots carga los agregados que implementan `models.ISnapshotAggregate` desde su ultimo snapshot
+// y guarda un snapshot nuevo cuando lo indica la politica, los demas agregados se cargan desde el inicio del stream
+func NewEventStoreAggregateStoreWithSnapshots[T models.IHaveEventSourcedAggregate](
+	log logger.Logger,
+	eventStore store.EventStore,
+	serializer *EsdbSerializer,
+	tracer trace.Tracer,
+	snapshotStore store.SnapshotStore,
+	snapshotPolicy store.SnapshotPolicy,
+) store.AggregateStore[T] {
+	return &esdbAggregateStore[T]{
+		log:            log,
+		eventStore:     eventStore,
+		serializer:     serializer,
+		tracer:         tracer,
+		snapshotStore:  snapshotStore,
+		snapshotPolicy: snapshotPolicy,
+	}
+}
+
 func (a *esdbAggregateStore[T]) StoreWithVersion(
 	aggregate T,
 	metadata metadata.Metadata,
@@ -74,6 +96,8 @@ func (a *esdbAggregateStore[T]) StoreWithVersion(
 	streamId := streamName.For[T](aggregate)
 	span.SetAttributes(attribute2.String("StreamId", streamId.String()))
 
+	previousVersion := aggregate.OriginalVersion()
+
 	var streamEvents []*models.StreamEvent
 
 	linq.From(aggregate.UncommittedEvents()).
@@ -111,6 +135,8 @@ func (a *esdbAggregateStore[T]) StoreWithVersion(
 
 	aggregate.MarkUncommittedEventAsCommitted()
 
+	a.takeSnapshot(ctx, aggregate, streamId, previousVersion)
+
 	span.SetAttributes(attribute.Object("Aggregate", aggregate))
 
 	a.log.Infow(
@@ -163,15 +189,31 @@ func (a *esdbAggregateStore[T]) Load(
 	ctx, span := a.tracer.Start(ctx, "esdbAggregateStore.Load")
 	defer span.End()
 
-	position := readPosition.Start
+	snapshot := a.loadSnapshot(ctx, aggregateId)
+	if snapshot == nil {
+		return a.LoadWithReadPosition(ctx, aggregateId, readPosition.Start)
+	}
 
-	return a.LoadWithReadPosition(ctx, aggregateId, position)
+	span.SetAttributes(attribute2.Int64("SnapshotVersion", snapshot.Version))
+
+	// only the events after the snapshot are read
+	return a.loadAggregate(ctx, aggregateId, readPosition.FromInt64(snapshot.Version).Next(), snapshot)
 }
 
 func (a *esdbAggregateStore[T]) LoadWithReadPosition(
 	ctx context.Context,
 	aggregateId uuid.UUID,
 	position readPosition.StreamReadPosition,
+) (T, error) {
+	return a.loadAggregate(ctx, aggregateId, position, nil)
+}
+
+// loadAggregate restores the aggregate from the snapshot, if there is one, and folds the events from the read position
+func (a *esdbAggregateStore[T]) loadAggregate(
+	ctx context.Context,
+	aggregateId uuid.UUID,
+	position readPosition.StreamReadPosition,
+	snapshot *models.Snapshot,
 ) (T, error) {
 	ctx, span := a.tracer.Start(ctx, "esdbAggregateStore.LoadWithReadPosition")
 	span.SetAttributes(attribute2.String("AggregateID", aggregateId.String()))
@@ -203,11 +245,26 @@ func (a *esdbAggregateStore[T]) LoadWithReadPosition(
 
 	method.Call([]reflect.Value{})
 
+	if snapshot != nil {
+		// the snapshot is loaded only for the aggregates that implement ISnapshotAggregate
+		if err := any(aggregate).(models.ISnapshotAggregate).RestoreSnapshot(snapshot.State); err != nil {
+			return *new(T), utils.TraceErrStatusFromSpan(
+				span,
+				errors.WrapIff(
+					err,
+					"[esdbAggregateStore.LoadWithReadPosition:RestoreSnapshot] error in restoring aggregate {%s} from snapshot",
+					aggregateId.String(),
+				),
+			)
+		}
+		aggregate.RestoreVersion(snapshot.Version)
+	}
+
 	streamId := streamName.ForID[T](utils2.ConvertGoogleUUIDToSatoriUUID(aggregateId))
 	span.SetAttributes(attribute2.String("StreamId", streamId.String()))
 
 	streamEvents, err := a.getStreamEvents(streamId, position, ctx)
-	if err != nil || len(streamEvents) == 0 {
+	if err != nil || (len(streamEvents) == 0 && snapshot == nil) {
 		return *new(T), utils.TraceErrStatusFromSpan(
 			span,
 			errors.WithMessage(
@@ -270,6 +327,91 @@ func (a *esdbAggregateStore[T]) Exists(
 	return a.eventStore.StreamExists(streamId, ctx)
 }
 
+// loadSnapshot returns the latest snapshot of the aggregate, it returns nil if the aggregate doesn't support snapshots,
+// the snapshot has another schema version or it can't be read. The aggregate is then loaded from the start of the stream
+func (a *esdbAggregateStore[T]) loadSnapshot(ctx context.Context, aggregateId uuid.UUID) *models.Snapshot {
+	if a.snapshotStore == nil {
+		return nil
+	}
+
+	var aggregate T
+	if _, ok := any(aggregate).(models.ISnapshotAggregate); !ok {
+		return nil
+	}
+
+	streamId := streamName.ForID[T](utils2.ConvertGoogleUUIDToSatoriUUID(aggregateId))
+
+	snapshot, err := a.snapshotStore.Load(ctx, streamId)
+	if err != nil {
+		a.log.WarnMsg(
+			fmt.Sprintf("[esdbAggregateStore.loadSnapshot] error in loading snapshot of stream {%s}, loading all the events", streamId),
+			err,
+		)
+
+		return nil
+	}
+	if snapshot == nil {
+		return nil
+	}
+
+	// the schema version of the type is read from a new instance, T is a nil pointer
+	aggregateType := reflect.TypeOf(aggregate)
+	if aggregateType.Kind() != reflect.Ptr {
+		return nil
+	}
+	instance := reflect.New(aggregateType.Elem()).Interface().(models.ISnapshotAggregate)
+	if instance.SnapshotSchemaVersion() != snapshot.SchemaVersion {
+		a.log.Infow(
+			fmt.Sprintf("[esdbAggregateStore.loadSnapshot] ignoring stale snapshot of stream {%s}", streamId),
+			logger.Fields{"StreamId": streamId.String(), "SchemaVersion": snapshot.SchemaVersion},
+		)
+
+		return nil
+	}
+
+	return snapshot
+}
+
+// takeSnapshot saves a snapshot of the aggregate when the policy says so, a failed snapshot doesn't fail the store
+// because the events are already appended, the next load just reads more events
+func (a *esdbAggregateStore[T]) takeSnapshot(
+	ctx context.Context,
+	aggregate T,
+	streamId streamName.StreamName,
+	previousVersion int64,
+) {
+	if a.snapshotStore == nil || a.snapshotPolicy == nil {
+		return
+	}
+
+	snapshotAggregate, ok := any(aggregate).(models.ISnapshotAggregate)
+	if !ok {
+		return
+	}
+
+	version := aggregate.CurrentVersion()
+	if !a.snapshotPolicy.ShouldTakeSnapshot(streamId.Category(), previousVersion, version) {
+		return
+	}
+
+	state, err := snapshotAggregate.CreateSnapshot()
+	if err == nil {
+		err = a.snapshotStore.Save(ctx, &models.Snapshot{
+			StreamId:      streamId.String(),
+			AggregateType: typemapper.GetFullTypeName(aggregate),
+			Version:       version,
+			SchemaVersion: snapshotAggregate.SnapshotSchemaVersion(),
+			State:         state,
+		})
+	}
+	if err != nil {
+		a.log.WarnMsg(
+			fmt.Sprintf("[esdbAggregateStore.takeSnapshot] error in saving snapshot of stream {%s}", streamId),
+			err,
+		)
+	}
+}
+
 func (a *esdbAggregateStore[T]) getStreamEvents(
 	streamId streamName.StreamName,
 	position readPosition.StreamReadPosition,
@@ -8,6 +8,7 @@ import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
 	appendResult "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/append_result"
 	readPosition "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/read_position"
@@ -15,7 +16,6 @@ import (
 	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
 	esErrors "github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/errors"
 
-	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/utils"
 	"github.com/EventStore/EventStore-Client-Go/esdb"
 	"github.com/ahmetb/go-linq/v3"
@@ -67,7 +67,7 @@ func (e *EsdbSerializer) StreamEventToEventData(
 	}
 	return esdb.EventData{
 		EventID:     id,
-		EventType:   typemapper.GetTypeName(streamEvent.Event),
+		EventType:   typeregistry.GetTypeName(streamEvent.Event),
 		Data:        eventSerializationResult.Data,
 		Metadata:    metadataSerializationResult,
 		ContentType: contentType,
@@ -220,7 +220,7 @@ func (e *EsdbSerializer) Serialize(
 
 	return &esdb.EventData{
 		EventID:     id,
-		EventType:   typemapper.GetTypeName(data),
+		EventType:   typeregistry.GetTypeName(data),
 		Data:        serializedData.Data,
 		ContentType: esdb.JsonContentType,
 		Metadata:    serializedMeta,
@@ -248,7 +248,7 @@ func (e *EsdbSerializer) SerializeObject(
 
 	return &esdb.EventData{
 		EventID:     id,
-		EventType:   typemapper.GetTypeName(data),
+		EventType:   typeregistry.GetTypeName(data),
 		Data:        serializedData.Data,
 		ContentType: esdb.JsonContentType,
 		Metadata:    serializedMeta,
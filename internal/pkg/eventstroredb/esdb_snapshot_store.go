@@ -0,0 +1,142 @@
+package eventstroredb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing/utils"
+
+	"emperror.dev/errors"
+	"github.com/EventStore/EventStore-Client-Go/esdb"
+	"github.com/goccy/go-json"
+	attribute2 "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	snapshotEventType = "Snapshot"
+	// snapshotStreamPrefix the snapshot streams are user streams, the `$` streams are reserved for the system and
+	// they need admin permissions
+	snapshotStreamPrefix = "snapshot-"
+)
+
+// esdbSnapshotStore guarda los snapshots de cada stream en un stream propio con `$maxCount` 1, eventstore borra los snapshots anteriores
+type esdbSnapshotStore struct {
+	client *esdb.Client
+	tracer trace.Tracer
+}
+
+func NewEsdbSnapshotStore(client *esdb.Client, tracer trace.Tracer) store.SnapshotStore {
+	return &esdbSnapshotStore{client: client, tracer: tracer}
+}
+
+func (e *esdbSnapshotStore) Save(ctx context.Context, snapshot *models.Snapshot) error {
+	ctx, span := e.tracer.Start(ctx, "esdbSnapshotStore.Save")
+	span.SetAttributes(attribute2.String("StreamId", snapshot.StreamId))
+	defer span.End()
+
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "error in marshaling the snapshot"))
+	}
+
+	eventData := esdb.EventData{
+		EventType:   snapshotEventType,
+		ContentType: esdb.JsonContentType,
+		Data:        data,
+	}
+	stream := getSnapshotStreamName(snapshot.StreamId)
+
+	_, err = e.client.AppendToStream(
+		ctx,
+		stream,
+		esdb.AppendToStreamOptions{ExpectedRevision: esdb.StreamExists{}},
+		eventData,
+	)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, esdb.ErrWrongExpectedStreamRevision) {
+		return utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "client.AppendToStream"))
+	}
+
+	// the stream doesn't exist, it is created with `$maxCount` 1 so it keeps only the latest snapshot
+	streamMeta := esdb.StreamMetadata{}
+	streamMeta.SetMaxCount(1)
+
+	_, err = e.client.SetStreamMetadata(
+		ctx,
+		stream,
+		esdb.AppendToStreamOptions{ExpectedRevision: esdb.NoStream{}},
+		streamMeta,
+	)
+	if err != nil {
+		return utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "client.SetStreamMetadata"))
+	}
+
+	_, err = e.client.AppendToStream(
+		ctx,
+		stream,
+		esdb.AppendToStreamOptions{ExpectedRevision: esdb.Any{}},
+		eventData,
+	)
+	if err != nil {
+		return utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "client.AppendToStream"))
+	}
+
+	return nil
+}
+
+func (e *esdbSnapshotStore) Load(ctx context.Context, streamName streamName.StreamName) (*models.Snapshot, error) {
+	ctx, span := e.tracer.Start(ctx, "esdbSnapshotStore.Load")
+	span.SetAttributes(attribute2.String("StreamId", streamName.String()))
+	defer span.End()
+
+	stream, err := e.client.ReadStream(
+		ctx,
+		getSnapshotStreamName(streamName.String()),
+		esdb.ReadStreamOptions{
+			Direction: esdb.Backwards,
+			From:      esdb.End{},
+		}, 1)
+	if errors.Is(err, esdb.ErrStreamNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "client.ReadStream"))
+	}
+	defer stream.Close()
+
+	event, err := stream.Recv()
+	if errors.Is(err, esdb.ErrStreamNotFound) || errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "stream.Recv"))
+	}
+
+	snapshot := &models.Snapshot{}
+	if err := json.Unmarshal(event.Event.Data, snapshot); err != nil {
+		return nil, utils.TraceErrStatusFromSpan(span, errors.WrapIf(err, "error in unmarshaling the snapshot"))
+	}
+
+	return snapshot, nil
+}
+
+func getSnapshotStreamName(streamId string) string {
+	return fmt.Sprintf("%s%s", snapshotStreamPrefix, streamId)
+}
+
+// isSnapshotEvent the snapshots are not system events, so the subscriptions to all receive them and they have to skip them
+func isSnapshotEvent(event *esdb.RecordedEvent) bool {
+	return event.EventType == snapshotEventType && strings.HasPrefix(event.StreamID, snapshotStreamPrefix)
+}
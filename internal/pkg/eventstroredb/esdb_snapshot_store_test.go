@@ -0,0 +1,46 @@
+package eventstroredb
+
+import (
+	"testing"
+
+	"github.com/EventStore/EventStore-Client-Go/esdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetSnapshotStreamName(t *testing.T) {
+	name := getSnapshotStreamName("order-3f1a")
+
+	assert.Equal(t, "snapshot-order-3f1a", name)
+	// los streams que empiezan con `$` son del sistema y necesitan permisos de admin
+	assert.NotEqual(t, "$", name[:1])
+}
+
+func Test_IsSnapshotEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *esdb.RecordedEvent
+		want  bool
+	}{
+		{
+			name:  "snapshot",
+			event: &esdb.RecordedEvent{StreamID: getSnapshotStreamName("order-3f1a"), EventType: snapshotEventType},
+			want:  true,
+		},
+		{
+			name:  "event of the aggregate stream",
+			event: &esdb.RecordedEvent{StreamID: "order-3f1a", EventType: "OrderCreatedV1"},
+			want:  false,
+		},
+		{
+			name:  "event named snapshot of other stream",
+			event: &esdb.RecordedEvent{StreamID: "order-3f1a", EventType: snapshotEventType},
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, isSnapshotEvent(test.event))
+		})
+	}
+}
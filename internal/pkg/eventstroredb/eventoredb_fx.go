@@ -4,9 +4,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/encryption"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/config"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/EventStore/EventStore-Client-Go/esdb"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
@@ -25,8 +29,19 @@ var (
 	// - order is not important in provide
 	// - provide can have parameter and will resolve if registered
 	// - execute its func only if it requested
+	// SnapshotModule guarda los snapshots de los agregados en eventstoredb, se usa con `NewEventStoreAggregateStoreWithSnapshots`
+	SnapshotModule = fx.Module( //nolint:gochecknoglobals
+		"eventstoredbsnapshotfx",
+		fx.Provide(fx.Annotate(
+			newSnapshotStore,
+			fx.ParamTags(``, ``, `optional:"true"`, `optional:"true"`),
+		)),
+	)
+
 	eventstoreProviders = fx.Options(fx.Provide( //nolint:gochecknoglobals
 		config.ProvideConfig,
+		es.ProvideSnapshotOptions,
+		es.NewSnapshotPolicy,
 		NewEsdbSerializer,
 		NewEventStoreDB,
 		NewEventStoreDbEventStore,
@@ -41,6 +56,16 @@ var (
 	eventstoreInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
 )
 
+// newSnapshotStore los snapshots se cifran con las opciones de cifrado de los mensajes, el key provider es opcional como en `core`
+func newSnapshotStore(
+	client *esdb.Client,
+	tracer trace.Tracer,
+	encryptionOptions *encryption.EncryptionOptions,
+	keyProvider encryption.KeyProvider,
+) (store.SnapshotStore, error) {
+	return es.NewSnapshotStoreWithEncryption(NewEsdbSnapshotStore(client, tracer), encryptionOptions, keyProvider)
+}
+
 // we don't want to register any dependencies here, its func body should execute always even we don't request for that, so we should use `invoke`
 func registerHooks(
 	lc fx.Lifecycle,
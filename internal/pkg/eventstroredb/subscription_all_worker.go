@@ -172,7 +172,7 @@ func (s *esdbSubscriptionAllWorker) handleEvent(
 	ctx context.Context,
 	resolvedEvent *esdb.ResolvedEvent,
 ) error {
-	if s.isCheckpointEvent(resolvedEvent) || s.isEventWithEmptyData(resolvedEvent) {
+	if s.isCheckpointEvent(resolvedEvent) || s.isSnapshotEvent(resolvedEvent) || s.isEventWithEmptyData(resolvedEvent) {
 		return nil
 	}
 
@@ -227,6 +227,15 @@ func (s *esdbSubscriptionAllWorker) isCheckpointEvent(resolvedEvent *esdb.Resolv
 	return true
 }
 
+func (s *esdbSubscriptionAllWorker) isSnapshotEvent(resolvedEvent *esdb.ResolvedEvent) bool {
+	if !isSnapshotEvent(resolvedEvent.Event) {
+		return false
+	}
+
+	s.log.Info("snapshot event received - skipping")
+	return true
+}
+
 //https://developers.eventstore.com/clients/grpc/subscriptions.html#handling-subscription-drops
 //func (s *esdbSubscriptionAllWorker) resubscribe(ctx context.Context) {
 //	for true {
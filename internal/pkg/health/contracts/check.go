@@ -11,3 +11,14 @@ func (check Check) AllUp() bool {
 
 	return true
 }
+
+// AllAlive es como AllUp pero acepta los componentes degradados, se usa para liveness
+func (check Check) AllAlive() bool {
+	for _, status := range check {
+		if !status.IsUp() && !status.IsDegraded() {
+			return false
+		}
+	}
+
+	return true
+}
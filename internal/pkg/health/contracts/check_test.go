@@ -0,0 +1,54 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    Check
+		allUp    bool
+		allAlive bool
+	}{
+		{
+			name:     "all up",
+			check:    Check{"postgres": {Status: StatusUp}, "redis": {Status: StatusUp}},
+			allUp:    true,
+			allAlive: true,
+		},
+		{
+			name:     "open circuit breaker",
+			check:    Check{"postgres": {Status: StatusUp}, "circuit_breakers.products": {Status: StatusOpen}},
+			allUp:    false,
+			allAlive: true,
+		},
+		{
+			name:     "half open circuit breaker",
+			check:    Check{"circuit_breakers.products": {Status: StatusHalfOpen}},
+			allUp:    false,
+			allAlive: true,
+		},
+		{
+			name:     "down",
+			check:    Check{"postgres": {Status: StatusDown}, "circuit_breakers.products": {Status: StatusOpen}},
+			allUp:    false,
+			allAlive: false,
+		},
+		{
+			name:     "draining consumer",
+			check:    Check{"rabbitmq.products": {Status: StatusDraining}},
+			allUp:    false,
+			allAlive: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.allUp, test.check.AllUp())
+			assert.Equal(t, test.allAlive, test.check.AllAlive())
+		})
+	}
+}
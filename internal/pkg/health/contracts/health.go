@@ -7,6 +7,13 @@ type Health interface {
 	GetHealthName() string
 }
 
+// StatusesHealth es un Health que reporta el estado de varios componentes con estados distintos de up/down,
+// ej: el estado del drenado de cada consumer del bus
+type StatusesHealth interface {
+	Health
+	CheckStatuses(ctx context.Context) Check
+}
+
 type HealthService interface {
 	CheckHealth(ctx context.Context) Check
 }
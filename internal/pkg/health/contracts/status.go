@@ -3,6 +3,13 @@ package contracts
 const (
 	StatusUp   = "up"
 	StatusDown = "down"
+	// StatusDraining y StatusDrained los reportan los consumers al apagarse, ninguno de los dos esta up
+	StatusDraining = "draining"
+	StatusDrained  = "drained"
+	// StatusOpen y StatusHalfOpen son los estados de los circuit breakers de los consumers que no estan cerrados,
+	// el servicio esta degradado pero sigue vivo
+	StatusOpen     = "open"
+	StatusHalfOpen = "half_open"
 )
 
 type Status struct {
@@ -19,3 +26,8 @@ func NewStatus(err error) Status {
 func (status Status) IsUp() bool {
 	return status.Status == StatusUp
 }
+
+// IsDegraded indica que el componente no esta up pero el servicio sigue vivo, solo afecta a readiness
+func (status Status) IsDegraded() bool {
+	return status.Status == StatusOpen || status.Status == StatusHalfOpen
+}
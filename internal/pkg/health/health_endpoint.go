@@ -24,12 +24,24 @@ func NewHealthCheckEndpoint(
 
 func (s *HealthCheckEndpoint) RegisterEndpoints() {
 	s.echoServer.GetEchoInstance().GET("health", s.checkHealth)
+	s.echoServer.GetEchoInstance().GET("health/ready", s.checkReadiness)
 	s.echoServer.GetEchoInstance().GET("test", s.testEndpoint) // test endpoint
 }
 
-// checkHealth is the endpoint that checks the health of the application
+// checkHealth is the endpoint that checks the health of the application, degraded components (e.g. an open circuit breaker) don't fail it
 func (s *HealthCheckEndpoint) checkHealth(c echo.Context) error {
 	// Use context.Background() instead of c.Request().Context() to avoid premature cancellation
+	ctx := context.Background()
+	check := s.service.CheckHealth(ctx)
+	if !check.AllAlive() {
+		return c.JSON(http.StatusServiceUnavailable, check)
+	}
+
+	return c.JSON(http.StatusOK, check)
+}
+
+// checkReadiness is the endpoint that checks if the application can take traffic, every component must be up
+func (s *HealthCheckEndpoint) checkReadiness(c echo.Context) error {
 	ctx := context.Background()
 	check := s.service.CheckHealth(ctx)
 	if !check.AllUp() {
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
 )
@@ -25,6 +26,14 @@ func (service *healthService) CheckHealth(ctx context.Context) contracts.Check {
 	checks := make(contracts.Check)
 
 	for _, health := range service.healthParams.Healths {
+		if statusesHealth, ok := health.(contracts.StatusesHealth); ok {
+			for name, status := range statusesHealth.CheckStatuses(ctx) {
+				checks[fmt.Sprintf("%s.%s", health.GetHealthName(), name)] = status
+			}
+
+			continue
+		}
+
 		checks[health.GetHealthName()] = contracts.NewStatus(
 			health.CheckHealth(ctx),
 		)
@@ -38,6 +38,8 @@ type EchoHttpOptions struct {
 	Host string `mapstructure:"host" env:"Host" json:"host"`
 	// Name es el nombre del servicio
 	Name string `mapstructure:"name" env:"ServiceName" json:"name"`
+	// EnableAdminApi expone los endpoints de administración (ej: dead letter queues), debe estar deshabilitado en producción si no hay autenticación
+	EnableAdminApi bool `mapstructure:"enableAdminApi" env:"EnableAdminApi" json:"enableAdminApi"`
 }
 
 // DefaultConfig retorna una configuración por defecto
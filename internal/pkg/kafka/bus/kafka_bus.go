@@ -0,0 +1,109 @@
+package bus
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/configurations"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/consumercontracts"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/producercontracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+)
+
+type KafkaBus interface {
+	bus.Bus
+	consumerConfigurations.KafkaConsumerConnector
+	// KafkaConfiguration devuelve la configuracion de producers y consumers con la que se construyo el bus
+	KafkaConfiguration() *configurations.KafkaConfiguration
+	// DrainStates devuelve el estado del drenado de cada consumer por su nombre
+	DrainStates() map[string]consumer2.DrainState
+}
+
+type kafkaBus struct {
+	*bus.BrokerBus
+	kafkaConfiguration *configurations.KafkaConfiguration
+	consumerFactory    consumercontracts.ConsumerFactory
+	producerFactory    producercontracts.ProducerFactory
+}
+
+func NewKafkaBus(
+	logger logger.Logger,
+	consumerFactory consumercontracts.ConsumerFactory,
+	producerFactory producercontracts.ProducerFactory,
+	kafkaBuilderFunc configurations.KafkaConfigurationBuilderFuc,
+) (KafkaBus, error) {
+	builder := configurations.NewKafkaConfigurationBuilder()
+	if kafkaBuilderFunc != nil {
+		kafkaBuilderFunc(builder)
+	}
+
+	b := &kafkaBus{
+		BrokerBus:          bus.NewBrokerBus(logger),
+		kafkaConfiguration: builder.Build(),
+		consumerFactory:    consumerFactory,
+		producerFactory:    producerFactory,
+	}
+
+	producersConfigurationMap := make(map[string]*producerConfigurations.KafkaProducerConfiguration)
+	for _, config := range b.kafkaConfiguration.ProducersConfigurations {
+		producersConfigurationMap[config.ProducerMessageType.String()] = config
+	}
+
+	for _, consumerConfiguration := range b.kafkaConfiguration.ConsumersConfigurations {
+		kafkaConsumer, err := consumerFactory.CreateConsumer(consumerConfiguration, b.NotifyConsumed)
+		if err != nil {
+			return nil, err
+		}
+
+		b.AddConsumer(consumerConfiguration.ConsumerMessageType, kafkaConsumer)
+	}
+
+	kafkaProducer, err := producerFactory.CreateProducer(producersConfigurationMap, b.NotifyProduced)
+	if err != nil {
+		return nil, err
+	}
+	b.SetProducer(kafkaProducer)
+
+	return b, nil
+}
+
+// KafkaConfiguration returns the built configuration of the producers and consumers
+func (k *kafkaBus) KafkaConfiguration() *configurations.KafkaConfiguration {
+	return k.kafkaConfiguration
+}
+
+// ConnectKafkaConsumer Add a new consumer to existing message type consumers. if there is no consumer, will create a new consumer for the message type
+func (k *kafkaBus) ConnectKafkaConsumer(
+	messageType types.IMessage,
+	consumerBuilderFunc consumerConfigurations.KafkaConsumerConfigurationBuilderFuc,
+) error {
+	builder := consumerConfigurations.NewKafkaConsumerConfigurationBuilder(messageType)
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+
+	kafkaConsumer, err := k.consumerFactory.CreateConsumer(builder.Build(), k.NotifyConsumed)
+	if err != nil {
+		return err
+	}
+
+	return k.ConnectConsumer(messageType, kafkaConsumer)
+}
+
+// ConnectConsumerHandler Add handler to existing consumer. creates new consumer if not exist
+func (k *kafkaBus) ConnectConsumerHandler(
+	messageType types.IMessage,
+	consumerHandler consumer2.ConsumerHandler,
+) error {
+	if k.ConnectHandlerToConsumers(messageType, consumerHandler) {
+		return nil
+	}
+
+	return k.ConnectKafkaConsumer(messageType, func(builder consumerConfigurations.KafkaConsumerConfigurationBuilder) {
+		builder.WithHandlers(func(handlersBuilder consumer2.ConsumerHandlerConfigurationBuilder) {
+			handlersBuilder.AddHandler(consumerHandler)
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/configurations"
+	kafkaConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/kafkatest"
+	kafkaProducer "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	*types.Message
+	OrderId string
+}
+
+// recordingHandler envia al canal los mensajes que recibe
+type recordingHandler struct {
+	received chan *orderCreated
+}
+
+func (h *recordingHandler) Handle(_ context.Context, consumeContext types.MessageConsumeContext) error {
+	if message, ok := consumeContext.Message().(*orderCreated); ok {
+		h.received <- message
+	}
+
+	return nil
+}
+
+func newTestBus(t *testing.T, cluster *kafkatest.FakeCluster, handler consumer2.ConsumerHandler) KafkaBus {
+	t.Helper()
+
+	options := &config.KafkaOptions{DrainTimeout: time.Second}
+	serializer := json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer())
+	logger := defaultlogger.GetLogger()
+
+	bus, err := NewKafkaBus(
+		logger,
+		kafkaConsumer.NewConsumerFactory(options, cluster, serializer, logger),
+		kafkaProducer.NewProducerFactory(options, cluster, serializer, logger),
+		func(builder configurations.KafkaConfigurationBuilder) {
+			builder.AddConsumer(&orderCreated{}, func(consumerBuilder consumerConfigurations.KafkaConsumerConfigurationBuilder) {
+				consumerBuilder.WithHandlers(func(handlersBuilder consumer2.ConsumerHandlerConfigurationBuilder) {
+					handlersBuilder.AddHandler(handler)
+				})
+			})
+		},
+	)
+	require.NoError(t, err)
+
+	return bus
+}
+
+func Test_PublishMessage_Is_Consumed_By_The_Consumer_Of_The_Bus(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(3)
+	handler := &recordingHandler{received: make(chan *orderCreated, 1)}
+	bus := newTestBus(t, cluster, handler)
+	require.NoError(t, bus.Start(context.Background()))
+	defer bus.Stop()
+
+	require.NoError(t, bus.PublishMessage(
+		context.Background(),
+		&orderCreated{Message: types.NewMessage(uuid.NewV4().String()), OrderId: "order-1"},
+	))
+
+	select {
+	case message := <-handler.received:
+		assert.Equal(t, "order-1", message.OrderId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not consumed")
+	}
+}
+
+func Test_Stop_Drains_The_Consumers(t *testing.T) {
+	bus := newTestBus(t, kafkatest.NewFakeCluster(1), &recordingHandler{received: make(chan *orderCreated, 1)})
+	require.NoError(t, bus.Start(context.Background()))
+
+	require.NoError(t, bus.Stop())
+
+	for _, state := range bus.DrainStates() {
+		assert.Equal(t, consumer2.DrainStateDrained, state)
+	}
+	assert.Len(t, bus.DrainStates(), 1)
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+type KafkaOptions struct {
+	Brokers  []string `mapstructure:"brokers"`
+	ClientId string   `mapstructure:"clientId"`
+	// Version es la version del protocolo de kafka, ej: `3.6.0`, si esta vacia se usa la version por defecto del cliente
+	Version   string `mapstructure:"version"`
+	AutoStart bool   `mapstructure:"autoStart" default:"true"`
+	// InitialOffset es el offset de los consumer groups sin offsets confirmados, `oldest` o `newest`
+	InitialOffset string `mapstructure:"initialOffset"`
+	// CommitInterval es cada cuanto se confirman los offsets de los mensajes procesados
+	CommitInterval time.Duration `mapstructure:"commitInterval"`
+	// DrainTimeout es la espera maxima de los mensajes en curso al detener los consumers
+	DrainTimeout time.Duration `mapstructure:"drainTimeout"`
+}
+
+func ProvideConfig(environment environment.Environment) (*KafkaOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[KafkaOptions]())
+	cfg, err := config.BindConfigKey[KafkaOptions](optionName)
+
+	return cfg, err
+}
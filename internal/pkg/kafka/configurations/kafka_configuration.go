@@ -0,0 +1,11 @@
+package configurations
+
+import (
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/configurations"
+)
+
+type KafkaConfiguration struct {
+	ProducersConfigurations []*producerConfigurations.KafkaProducerConfiguration
+	ConsumersConfigurations []*consumerConfigurations.KafkaConsumerConfiguration
+}
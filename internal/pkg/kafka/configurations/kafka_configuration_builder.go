@@ -0,0 +1,65 @@
+package configurations
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/configurations"
+)
+
+type KafkaConfigurationBuilder interface {
+	AddProducer(
+		producerMessageType types.IMessage,
+		producerBuilderFunc producerConfigurations.KafkaProducerConfigurationBuilderFuc,
+	) KafkaConfigurationBuilder
+	AddConsumer(
+		consumerMessageType types.IMessage,
+		consumerBuilderFunc consumerConfigurations.KafkaConsumerConfigurationBuilderFuc,
+	) KafkaConfigurationBuilder
+	Build() *KafkaConfiguration
+}
+
+type kafkaConfigurationBuilder struct {
+	builder bus.BrokerConfigurationBuilder[
+		*producerConfigurations.KafkaProducerConfiguration,
+		*consumerConfigurations.KafkaConsumerConfiguration,
+	]
+}
+
+func NewKafkaConfigurationBuilder() KafkaConfigurationBuilder {
+	return &kafkaConfigurationBuilder{}
+}
+
+func (k *kafkaConfigurationBuilder) AddProducer(
+	producerMessageType types.IMessage,
+	producerBuilderFunc producerConfigurations.KafkaProducerConfigurationBuilderFuc,
+) KafkaConfigurationBuilder {
+	builder := producerConfigurations.NewKafkaProducerConfigurationBuilder(producerMessageType)
+	if producerBuilderFunc != nil {
+		producerBuilderFunc(builder)
+	}
+
+	k.builder.AddProducer(producerMessageType, builder.Build)
+
+	return k
+}
+
+func (k *kafkaConfigurationBuilder) AddConsumer(
+	consumerMessageType types.IMessage,
+	consumerBuilderFunc consumerConfigurations.KafkaConsumerConfigurationBuilderFuc,
+) KafkaConfigurationBuilder {
+	builder := consumerConfigurations.NewKafkaConsumerConfigurationBuilder(consumerMessageType)
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+
+	k.builder.AddConsumer(consumerMessageType, builder.Build)
+
+	return k
+}
+
+func (k *kafkaConfigurationBuilder) Build() *KafkaConfiguration {
+	producers, consumers := k.builder.Build()
+
+	return &KafkaConfiguration{ProducersConfigurations: producers, ConsumersConfigurations: consumers}
+}
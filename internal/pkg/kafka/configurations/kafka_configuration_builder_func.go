@@ -0,0 +1,3 @@
+package configurations
+
+type KafkaConfigurationBuilderFuc func(builder KafkaConfigurationBuilder)
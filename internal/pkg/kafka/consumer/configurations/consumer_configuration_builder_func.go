@@ -0,0 +1,3 @@
+package configurations
+
+type KafkaConsumerConfigurationBuilderFuc func(builder KafkaConsumerConfigurationBuilder)
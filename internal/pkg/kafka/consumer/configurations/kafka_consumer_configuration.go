@@ -0,0 +1,89 @@
+package configurations
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+)
+
+type KafkaConsumerConfiguration struct {
+	Name                string
+	ConsumerMessageType reflect.Type
+	Pipelines           []pipeline.ConsumerPipeline
+	Handlers            []consumer2.ConsumerHandler
+	*consumer2.ConsumerOptions
+	Topic string
+	// GroupId es el consumer group, las instancias con el mismo group se reparten las particiones del topic
+	// y cada particion se consume en orden por una sola instancia
+	GroupId string
+	// RetryPolicy son los reintentos en proceso del handler, la particion espera mientras se reintenta el mensaje.
+	// `DelayedRedelivery` no se soporta
+	RetryPolicy *consumer2.RetryPolicy
+	// DeadLetterTopic recibe los mensajes que agotan sus reintentos o que no se pueden deserializar, por defecto
+	// `<topic>.<groupId>.dlq`. El offset de un mensaje fallido solo se confirma despues de publicarlo en este topic
+	DeadLetterTopic string
+	// DelayTopic es el prefijo de los delay topics que guardan los mensajes programados que todavia no vencen para no bloquear
+	// la particion del topic, por defecto `<topic>.<groupId>.delay`. Cada delay tier tiene su topic `<DelayTopic>.<tier>ms`
+	DelayTopic string
+}
+
+func NewDefaultKafkaConsumerConfiguration(messageType types2.IMessage) *KafkaConsumerConfiguration {
+	name := fmt.Sprintf("%s_consumer", utils.GetMessageName(messageType))
+
+	return &KafkaConsumerConfiguration{
+		Name:                name,
+		ConsumerMessageType: utils.GetMessageBaseReflectType(messageType),
+		ConsumerOptions:     &consumer2.ConsumerOptions{ExitOnError: false, ConsumerId: ""},
+		Topic:               utils.GetTopicOrExchangeName(messageType),
+		GroupId:             utils.GetQueueName(messageType),
+		RetryPolicy:         consumer2.NewDefaultRetryPolicy(),
+	}
+}
+
+// GetDelayTopic devuelve el delay topic del tier, cada consumer group tiene los suyos porque cada group
+// mueve su copia de los mensajes programados
+func (c *KafkaConsumerConfiguration) GetDelayTopic(tier time.Duration) string {
+	prefix := c.DelayTopic
+	if prefix == "" {
+		prefix = fmt.Sprintf("%s.%s.delay", c.Topic, c.GroupId)
+	}
+
+	return fmt.Sprintf("%s.%dms", prefix, tier.Milliseconds())
+}
+
+// GetDelayTopics devuelve los delay topics de todos los tiers
+func (c *KafkaConsumerConfiguration) GetDelayTopics() []string {
+	topics := make([]string, 0, len(types.DelayTiers))
+	for _, tier := range types.DelayTiers {
+		topics = append(topics, c.GetDelayTopic(tier))
+	}
+
+	return topics
+}
+
+// IsDelayTopic devuelve true si el topic es uno de los delay topics del consumer
+func (c *KafkaConsumerConfiguration) IsDelayTopic(topic string) bool {
+	for _, delayTopic := range c.GetDelayTopics() {
+		if delayTopic == topic {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetDeadLetterTopic devuelve el dead letter topic del consumer, cada consumer group tiene el suyo porque cada group
+// falla de forma independiente con sus propios handlers
+func (c *KafkaConsumerConfiguration) GetDeadLetterTopic() string {
+	if c.DeadLetterTopic != "" {
+		return c.DeadLetterTopic
+	}
+
+	return fmt.Sprintf("%s.%s.dlq", c.Topic, c.GroupId)
+}
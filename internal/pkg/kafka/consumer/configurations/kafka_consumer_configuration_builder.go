@@ -0,0 +1,101 @@
+package configurations
+
+import (
+	messageConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+)
+
+type KafkaConsumerConfigurationBuilder interface {
+	WithHandlers(consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc) KafkaConsumerConfigurationBuilder
+	WithPipelines(pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc) KafkaConsumerConfigurationBuilder
+	WithName(name string) KafkaConsumerConfigurationBuilder
+	WithTopic(topic string) KafkaConsumerConfigurationBuilder
+	WithGroupId(groupId string) KafkaConsumerConfigurationBuilder
+	WithRetryPolicy(retryPolicy *messageConsumer.RetryPolicy) KafkaConsumerConfigurationBuilder
+	// WithDeadLetter envia los mensajes fallidos a `topic`, si esta vacio se usa `<topic>.<groupId>.dlq`
+	WithDeadLetter(topic string) KafkaConsumerConfigurationBuilder
+	// WithDelayTopic cambia el prefijo de los delay topics de los mensajes programados que todavia no vencen
+	WithDelayTopic(topic string) KafkaConsumerConfigurationBuilder
+	Build() *KafkaConsumerConfiguration
+}
+
+type kafkaConsumerConfigurationBuilder struct {
+	kafkaConsumerConfigurations *KafkaConsumerConfiguration
+	pipelinesBuilder            pipeline.ConsumerPipelineConfigurationBuilder
+	handlersBuilder             messageConsumer.ConsumerHandlerConfigurationBuilder
+}
+
+func NewKafkaConsumerConfigurationBuilder(messageType types.IMessage) KafkaConsumerConfigurationBuilder {
+	return &kafkaConsumerConfigurationBuilder{
+		kafkaConsumerConfigurations: NewDefaultKafkaConsumerConfiguration(messageType),
+	}
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithHandlers(
+	consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc,
+) KafkaConsumerConfigurationBuilder {
+	builder := messageConsumer.NewConsumerHandlersConfigurationBuilder()
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+	b.handlersBuilder = builder
+
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc,
+) KafkaConsumerConfigurationBuilder {
+	builder := pipeline.NewConsumerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.pipelinesBuilder = builder
+
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithName(name string) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.Name = name
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithTopic(topic string) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.Topic = topic
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithGroupId(groupId string) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.GroupId = groupId
+	return b
+}
+
+// WithRetryPolicy reemplaza la politica de reintentos por defecto del consumer
+func (b *kafkaConsumerConfigurationBuilder) WithRetryPolicy(
+	retryPolicy *messageConsumer.RetryPolicy,
+) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.RetryPolicy = retryPolicy
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithDeadLetter(topic string) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.DeadLetterTopic = topic
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) WithDelayTopic(topic string) KafkaConsumerConfigurationBuilder {
+	b.kafkaConsumerConfigurations.DelayTopic = topic
+	return b
+}
+
+func (b *kafkaConsumerConfigurationBuilder) Build() *KafkaConsumerConfiguration {
+	if b.pipelinesBuilder != nil {
+		b.kafkaConsumerConfigurations.Pipelines = b.pipelinesBuilder.Build().Pipelines
+	}
+	if b.handlersBuilder != nil {
+		b.kafkaConsumerConfigurations.Handlers = b.handlersBuilder.Build().Handlers
+	}
+
+	return b.kafkaConsumerConfigurations
+}
@@ -0,0 +1,72 @@
+package consumer
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/consumercontracts"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+)
+
+type consumerFactory struct {
+	connection        types2.IConnection
+	messageSerializer serializer.MessageSerializer
+	logger            logger.Logger
+	kafkaOptions      *config.KafkaOptions
+	pipelines         []pipeline.ConsumerPipeline // the pipelines of all the consumers, they wrap the pipelines of each consumer
+}
+
+func NewConsumerFactory(
+	kafkaOptions *config.KafkaOptions,
+	connection types2.IConnection,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+	pipelines ...pipeline.ConsumerPipeline,
+) consumercontracts.ConsumerFactory {
+	factory := &consumerFactory{
+		kafkaOptions:      kafkaOptions,
+		logger:            logger,
+		messageSerializer: messageSerializer,
+		connection:        connection,
+	}
+
+	for _, p := range pipelines {
+		if p != nil {
+			factory.pipelines = append(factory.pipelines, p)
+		}
+	}
+
+	return factory
+}
+
+func (c *consumerFactory) CreateConsumer(
+	consumerConfiguration *consumerConfigurations.KafkaConsumerConfiguration,
+	isConsumedNotifications ...func(message types.IMessage),
+) (consumer.Consumer, error) {
+	if consumerConfiguration != nil && len(c.pipelines) > 0 {
+		// a copy, so the configuration of the bus doesn't accumulate the factory pipelines
+		configuration := *consumerConfiguration
+		configuration.Pipelines = append(
+			append([]pipeline.ConsumerPipeline{}, c.pipelines...),
+			consumerConfiguration.Pipelines...,
+		)
+		consumerConfiguration = &configuration
+	}
+
+	return NewKafkaConsumer(
+		c.kafkaOptions,
+		c.connection,
+		consumerConfiguration,
+		c.messageSerializer,
+		c.logger,
+		isConsumedNotifications...,
+	)
+}
+
+func (c *consumerFactory) Connection() types2.IConnection {
+	return c.connection
+}
@@ -0,0 +1,18 @@
+package consumercontracts
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+)
+
+// ConsumerFactory is a factory for creating consumers
+type ConsumerFactory interface {
+	CreateConsumer(
+		consumerConfiguration *configurations.KafkaConsumerConfiguration,
+		isConsumedNotifications ...func(message messagingTypes.IMessage),
+	) (consumer.Consumer, error)
+
+	Connection() types.IConnection
+}
@@ -0,0 +1,641 @@
+package consumer
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	consumertracing "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+	errorUtils "github.com/DavidReque/go-food-delivery/internal/pkg/utils/errorutils"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+	"github.com/avast/retry-go"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const (
+	defaultDrainTimeout = 10 * time.Second
+	// resumePollInterval is how often a partition blocked by a paused consumer checks if the consumer was resumed
+	resumePollInterval = 500 * time.Millisecond
+	// minRepublishBackoff and maxRepublishBackoff bound the wait between the attempts of a publish to the delay or
+	// dead letter topic, the partition doesn't advance until the publish succeeds
+	minRepublishBackoff = 500 * time.Millisecond
+	maxRepublishBackoff = 30 * time.Second
+)
+
+type kafkaConsumer struct {
+	kafkaOptions            *config.KafkaOptions
+	connection              types.IConnection
+	consumerConfiguration   *configurations.KafkaConsumerConfiguration
+	messageSerializer       serializer.MessageSerializer
+	logger                  logger.Logger
+	handlers                []consumer.ConsumerHandler
+	handlersLock            sync.Mutex
+	pipelines               []pipeline.ConsumerPipeline
+	isConsumedNotifications []func(message messagingTypes.IMessage)
+	consumerGroup           sarama.ConsumerGroup
+	producer                sarama.SyncProducer
+	cancel                  context.CancelFunc
+	consumeDone             chan struct{}
+	lock                    sync.Mutex
+	drainState              consumer.DrainState
+	paused                  bool
+	retryPolicy             *consumer.RetryPolicy
+}
+
+// NewKafkaConsumer crea un consumer que se une al consumer group de su configuracion, cada particion asignada
+// se consume en orden y el offset de un mensaje se confirma despues de que sus handlers terminan sin error
+func NewKafkaConsumer(
+	kafkaOptions *config.KafkaOptions,
+	connection types.IConnection,
+	consumerConfiguration *configurations.KafkaConsumerConfiguration,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+	isConsumedNotifications ...func(message messagingTypes.IMessage),
+) (consumer.Consumer, error) {
+	if consumerConfiguration == nil {
+		return nil, errors.New("consumer configuration is required")
+	}
+
+	if consumerConfiguration.ConsumerMessageType == nil {
+		return nil, errors.New("consumer message type is required")
+	}
+
+	if connection == nil {
+		return nil, errors.New("kafka connection is nil")
+	}
+
+	retryPolicy := consumerConfiguration.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = consumer.NewDefaultRetryPolicy()
+	}
+	// kafka has no retry queues, the retries of a message are done in process
+	if retryPolicy.DelayedRedelivery {
+		return nil, errors.New("kafka consumer doesn't support delayed redelivery, the retries are done in process")
+	}
+
+	// the producer moves the scheduled messages to the delay topic and the failed messages to the dead letter topic
+	syncProducer, err := connection.NewSyncProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkaConsumer{
+		kafkaOptions:            kafkaOptions,
+		connection:              connection,
+		consumerConfiguration:   consumerConfiguration,
+		messageSerializer:       messageSerializer,
+		logger:                  logger,
+		handlers:                consumerConfiguration.Handlers,
+		pipelines:               consumerConfiguration.Pipelines,
+		isConsumedNotifications: isConsumedNotifications,
+		producer:                syncProducer,
+		drainState:              consumer.DrainStateRunning,
+		retryPolicy:             retryPolicy,
+	}, nil
+}
+
+func (k *kafkaConsumer) IsConsumed(h func(message messagingTypes.IMessage)) {
+	k.isConsumedNotifications = append(k.isConsumedNotifications, h)
+}
+
+func (k *kafkaConsumer) ConnectionHandler(handler consumer.ConsumerHandler) {
+	k.handlersLock.Lock()
+	defer k.handlersLock.Unlock()
+
+	k.handlers = append(k.handlers, handler)
+}
+
+// GetName returns the name of the consumer
+func (k *kafkaConsumer) GetName() string {
+	return k.consumerConfiguration.Name
+}
+
+// Start joins the consumer group, the group is consumed in the background until the consumer is stopped
+func (k *kafkaConsumer) Start(ctx context.Context) error {
+	consumerGroup, err := k.connection.NewConsumerGroup(k.consumerConfiguration.GroupId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	k.lock.Lock()
+	k.consumerGroup = consumerGroup
+	k.cancel = cancel
+	k.consumeDone = make(chan struct{})
+	k.drainState = consumer.DrainStateRunning
+	k.paused = false
+	k.lock.Unlock()
+
+	go func() {
+		defer errorUtils.HandlePanic()
+
+		for err := range consumerGroup.Errors() {
+			k.logger.Errorf("error in consumer group of consumer %s: %v", k.GetName(), err)
+		}
+	}()
+
+	go func() {
+		defer errorUtils.HandlePanic()
+		defer close(k.consumeDone)
+
+		topics := append([]string{k.consumerConfiguration.Topic}, k.consumerConfiguration.GetDelayTopics()...)
+		// Consume returns on each rebalance, so it is called again until the consumer is stopped
+		for {
+			err := consumerGroup.Consume(ctx, topics, &consumerGroupHandler{consumer: k})
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				k.logger.Errorf("error in consuming topic `%s` by consumer %s: %v", k.consumerConfiguration.Topic, k.GetName(), err)
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+
+	k.logger.Infof(
+		"consumer %s joined the group `%s` of topic `%s`",
+		k.GetName(),
+		k.consumerConfiguration.GroupId,
+		k.consumerConfiguration.Topic,
+	)
+
+	return nil
+}
+
+// Stop leaves the consumer group after the in-flight messages finish, the offsets of the handled messages are committed
+// when the group session ends. The messages that are not handled before the drain timeout are consumed again by the group
+func (k *kafkaConsumer) Stop() error {
+	k.lock.Lock()
+	consumerGroup, cancel, consumeDone := k.consumerGroup, k.cancel, k.consumeDone
+	k.lock.Unlock()
+
+	if consumerGroup == nil {
+		k.setDrainState(consumer.DrainStateDrained)
+		return nil
+	}
+
+	k.setDrainState(consumer.DrainStateDraining)
+	cancel()
+
+	drainTimeout := defaultDrainTimeout
+	if k.kafkaOptions != nil && k.kafkaOptions.DrainTimeout > 0 {
+		drainTimeout = k.kafkaOptions.DrainTimeout
+	}
+
+	select {
+	case <-consumeDone:
+	case <-time.After(drainTimeout):
+		k.logger.Errorf("consumer %s didn't drain its in-flight messages in %s", k.GetName(), drainTimeout)
+	}
+
+	err := errors.Append(consumerGroup.Close(), k.producer.Close())
+
+	k.setDrainState(consumer.DrainStateDrained)
+
+	return err
+}
+
+// Pause stops fetching the partitions of the consumer, the handler of each partition waits until the consumer is resumed
+func (k *kafkaConsumer) Pause() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.paused || k.consumerGroup == nil {
+		return nil
+	}
+	k.paused = true
+	k.consumerGroup.PauseAll()
+	k.logger.Infof("consumer %s paused", k.GetName())
+
+	return nil
+}
+
+func (k *kafkaConsumer) Resume() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if !k.paused || k.consumerGroup == nil {
+		return nil
+	}
+	k.paused = false
+	k.consumerGroup.ResumeAll()
+	k.logger.Infof("consumer %s resumed", k.GetName())
+
+	return nil
+}
+
+func (k *kafkaConsumer) IsPaused() bool {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return k.paused
+}
+
+// DrainState returns the drain state of the consumer
+func (k *kafkaConsumer) DrainState() consumer.DrainState {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return k.drainState
+}
+
+func (k *kafkaConsumer) setDrainState(state consumer.DrainState) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.drainState = state
+}
+
+// consumeClaim handles the messages of a partition one by one, so the messages with the same key are handled in order
+func (k *kafkaConsumer) consumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if !k.handleMessage(session, msg) {
+				// the session ended before the message was handled, it is consumed again from its offset
+				return nil
+			}
+		}
+	}
+}
+
+// handleMessage returns false if the message was not handled because the session ended
+func (k *kafkaConsumer) handleMessage(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) bool {
+	sessionCtx := session.Context()
+	meta := types.HeadersToMetadata(msg.Headers)
+
+	// kafka has no delayed delivery, a scheduled message that is not due yet is moved to the delay topic of its delay tier
+	// so it doesn't block the next messages of its partition. All the records of a delay topic wait the same tier since
+	// they were written, so a delay topic is never blocked by a record that is due later than the next ones
+	if scheduled := messageHeader.GetMessageScheduled(meta); !scheduled.IsZero() {
+		if k.consumerConfiguration.IsDelayTopic(msg.Topic) {
+			wait := time.Until(scheduled)
+			if due := meta.GetTime(types.DelayDueHeader); !due.IsZero() {
+				wait = min(wait, time.Until(due))
+			}
+			if wait > 0 {
+				select {
+				case <-sessionCtx.Done():
+					return false
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		// a record that leaves its tier before its time waits the remainder in the delay topic of a smaller tier
+		if remaining := time.Until(scheduled); remaining > 0 {
+			tier := types.DelayTier(remaining)
+			if !k.sendUntilDelivered(sessionCtx, func() *sarama.ProducerMessage {
+				return copyRecord(msg, k.consumerConfiguration.GetDelayTopic(tier), map[string]string{
+					types.DelayDueHeader: time.Now().Add(tier).UTC().Format(time.RFC3339Nano),
+				})
+			}) {
+				return false
+			}
+			session.MarkMessage(msg, "")
+
+			return true
+		}
+	}
+
+	// the in-flight handler is not canceled on stop, it is drained
+	ctx := context.WithoutCancel(sessionCtx)
+
+	consumerTraceOption := &consumertracing.ConsumerTracingOptions{
+		MessagingSystem: "kafka",
+		DestinationKind: "topic",
+		Destination:     msg.Topic,
+		OtherAttributes: []attribute.KeyValue{
+			semconv.MessagingKafkaConsumerGroup(k.consumerConfiguration.GroupId),
+			semconv.MessagingKafkaDestinationPartition(int(msg.Partition)),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		},
+	}
+	ctx, beforeConsumeSpan := consumertracing.StartConsumerSpan(ctx, &meta, string(msg.Value), consumerTraceOption)
+
+	consumeContext := k.createConsumeContext(msg, meta)
+	ctx = consumer.ContextWithConsumeInfo(ctx, consumer.ConsumeInfo{ConsumerName: k.GetName(), Consumer: k})
+
+	// a message that can't be deserialized fails the same way in every attempt, so it goes straight to the dead letter topic
+	if consumeContext.Message() == nil {
+		err := errors.Errorf(
+			"message at offset %d of partition %d of topic `%s` could not be deserialized",
+			msg.Offset,
+			msg.Partition,
+			msg.Topic,
+		)
+		handled := k.deadLetter(sessionCtx, session, msg, err, 0)
+		_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, err)
+
+		return handled
+	}
+
+	for {
+		attempts, err := k.runHandlers(ctx, consumeContext)
+		if err == nil {
+			session.MarkMessage(msg, "")
+			_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, nil)
+
+			for _, notification := range k.isConsumedNotifications {
+				if notification != nil {
+					notification(consumeContext.Message())
+				}
+			}
+
+			return true
+		}
+
+		// while the consumer is paused, e.g. by an open circuit breaker, the message waits in its partition
+		// and it is handled again when the consumer is resumed
+		if k.IsPaused() {
+			if !k.waitResume(sessionCtx) {
+				_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, err)
+				return false
+			}
+			continue
+		}
+
+		k.logger.Errorf(
+			"[kafkaConsumer.Handle] message with id `%s` failed after %d attempts: %v",
+			consumeContext.MessageId(),
+			attempts,
+			err,
+		)
+
+		handled := k.deadLetter(sessionCtx, session, msg, err, attempts)
+		_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, err)
+
+		return handled
+	}
+}
+
+// deadLetter moves the failed message to the dead letter topic and commits its offset only after kafka acknowledges the copy,
+// so a failed message is never skipped. The partition waits while the publish fails and if the session ends before,
+// the offset is not committed and the message is consumed again. It returns false if the session ended
+func (k *kafkaConsumer) deadLetter(
+	ctx context.Context,
+	session sarama.ConsumerGroupSession,
+	msg *sarama.ConsumerMessage,
+	handleErr error,
+	attempts uint,
+) bool {
+	if !k.sendUntilDelivered(ctx, func() *sarama.ProducerMessage {
+		return k.deadLetterRecord(msg, handleErr, attempts)
+	}) {
+		return false
+	}
+
+	session.MarkMessage(msg, "")
+
+	return true
+}
+
+// waitResume waits until the consumer is resumed, it returns false if the session ended before
+func (k *kafkaConsumer) waitResume(ctx context.Context) bool {
+	ticker := time.NewTicker(resumePollInterval)
+	defer ticker.Stop()
+
+	for k.IsPaused() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
+
+// runHandlers runs the handlers with their pipelines and retries, it returns the attempts of the failed handler
+func (k *kafkaConsumer) runHandlers(
+	ctx context.Context,
+	consumeContext messagingTypes.MessageConsumeContext,
+) (uint, error) {
+	k.handlersLock.Lock()
+	handlers := k.handlers
+	k.handlersLock.Unlock()
+
+	info, _ := consumer.ConsumeInfoFromContext(ctx)
+
+	for _, handler := range handlers {
+		info.HandlerName = consumer.GetHandlerName(handler)
+
+		var attempts uint
+		err := retry.Do(func() error {
+			info.Attempt = attempts
+			ctx := consumer.ContextWithConsumeInfo(ctx, info)
+			attempts++
+
+			return k.runPipelines(ctx, handler, consumeContext)
+		}, k.retryOptions(ctx)...)
+		if err != nil {
+			return attempts, err
+		}
+	}
+
+	return 0, nil
+}
+
+// runPipelines runs the consumer pipelines around the handler, the first pipeline is the outermost one
+func (k *kafkaConsumer) runPipelines(
+	ctx context.Context,
+	handler consumer.ConsumerHandler,
+	consumeContext messagingTypes.MessageConsumeContext,
+) error {
+	var next pipeline.ConsumerHandlerFunc = func(ctx context.Context) error {
+		return handler.Handle(ctx, consumeContext)
+	}
+
+	for i := len(k.pipelines) - 1; i >= 0; i-- {
+		pipe, inner := k.pipelines[i], next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, consumeContext, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+// retryOptions builds the in-process retry options from the retry policy
+func (k *kafkaConsumer) retryOptions(ctx context.Context) []retry.Option {
+	attempts := k.retryPolicy.MaxAttempts
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	return []retry.Option{
+		retry.Attempts(attempts),
+		retry.DelayType(func(n uint, _ error, _ *retry.Config) time.Duration {
+			return k.retryPolicy.DelayWithJitter(n + 1)
+		}), // exponential backoff with jitter
+		retry.LastErrorOnly(true),
+		retry.RetryIf(func(err error) bool {
+			// non retryable errors, e.g. validation errors, and an open circuit fail on the first attempt, and a paused consumer doesn't retry
+			return k.retryPolicy.IsRetryable(err) && !circuitbreaker.IsCircuitOpen(err) && !k.IsPaused()
+		}),
+		retry.Context(ctx),
+	}
+}
+
+func (k *kafkaConsumer) createConsumeContext(
+	msg *sarama.ConsumerMessage,
+	meta metadata.Metadata,
+) messagingTypes.MessageConsumeContext {
+	contentType := messageHeader.GetMessageContentType(meta)
+	// the consumer only receives messages of its type, the type header has the short name of the message
+	// that can be ambiguous between packages
+	messageType := typemapper.GetFullTypeNameByType(reflect.PointerTo(k.consumerConfiguration.ConsumerMessageType))
+
+	return messagingTypes.NewMessageConsumeContext(
+		k.deserializeData(contentType, messageType, msg.Value),
+		meta,
+		contentType,
+		messageType,
+		msg.Timestamp,
+		uint64(msg.Offset),
+		messageHeader.GetMessageId(meta),
+		messageHeader.GetCorrelationId(meta),
+		msg.Value,
+	)
+}
+
+// deserializeData deserializes the value of the record with the serializer of its content type
+func (k *kafkaConsumer) deserializeData(
+	contentType string,
+	messageType string,
+	body []byte,
+) messagingTypes.IMessage {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	if len(body) == 0 {
+		k.logger.Error("message body is nil or empty in the consumer")
+		return nil
+	}
+
+	message, err := k.messageSerializer.Deserialize(body, messageType, contentType)
+	if err != nil {
+		k.logger.Errorf("error in deserializing of type '%s' in the consumer: %v", messageType, err)
+		return nil
+	}
+
+	return message
+}
+
+// sendUntilDelivered publishes the record until kafka acknowledges it, with a backoff between the attempts.
+// The record is created again in each attempt because sarama doesn't allow to send the same record twice.
+// It returns false if the session ended before the record was published
+func (k *kafkaConsumer) sendUntilDelivered(ctx context.Context, newRecord func() *sarama.ProducerMessage) bool {
+	backoff := minRepublishBackoff
+
+	for {
+		record := newRecord()
+		_, _, err := k.producer.SendMessage(record)
+		if err == nil {
+			return true
+		}
+
+		k.logger.Errorf(
+			"error in publishing to the topic `%s` by consumer %s, retrying in %s: %v",
+			record.Topic,
+			k.GetName(),
+			backoff,
+			err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxRepublishBackoff)
+	}
+}
+
+// deadLetterRecord is a copy of the failed record for the dead letter topic with the error details in its headers
+func (k *kafkaConsumer) deadLetterRecord(msg *sarama.ConsumerMessage, handleErr error, attempts uint) *sarama.ProducerMessage {
+	return copyRecord(msg, k.consumerConfiguration.GetDeadLetterTopic(), map[string]string{
+		types.DeadLetterExceptionMessageHeader:    handleErr.Error(),
+		types.DeadLetterExceptionStackTraceHeader: errorUtils.ErrorsWithStack(handleErr),
+		types.DeadLetterConsumerNameHeader:        k.GetName(),
+		types.DeadLetterAttemptsHeader:            strconv.FormatUint(uint64(attempts), 10),
+		types.DeadLetterOriginalTopicHeader:       msg.Topic,
+		types.DeadLetterOriginalPartitionHeader:   strconv.FormatInt(int64(msg.Partition), 10),
+		types.DeadLetterOriginalOffsetHeader:      strconv.FormatInt(msg.Offset, 10),
+		types.DeadLetterFailedAtHeader:            time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// copyRecord copies the consumed record to the topic with the extra headers, the extra headers replace the headers
+// of the record with the same key. The record keeps its key, so the topic is partitioned like the original topic
+func copyRecord(msg *sarama.ConsumerMessage, topic string, extraHeaders map[string]string) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+len(extraHeaders))
+	for _, header := range msg.Headers {
+		if header == nil {
+			continue
+		}
+		if _, replaced := extraHeaders[string(header.Key)]; !replaced {
+			headers = append(headers, *header)
+		}
+	}
+	for key, value := range extraHeaders {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	record := &sarama.ProducerMessage{
+		Topic:     topic,
+		Value:     sarama.ByteEncoder(msg.Value),
+		Headers:   headers,
+		Timestamp: msg.Timestamp,
+	}
+	if len(msg.Key) > 0 {
+		record.Key = sarama.ByteEncoder(msg.Key)
+	}
+
+	return record
+}
+
+// consumerGroupHandler adapts the consumer to the `sarama.ConsumerGroupHandler` of each group session
+type consumerGroupHandler struct {
+	consumer *kafkaConsumer
+}
+
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.consumer.logger.Infof("consumer %s was assigned the partitions %v", h.consumer.GetName(), session.Claims())
+	return nil
+}
+
+func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	return h.consumer.consumeClaim(session, claim)
+}
@@ -0,0 +1,376 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/kafkatest"
+	kafkaProducer "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type productUpdated struct {
+	*messagingTypes.Message
+	ProductId string
+	Sequence  int
+}
+
+func newProductUpdated(productId string, sequence int) *productUpdated {
+	return &productUpdated{
+		Message:   messagingTypes.NewMessage(uuid.NewV4().String()),
+		ProductId: productId,
+		Sequence:  sequence,
+	}
+}
+
+// recordingHandler envia al canal los mensajes que recibe y falla con `err` si no es nil
+type recordingHandler struct {
+	received chan *productUpdated
+	err      error
+}
+
+func newRecordingHandler(err error) *recordingHandler {
+	return &recordingHandler{received: make(chan *productUpdated, 100), err: err}
+}
+
+func (h *recordingHandler) Handle(_ context.Context, consumeContext messagingTypes.MessageConsumeContext) error {
+	if message, ok := consumeContext.Message().(*productUpdated); ok {
+		h.received <- message
+	}
+
+	return h.err
+}
+
+func (h *recordingHandler) next(t *testing.T) *productUpdated {
+	t.Helper()
+
+	select {
+	case message := <-h.received:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not delivered")
+
+		return nil
+	}
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&productUpdated{}))
+}
+
+func newTestConsumer(
+	t *testing.T,
+	cluster *kafkatest.FakeCluster,
+	handler consumer.ConsumerHandler,
+	configure func(configuration *configurations.KafkaConsumerConfiguration),
+) (consumer.Consumer, *configurations.KafkaConsumerConfiguration) {
+	t.Helper()
+
+	configuration := configurations.NewDefaultKafkaConsumerConfiguration(&productUpdated{})
+	configuration.Handlers = []consumer.ConsumerHandler{handler}
+	configuration.RetryPolicy = &consumer.RetryPolicy{MaxAttempts: 1}
+	if configure != nil {
+		configure(configuration)
+	}
+
+	c, err := NewKafkaConsumer(
+		&config.KafkaOptions{DrainTimeout: time.Second},
+		cluster,
+		configuration,
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		defaultlogger.GetLogger(),
+	)
+	require.NoError(t, err)
+
+	return c, configuration
+}
+
+func startTestConsumer(
+	t *testing.T,
+	cluster *kafkatest.FakeCluster,
+	handler consumer.ConsumerHandler,
+	configure func(configuration *configurations.KafkaConsumerConfiguration),
+) *configurations.KafkaConsumerConfiguration {
+	t.Helper()
+
+	c, configuration := newTestConsumer(t, cluster, handler, configure)
+	require.NoError(t, c.Start(context.Background()))
+	t.Cleanup(func() { _ = c.Stop() })
+
+	return configuration
+}
+
+func withDeadLetter(configuration *configurations.KafkaConsumerConfiguration) {
+	configuration.DeadLetterTopic = fmt.Sprintf("%s.dlq", configuration.Topic)
+}
+
+func newTestProducer(t *testing.T, cluster *kafkatest.FakeCluster) producer.Producer {
+	t.Helper()
+
+	p, err := kafkaProducer.NewKafkaProducer(
+		&config.KafkaOptions{},
+		cluster,
+		nil,
+		defaultlogger.GetLogger(),
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+	)
+	require.NoError(t, err)
+
+	return p
+}
+
+func Test_Consumer_Commits_The_Offset_After_The_Handler_Succeeds(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, cluster, handler, nil)
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+
+	assert.Equal(t, "product-1", handler.next(t).ProductId)
+	assert.Eventually(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Consumer_Handles_The_Messages_Of_A_Partition_Key_In_Order(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(3)
+	handler := newRecordingHandler(nil)
+	startTestConsumer(t, cluster, handler, nil)
+	p := newTestProducer(t, cluster)
+
+	for i := 0; i < 5; i++ {
+		meta := metadata.New()
+		messageHeader.SetPartitionKey(meta, "product-1")
+		require.NoError(t, p.PublishMessageWithTopicName(context.Background(), newProductUpdated("product-1", i), meta, ""))
+	}
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, i, handler.next(t).Sequence)
+	}
+}
+
+func Test_Consumer_Moves_Scheduled_Messages_To_The_Delay_Topic(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, cluster, handler, nil)
+	p := newTestProducer(t, cluster)
+
+	require.NoError(t, p.ScheduleMessage(context.Background(), newProductUpdated("product-1", 1), nil, time.Now().Add(2*time.Hour)))
+	require.NoError(t, p.PublishMessage(context.Background(), newProductUpdated("product-2", 2)))
+
+	// the message after the scheduled one is not blocked by it
+	assert.Equal(t, "product-2", handler.next(t).ProductId)
+	assert.Eventually(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, cluster.Messages(configuration.GetDelayTopic(time.Hour)), 1)
+}
+
+func Test_Consumer_Does_Not_Block_A_Scheduled_Message_Behind_A_Later_One(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, cluster, handler, nil)
+	p := newTestProducer(t, cluster)
+
+	due := time.Now().Add(300 * time.Millisecond)
+	require.NoError(t, p.ScheduleMessage(context.Background(), newProductUpdated("product-1", 1), nil, time.Now().Add(2*time.Hour)))
+	require.NoError(t, p.ScheduleMessage(context.Background(), newProductUpdated("product-2", 2), nil, due))
+
+	// each message waits in the delay topic of its tier, the message due in two hours doesn't delay the other one
+	assert.Equal(t, "product-2", handler.next(t).ProductId)
+	assert.False(t, time.Now().Before(due))
+	assert.Len(t, cluster.Messages(configuration.GetDelayTopic(time.Hour)), 1)
+	assert.Len(t, cluster.Messages(configuration.GetDelayTopic(time.Second)), 1)
+}
+
+func Test_Consumer_Moves_A_Scheduled_Message_To_Smaller_Delay_Tiers_Until_It_Is_Due(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, cluster, handler, nil)
+
+	due := time.Now().Add(1500 * time.Millisecond)
+	require.NoError(t, newTestProducer(t, cluster).ScheduleMessage(context.Background(), newProductUpdated("product-1", 1), nil, due))
+
+	assert.Equal(t, "product-1", handler.next(t).ProductId)
+	assert.False(t, time.Now().Before(due))
+	// the message waits the first second in the tier of one second and the remainder in the same tier again
+	assert.Len(t, cluster.Messages(configuration.GetDelayTopic(time.Second)), 2)
+}
+
+func Test_Consumer_Handles_Scheduled_Messages_When_They_Are_Due(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	startTestConsumer(t, cluster, handler, nil)
+
+	due := time.Now().Add(300 * time.Millisecond)
+	require.NoError(t, newTestProducer(t, cluster).ScheduleMessage(context.Background(), newProductUpdated("product-1", 1), nil, due))
+
+	assert.Equal(t, "product-1", handler.next(t).ProductId)
+	assert.False(t, time.Now().Before(due))
+}
+
+func Test_Consumer_Retries_The_Dead_Letter_Publish_Until_It_Succeeds(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(errors.New("handler failed"))
+	configuration := startTestConsumer(t, cluster, handler, withDeadLetter)
+	cluster.FailProduce(configuration.DeadLetterTopic, errors.New("broker not available"))
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+	handler.next(t)
+
+	// the failed message is not skipped while the dead letter topic is not available
+	assert.Never(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) != 0
+	}, 700*time.Millisecond, 50*time.Millisecond)
+
+	cluster.FailProduce(configuration.DeadLetterTopic, nil)
+
+	assert.Eventually(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Len(t, cluster.Messages(configuration.DeadLetterTopic), 1)
+}
+
+func Test_Consumer_Consumes_Again_The_Messages_Not_Sent_To_The_Dead_Letter_Topic(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	failing := newRecordingHandler(errors.New("handler failed"))
+	first, configuration := newTestConsumer(t, cluster, failing, withDeadLetter)
+	cluster.FailProduce(configuration.DeadLetterTopic, errors.New("broker not available"))
+	require.NoError(t, first.Start(context.Background()))
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+	failing.next(t)
+	require.NoError(t, first.Stop())
+
+	// the next member of the group consumes the message from its uncommitted offset
+	handler := newRecordingHandler(nil)
+	startTestConsumer(t, cluster, handler, withDeadLetter)
+
+	assert.Equal(t, "product-1", handler.next(t).ProductId)
+}
+
+func Test_Consumer_Does_Not_Commit_A_Failed_Message_Without_A_Configured_Dead_Letter_Topic(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(errors.New("handler failed"))
+	configuration := startTestConsumer(t, cluster, handler, nil)
+	require.Empty(t, configuration.DeadLetterTopic)
+
+	deadLetterTopic := fmt.Sprintf("%s.%s.dlq", configuration.Topic, configuration.GroupId)
+	cluster.FailProduce(deadLetterTopic, errors.New("broker not available"))
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+	handler.next(t)
+
+	// the failed message is not dropped, its offset waits for the copy in the default dead letter topic
+	assert.Never(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) != 0
+	}, 700*time.Millisecond, 50*time.Millisecond)
+
+	cluster.FailProduce(deadLetterTopic, nil)
+
+	assert.Eventually(t, func() bool {
+		return cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Len(t, cluster.Messages(deadLetterTopic), 1)
+}
+
+func Test_Consumer_Moves_Messages_That_Can_Not_Be_Deserialized_To_The_Dead_Letter_Topic(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, cluster, handler, withDeadLetter)
+
+	syncProducer, err := cluster.NewSyncProducer()
+	require.NoError(t, err)
+	_, _, err = syncProducer.SendMessage(&sarama.ProducerMessage{
+		Topic: configuration.Topic,
+		Value: sarama.StringEncoder("not a json message"),
+	})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(cluster.Messages(configuration.DeadLetterTopic)) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int64(1), cluster.CommittedOffset(configuration.GroupId, configuration.Topic, 0))
+	assert.Empty(t, handler.received)
+}
+
+func Test_Consumer_Retries_The_Handler_With_Its_Retry_Policy(t *testing.T) {
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(errors.New("handler failed"))
+	configuration := startTestConsumer(t, cluster, handler, func(configuration *configurations.KafkaConsumerConfiguration) {
+		withDeadLetter(configuration)
+		configuration.RetryPolicy = &consumer.RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: 10 * time.Millisecond,
+			Multiplier:   2,
+			Jitter:       0.5,
+		}
+	})
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+
+	for i := 0; i < 3; i++ {
+		handler.next(t)
+	}
+	assert.Eventually(t, func() bool {
+		return len(cluster.Messages(configuration.DeadLetterTopic)) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Empty(t, handler.received)
+}
+
+func Test_Consumer_Does_Not_Retry_The_Non_Retryable_Errors_Of_Its_Retry_Policy(t *testing.T) {
+	errNotRetryable := errors.Sentinel("product not found")
+	cluster := kafkatest.NewFakeCluster(1)
+	handler := newRecordingHandler(errNotRetryable)
+	configuration := startTestConsumer(t, cluster, handler, func(configuration *configurations.KafkaConsumerConfiguration) {
+		withDeadLetter(configuration)
+		configuration.RetryPolicy = &consumer.RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: 10 * time.Millisecond,
+			NonRetryableErrors: []func(err error) bool{
+				func(err error) bool { return errors.Is(err, errNotRetryable) },
+			},
+		}
+	})
+
+	require.NoError(t, newTestProducer(t, cluster).PublishMessage(context.Background(), newProductUpdated("product-1", 1)))
+
+	handler.next(t)
+	assert.Eventually(t, func() bool {
+		return len(cluster.Messages(configuration.DeadLetterTopic)) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Empty(t, handler.received)
+}
+
+func Test_NewKafkaConsumer_Rejects_Delayed_Redelivery(t *testing.T) {
+	configuration := configurations.NewDefaultKafkaConsumerConfiguration(&productUpdated{})
+	configuration.RetryPolicy = &consumer.RetryPolicy{MaxAttempts: 3, DelayedRedelivery: true}
+
+	_, err := NewKafkaConsumer(
+		&config.KafkaOptions{},
+		kafkatest.NewFakeCluster(1),
+		configuration,
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		defaultlogger.GetLogger(),
+	)
+
+	assert.ErrorContains(t, err, "delayed redelivery")
+}
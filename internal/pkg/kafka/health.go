@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+
+	"emperror.dev/errors"
+)
+
+type kafkaHealthChecker struct {
+	connection types.IConnection
+}
+
+func NewKafkaHealthChecker(connection types.IConnection) contracts.Health {
+	return &kafkaHealthChecker{connection}
+}
+
+// CheckHealth pide la metadata del cluster, falla si ningun broker responde
+func (k *kafkaHealthChecker) CheckHealth(ctx context.Context) error {
+	if k.connection.IsClosed() {
+		return errors.New("kafka connection is closed")
+	}
+
+	return k.connection.Client().RefreshMetadata()
+}
+
+func (k *kafkaHealthChecker) GetHealthName() string {
+	return "kafka"
+}
+
+// NewKafkaConsumersHealthChecker reporta el drenado de cada consumer de kafka
+func NewKafkaConsumersHealthChecker(bus bus.KafkaBus) contracts.Health {
+	return consumer.NewConsumersHealthChecker(bus, "kafka_consumers")
+}
@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	consumermetrics "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/metrics/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	kafkaconsumer "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/consumer"
+	kafkaproducer "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/metrics"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// ModuleFunc provided to fxlog, the bus is provided as `bus.KafkaBus` and not as the messaging `bus.Bus`,
+	// so a service can use kafka together with the rabbitmq bus
+	// https://uber-go.github.io/fx/modules.html
+	ModuleFunc = func(kafkaConfigurationConstructor interface{}) fx.Option { //nolint:gochecknoglobals
+		return fx.Module(
+			"kafkafx",
+			fx.Provide(kafkaConfigurationConstructor),
+			kafkaProviders,
+			kafkaInvokes,
+		)
+	}
+
+	kafkaProviders = fx.Options( //nolint:gochecknoglobals
+		fx.Provide(config.ProvideConfig),
+		fx.Provide(types.NewKafkaConnection),
+		fx.Provide(fx.Annotate(
+			bus.NewKafkaBus,
+			fx.ParamTags(``, ``, ``, `optional:"true"`),
+		)),
+		fx.Provide(fx.Annotate(
+			newConsumerMetricsPipeline,
+			fx.ParamTags(`optional:"true"`),
+			fx.ResultTags(`group:"kafka_consumer_pipelines"`),
+		)),
+		fx.Provide(fx.Annotate(
+			kafkaconsumer.NewConsumerFactory,
+			fx.ParamTags(``, ``, ``, ``, `group:"kafka_consumer_pipelines"`),
+		)),
+		fx.Provide(kafkaproducer.NewProducerFactory),
+		fx.Provide(fx.Annotate(
+			NewKafkaHealthChecker,
+			fx.As(new(contracts.Health)),
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
+		)),
+		fx.Provide(fx.Annotate(
+			NewKafkaConsumersHealthChecker,
+			fx.As(new(contracts.Health)),
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
+		)))
+
+	kafkaInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
+)
+
+// newConsumerMetricsPipeline registra las metricas de los consumers de kafka, sin el modulo de metricas no se agrega el pipeline
+func newConsumerMetricsPipeline(appMetrics metrics.AppMetrics) (pipeline.ConsumerPipeline, error) {
+	if appMetrics == nil {
+		return nil, nil
+	}
+
+	return consumermetrics.NewConsumerMetricsPipeline(appMetrics)
+}
+
+func registerHooks(
+	lc fx.Lifecycle,
+	bus bus.KafkaBus,
+	connection types.IConnection,
+	kafkaOptions *config.KafkaOptions,
+	logger logger.Logger,
+) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if !kafkaOptions.AutoStart {
+				return nil
+			}
+
+			// the start ctx is canceled after the start callbacks, the consumers live until the bus is stopped
+			if err := bus.Start(context.Background()); err != nil {
+				return err
+			}
+			logger.Info("kafka consumers are listening.")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := bus.Stop(); err != nil {
+				logger.Errorf("error shutting down kafka consumers: %v", err)
+			}
+
+			if err := connection.Close(); err != nil {
+				logger.Errorf("error in closing the kafka connection: %v", err)
+				return nil
+			}
+			logger.Info("kafka bus shutdown gracefully")
+
+			return nil
+		},
+	})
+}
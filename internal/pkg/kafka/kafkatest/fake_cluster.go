@@ -0,0 +1,586 @@
+package kafkatest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+
+	"github.com/IBM/sarama"
+)
+
+// FakeCluster es un cluster de kafka en memoria para probar los transports sin brokers. Implementa la conexion de kafka:
+// los topics se crean al publicar o consumir con `partitions` particiones, los producers particionan por key como
+// el producer de sarama y los consumer groups se reparten las particiones y confirman los offsets marcados
+type FakeCluster struct {
+	partitions    int32
+	config        *sarama.Config
+	lock          sync.Mutex
+	topics        map[string][][]*sarama.ConsumerMessage
+	groups        map[string]*fakeGroup
+	produceErrors map[string]error
+	// changed se cierra y se reemplaza en cada cambio del cluster para despertar a las particiones que esperan mensajes
+	changed chan struct{}
+	closed  bool
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// fakeGroup son los miembros de un consumer group y sus offsets confirmados, cada cambio de miembros es un rebalanceo
+type fakeGroup struct {
+	members    []*fakeConsumerGroup
+	generation int32
+	rebalance  chan struct{}
+	offsets    map[topicPartition]int64
+}
+
+var _ types.IConnection = (*FakeCluster)(nil)
+
+func NewFakeCluster(partitions int32) *FakeCluster {
+	if partitions <= 0 {
+		partitions = 1
+	}
+
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	return &FakeCluster{
+		partitions:    partitions,
+		config:        config,
+		topics:        map[string][][]*sarama.ConsumerMessage{},
+		groups:        map[string]*fakeGroup{},
+		produceErrors: map[string]error{},
+		changed:       make(chan struct{}),
+	}
+}
+
+// Client devuelve nil, el cluster en memoria no tiene un cliente de sarama
+func (c *FakeCluster) Client() sarama.Client {
+	return nil
+}
+
+func (c *FakeCluster) Config() *sarama.Config {
+	return c.config
+}
+
+func (c *FakeCluster) NewConsumerGroup(groupId string) (sarama.ConsumerGroup, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil, sarama.ErrClosedClient
+	}
+
+	return &fakeConsumerGroup{
+		cluster:  c,
+		groupId:  groupId,
+		memberId: fmt.Sprintf("%s-%d", groupId, time.Now().UnixNano()),
+		errors:   make(chan error),
+		closed:   make(chan struct{}),
+		paused:   map[topicPartition]bool{},
+	}, nil
+}
+
+func (c *FakeCluster) NewSyncProducer() (sarama.SyncProducer, error) {
+	return &fakeSyncProducer{cluster: c}, nil
+}
+
+func (c *FakeCluster) IsClosed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closed
+}
+
+func (c *FakeCluster) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	c.broadcast()
+
+	return nil
+}
+
+// Messages devuelve los mensajes del topic ordenados por particion y offset
+func (c *FakeCluster) Messages(topic string) []*sarama.ConsumerMessage {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var messages []*sarama.ConsumerMessage
+	for _, partition := range c.topics[topic] {
+		messages = append(messages, partition...)
+	}
+
+	return messages
+}
+
+// CommittedOffset devuelve el siguiente offset que consume el group en la particion, 0 si no confirmo ninguno
+func (c *FakeCluster) CommittedOffset(groupId string, topic string, partition int32) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	group, ok := c.groups[groupId]
+	if !ok {
+		return 0
+	}
+
+	return group.offsets[topicPartition{topic: topic, partition: partition}]
+}
+
+// FailProduce hace fallar las publicaciones al topic con `err`, con nil el topic vuelve a aceptar mensajes
+func (c *FakeCluster) FailProduce(topic string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err == nil {
+		delete(c.produceErrors, topic)
+		return
+	}
+	c.produceErrors[topic] = err
+}
+
+func (c *FakeCluster) produce(record *sarama.ProducerMessage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return sarama.ErrClosedClient
+	}
+	if err := c.produceErrors[record.Topic]; err != nil {
+		return err
+	}
+
+	partition, err := sarama.NewHashPartitioner(record.Topic).Partition(record, c.partitions)
+	if err != nil {
+		return err
+	}
+
+	key, err := encode(record.Key)
+	if err != nil {
+		return err
+	}
+	value, err := encode(record.Value)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]*sarama.RecordHeader, 0, len(record.Headers))
+	for _, header := range record.Headers {
+		headers = append(headers, &sarama.RecordHeader{Key: header.Key, Value: header.Value})
+	}
+
+	timestamp := record.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	partitions := c.topic(record.Topic)
+	offset := int64(len(partitions[partition]))
+	partitions[partition] = append(partitions[partition], &sarama.ConsumerMessage{
+		Topic:     record.Topic,
+		Partition: partition,
+		Offset:    offset,
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+		Timestamp: timestamp,
+	})
+	record.Partition, record.Offset = partition, offset
+	c.broadcast()
+
+	return nil
+}
+
+// topic devuelve las particiones del topic y lo crea si no existe, requiere el lock
+func (c *FakeCluster) topic(name string) [][]*sarama.ConsumerMessage {
+	partitions, ok := c.topics[name]
+	if !ok {
+		partitions = make([][]*sarama.ConsumerMessage, c.partitions)
+		c.topics[name] = partitions
+	}
+
+	return partitions
+}
+
+// broadcast despierta a las particiones que esperan un cambio, requiere el lock
+func (c *FakeCluster) broadcast() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// join agrega el miembro a su group, un miembro nuevo rebalancea el group. Requiere el lock
+func (c *FakeCluster) join(member *fakeConsumerGroup) *fakeGroup {
+	group, ok := c.groups[member.groupId]
+	if !ok {
+		group = &fakeGroup{rebalance: make(chan struct{}), offsets: map[topicPartition]int64{}}
+		c.groups[member.groupId] = group
+	}
+
+	for _, m := range group.members {
+		if m == member {
+			return group
+		}
+	}
+
+	group.members = append(group.members, member)
+	group.rebalanceMembers()
+
+	return group
+}
+
+func (c *FakeCluster) leave(member *fakeConsumerGroup) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	group, ok := c.groups[member.groupId]
+	if !ok {
+		return
+	}
+
+	for i, m := range group.members {
+		if m == member {
+			group.members = append(group.members[:i], group.members[i+1:]...)
+			group.rebalanceMembers()
+			break
+		}
+	}
+	c.broadcast()
+}
+
+// assign reparte las particiones de los topics entre los miembros del group en el orden en que se unieron. Requiere el lock
+func (c *FakeCluster) assign(group *fakeGroup, member *fakeConsumerGroup, topics []string) map[string][]int32 {
+	sortedTopics := append([]string{}, topics...)
+	sort.Strings(sortedTopics)
+
+	index := 0
+	for i, m := range group.members {
+		if m == member {
+			index = i
+		}
+	}
+
+	claims := map[string][]int32{}
+	i := 0
+	for _, topic := range sortedTopics {
+		c.topic(topic)
+		for partition := int32(0); partition < c.partitions; partition++ {
+			if i%len(group.members) == index {
+				claims[topic] = append(claims[topic], partition)
+			}
+			i++
+		}
+	}
+
+	return claims
+}
+
+func (g *fakeGroup) rebalanceMembers() {
+	g.generation++
+	close(g.rebalance)
+	g.rebalance = make(chan struct{})
+}
+
+func encode(encoder sarama.Encoder) ([]byte, error) {
+	if encoder == nil {
+		return nil, nil
+	}
+
+	return encoder.Encode()
+}
+
+// fakeSyncProducer publica en el cluster, solo implementa las publicaciones sin transacciones
+type fakeSyncProducer struct {
+	sarama.SyncProducer
+	cluster *FakeCluster
+}
+
+func (p *fakeSyncProducer) SendMessage(record *sarama.ProducerMessage) (int32, int64, error) {
+	if err := p.cluster.produce(record); err != nil {
+		return -1, -1, err
+	}
+
+	return record.Partition, record.Offset, nil
+}
+
+func (p *fakeSyncProducer) SendMessages(records []*sarama.ProducerMessage) error {
+	var producerErrors sarama.ProducerErrors
+	for _, record := range records {
+		if err := p.cluster.produce(record); err != nil {
+			producerErrors = append(producerErrors, &sarama.ProducerError{Msg: record, Err: err})
+		}
+	}
+
+	if len(producerErrors) > 0 {
+		return producerErrors
+	}
+
+	return nil
+}
+
+func (p *fakeSyncProducer) Close() error {
+	return nil
+}
+
+// fakeConsumerGroup es un miembro de un consumer group, se une al group en el primer `Consume` y lo deja en `Close`
+type fakeConsumerGroup struct {
+	cluster   *FakeCluster
+	groupId   string
+	memberId  string
+	errors    chan error
+	closed    chan struct{}
+	closeOnce sync.Once
+	// paused son las particiones pausadas, la clave sin topic pausa todas. Se protege con el lock del cluster
+	paused map[topicPartition]bool
+}
+
+// Consume consume las particiones asignadas hasta que el ctx se cancela, el group se rebalancea o un `ConsumeClaim` termina
+func (g *fakeConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	select {
+	case <-g.closed:
+		return sarama.ErrClosedConsumerGroup
+	default:
+	}
+
+	g.cluster.lock.Lock()
+	group := g.cluster.join(g)
+	claims := g.cluster.assign(group, g, topics)
+	generation, rebalance := group.generation, group.rebalance
+	g.cluster.lock.Unlock()
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-rebalance:
+		case <-g.closed:
+		case <-sessionCtx.Done():
+		}
+		cancel()
+	}()
+
+	session := &fakeSession{group: g, claims: claims, generation: generation, ctx: sessionCtx}
+	if err := handler.Setup(session); err != nil {
+		return err
+	}
+
+	waitGroup := sync.WaitGroup{}
+	for topic, partitions := range claims {
+		for _, partition := range partitions {
+			claim := &fakeClaim{
+				cluster:       g.cluster,
+				topic:         topic,
+				partition:     partition,
+				initialOffset: g.initialOffset(topic, partition),
+				messages:      make(chan *sarama.ConsumerMessage),
+			}
+
+			waitGroup.Add(2)
+			go func() {
+				defer waitGroup.Done()
+				g.feed(sessionCtx, claim)
+			}()
+			go func() {
+				defer waitGroup.Done()
+				// like sarama, the session ends when any of its claims returns
+				defer cancel()
+				_ = handler.ConsumeClaim(session, claim)
+			}()
+		}
+	}
+
+	<-sessionCtx.Done()
+	waitGroup.Wait()
+
+	return handler.Cleanup(session)
+}
+
+func (g *fakeConsumerGroup) initialOffset(topic string, partition int32) int64 {
+	g.cluster.lock.Lock()
+	defer g.cluster.lock.Unlock()
+
+	if offset, ok := g.cluster.groups[g.groupId].offsets[topicPartition{topic: topic, partition: partition}]; ok {
+		return offset
+	}
+	if g.cluster.config.Consumer.Offsets.Initial == sarama.OffsetNewest {
+		return int64(len(g.cluster.topic(topic)[partition]))
+	}
+
+	return 0
+}
+
+// feed envia los mensajes de la particion al claim desde su offset inicial, y espera los nuevos mientras la sesion sigue activa
+func (g *fakeConsumerGroup) feed(ctx context.Context, claim *fakeClaim) {
+	defer close(claim.messages)
+
+	offset := claim.initialOffset
+	for {
+		g.cluster.lock.Lock()
+		var message *sarama.ConsumerMessage
+		log := g.cluster.topic(claim.topic)[claim.partition]
+		if !g.isPaused(claim.topic, claim.partition) && offset < int64(len(log)) {
+			message = log[offset]
+		}
+		changed := g.cluster.changed
+		g.cluster.lock.Unlock()
+
+		if message == nil {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case claim.messages <- message:
+			offset++
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// isPaused requiere el lock del cluster
+func (g *fakeConsumerGroup) isPaused(topic string, partition int32) bool {
+	return g.paused[topicPartition{}] || g.paused[topicPartition{topic: topic, partition: partition}]
+}
+
+func (g *fakeConsumerGroup) setPaused(partitions map[string][]int32, paused bool) {
+	g.cluster.lock.Lock()
+	defer g.cluster.lock.Unlock()
+
+	for topic, topicPartitions := range partitions {
+		for _, partition := range topicPartitions {
+			g.paused[topicPartition{topic: topic, partition: partition}] = paused
+		}
+	}
+	g.cluster.broadcast()
+}
+
+func (g *fakeConsumerGroup) Errors() <-chan error {
+	return g.errors
+}
+
+func (g *fakeConsumerGroup) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.closed)
+		close(g.errors)
+		g.cluster.leave(g)
+	})
+
+	return nil
+}
+
+func (g *fakeConsumerGroup) Pause(partitions map[string][]int32) {
+	g.setPaused(partitions, true)
+}
+
+func (g *fakeConsumerGroup) Resume(partitions map[string][]int32) {
+	g.setPaused(partitions, false)
+}
+
+func (g *fakeConsumerGroup) PauseAll() {
+	g.cluster.lock.Lock()
+	defer g.cluster.lock.Unlock()
+
+	g.paused[topicPartition{}] = true
+	g.cluster.broadcast()
+}
+
+func (g *fakeConsumerGroup) ResumeAll() {
+	g.cluster.lock.Lock()
+	defer g.cluster.lock.Unlock()
+
+	g.paused = map[topicPartition]bool{}
+	g.cluster.broadcast()
+}
+
+type fakeSession struct {
+	group      *fakeConsumerGroup
+	claims     map[string][]int32
+	generation int32
+	ctx        context.Context
+}
+
+func (s *fakeSession) Claims() map[string][]int32 {
+	return s.claims
+}
+
+func (s *fakeSession) MemberID() string {
+	return s.group.memberId
+}
+
+func (s *fakeSession) GenerationID() int32 {
+	return s.generation
+}
+
+// MarkOffset confirma el offset al momento, el cluster no tiene un intervalo de auto commit
+func (s *fakeSession) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	cluster := s.group.cluster
+	cluster.lock.Lock()
+	defer cluster.lock.Unlock()
+
+	offsets := cluster.groups[s.group.groupId].offsets
+	key := topicPartition{topic: topic, partition: partition}
+	if offset > offsets[key] {
+		offsets[key] = offset
+	}
+}
+
+func (s *fakeSession) Commit() {}
+
+func (s *fakeSession) ResetOffset(topic string, partition int32, offset int64, _ string) {
+	cluster := s.group.cluster
+	cluster.lock.Lock()
+	defer cluster.lock.Unlock()
+
+	cluster.groups[s.group.groupId].offsets[topicPartition{topic: topic, partition: partition}] = offset
+}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+func (s *fakeSession) Context() context.Context {
+	return s.ctx
+}
+
+type fakeClaim struct {
+	cluster       *FakeCluster
+	topic         string
+	partition     int32
+	initialOffset int64
+	messages      chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string {
+	return c.topic
+}
+
+func (c *fakeClaim) Partition() int32 {
+	return c.partition
+}
+
+func (c *fakeClaim) InitialOffset() int64 {
+	return c.initialOffset
+}
+
+func (c *fakeClaim) HighWaterMarkOffset() int64 {
+	c.cluster.lock.Lock()
+	defer c.cluster.lock.Unlock()
+
+	return int64(len(c.cluster.topic(c.topic)[c.partition]))
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
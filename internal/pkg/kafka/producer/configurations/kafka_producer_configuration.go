@@ -0,0 +1,24 @@
+package configurations
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+)
+
+type KafkaProducerConfiguration struct {
+	ProducerMessageType reflect.Type
+	// Topic es el topic de los mensajes del tipo, por defecto el nombre del exchange del tipo en rabbitmq
+	Topic string
+	// Pipelines se ejecutan antes de publicar cada mensaje, el primero envuelve a los demas
+	Pipelines []pipeline.ProducerPipeline
+}
+
+func NewDefaultKafkaProducerConfiguration(messageType types2.IMessage) *KafkaProducerConfiguration {
+	return &KafkaProducerConfiguration{
+		ProducerMessageType: utils.GetMessageBaseReflectType(messageType),
+		Topic:               utils.GetTopicOrExchangeName(messageType),
+	}
+}
@@ -0,0 +1,43 @@
+package configurations
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+)
+
+type KafkaProducerConfigurationBuilder interface {
+	WithTopic(topic string) KafkaProducerConfigurationBuilder
+	WithPipelines(pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc) KafkaProducerConfigurationBuilder
+	Build() *KafkaProducerConfiguration
+}
+
+type kafkaProducerConfigurationBuilder struct {
+	kafkaProducerConfigurations *KafkaProducerConfiguration
+}
+
+func NewKafkaProducerConfigurationBuilder(messageType types.IMessage) KafkaProducerConfigurationBuilder {
+	return &kafkaProducerConfigurationBuilder{
+		kafkaProducerConfigurations: NewDefaultKafkaProducerConfiguration(messageType),
+	}
+}
+
+func (b *kafkaProducerConfigurationBuilder) WithTopic(topic string) KafkaProducerConfigurationBuilder {
+	b.kafkaProducerConfigurations.Topic = topic
+	return b
+}
+
+func (b *kafkaProducerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc,
+) KafkaProducerConfigurationBuilder {
+	builder := pipeline.NewProducerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.kafkaProducerConfigurations.Pipelines = builder.Build().Pipelines
+
+	return b
+}
+
+func (b *kafkaProducerConfigurationBuilder) Build() *KafkaProducerConfiguration {
+	return b.kafkaProducerConfigurations
+}
@@ -0,0 +1,334 @@
+package producer
+
+import (
+	"context"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	producertracing "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+	uuid "github.com/satori/go.uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+type kafkaProducer struct {
+	logger                  logger.Logger
+	kafkaOptions            *config.KafkaOptions
+	connection              types.IConnection
+	messageSerializer       serializer.MessageSerializer
+	producersConfigurations map[string]*configurations.KafkaProducerConfiguration
+	isProducedNotifications []func(message messagingTypes.IMessage)
+	syncProducer            sarama.SyncProducer
+}
+
+// pendingMessage is a serialized message with its producer span, the span is finished when kafka acknowledges the message
+type pendingMessage struct {
+	message         messagingTypes.IMessage
+	producerMessage *sarama.ProducerMessage
+	finishSpan      func(err error) error
+}
+
+func NewKafkaProducer(
+	cfg *config.KafkaOptions,
+	connection types.IConnection,
+	kafkaProducersConfiguration map[string]*configurations.KafkaProducerConfiguration,
+	logger logger.Logger,
+	messageSerializer serializer.MessageSerializer,
+	isProducedNotifications ...func(message messagingTypes.IMessage),
+) (producer.Producer, error) {
+	if connection == nil {
+		return nil, errors.New("kafka connection is nil")
+	}
+
+	// the producer shares the client of the connection, so it uses the same brokers metadata as the consumers
+	syncProducer, err := connection.NewSyncProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkaProducer{
+		logger:                  logger,
+		kafkaOptions:            cfg,
+		connection:              connection,
+		messageSerializer:       messageSerializer,
+		producersConfigurations: kafkaProducersConfiguration,
+		isProducedNotifications: isProducedNotifications,
+		syncProducer:            syncProducer,
+	}, nil
+}
+
+// IsProduced is a method that adds a notification function to the list of produced notifications
+func (k *kafkaProducer) IsProduced(h func(message messagingTypes.IMessage)) {
+	k.isProducedNotifications = append(k.isProducedNotifications, h)
+}
+
+// PublishMessage publishes the message to the topic of its type
+func (k *kafkaProducer) PublishMessage(ctx context.Context, message messagingTypes.IMessage) error {
+	return k.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+// PublishMessageWithTopicName publishes the message to the topic, the messages with the same partition key
+// in the metadata go to the same partition and are consumed in order
+func (k *kafkaProducer) PublishMessageWithTopicName(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	return k.PublishMessages(ctx, []messagingTypes.IMessage{message}, meta, topicName)
+}
+
+// PublishMessageWithDelay publishes a message that is handled by the consumers after the delay
+func (k *kafkaProducer) PublishMessageWithDelay(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return k.ScheduleMessage(ctx, message, meta, time.Now().Add(delay))
+}
+
+// ScheduleMessage publishes the message right away with its scheduled time in the metadata, kafka has no delayed
+// delivery so the consumer moves the message to its delay topic and handles it there at the scheduled time,
+// the next messages of the partition don't wait for it
+func (k *kafkaProducer) ScheduleMessage(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	if meta == nil {
+		meta = metadata.New()
+	}
+	messageHeader.SetMessageScheduled(meta, at.UTC())
+
+	return k.PublishMessageWithTopicName(ctx, message, meta, "")
+}
+
+// PublishMessages sends the messages in a single request and waits for the acknowledgement of all of them
+func (k *kafkaProducer) PublishMessages(
+	ctx context.Context,
+	messages []messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	pending := make([]*pendingMessage, 0, len(messages))
+	for _, message := range messages {
+		p, err := k.prepare(ctx, message, meta, topicName)
+		if err != nil {
+			for _, prepared := range pending {
+				_ = prepared.finishSpan(err)
+			}
+			return err
+		}
+		// a pipeline skipped the publish
+		if p == nil {
+			continue
+		}
+		pending = append(pending, p)
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	producerMessages := make([]*sarama.ProducerMessage, 0, len(pending))
+	for _, p := range pending {
+		producerMessages = append(producerMessages, p.producerMessage)
+	}
+
+	sendErr := k.syncProducer.SendMessages(producerMessages)
+
+	// SendMessages returns the failed messages in a `sarama.ProducerErrors`, the other messages were acknowledged
+	failed := map[*sarama.ProducerMessage]error{}
+	var producerErrors sarama.ProducerErrors
+	if errors.As(sendErr, &producerErrors) {
+		for _, producerError := range producerErrors {
+			failed[producerError.Msg] = producerError.Err
+		}
+	}
+
+	var publishErr error
+	for _, p := range pending {
+		err, ok := failed[p.producerMessage]
+		if !ok && len(failed) == 0 {
+			err = sendErr
+		}
+
+		if err = p.finishSpan(err); err != nil {
+			publishErr = errors.Append(publishErr, err)
+			continue
+		}
+
+		for _, notification := range k.isProducedNotifications {
+			if notification != nil {
+				notification(p.message)
+			}
+		}
+	}
+
+	return publishErr
+}
+
+// prepare runs the pipelines of the message and returns its record, it returns nil if a pipeline skipped the publish
+func (k *kafkaProducer) prepare(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) (*pendingMessage, error) {
+	producerConfiguration := k.getProducerConfigurationByMessage(message)
+	if producerConfiguration == nil {
+		producerConfiguration = configurations.NewDefaultKafkaProducerConfiguration(message)
+	}
+
+	topic := topicName
+	if topic == "" {
+		topic = producerConfiguration.Topic
+	}
+
+	// each message of a batch gets its own metadata, the message id and type are different
+	meta = k.getMetadata(message, copyMetadata(meta))
+	partitionKey := messageHeader.GetPartitionKey(meta)
+
+	otherAttributes := []attribute.KeyValue{}
+	if partitionKey != "" {
+		otherAttributes = append(otherAttributes, semconv.MessagingKafkaMessageKey(partitionKey))
+	}
+	producerOptions := &producertracing.ProducerTracingOptions{
+		MessagingSystem: "kafka",
+		DestinationKind: "topic",
+		Destination:     topic,
+		OtherAttributes: otherAttributes,
+	}
+
+	serializedObj, err := k.messageSerializer.Serialize(message)
+	if err != nil {
+		return nil, err
+	}
+	messageHeader.SetMessageContentType(meta, serializedObj.ContentType)
+	// the serializer headers, like the key id of an encrypted payload, travel with the message
+	for key, value := range serializedObj.Metadata {
+		meta.Set(key, value)
+	}
+
+	var pending *pendingMessage
+
+	// building the record is the innermost handler, the pipelines can validate or enrich the message before it is sent
+	publishHandler := func(ctx context.Context) error {
+		_, beforeProduceSpan := producertracing.StartProducerSpan(
+			ctx,
+			message,
+			&meta,
+			string(serializedObj.Data),
+			producerOptions,
+		)
+
+		producerMessage := &sarama.ProducerMessage{
+			Topic:     topic,
+			Value:     sarama.ByteEncoder(serializedObj.Data),
+			Headers:   types.MetadataToHeaders(meta),
+			Timestamp: time.Now(),
+		}
+		// the messages without a partition key are spread between the partitions
+		if partitionKey != "" {
+			producerMessage.Key = sarama.StringEncoder(partitionKey)
+		}
+
+		pending = &pendingMessage{
+			message:         message,
+			producerMessage: producerMessage,
+			finishSpan: func(err error) error {
+				return producertracing.FinishProducerSpan(beforeProduceSpan, err)
+			},
+		}
+
+		return nil
+	}
+
+	producerContext := &pipeline.ProducerContext{
+		Message:     message,
+		Metadata:    meta,
+		Body:        serializedObj.Data,
+		ContentType: serializedObj.ContentType,
+	}
+	if err := k.runPipelines(ctx, producerConfiguration.Pipelines, producerContext, publishHandler); err != nil {
+		if pending != nil {
+			_ = pending.finishSpan(err)
+		}
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// runPipelines runs the producer pipelines around the publish, the first pipeline is the outermost one
+func (k *kafkaProducer) runPipelines(
+	ctx context.Context,
+	pipelines []pipeline.ProducerPipeline,
+	producerContext *pipeline.ProducerContext,
+	publishHandler pipeline.ProducerHandlerFunc,
+) error {
+	next := publishHandler
+	for i := len(pipelines) - 1; i >= 0; i-- {
+		pipe, inner := pipelines[i], next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, producerContext, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+func (k *kafkaProducer) getProducerConfigurationByMessage(
+	message messagingTypes.IMessage,
+) *configurations.KafkaProducerConfiguration {
+	messageType := utils.GetMessageBaseReflectType(message)
+	return k.producersConfigurations[messageType.String()]
+}
+
+// getMetadata sets the headers of the message, the consumers pick the type and the deserializer of the message with them
+func (k *kafkaProducer) getMetadata(
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+) metadata.Metadata {
+	messageHeader.SetMessageType(meta, typeregistry.GetTypeName(message))
+	messageHeader.SetMessageContentType(meta, k.messageSerializer.ContentType())
+	messageHeader.SetMessageId(meta, message.GeMessageId())
+	messageHeader.SetMessageCreated(meta, message.GetCreated())
+	messageHeader.SetMessageName(meta, utils.GetMessageName(message))
+
+	if messageHeader.GetCorrelationId(meta) == "" {
+		messageHeader.SetCorrelationId(meta, uuid.NewV4().String())
+	}
+
+	return meta
+}
+
+func copyMetadata(meta metadata.Metadata) metadata.Metadata {
+	copied := metadata.New()
+	for key, value := range meta {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// Close closes the producer, the client of the connection is closed by the connection
+func (k *kafkaProducer) Close() error {
+	return k.syncProducer.Close()
+}
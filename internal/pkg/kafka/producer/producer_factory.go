@@ -0,0 +1,47 @@
+package producer
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/producer/producercontracts"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/kafka/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+)
+
+type producerFactory struct {
+	connection        types2.IConnection
+	logger            logger.Logger
+	messageSerializer serializer.MessageSerializer
+	kafkaOptions      *config.KafkaOptions
+}
+
+func NewProducerFactory(
+	kafkaOptions *config.KafkaOptions,
+	connection types2.IConnection,
+	messageSerializer serializer.MessageSerializer,
+	l logger.Logger,
+) producercontracts.ProducerFactory {
+	return &producerFactory{
+		kafkaOptions:      kafkaOptions,
+		logger:            l,
+		connection:        connection,
+		messageSerializer: messageSerializer,
+	}
+}
+
+func (p *producerFactory) CreateProducer(
+	kafkaProducersConfiguration map[string]*producerConfigurations.KafkaProducerConfiguration,
+	isProducedNotifications ...func(message types.IMessage),
+) (producer.Producer, error) {
+	return NewKafkaProducer(
+		p.kafkaOptions,
+		p.connection,
+		kafkaProducersConfiguration,
+		p.logger,
+		p.messageSerializer,
+		isProducedNotifications...,
+	)
+}
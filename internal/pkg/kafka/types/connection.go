@@ -0,0 +1,128 @@
+package types
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/kafka/config"
+
+	"emperror.dev/errors"
+	"github.com/IBM/sarama"
+)
+
+const defaultCommitInterval = time.Second
+
+type IConnection interface {
+	// Client es el cliente compartido por el producer y los consumer groups
+	Client() sarama.Client
+	Config() *sarama.Config
+	// NewConsumerGroup crea el consumer group `groupId` con su propio cliente, los consumer groups no pueden compartir el cliente
+	NewConsumerGroup(groupId string) (sarama.ConsumerGroup, error)
+	// NewSyncProducer crea un producer que comparte el cliente de la conexion
+	NewSyncProducer() (sarama.SyncProducer, error)
+	IsClosed() bool
+	Close() error
+}
+
+type kafkaConnection struct {
+	brokers []string
+	client  sarama.Client
+	config  *sarama.Config
+}
+
+func NewKafkaConnection(cfg *config.KafkaOptions) (IConnection, error) {
+	if cfg == nil || len(cfg.Brokers) == 0 {
+		return nil, errors.New("kafka brokers are not configured")
+	}
+
+	saramaConfig, err := NewSaramaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(cfg.Brokers, saramaConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in connecting to kafka")
+	}
+
+	return &kafkaConnection{brokers: cfg.Brokers, client: client, config: saramaConfig}, nil
+}
+
+// NewSaramaConfig crea la configuracion del cliente, los offsets se confirman solo despues de que el consumer
+// marca el mensaje como procesado
+func NewSaramaConfig(cfg *config.KafkaOptions) (*sarama.Config, error) {
+	saramaConfig := sarama.NewConfig()
+
+	if cfg.ClientId != "" {
+		saramaConfig.ClientID = cfg.ClientId
+	}
+
+	if cfg.Version != "" {
+		version, err := sarama.ParseKafkaVersion(cfg.Version)
+		if err != nil {
+			return nil, errors.WrapIf(err, "error in parsing the kafka version")
+		}
+		saramaConfig.Version = version
+	}
+
+	// the sync producer requires the successes, and the messages are confirmed by all the in-sync replicas
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	// the messages with the same key go to the same partition, so they are consumed in order
+	saramaConfig.Producer.Partitioner = sarama.NewHashPartitioner
+
+	saramaConfig.Consumer.Return.Errors = true
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = true
+	saramaConfig.Consumer.Offsets.AutoCommit.Interval = defaultCommitInterval
+	if cfg.CommitInterval > 0 {
+		saramaConfig.Consumer.Offsets.AutoCommit.Interval = cfg.CommitInterval
+	}
+
+	switch cfg.InitialOffset {
+	case "", "oldest":
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	case "newest":
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	default:
+		return nil, errors.Errorf("invalid kafka initial offset `%s`, it should be `oldest` or `newest`", cfg.InitialOffset)
+	}
+
+	return saramaConfig, nil
+}
+
+func (c *kafkaConnection) Client() sarama.Client {
+	return c.client
+}
+
+func (c *kafkaConnection) Config() *sarama.Config {
+	return c.config
+}
+
+func (c *kafkaConnection) NewConsumerGroup(groupId string) (sarama.ConsumerGroup, error) {
+	consumerGroup, err := sarama.NewConsumerGroup(c.brokers, groupId, c.config)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in creating the kafka consumer group `%s`", groupId)
+	}
+
+	return consumerGroup, nil
+}
+
+func (c *kafkaConnection) NewSyncProducer() (sarama.SyncProducer, error) {
+	syncProducer, err := sarama.NewSyncProducerFromClient(c.client)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in creating the kafka producer")
+	}
+
+	return syncProducer, nil
+}
+
+func (c *kafkaConnection) IsClosed() bool {
+	return c.client.Closed()
+}
+
+func (c *kafkaConnection) Close() error {
+	if c.client.Closed() {
+		return nil
+	}
+
+	return c.client.Close()
+}
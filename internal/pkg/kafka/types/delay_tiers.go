@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// DelayTiers son las esperas de los delay topics de un consumer, cada tier tiene su propio topic y todos sus records
+// esperan lo mismo desde que se escriben, asi vencen en el orden de sus offsets. Son menos tiers que las staging queues
+// de rabbitmq porque cada tier es un topic por consumer group
+var DelayTiers = []time.Duration{ //nolint:gochecknoglobals
+	time.Second,
+	10 * time.Second,
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// DelayTier devuelve el tier mas grande que no es mayor a la espera restante, un record que sale de su tier antes de su hora
+// vuelve al delay topic del tier de lo que le falta. Una espera menor al primer tier usa el primer tier
+func DelayTier(remaining time.Duration) time.Duration {
+	tier := DelayTiers[0]
+	for _, t := range DelayTiers {
+		if t > remaining {
+			break
+		}
+		tier = t
+	}
+
+	return tier
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+
+	"github.com/IBM/sarama"
+)
+
+// headers que se agregan a los mensajes enviados al dead letter topic, sirven para inspeccionar y re-enviar los mensajes fallidos
+const (
+	DeadLetterExceptionMessageHeader    = "x-exception-message"
+	DeadLetterExceptionStackTraceHeader = "x-exception-stacktrace"
+	DeadLetterConsumerNameHeader        = "x-consumer-name"
+	DeadLetterAttemptsHeader            = "x-attempts"
+	DeadLetterOriginalTopicHeader       = "x-original-topic"
+	DeadLetterOriginalPartitionHeader   = "x-original-partition"
+	DeadLetterOriginalOffsetHeader      = "x-original-offset"
+	DeadLetterFailedAtHeader            = "x-failed-at"
+)
+
+// DelayDueHeader es el momento en el que un record de un delay topic sale de su tier
+const DelayDueHeader = "x-delay-due"
+
+// timeHeaders son los headers de la metadata que se leen como `time.Time`
+var timeHeaders = map[string]bool{ //nolint:gochecknoglobals
+	messageHeader.Created:    true,
+	messageHeader.Scheduled:  true,
+	DeadLetterFailedAtHeader: true,
+	DelayDueHeader:           true,
+}
+
+// MetadataToHeaders convierte la metadata en los headers del record, los headers de kafka son bytes,
+// los tiempos se escriben en RFC3339 y los demas valores con su formato por defecto
+func MetadataToHeaders(meta metadata.Metadata) []sarama.RecordHeader {
+	headers := make([]sarama.RecordHeader, 0, len(meta))
+
+	for key, value := range meta {
+		if value == nil {
+			continue
+		}
+
+		var data string
+		switch v := value.(type) {
+		case string:
+			data = v
+		case []byte:
+			data = string(v)
+		case time.Time:
+			data = v.UTC().Format(time.RFC3339Nano)
+		default:
+			data = fmt.Sprint(v)
+		}
+
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(data)})
+	}
+
+	return headers
+}
+
+// HeadersToMetadata convierte los headers del record en la metadata del mensaje
+func HeadersToMetadata(headers []*sarama.RecordHeader) metadata.Metadata {
+	meta := metadata.New()
+
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+
+		key := string(header.Key)
+		value := string(header.Value)
+
+		if timeHeaders[key] {
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				meta.Set(key, t)
+				continue
+			}
+		}
+
+		meta.Set(key, value)
+	}
+
+	return meta
+}
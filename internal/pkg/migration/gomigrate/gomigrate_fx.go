@@ -13,6 +13,9 @@ var (
 
 	mangoProviders = fx.Provide(
 		migration.ProvideConfig,
-		NewGoMigratorPostgres,
+		fx.Annotate(
+			NewGoMigratorPostgres,
+			fx.ParamTags(``, ``, ``, `group:"migration_sources"`),
+		),
 	)
 )
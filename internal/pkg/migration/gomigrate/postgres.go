@@ -11,8 +11,9 @@ import (
 
 	"emperror.dev/errors"
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 type goMigratePostgresMigrator struct {
@@ -22,12 +23,14 @@ type goMigratePostgresMigrator struct {
 	datasource string
 	logger     logger.Logger
 	migration  *migrate.Migrate
+	sources    []*migration.MigrationSource
 }
 
 func NewGoMigratorPostgres(
 	config *migration.MigrationOptions,
 	db *sql.DB,
 	logger logger.Logger,
+	sources []*migration.MigrationSource,
 ) (contracts.PostgresMigrationRunner, error) {
 	if config.DBName == "" {
 		return nil, errors.New("dbName is required in the config")
@@ -53,6 +56,7 @@ func NewGoMigratorPostgres(
 		datasource: datasource,
 		logger:     logger,
 		migration:  migration,
+		sources:    sources,
 	}, nil
 }
 
@@ -77,11 +81,19 @@ func (m *goMigratePostgresMigrator) Up(_ context.Context, version uint) error {
 		return errors.WrapIf(err, "failed to migrate database")
 	}
 
+	// the sources are migrated to their last version, the version only applies to the migrations of the service
+	for _, source := range m.sources {
+		if err := m.upSource(source); err != nil {
+			return err
+		}
+	}
+
 	m.logger.Info("migration finished")
 
 	return nil
 }
 
+// Down only reverts the migrations of the service, the tables of the sources can be used by other services in the same database
 func (m *goMigratePostgresMigrator) Down(_ context.Context, version uint) error {
 	if m.config.SkipMigration {
 		m.logger.Info("database migration skipped")
@@ -104,6 +116,42 @@ func (m *goMigratePostgresMigrator) Down(_ context.Context, version uint) error
 	return nil
 }
 
+// upSource applies the golang-migrate migrations of the source with its own version table
+func (m *goMigratePostgresMigrator) upSource(source *migration.MigrationSource) error {
+	if source == nil || source.GoMigrate == nil {
+		return nil
+	}
+
+	driver, err := iofs.New(source.GoMigrate, ".")
+	if err != nil {
+		return errors.WrapIf(err, "failed to read the migrations of the source")
+	}
+
+	versionTable := m.config.VersionTable
+	if versionTable == "" {
+		versionTable = postgres.DefaultMigrationsTable
+	}
+
+	sourceMigration, err := migrate.NewWithSourceInstance(
+		"iofs",
+		driver,
+		fmt.Sprintf("%s&x-migrations-table=%s", m.datasource, source.VersionTable(versionTable)),
+	)
+	if err != nil {
+		return errors.WrapIf(err, "failed to initialize migrator of the source")
+	}
+	defer sourceMigration.Close()
+
+	err = sourceMigration.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return errors.WrapIf(err, fmt.Sprintf("failed to migrate source `%s`", source.Name))
+	}
+
+	m.logger.Infof("migrations of `%s` applied", source.Name)
+
+	return nil
+}
+
 func (m *goMigratePostgresMigrator) executeCommand(command migration.CommandType, version uint) error {
 	var err error
 	switch command {
@@ -15,6 +15,9 @@ var (
 
 	mongoProviders = fx.Provide( //nolint:gochecknoglobals
 		migration.ProvideConfig,
-		NewGoosePostgres,
+		fx.Annotate(
+			NewGoosePostgres,
+			fx.ParamTags(``, ``, ``, `group:"migration_sources"`),
+		),
 	)
 )
@@ -13,35 +13,49 @@ import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/migration/contracts"
 
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
 )
 
 type goosePostgresMigrator struct {
-	config *migration.MigrationOptions
-	db     *sql.DB
-	logger logger.Logger
+	config  *migration.MigrationOptions
+	db      *sql.DB
+	logger  logger.Logger
+	sources []*migration.MigrationSource
 }
 
 func NewGoosePostgres(
 	config *migration.MigrationOptions,
 	db *sql.DB,
 	logger logger.Logger,
+	sources []*migration.MigrationSource,
 ) contracts.PostgresMigrationRunner {
 	goose.SetBaseFS(nil)
 
-	return &goosePostgresMigrator{config: config, db: db, logger: logger}
+	return &goosePostgresMigrator{config: config, db: db, logger: logger, sources: sources}
 }
 
-func (m *goosePostgresMigrator) Up(_ context.Context, version uint) error {
+func (m *goosePostgresMigrator) Up(ctx context.Context, version uint) error {
 	if m.config.SkipMigration {
 		m.logger.Info("database migration skipped")
 		return nil
 	}
 
 	err := m.executeCommand(migration.Up, version)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// the sources are migrated to their last version, the version only applies to the migrations of the service
+	for _, source := range m.sources {
+		if err := m.upSource(ctx, source); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
+// Down only reverts the migrations of the service, the tables of the sources can be used by other services in the same database
 func (m *goosePostgresMigrator) Down(_ context.Context, version uint) error {
 	if m.config.SkipMigration {
 		m.logger.Info("database migration skipped")
@@ -53,6 +67,36 @@ func (m *goosePostgresMigrator) Down(_ context.Context, version uint) error {
 	return err
 }
 
+// upSource applies the goose migrations of the source with its own version table
+func (m *goosePostgresMigrator) upSource(ctx context.Context, source *migration.MigrationSource) error {
+	if source == nil || source.Goose == nil {
+		return nil
+	}
+
+	versionTable := m.config.VersionTable
+	if versionTable == "" {
+		versionTable = goose.DefaultTablename
+	}
+
+	store, err := database.NewStore(database.DialectPostgres, source.VersionTable(versionTable))
+	if err != nil {
+		return err
+	}
+
+	provider, err := goose.NewProvider("", m.db, source.Goose, goose.WithStore(store))
+	if err != nil {
+		return err
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return err
+	}
+	m.logger.Infof("%d migrations of `%s` applied", len(results), source.Name)
+
+	return nil
+}
+
 func (m *goosePostgresMigrator) executeCommand(
 	command migration.CommandType,
 	version uint,
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+
+	"go.uber.org/fx"
+)
+
+// MigrationSource son las migraciones embebidas de un paquete de `pkg`, por ejemplo las tablas del event store de postgres.
+// Los runners las aplican en `Up` despues de las migraciones del servicio, cada source con su propia tabla de versiones
+// para que sus versiones no se mezclen con las del servicio
+type MigrationSource struct {
+	// Name identifica al source, su tabla de versiones es la tabla de versiones del runner con el sufijo `_<Name>`
+	Name string
+	// Goose tiene las migraciones en el formato de goose
+	Goose fs.FS
+	// GoMigrate tiene las migraciones `.up.sql` y `.down.sql` de golang-migrate
+	GoMigrate fs.FS
+}
+
+// VersionTable returns the version table of the source from the version table of the runner
+func (s *MigrationSource) VersionTable(runnerVersionTable string) string {
+	return fmt.Sprintf("%s_%s", runnerVersionTable, s.Name)
+}
+
+// AsMigrationSource registers the source constructor in the group of sources applied by the migration runners
+func AsMigrationSource(source interface{}) interface{} {
+	return fx.Annotate(
+		source,
+		fx.ResultTags(`group:"migration_sources"`),
+	)
+}
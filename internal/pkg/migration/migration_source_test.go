@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+func Test_VersionTable_Adds_The_Source_Name(t *testing.T) {
+	source := &MigrationSource{Name: "event_store"}
+
+	assert.Equal(t, "goose_db_version_event_store", source.VersionTable("goose_db_version"))
+}
+
+func Test_AsMigrationSource_Registers_The_Sources_In_The_Group(t *testing.T) {
+	var sources []*MigrationSource
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			AsMigrationSource(func() *MigrationSource {
+				return &MigrationSource{Name: "event_store", Goose: fstest.MapFS{}}
+			}),
+			AsMigrationSource(func() *MigrationSource {
+				return &MigrationSource{Name: "outbox", Goose: fstest.MapFS{}}
+			}),
+		),
+		fx.Invoke(fx.Annotate(func(s []*MigrationSource) {
+			sources = s
+		}, fx.ParamTags(`group:"migration_sources"`))),
+	)
+	require.NoError(t, app.Err())
+
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	assert.ElementsMatch(t, []string{"event_store", "outbox"}, names)
+}
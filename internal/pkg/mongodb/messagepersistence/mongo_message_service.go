@@ -0,0 +1,561 @@
+package messagepersistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
+
+	"emperror.dev/errors"
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// storeMessageDocument es la representacion en mongo de un persistmessage.StoreMessage
+type storeMessageDocument struct {
+	ID            string                             `bson:"_id"`
+	DataType      string                             `bson:"dataType"`
+	Data          string                             `bson:"data"`
+	CreatedAt     time.Time                          `bson:"createdAt"`
+	RetryCount    int                                `bson:"retryCount"`
+	MessageStatus persistmessage.MessageStatus       `bson:"messageStatus"`
+	DeliveryType  persistmessage.MessageDeliveryType `bson:"deliveryType"`
+	// ProcessedAt solo existe en los mensajes procesados, el indice TTL se crea sobre este campo
+	ProcessedAt *time.Time `bson:"processedAt,omitempty"`
+	LockedUntil *time.Time `bson:"lockedUntil,omitempty"`
+}
+
+type mongoMessagePersistenceService struct {
+	db                *mongo.Client
+	databaseName      string
+	collectionName    string
+	messageSerializer serializer.MessageSerializer
+	producer          producer.Producer
+	outboxOptions     *persistmessage.OutboxOptions
+	logger            logger.Logger
+}
+
+func NewMongoMessageService(
+	db *mongo.Client,
+	mongoOptions *mongodb.MongoDbOptions,
+	messagingOptions *MongoMessagingOptions,
+	messageSerializer serializer.MessageSerializer,
+	producer producer.Producer,
+	l logger.Logger,
+) persistmessage.MessagePersistenceService {
+	return &mongoMessagePersistenceService{
+		db:                db,
+		databaseName:      mongoOptions.Database,
+		collectionName:    messagingOptions.CollectionName,
+		messageSerializer: messageSerializer,
+		producer:          producer,
+		outboxOptions:     messagingOptions.OutboxOptions,
+		logger:            l,
+	}
+}
+
+func (m *mongoMessagePersistenceService) collection() *mongo.Collection {
+	return m.db.Database(m.databaseName).Collection(m.collectionName)
+}
+
+func (m *mongoMessagePersistenceService) Add(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	_, err := m.collection().InsertOne(ctx, toStoreMessageDocument(storeMessage))
+	if mongo.IsDuplicateKeyError(err) {
+		return customErrors.NewConflictErrorWrap(
+			err,
+			"storeMessage already exists",
+		)
+	}
+	if err != nil {
+		return customErrors.NewInternalServerErrorWrap(
+			err,
+			"error in inserting the storeMessage",
+		)
+	}
+
+	return nil
+}
+
+// Update actualiza solo los campos que cambian de un mensaje, `ReplaceOne` reiniciaria la fecha `processedAt` del
+// indice TTL y eliminaria la reserva `lockedUntil` de las instancias que lo estan publicando
+func (m *mongoMessagePersistenceService) Update(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	set := bson.M{
+		"dataType":      storeMessage.DataType,
+		"data":          storeMessage.Data,
+		"retryCount":    storeMessage.RetryCount,
+		"messageStatus": storeMessage.MessageStatus,
+		"deliveryType":  storeMessage.DeliveryType,
+	}
+	// a message loaded before the lease was taken has no `LockedUntil`, the lease in the database is kept
+	if storeMessage.LockedUntil != nil {
+		set["lockedUntil"] = *storeMessage.LockedUntil
+	}
+
+	result, err := m.collection().UpdateOne(
+		ctx,
+		bson.M{"_id": storeMessage.ID.String()},
+		withProcessedAt(bson.M{"$set": set}, storeMessage.MessageStatus, storeMessage.ProcessedAt),
+	)
+	if err != nil {
+		return customErrors.NewInternalServerErrorWrap(
+			err,
+			"error in updating the storeMessage",
+		)
+	}
+
+	m.logger.Infof("Number of affected documents are: %d", result.ModifiedCount)
+
+	return nil
+}
+
+func (m *mongoMessagePersistenceService) ChangeState(
+	ctx context.Context,
+	messageID uuid.UUID,
+	status persistmessage.MessageStatus,
+) error {
+	result, err := m.collection().UpdateOne(
+		ctx,
+		bson.M{"_id": messageID.String()},
+		withProcessedAt(bson.M{"$set": bson.M{"messageStatus": status}}, status, nil),
+	)
+	if err != nil {
+		return customErrors.NewInternalServerErrorWrap(
+			err,
+			"error in changing the storeMessage state",
+		)
+	}
+
+	if result.MatchedCount == 0 {
+		return customErrors.NewNotFoundError(
+			fmt.Sprintf(
+				"storeMessage with id `%s` not found in the database",
+				messageID.String(),
+			),
+		)
+	}
+
+	return nil
+}
+
+func (m *mongoMessagePersistenceService) GetAllActive(
+	ctx context.Context,
+) ([]*persistmessage.StoreMessage, error) {
+	return m.find(ctx, bson.M{"messageStatus": persistmessage.Stored})
+}
+
+func (m *mongoMessagePersistenceService) GetByFilter(
+	ctx context.Context,
+	predicate func(*persistmessage.StoreMessage) bool,
+) ([]*persistmessage.StoreMessage, error) {
+	storeMessages, err := m.find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	// el predicado es una funcion de go, por eso el filtro se aplica en memoria
+	var filtered []*persistmessage.StoreMessage
+	for _, storeMessage := range storeMessages {
+		if predicate(storeMessage) {
+			filtered = append(filtered, storeMessage)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (m *mongoMessagePersistenceService) GetById(
+	ctx context.Context,
+	id uuid.UUID,
+) (*persistmessage.StoreMessage, error) {
+	var document storeMessageDocument
+
+	err := m.collection().FindOne(ctx, bson.M{"_id": id.String()}).Decode(&document)
+	if err != nil {
+		// ErrNoDocuments means that the filter did not match any documents in the collection
+		if err == mongo.ErrNoDocuments {
+			return nil, customErrors.NewNotFoundErrorWrap(
+				err,
+				fmt.Sprintf(
+					"storeMessage with id `%s` not found in the database",
+					id.String(),
+				),
+			)
+		}
+
+		return nil, customErrors.NewInternalServerErrorWrap(
+			err,
+			fmt.Sprintf(
+				"error in fetching storeMessage with id `%s` from the database",
+				id.String(),
+			),
+		)
+	}
+
+	return toStoreMessage(&document)
+}
+
+func (m *mongoMessagePersistenceService) Remove(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) (bool, error) {
+	result, err := m.collection().DeleteOne(ctx, bson.M{"_id": storeMessage.ID.String()})
+	if err != nil {
+		return false, customErrors.NewInternalServerErrorWrap(
+			err,
+			fmt.Sprintf(
+				"error in deleting storeMessage with id `%s` in the database",
+				storeMessage.ID.String(),
+			),
+		)
+	}
+
+	if result.DeletedCount == 0 {
+		return false, customErrors.NewNotFoundError(
+			fmt.Sprintf(
+				"storeMessage with id `%s` not found in the database",
+				storeMessage.ID.String(),
+			),
+		)
+	}
+
+	return true, nil
+}
+
+// CleanupMessages elimina los mensajes procesados del outbox, los del inbox se usan para deduplicar las reentregas
+// y solo los elimina el indice TTL sobre `processedAt` despues de `ProcessedMessagesTTL`
+func (m *mongoMessagePersistenceService) CleanupMessages(
+	ctx context.Context,
+) error {
+	result, err := m.collection().DeleteMany(ctx, bson.M{
+		"messageStatus": persistmessage.Processed,
+		"deliveryType":  persistmessage.Outbox,
+	})
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof("Number of deleted documents are: %d", result.DeletedCount)
+
+	return nil
+}
+
+func (m *mongoMessagePersistenceService) Process(messageID string, ctx context.Context) error {
+	id, err := uuid.FromString(messageID)
+	if err != nil {
+		return err
+	}
+
+	storeMessage, err := m.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return m.processStoreMessage(ctx, storeMessage)
+}
+
+// ProcessAll publica un lote de mensajes pendientes del outbox, cada mensaje se reserva antes de publicarlo
+// para que varias instancias del servicio no publiquen el mismo mensaje
+func (m *mongoMessagePersistenceService) ProcessAll(ctx context.Context) error {
+	for i := 0; i < m.outboxOptions.BatchSize; i++ {
+		storeMessage, err := m.lockNextOutboxMessage(ctx)
+		if err != nil {
+			return err
+		}
+
+		// no quedan mensajes pendientes
+		if storeMessage == nil {
+			return nil
+		}
+
+		// un mensaje fallido no debe bloquear al resto del lote, se reintenta cuando expire su reserva
+		if err := m.processStoreMessage(ctx, storeMessage); err != nil {
+			m.logger.Errorf(
+				"error in processing outbox message with id: %v, error: %v",
+				storeMessage.ID,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (m *mongoMessagePersistenceService) AddPublishMessage(
+	messageEnvelope types.MessageEnvelope,
+	ctx context.Context,
+) error {
+	return m.AddMessageCore(ctx, messageEnvelope, persistmessage.Outbox)
+}
+
+func (m *mongoMessagePersistenceService) AddReceivedMessage(
+	messageEnvelope types.MessageEnvelope,
+	ctx context.Context,
+) error {
+	return m.AddMessageCore(ctx, messageEnvelope, persistmessage.Inbox)
+}
+
+func (m *mongoMessagePersistenceService) AddMessageCore(
+	ctx context.Context,
+	messageEnvelope types.MessageEnvelope,
+	deliveryType persistmessage.MessageDeliveryType,
+) error {
+	if messageEnvelope.Message == nil {
+		return errors.New("messageEnvelope.Message is nil")
+	}
+
+	id := messageEnvelope.Message.GeMessageId()
+	if id == "" {
+		id = uuid.NewV4().String()
+	}
+
+	data, err := m.messageSerializer.SerializeEnvelop(messageEnvelope)
+	if err != nil {
+		return err
+	}
+
+	uuidId, err := uuid.FromString(id)
+	if err != nil {
+		return err
+	}
+	// the inbox row is scoped to the consumer and the handler of the context
+	if deliveryType == persistmessage.Inbox {
+		uuidId = persistmessage.InboxMessageId(ctx, uuidId)
+	}
+
+	storeMessage := persistmessage.NewStoreMessage(
+		uuidId,
+		typeregistry.GetFullTypeName(messageEnvelope.Message),
+		string(data.Data),
+		deliveryType,
+	)
+
+	err = m.Add(ctx, storeMessage)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof(
+		"Message with id: %v and delivery type: %v saved in persistence message store",
+		uuidId,
+		deliveryType,
+	)
+
+	return nil
+}
+
+func (m *mongoMessagePersistenceService) find(
+	ctx context.Context,
+	filter interface{},
+) ([]*persistmessage.StoreMessage, error) {
+	cursor, err := m.collection().Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*storeMessageDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	storeMessages := make([]*persistmessage.StoreMessage, 0, len(documents))
+	for _, document := range documents {
+		storeMessage, err := toStoreMessage(document)
+		if err != nil {
+			return nil, err
+		}
+		storeMessages = append(storeMessages, storeMessage)
+	}
+
+	return storeMessages, nil
+}
+
+func (m *mongoMessagePersistenceService) lockNextOutboxMessage(
+	ctx context.Context,
+) (*persistmessage.StoreMessage, error) {
+	// mongo no tiene `SKIP LOCKED` por eso la reserva se hace con el campo `lockedUntil`
+	now := time.Now()
+	lockedUntil := now.Add(m.outboxOptions.LockDuration)
+
+	filter := bson.M{
+		"messageStatus": persistmessage.Stored,
+		"deliveryType":  persistmessage.Outbox,
+		"$or": bson.A{
+			bson.M{"lockedUntil": bson.M{"$exists": false}},
+			bson.M{"lockedUntil": bson.M{"$lt": now}},
+		},
+	}
+
+	var document storeMessageDocument
+	err := m.collection().FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"lockedUntil": lockedUntil}},
+		options.FindOneAndUpdate().
+			SetSort(bson.M{"createdAt": 1}).
+			SetReturnDocument(options.After),
+	).Decode(&document)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, customErrors.NewInternalServerErrorWrap(
+			err,
+			"error in fetching outbox messages",
+		)
+	}
+
+	return toStoreMessage(&document)
+}
+
+func (m *mongoMessagePersistenceService) processStoreMessage(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	if storeMessage.MessageStatus == persistmessage.Processed {
+		return nil
+	}
+
+	switch storeMessage.DeliveryType {
+	case persistmessage.Outbox:
+		err := m.publishStoreMessage(ctx, storeMessage)
+		if err != nil {
+			// se usa `$inc` en lugar de Update para conservar la reserva y no reintentar el mensaje en el mismo ciclo
+			update := bson.M{"$inc": bson.M{"retryCount": 1}}
+			if storeMessage.RetryCount+1 >= m.outboxOptions.MaxRetryCount {
+				update["$set"] = bson.M{"messageStatus": persistmessage.Failed}
+				m.logger.Errorf(
+					"outbox message with id: %v reached the max retry count and it is marked as failed",
+					storeMessage.ID,
+				)
+			}
+			_, updateErr := m.collection().UpdateOne(
+				ctx,
+				bson.M{"_id": storeMessage.ID.String()},
+				update,
+			)
+			if updateErr != nil {
+				return errors.WrapIf(updateErr, err.Error())
+			}
+
+			return err
+		}
+	case persistmessage.Inbox:
+		// los mensajes del inbox ya fueron manejados por el consumidor, solo se guardan para idempotencia
+	default:
+		return errors.Errorf("delivery type `%v` is not supported", storeMessage.DeliveryType)
+	}
+
+	return m.ChangeState(ctx, storeMessage.ID, persistmessage.Processed)
+}
+
+func (m *mongoMessagePersistenceService) publishStoreMessage(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	messageEnvelope, err := m.messageSerializer.DeserializeEnvelop(
+		[]byte(storeMessage.Data),
+		storeMessage.DataType,
+		m.messageSerializer.ContentType(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = m.producer.PublishMessageWithTopicName(
+		ctx,
+		messageEnvelope.Message,
+		metadata.MapToMetadata(messageEnvelope.Headers),
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof(
+		"Message with id: %v and type: %s published from the outbox",
+		storeMessage.ID,
+		storeMessage.DataType,
+	)
+
+	return nil
+}
+
+// withProcessedAt agrega a un update la fecha `processedAt` de los mensajes procesados, `$min` conserva la fecha del
+// primer procesamiento para que el indice TTL no se reinicie, los mensajes que no estan procesados no expiran
+func withProcessedAt(
+	update bson.M,
+	status persistmessage.MessageStatus,
+	processedAt *time.Time,
+) bson.M {
+	if status != persistmessage.Processed {
+		update["$unset"] = bson.M{"processedAt": ""}
+
+		return update
+	}
+
+	if processedAt == nil {
+		now := time.Now()
+		processedAt = &now
+	}
+	update["$min"] = bson.M{"processedAt": *processedAt}
+
+	return update
+}
+
+func toStoreMessageDocument(storeMessage *persistmessage.StoreMessage) *storeMessageDocument {
+	document := &storeMessageDocument{
+		ID:            storeMessage.ID.String(),
+		DataType:      storeMessage.DataType,
+		Data:          storeMessage.Data,
+		CreatedAt:     storeMessage.CreatedAt,
+		RetryCount:    storeMessage.RetryCount,
+		MessageStatus: storeMessage.MessageStatus,
+		DeliveryType:  storeMessage.DeliveryType,
+		ProcessedAt:   storeMessage.ProcessedAt,
+		LockedUntil:   storeMessage.LockedUntil,
+	}
+
+	if storeMessage.MessageStatus == persistmessage.Processed && document.ProcessedAt == nil {
+		processedAt := time.Now()
+		document.ProcessedAt = &processedAt
+	}
+
+	return document
+}
+
+func toStoreMessage(document *storeMessageDocument) (*persistmessage.StoreMessage, error) {
+	id, err := uuid.FromString(document.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &persistmessage.StoreMessage{
+		ID:            id,
+		DataType:      document.DataType,
+		Data:          document.Data,
+		CreatedAt:     document.CreatedAt,
+		RetryCount:    document.RetryCount,
+		MessageStatus: document.MessageStatus,
+		DeliveryType:  document.DeliveryType,
+		LockedUntil:   document.LockedUntil,
+		ProcessedAt:   document.ProcessedAt,
+	}, nil
+}
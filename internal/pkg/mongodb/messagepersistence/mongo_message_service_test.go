@@ -0,0 +1,296 @@
+//go:build integration
+
+package messagepersistence
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testMongoURIEnv es la conexion a un mongo de pruebas, cada test usa su propia coleccion y la elimina al terminar,
+// por ejemplo `MONGO_MESSAGE_SERVICE_TEST_URI=mongodb://localhost:27017`
+const testMongoURIEnv = "MONGO_MESSAGE_SERVICE_TEST_URI"
+
+const testDatabaseName = "message_service_test"
+
+type testMessage struct {
+	*types.Message
+	Value string
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&testMessage{}))
+}
+
+func newTestMessage(value string) *testMessage {
+	return &testMessage{Message: types.NewMessage(uuid.NewV4().String()), Value: value}
+}
+
+// fakeProducer solo implementa la publicacion que usa el outbox
+type fakeProducer struct {
+	mu        sync.Mutex
+	published []types.IMessage
+}
+
+func (f *fakeProducer) PublishMessage(ctx context.Context, message types.IMessage) error {
+	return f.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+func (f *fakeProducer) PublishMessageWithTopicName(
+	_ context.Context,
+	message types.IMessage,
+	_ metadata.Metadata,
+	_ string,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.published = append(f.published, message)
+
+	return nil
+}
+
+func (f *fakeProducer) PublishMessageWithDelay(
+	context.Context,
+	types.IMessage,
+	metadata.Metadata,
+	time.Duration,
+) error {
+	return nil
+}
+
+func (f *fakeProducer) ScheduleMessage(context.Context, types.IMessage, metadata.Metadata, time.Time) error {
+	return nil
+}
+
+func (f *fakeProducer) PublishMessages(context.Context, []types.IMessage, metadata.Metadata, string) error {
+	return nil
+}
+
+func (f *fakeProducer) IsProduced(func(message types.IMessage)) {}
+
+func (f *fakeProducer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.published)
+}
+
+func newTestService(
+	t *testing.T,
+	producer *fakeProducer,
+	outboxOptions *persistmessage.OutboxOptions,
+) *mongoMessagePersistenceService {
+	t.Helper()
+
+	uri := os.Getenv(testMongoURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", testMongoURIEnv)
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+
+	messagingOptions := &MongoMessagingOptions{
+		CollectionName:       "store_messages_" + uuid.NewV4().String(),
+		ProcessedMessagesTTL: defaultProcessedMessagesTTL,
+		OutboxOptions:        persistmessage.NewOutboxOptionsWithDefaults(outboxOptions),
+	}
+	t.Cleanup(func() {
+		_ = client.Database(testDatabaseName).Collection(messagingOptions.CollectionName).Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	service := NewMongoMessageService(
+		client,
+		&mongodb.MongoDbOptions{Database: testDatabaseName},
+		messagingOptions,
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		producer,
+		defaultlogger.GetLogger(),
+	)
+
+	return service.(*mongoMessagePersistenceService)
+}
+
+func rawDocument(t *testing.T, service *mongoMessagePersistenceService, id uuid.UUID) bson.M {
+	t.Helper()
+
+	var document bson.M
+	require.NoError(t, service.collection().FindOne(context.Background(), bson.M{"_id": id.String()}).Decode(&document))
+
+	return document
+}
+
+func Test_Update_Keeps_ProcessedAt_And_Lease(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, &fakeProducer{}, nil)
+
+	storeMessage := persistmessage.NewStoreMessage(uuid.NewV4(), "testMessage", "{}", persistmessage.Outbox)
+	require.NoError(t, service.Add(ctx, storeMessage))
+
+	leased, err := service.lockNextOutboxMessage(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, leased)
+	require.NotNil(t, leased.LockedUntil)
+
+	// the message was loaded before the lease, updating it must not release the lease
+	storeMessage.IncreaseRetry()
+	require.NoError(t, service.Update(ctx, storeMessage))
+
+	updated, err := service.GetById(ctx, storeMessage.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.RetryCount)
+	require.NotNil(t, updated.LockedUntil)
+	assert.WithinDuration(t, *leased.LockedUntil, *updated.LockedUntil, time.Millisecond)
+
+	require.NoError(t, service.ChangeState(ctx, storeMessage.ID, persistmessage.Processed))
+	processed, err := service.GetById(ctx, storeMessage.ID)
+	require.NoError(t, err)
+	require.NotNil(t, processed.ProcessedAt)
+	processedAt := *processed.ProcessedAt
+
+	// a later update of the processed message doesn't restart the TTL of the document
+	time.Sleep(10 * time.Millisecond)
+	processed.Data = `{"updated":true}`
+	processed.ProcessedAt = nil
+	require.NoError(t, service.Update(ctx, processed))
+
+	updated, err = service.GetById(ctx, storeMessage.ID)
+	require.NoError(t, err)
+	assert.Equal(t, `{"updated":true}`, updated.Data)
+	require.NotNil(t, updated.ProcessedAt)
+	assert.WithinDuration(t, processedAt, *updated.ProcessedAt, time.Millisecond)
+	assert.Equal(t, persistmessage.Processed, updated.MessageStatus)
+}
+
+func Test_ProcessAll_Skips_Leased_Messages_Until_The_Lease_Expires(t *testing.T) {
+	ctx := context.Background()
+	producer := &fakeProducer{}
+	service := newTestService(t, producer, &persistmessage.OutboxOptions{LockDuration: 200 * time.Millisecond})
+
+	message := newTestMessage("leased")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(message, nil), ctx))
+
+	// another instance took the lease of the message
+	leased, err := service.lockNextOutboxMessage(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, leased)
+
+	require.NoError(t, service.ProcessAll(ctx))
+	assert.Equal(t, 0, producer.count())
+
+	time.Sleep(250 * time.Millisecond)
+
+	require.NoError(t, service.ProcessAll(ctx))
+	assert.Equal(t, 1, producer.count())
+
+	storeMessage, err := service.GetById(ctx, uuid.FromStringOrNil(message.GeMessageId()))
+	require.NoError(t, err)
+	assert.Equal(t, persistmessage.Processed, storeMessage.MessageStatus)
+	assert.NotNil(t, storeMessage.ProcessedAt)
+}
+
+func Test_GetByFilter_Returns_Only_Matching_Messages(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, &fakeProducer{}, nil)
+
+	first := newTestMessage("first")
+	second := newTestMessage("second")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(first, nil), ctx))
+	require.NoError(t, service.AddReceivedMessage(*types.NewMessageEnvelope(newTestMessage("inbox"), nil), ctx))
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(second, nil), ctx))
+
+	outboxMessages, err := service.GetByFilter(ctx, func(storeMessage *persistmessage.StoreMessage) bool {
+		return storeMessage.DeliveryType == persistmessage.Outbox
+	})
+	require.NoError(t, err)
+	ids := make([]string, 0, len(outboxMessages))
+	for _, storeMessage := range outboxMessages {
+		ids = append(ids, storeMessage.ID.String())
+	}
+	assert.ElementsMatch(t, []string{first.GeMessageId(), second.GeMessageId()}, ids)
+
+	none, err := service.GetByFilter(ctx, func(*persistmessage.StoreMessage) bool { return false })
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func Test_ChangeState_Sets_ProcessedAt_Only_For_Processed_Messages(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, &fakeProducer{}, nil)
+
+	storeMessage := persistmessage.NewStoreMessage(uuid.NewV4(), "testMessage", "{}", persistmessage.Outbox)
+	require.NoError(t, service.Add(ctx, storeMessage))
+	assert.NotContains(t, rawDocument(t, service, storeMessage.ID), "processedAt")
+
+	require.NoError(t, service.ChangeState(ctx, storeMessage.ID, persistmessage.Processed))
+	first := rawDocument(t, service, storeMessage.ID)["processedAt"]
+	require.NotNil(t, first)
+
+	// processing the message again keeps the first date
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, service.ChangeState(ctx, storeMessage.ID, persistmessage.Processed))
+	assert.Equal(t, first, rawDocument(t, service, storeMessage.ID)["processedAt"])
+
+	// a message that isn't processed must not expire
+	require.NoError(t, service.ChangeState(ctx, storeMessage.ID, persistmessage.Failed))
+	document := rawDocument(t, service, storeMessage.ID)
+	assert.NotContains(t, document, "processedAt")
+	assert.EqualValues(t, persistmessage.Failed, document["messageStatus"])
+
+	err := service.ChangeState(ctx, uuid.NewV4(), persistmessage.Processed)
+	assert.True(t, customErrors.IsNotFoundError(err))
+}
+
+func Test_CleanupMessages_Removes_Processed_Outbox_And_Keeps_Inbox_Messages(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, &fakeProducer{}, nil)
+
+	processedOutbox := newTestMessage("processed outbox")
+	pendingOutbox := newTestMessage("pending outbox")
+	inbox := newTestMessage("inbox")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(processedOutbox, nil), ctx))
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(pendingOutbox, nil), ctx))
+	require.NoError(t, service.AddReceivedMessage(*types.NewMessageEnvelope(inbox, nil), ctx))
+
+	processedOutboxID := uuid.FromStringOrNil(processedOutbox.GeMessageId())
+	inboxID := persistmessage.InboxMessageId(ctx, uuid.FromStringOrNil(inbox.GeMessageId()))
+	require.NoError(t, service.ChangeState(ctx, processedOutboxID, persistmessage.Processed))
+	require.NoError(t, service.ChangeState(ctx, inboxID, persistmessage.Processed))
+
+	require.NoError(t, service.CleanupMessages(ctx))
+
+	_, err := service.GetById(ctx, processedOutboxID)
+	assert.True(t, customErrors.IsNotFoundError(err))
+
+	_, err = service.GetById(ctx, uuid.FromStringOrNil(pendingOutbox.GeMessageId()))
+	assert.NoError(t, err)
+
+	// the processed inbox rows deduplicate the redeliveries until the TTL index removes them
+	inboxMessage, err := service.GetById(ctx, inboxID)
+	require.NoError(t, err)
+	assert.Equal(t, persistmessage.Processed, inboxMessage.MessageStatus)
+	assert.NotNil(t, inboxMessage.ProcessedAt)
+}
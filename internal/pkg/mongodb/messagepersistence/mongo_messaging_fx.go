@@ -0,0 +1,84 @@
+package messagepersistence
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+)
+
+var (
+	// Module provided to fxlog
+	// https://uber-go.github.io/fx/modules.html
+	Module = fx.Module( //nolint:gochecknoglobals
+		"mongomessagingfx",
+		mongoMessagingProviders,
+		mongoMessagingInvokes,
+	)
+
+	mongoMessagingProviders = fx.Provide( //nolint:gochecknoglobals
+		ProvideConfig,
+		ProvideOutboxOptions,
+		NewMongoMessageService,
+		persistmessage.NewOutboxDispatcher,
+	)
+
+	mongoMessagingInvokes = fx.Invoke(createIndexes, registerHooks) //nolint:gochecknoglobals
+)
+
+// createIndexes crea los indices de la coleccion de mensajes, el indice TTL elimina los mensajes
+// procesados despues de `ProcessedMessagesTTL`, los mensajes pendientes no tienen `processedAt` y no expiran
+func createIndexes(
+	client *mongo.Client,
+	mongoOptions *mongodb.MongoDbOptions,
+	messagingOptions *MongoMessagingOptions,
+) error {
+	collection := client.Database(mongoOptions.Database).Collection(messagingOptions.CollectionName)
+
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "processedAt", Value: 1}},
+			Options: options.Index().
+				SetExpireAfterSeconds(int32(messagingOptions.ProcessedMessagesTTL.Seconds())),
+		},
+		{
+			Keys: bson.D{
+				{Key: "messageStatus", Value: 1},
+				{Key: "deliveryType", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+	})
+
+	return err
+}
+
+func registerHooks(
+	lc fx.Lifecycle,
+	dispatcher persistmessage.OutboxDispatcher,
+	logger logger.Logger,
+) {
+	lifetimeCtx := context.Background()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// el ctx de OnStart tiene un timeout corto, el dispatcher necesita un contexto que viva durante toda la aplicacion
+			if err := dispatcher.Start(lifetimeCtx); err != nil {
+				logger.Errorf("error in starting mongo outbox dispatcher: %v", err)
+
+				return err
+			}
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return dispatcher.Stop(ctx)
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package messagepersistence
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	defaultCollectionName       = "store_messages"
+	defaultProcessedMessagesTTL = 7 * 24 * time.Hour
+)
+
+type MongoMessagingOptions struct {
+	CollectionName string `mapstructure:"collectionName"`
+	// ProcessedMessagesTTL es el tiempo que se conservan los mensajes procesados antes de que mongo los elimine con el indice TTL
+	ProcessedMessagesTTL time.Duration                 `mapstructure:"processedMessagesTTL"`
+	OutboxOptions        *persistmessage.OutboxOptions `mapstructure:"outboxOptions"`
+}
+
+func ProvideConfig(environment environment.Environment) (*MongoMessagingOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[MongoMessagingOptions]())
+	cfg, err := config.BindConfigKey[MongoMessagingOptions](optionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CollectionName == "" {
+		cfg.CollectionName = defaultCollectionName
+	}
+	if cfg.ProcessedMessagesTTL <= 0 {
+		cfg.ProcessedMessagesTTL = defaultProcessedMessagesTTL
+	}
+	cfg.OutboxOptions = persistmessage.NewOutboxOptionsWithDefaults(cfg.OutboxOptions)
+
+	return cfg, nil
+}
+
+func ProvideOutboxOptions(cfg *MongoMessagingOptions) *persistmessage.OutboxOptions {
+	return cfg.OutboxOptions
+}
@@ -0,0 +1,39 @@
+package snapshotstore
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/encryption"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/fx"
+)
+
+// Module registra el snapshot store de mongo, se usa en lugar de `eventstroredb.SnapshotModule`
+// https://uber-go.github.io/fx/modules.html
+var Module = fx.Module( //nolint:gochecknoglobals
+	"mongosnapshotfx",
+	fx.Provide(
+		ProvideConfig,
+	),
+	fx.Provide(fx.Annotate(
+		newSnapshotStore,
+		fx.ParamTags(``, ``, ``, `optional:"true"`, `optional:"true"`),
+	)),
+)
+
+// newSnapshotStore los snapshots se cifran con las opciones de cifrado de los mensajes, el key provider es opcional como en `core`
+func newSnapshotStore(
+	db *mongo.Client,
+	mongoOptions *mongodb.MongoDbOptions,
+	snapshotOptions *MongoSnapshotOptions,
+	encryptionOptions *encryption.EncryptionOptions,
+	keyProvider encryption.KeyProvider,
+) (store.SnapshotStore, error) {
+	return es.NewSnapshotStoreWithEncryption(
+		NewMongoSnapshotStore(db, mongoOptions, snapshotOptions),
+		encryptionOptions,
+		keyProvider,
+	)
+}
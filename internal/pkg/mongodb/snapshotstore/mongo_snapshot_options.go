@@ -0,0 +1,29 @@
+package snapshotstore
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+const defaultCollectionName = "snapshots"
+
+type MongoSnapshotOptions struct {
+	CollectionName string `mapstructure:"collectionName"`
+}
+
+func ProvideConfig(environment environment.Environment) (*MongoSnapshotOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[MongoSnapshotOptions]())
+	cfg, err := config.BindConfigKey[MongoSnapshotOptions](optionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CollectionName == "" {
+		cfg.CollectionName = defaultCollectionName
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,77 @@
+package snapshotstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
+
+	"emperror.dev/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoSnapshotStore guarda un documento por stream, el `_id` es el nombre del stream
+type mongoSnapshotStore struct {
+	db             *mongo.Client
+	databaseName   string
+	collectionName string
+}
+
+func NewMongoSnapshotStore(
+	db *mongo.Client,
+	mongoOptions *mongodb.MongoDbOptions,
+	snapshotOptions *MongoSnapshotOptions,
+) store.SnapshotStore {
+	return &mongoSnapshotStore{
+		db:             db,
+		databaseName:   mongoOptions.Database,
+		collectionName: snapshotOptions.CollectionName,
+	}
+}
+
+func (m *mongoSnapshotStore) collection() *mongo.Collection {
+	return m.db.Database(m.databaseName).Collection(m.collectionName)
+}
+
+// Save reemplaza el snapshot del stream solo si el nuevo es de una version mayor, dos instancias pueden guardar
+// snapshots del mismo agregado y el mas viejo no debe pisar al mas nuevo
+func (m *mongoSnapshotStore) Save(ctx context.Context, snapshot *models.Snapshot) error {
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+
+	_, err := m.collection().ReplaceOne(
+		ctx,
+		bson.M{"_id": snapshot.StreamId, "version": bson.M{"$lt": snapshot.Version}},
+		snapshot,
+		options.Replace().SetUpsert(true),
+	)
+	// the filter doesn't match a newer snapshot, the upsert then collides with its `_id` and the newer one is kept
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapIff(err, "error in saving the snapshot of stream `%s`", snapshot.StreamId)
+	}
+
+	return nil
+}
+
+func (m *mongoSnapshotStore) Load(ctx context.Context, streamName streamName.StreamName) (*models.Snapshot, error) {
+	snapshot := &models.Snapshot{}
+
+	err := m.collection().FindOne(ctx, bson.M{"_id": streamName.String()}).Decode(snapshot)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in loading the snapshot of stream `%s`", streamName.String())
+	}
+
+	return snapshot, nil
+}
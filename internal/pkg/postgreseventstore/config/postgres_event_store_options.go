@@ -0,0 +1,66 @@
+package config
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	defaultNotificationChannel = "es_events"
+	defaultPollingInterval     = 1 * time.Second
+	defaultBatchSize           = 500
+	defaultSubscriptionId      = "defaultLogger"
+)
+
+// PostgresEventStoreOptions configura el event store de postgres y su suscripcion al `$all`, las tablas se crean con
+// las migraciones de `postgreseventstore/migrations` que aplica el runner de migraciones del servicio
+type PostgresEventStoreOptions struct {
+	NotificationChannel string        `mapstructure:"notificationChannel"` // canal de LISTEN/NOTIFY de los appends
+	PollingInterval     time.Duration `mapstructure:"pollingInterval"`     // la suscripcion revisa la tabla aunque no lleguen notificaciones
+	BatchSize           int           `mapstructure:"batchSize"`           // cantidad maxima de eventos por lectura del `$all`
+	Subscription        *Subscription `mapstructure:"subscription"`
+}
+
+type Subscription struct {
+	Prefix         []string `mapstructure:"prefix"`
+	SubscriptionId string   `mapstructure:"subscriptionId"`
+}
+
+func ProvideConfig(environment environment.Environment) (*PostgresEventStoreOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[PostgresEventStoreOptions]())
+	cfg, err := config.BindConfigKey[PostgresEventStoreOptions](optionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPostgresEventStoreOptionsWithDefaults(cfg), nil
+}
+
+// NewPostgresEventStoreOptionsWithDefaults completa con valores por defecto los campos que no vienen en la configuracion
+func NewPostgresEventStoreOptionsWithDefaults(options *PostgresEventStoreOptions) *PostgresEventStoreOptions {
+	if options == nil {
+		options = &PostgresEventStoreOptions{}
+	}
+	if options.NotificationChannel == "" {
+		options.NotificationChannel = defaultNotificationChannel
+	}
+	if options.PollingInterval <= 0 {
+		options.PollingInterval = defaultPollingInterval
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.Subscription == nil {
+		options.Subscription = &Subscription{}
+	}
+	if options.Subscription.SubscriptionId == "" {
+		options.Subscription.SubscriptionId = defaultSubscriptionId
+	}
+
+	return options
+}
@@ -0,0 +1,31 @@
+package postgreseventstore
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/migration"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// NewMigrationSource devuelve las migraciones de las tablas del event store, el runner de migraciones del servicio
+// (goose o gomigrate) las aplica en su `Up` con la tabla de versiones `<versionTable>_event_store`
+func NewMigrationSource() (*migration.MigrationSource, error) {
+	gooseMigrations, err := fs.Sub(migrationsFS, "migrations/goose-migrate")
+	if err != nil {
+		return nil, err
+	}
+
+	goMigrateMigrations, err := fs.Sub(migrationsFS, "migrations/go-migrate")
+	if err != nil {
+		return nil, err
+	}
+
+	return &migration.MigrationSource{
+		Name:      "event_store",
+		Goose:     gooseMigrations,
+		GoMigrate: goMigrateMigrations,
+	}, nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS es_subscription_checkpoints;
+DROP TABLE IF EXISTS es_streams;
+DROP TABLE IF EXISTS es_events;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS es_events (
+    global_position BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    stream_name VARCHAR(500) NOT NULL,
+    stream_version BIGINT NOT NULL,
+    event_type VARCHAR(500) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    data BYTEA NOT NULL,
+    metadata BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_es_events_stream_version UNIQUE (stream_name, stream_version)
+);
+
+CREATE TABLE IF NOT EXISTS es_streams (
+    stream_name VARCHAR(500) PRIMARY KEY,
+    stream_version BIGINT NOT NULL,
+    truncate_before BIGINT NOT NULL DEFAULT 0,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS es_subscription_checkpoints (
+    subscription_id VARCHAR(255) PRIMARY KEY,
+    position BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS es_subscription_checkpoints;
+DROP TABLE IF EXISTS es_streams;
+DROP TABLE IF EXISTS es_events;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+package postgreseventstore
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MigrationSource_Has_The_Goose_Migrations(t *testing.T) {
+	source, err := NewMigrationSource()
+	require.NoError(t, err)
+
+	// sql.Open no se conecta, goose solo lee las migraciones del fs
+	db, err := sql.Open("pgx", "postgres://localhost/event_store?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, source.Goose)
+	require.NoError(t, err)
+
+	sources := provider.ListSources()
+	require.Len(t, sources, 1)
+	assert.Equal(t, int64(20261017), sources[0].Version)
+}
+
+func Test_MigrationSource_Has_The_GoMigrate_Migrations(t *testing.T) {
+	source, err := NewMigrationSource()
+	require.NoError(t, err)
+
+	driver, err := iofs.New(source.GoMigrate, ".")
+	require.NoError(t, err)
+	defer driver.Close()
+
+	version, err := driver.First()
+	require.NoError(t, err)
+	assert.Equal(t, uint(20261017), version)
+
+	_, _, err = driver.ReadUp(version)
+	assert.NoError(t, err)
+	_, _, err = driver.ReadDown(version)
+	assert.NoError(t, err)
+
+	_, err = driver.Next(version)
+	assert.Error(t, err)
+}
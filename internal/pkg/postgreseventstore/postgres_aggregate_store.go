@@ -0,0 +1,303 @@
+package postgreseventstore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	appendResult "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/append_result"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	readPosition "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/read_position"
+	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
+	esErrors "github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/errors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing/attribute"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+	utils2 "github.com/DavidReque/go-food-delivery/internal/pkg/utils"
+
+	"emperror.dev/errors"
+	"github.com/google/uuid"
+	attribute2 "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type postgresAggregateStore[T models.IHaveEventSourcedAggregate] struct {
+	log            logger.Logger
+	eventStore     store.EventStore
+	tracer         trace.Tracer
+	snapshotStore  store.SnapshotStore
+	snapshotPolicy store.SnapshotPolicy
+}
+
+func NewPostgresAggregateStore[T models.IHaveEventSourcedAggregate](
+	log logger.Logger,
+	eventStore PostgresEventStore,
+	tracer trace.Tracer,
+) store.AggregateStore[T] {
+	return &postgresAggregateStore[T]{
+		log:        log,
+		eventStore: eventStore,
+		tracer:     tracer,
+	}
+}
+
+// NewPostgresAggregateStoreWithSnapshots carga los agregados que implementan `models.ISnapshotAggregate` desde su ultimo
+// snapshot, igual que `eventstroredb.NewEventStoreAggregateStoreWithSnapshots`
+func NewPostgresAggregateStoreWithSnapshots[T models.IHaveEventSourcedAggregate](
+	log logger.Logger,
+	eventStore PostgresEventStore,
+	tracer trace.Tracer,
+	snapshotStore store.SnapshotStore,
+	snapshotPolicy store.SnapshotPolicy,
+) store.AggregateStore[T] {
+	return &postgresAggregateStore[T]{
+		log:            log,
+		eventStore:     eventStore,
+		tracer:         tracer,
+		snapshotStore:  snapshotStore,
+		snapshotPolicy: snapshotPolicy,
+	}
+}
+
+func (a *postgresAggregateStore[T]) StoreWithVersion(
+	aggregate T,
+	metadata metadata.Metadata,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	ctx, span := a.tracer.Start(ctx, "postgresAggregateStore.StoreWithVersion")
+	span.SetAttributes(attribute2.String("AggregateID", aggregate.Id().String()))
+	defer span.End()
+
+	if len(aggregate.UncommittedEvents()) == 0 {
+		return appendResult.NoOp, nil
+	}
+
+	streamId := streamName.For[T](aggregate)
+	span.SetAttributes(attribute2.String("StreamId", streamId.String()))
+
+	previousVersion := aggregate.OriginalVersion()
+
+	// the event store sets the version and the position of the stored events
+	streamEvents := make([]*models.StreamEvent, 0, len(aggregate.UncommittedEvents()))
+	for _, domainEvent := range aggregate.UncommittedEvents() {
+		streamEvents = append(streamEvents, &models.StreamEvent{
+			EventID:  utils2.ConvertSatoriUUIDToGoogleUUID(domainEvent.GetEventId()),
+			Event:    domainEvent,
+			Metadata: metadata,
+		})
+	}
+
+	streamAppendResult, err := a.eventStore.AppendEvents(streamId, expectedVersion, streamEvents, ctx)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WrapIff(
+				err,
+				"[postgresAggregateStore_StoreWithVersion:AppendEvents] error in storing aggregate with id {%s}",
+				aggregate.Id().String(),
+			),
+		)
+	}
+
+	aggregate.MarkUncommittedEventAsCommitted()
+
+	a.takeSnapshot(ctx, aggregate, streamId, previousVersion)
+
+	span.SetAttributes(attribute.Object("Aggregate", aggregate))
+
+	return streamAppendResult, nil
+}
+
+func (a *postgresAggregateStore[T]) Store(
+	aggregate T,
+	metadata metadata.Metadata,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	return a.StoreWithVersion(
+		aggregate,
+		metadata,
+		expectedStreamVersion.FromInt64(aggregate.OriginalVersion()),
+		ctx,
+	)
+}
+
+func (a *postgresAggregateStore[T]) Load(ctx context.Context, aggregateId uuid.UUID) (T, error) {
+	snapshot := a.loadSnapshot(ctx, aggregateId)
+	if snapshot == nil {
+		return a.loadAggregate(ctx, aggregateId, readPosition.Start, nil)
+	}
+
+	// only the events after the snapshot are read
+	return a.loadAggregate(ctx, aggregateId, readPosition.FromInt64(snapshot.Version).Next(), snapshot)
+}
+
+func (a *postgresAggregateStore[T]) LoadWithReadPosition(
+	ctx context.Context,
+	aggregateId uuid.UUID,
+	position readPosition.StreamReadPosition,
+) (T, error) {
+	return a.loadAggregate(ctx, aggregateId, position, nil)
+}
+
+func (a *postgresAggregateStore[T]) Exists(ctx context.Context, aggregateId uuid.UUID) (bool, error) {
+	streamId := streamName.ForID[T](utils2.ConvertGoogleUUIDToSatoriUUIDSimple(aggregateId))
+
+	return a.eventStore.StreamExists(streamId, ctx)
+}
+
+func (a *postgresAggregateStore[T]) loadAggregate(
+	ctx context.Context,
+	aggregateId uuid.UUID,
+	position readPosition.StreamReadPosition,
+	snapshot *models.Snapshot,
+) (T, error) {
+	ctx, span := a.tracer.Start(ctx, "postgresAggregateStore.LoadWithReadPosition")
+	span.SetAttributes(attribute2.String("AggregateID", aggregateId.String()))
+	defer span.End()
+
+	aggregate, err := newEmptyAggregate[T]()
+	if err != nil {
+		return *new(T), utils.TraceErrStatusFromSpan(span, err)
+	}
+
+	if snapshot != nil {
+		if err := any(aggregate).(models.ISnapshotAggregate).RestoreSnapshot(snapshot.State); err != nil {
+			return *new(T), utils.TraceErrStatusFromSpan(
+				span,
+				errors.WrapIff(
+					err,
+					"[postgresAggregateStore.LoadWithReadPosition:RestoreSnapshot] error in restoring aggregate {%s} from snapshot",
+					aggregateId.String(),
+				),
+			)
+		}
+		aggregate.RestoreVersion(snapshot.Version)
+	}
+
+	streamId := streamName.ForID[T](utils2.ConvertGoogleUUIDToSatoriUUIDSimple(aggregateId))
+	span.SetAttributes(attribute2.String("StreamId", streamId.String()))
+
+	streamEvents, err := a.eventStore.ReadEventsWithMaxCount(streamId, position, ctx)
+	if err != nil || (len(streamEvents) == 0 && snapshot == nil) {
+		return *new(T), utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewAggregateNotFoundError(err, utils2.ConvertGoogleUUIDToSatoriUUIDSimple(aggregateId)),
+				"[postgresAggregateStore.LoadWithReadPosition] error in loading aggregate",
+			),
+		)
+	}
+
+	var meta metadata.Metadata
+	domainEvents := make([]domain.IDomainEvent, 0, len(streamEvents))
+	for _, streamEvent := range streamEvents {
+		meta = streamEvent.Metadata
+		domainEvents = append(domainEvents, streamEvent.Event)
+	}
+
+	if err := aggregate.LoadFromHistory(domainEvents, meta); err != nil {
+		return *new(T), utils.TraceStatusFromSpan(span, err)
+	}
+
+	span.SetAttributes(attribute.Object("Aggregate", aggregate))
+
+	return aggregate, nil
+}
+
+// loadSnapshot returns the latest snapshot of the aggregate, the aggregate is loaded from the start of the stream when
+// there isn't a snapshot with the current schema version
+func (a *postgresAggregateStore[T]) loadSnapshot(ctx context.Context, aggregateId uuid.UUID) *models.Snapshot {
+	if a.snapshotStore == nil {
+		return nil
+	}
+
+	instance, err := newEmptyAggregate[T]()
+	if err != nil {
+		return nil
+	}
+	snapshotAggregate, ok := any(instance).(models.ISnapshotAggregate)
+	if !ok {
+		return nil
+	}
+
+	streamId := streamName.ForID[T](utils2.ConvertGoogleUUIDToSatoriUUIDSimple(aggregateId))
+
+	snapshot, err := a.snapshotStore.Load(ctx, streamId)
+	if err != nil {
+		a.log.WarnMsg(
+			fmt.Sprintf("[postgresAggregateStore.loadSnapshot] error in loading snapshot of stream {%s}, loading all the events", streamId),
+			err,
+		)
+
+		return nil
+	}
+	if snapshot == nil || snapshot.SchemaVersion != snapshotAggregate.SnapshotSchemaVersion() {
+		return nil
+	}
+
+	return snapshot
+}
+
+// takeSnapshot saves a snapshot when the policy says so, a failed snapshot is only logged because the events are already stored
+func (a *postgresAggregateStore[T]) takeSnapshot(
+	ctx context.Context,
+	aggregate T,
+	streamId streamName.StreamName,
+	previousVersion int64,
+) {
+	if a.snapshotStore == nil || a.snapshotPolicy == nil {
+		return
+	}
+
+	snapshotAggregate, ok := any(aggregate).(models.ISnapshotAggregate)
+	if !ok {
+		return
+	}
+
+	version := aggregate.CurrentVersion()
+	if !a.snapshotPolicy.ShouldTakeSnapshot(streamId.Category(), previousVersion, version) {
+		return
+	}
+
+	state, err := snapshotAggregate.CreateSnapshot()
+	if err == nil {
+		err = a.snapshotStore.Save(ctx, &models.Snapshot{
+			StreamId:      streamId.String(),
+			AggregateType: typemapper.GetFullTypeName(aggregate),
+			Version:       version,
+			SchemaVersion: snapshotAggregate.SnapshotSchemaVersion(),
+			State:         state,
+		})
+	}
+	if err != nil {
+		a.log.WarnMsg(
+			fmt.Sprintf("[postgresAggregateStore.takeSnapshot] error in saving snapshot of stream {%s}", streamId),
+			err,
+		)
+	}
+}
+
+// newEmptyAggregate creates the aggregate of the pointer type T and initializes it with its `NewEmptyAggregate` method
+func newEmptyAggregate[T models.IHaveEventSourcedAggregate]() (T, error) {
+	aggregateType := reflect.TypeOf((*T)(nil)).Elem()
+	if aggregateType.Kind() != reflect.Ptr {
+		return *new(T), errors.New(
+			fmt.Sprintf("[postgresAggregateStore] aggregate %s is not a pointer", aggregateType.String()),
+		)
+	}
+	aggregate := reflect.New(aggregateType.Elem()).Interface().(T)
+
+	emptyAggregate, ok := any(aggregate).(interface{ NewEmptyAggregate() })
+	if !ok {
+		return *new(T), errors.New("[postgresAggregateStore] aggregate does not have a `NewEmptyAggregate` method")
+	}
+	emptyAggregate.NewEmptyAggregate()
+
+	return aggregate, nil
+}
@@ -0,0 +1,642 @@
+package postgreseventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models"
+	appendResult "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/append_result"
+	streamName "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_name"
+	readPosition "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/read_position"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_position/truncatePosition"
+	expectedStreamVersion "github.com/DavidReque/go-food-delivery/internal/pkg/es/models/stream_version"
+	esErrors "github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb/errors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing/attribute"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/postgreseventstore/config"
+
+	"emperror.dev/errors"
+	"github.com/google/uuid"
+	attribute2 "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// appendLockId es la llave del advisory lock de los appends, los appends se serializan para que las posiciones globales
+// se confirmen en orden y la suscripcion al `$all` no se salte un evento que se confirma despues con una posicion menor
+const appendLockId = 7450013
+
+const selectEventColumns = `global_position, event_id, stream_name, stream_version, event_type, content_type, data, metadata`
+
+// PostgresEventStore es un `store.EventStore` en postgres para los ambientes sin EventStoreDB, la version de cada
+// stream es unica en la tabla `es_events` y los appends validan la version esperada como eventstoredb
+type PostgresEventStore interface {
+	store.EventStore
+
+	// ReadAllEvents lee los eventos de todos los streams despues de la posicion global, como el stream `$all`
+	ReadAllEvents(ctx context.Context, position uint64, count int) ([]*AllStreamEvent, error)
+}
+
+// AllStreamEvent es un evento del stream `$all` con el nombre de su stream
+type AllStreamEvent struct {
+	StreamName  streamName.StreamName
+	StreamEvent *models.StreamEvent
+}
+
+type postgresEventStore struct {
+	log                logger.Logger
+	db                 *sql.DB
+	cfg                *config.PostgresEventStoreOptions
+	eventSerializer    serializer.EventSerializer
+	metadataSerializer serializer.MetadataSerializer
+	tracer             trace.Tracer
+}
+
+type streamRow struct {
+	found          bool
+	version        int64
+	truncateBefore int64
+	deleted        bool
+}
+
+// currentVersion es la ultima revision del stream, los streams borrados son NoStream aunque sus revisiones continuan
+func (s streamRow) currentVersion() int64 {
+	if !s.found || s.deleted {
+		return expectedStreamVersion.NoStream.Value()
+	}
+
+	return s.version
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func NewPostgresEventStore(
+	log logger.Logger,
+	db *sql.DB,
+	cfg *config.PostgresEventStoreOptions,
+	eventSerializer serializer.EventSerializer,
+	metadataSerializer serializer.MetadataSerializer,
+	tracer trace.Tracer,
+) PostgresEventStore {
+	return &postgresEventStore{
+		log:                log,
+		db:                 db,
+		cfg:                cfg,
+		eventSerializer:    eventSerializer,
+		metadataSerializer: metadataSerializer,
+		tracer:             tracer,
+	}
+}
+
+func (e *postgresEventStore) StreamExists(
+	streamName streamName.StreamName,
+	ctx context.Context,
+) (bool, error) {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.StreamExists")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	stream, err := selectStream(ctx, e.db, streamName, false)
+	if err != nil {
+		return false, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewReadStreamError(err),
+				"error in reading stream",
+			),
+		)
+	}
+
+	return stream.currentVersion() >= 0, nil
+}
+
+func (e *postgresEventStore) AppendEvents(
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	events []*models.StreamEvent,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.AppendEvents")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	appendEventsResult, err := e.appendEvents(ctx, streamName, expectedVersion, events)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewAppendToStreamError(err, streamName.String()),
+				"error in appending to stream",
+			),
+		)
+	}
+
+	span.SetAttributes(attribute.Object("AppendEventsResult", appendEventsResult))
+
+	e.log.Infow(
+		"events append to stream successfully",
+		logger.Fields{
+			"AppendEventsResult": appendEventsResult,
+			"StreamId":           streamName.String(),
+		},
+	)
+
+	return appendEventsResult, nil
+}
+
+func (e *postgresEventStore) appendEvents(
+	ctx context.Context,
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	events []*models.StreamEvent,
+) (*appendResult.AppendEventsResult, error) {
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", appendLockId); err != nil {
+		return nil, errors.WrapIf(err, "error in locking the event store for append")
+	}
+
+	stream, err := selectStream(ctx, tx, streamName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !expectedVersion.IsSatisfiedBy(stream.currentVersion()) {
+		return nil, errors.New(
+			fmt.Sprintf(
+				"wrong expected version, expected %d but the current version is %d",
+				expectedVersion.Value(),
+				stream.currentVersion(),
+			),
+		)
+	}
+
+	if len(events) == 0 {
+		return appendResult.From(0, uint64(max(stream.currentVersion(), 0))), nil
+	}
+
+	// the revisions of a deleted stream continue after its last revision, like a soft deleted stream in eventstoredb
+	version := expectedStreamVersion.NoStream.Value()
+	if stream.found {
+		version = stream.version
+	}
+
+	var globalPosition int64
+	for _, event := range events {
+		version++
+
+		serializedEvent, err := e.eventSerializer.Serialize(event.Event)
+		if err != nil {
+			return nil, errors.WrapIf(err, "error in serializing event")
+		}
+
+		serializedMetadata, err := e.metadataSerializer.Serialize(event.Metadata)
+		if err != nil {
+			return nil, errors.WrapIf(err, "error in serializing event metadata")
+		}
+
+		eventId := event.EventID
+		if eventId == uuid.Nil {
+			eventId = uuid.New()
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			`INSERT INTO es_events (event_id, stream_name, stream_version, event_type, content_type, data, metadata)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING global_position`,
+			eventId,
+			streamName.String(),
+			version,
+			typeregistry.GetTypeName(event.Event),
+			serializedEvent.ContentType,
+			serializedEvent.Data,
+			serializedMetadata,
+		).Scan(&globalPosition)
+		if err != nil {
+			return nil, errors.WrapIf(err, "error in inserting event")
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO es_streams (stream_name, stream_version) VALUES ($1, $2)
+		ON CONFLICT (stream_name) DO UPDATE SET stream_version = EXCLUDED.stream_version, deleted = FALSE, updated_at = CURRENT_TIMESTAMP`,
+		streamName.String(),
+		version,
+	)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in updating stream version")
+	}
+
+	// the notification is delivered on commit, it wakes up the subscriptions to all
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", e.cfg.NotificationChannel, streamName.String()); err != nil {
+		return nil, errors.WrapIf(err, "error in notifying appended events")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return appendResult.From(uint64(globalPosition), uint64(version)), nil
+}
+
+func (e *postgresEventStore) AppendNewEvents(
+	streamName streamName.StreamName,
+	events []*models.StreamEvent,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	return e.AppendEvents(streamName, expectedStreamVersion.NoStream, events, ctx)
+}
+
+func (e *postgresEventStore) ReadEvents(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.ReadEvents")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	stream, err := e.readableStream(ctx, streamName)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(span, err)
+	}
+
+	// reading forwards from the end doesn't return events
+	if readPosition.IsEnd() {
+		return nil, nil
+	}
+
+	events, err := e.queryStreamEvents(
+		ctx,
+		`SELECT `+selectEventColumns+` FROM es_events
+		WHERE stream_name = $1 AND stream_version >= $2 ORDER BY stream_version LIMIT $3`,
+		streamName.String(),
+		max(readPosition.Value(), stream.truncateBefore),
+		limit(count),
+	)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewReadStreamError(err),
+				"error in reading stream",
+			),
+		)
+	}
+
+	return events, nil
+}
+
+func (e *postgresEventStore) ReadEventsWithMaxCount(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEvents(streamName, readPosition, uint64(math.MaxUint64), ctx)
+}
+
+func (e *postgresEventStore) ReadEventsFromStart(
+	streamName streamName.StreamName,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEvents(streamName, readPosition.Start, count, ctx)
+}
+
+func (e *postgresEventStore) ReadEventsBackwards(
+	streamName streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.ReadEventsBackwards")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	stream, err := e.readableStream(ctx, streamName)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(span, err)
+	}
+
+	from := readPosition.Value()
+	if readPosition.IsEnd() || from > stream.version {
+		from = stream.version
+	}
+
+	events, err := e.queryStreamEvents(
+		ctx,
+		`SELECT `+selectEventColumns+` FROM es_events
+		WHERE stream_name = $1 AND stream_version <= $2 AND stream_version >= $3 ORDER BY stream_version DESC LIMIT $4`,
+		streamName.String(),
+		from,
+		stream.truncateBefore,
+		limit(count),
+	)
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewReadStreamError(err),
+				"error in reading stream backwards",
+			),
+		)
+	}
+
+	return events, nil
+}
+
+func (e *postgresEventStore) ReadEventsBackwardsWithMaxCount(
+	stream streamName.StreamName,
+	readPosition readPosition.StreamReadPosition,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEventsBackwards(stream, readPosition, uint64(math.MaxUint64), ctx)
+}
+
+func (e *postgresEventStore) ReadEventsBackwardsFromEnd(
+	streamName streamName.StreamName,
+	count uint64,
+	ctx context.Context,
+) ([]*models.StreamEvent, error) {
+	return e.ReadEventsBackwards(streamName, readPosition.End, count, ctx)
+}
+
+func (e *postgresEventStore) TruncateStream(
+	streamName streamName.StreamName,
+	truncatePosition truncatePosition.StreamTruncatePosition,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	ctx context.Context,
+) (*appendResult.AppendEventsResult, error) {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.TruncateStream")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	var truncateResult *appendResult.AppendEventsResult
+	err := e.updateStream(ctx, streamName, expectedVersion, func(tx *sql.Tx, stream streamRow) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE es_streams SET truncate_before = GREATEST(truncate_before, $2), updated_at = CURRENT_TIMESTAMP
+			WHERE stream_name = $1`,
+			streamName.String(),
+			truncatePosition.Value(),
+		)
+		if err != nil {
+			return err
+		}
+
+		var globalPosition int64
+		err = tx.QueryRowContext(
+			ctx,
+			`SELECT COALESCE(MAX(global_position), 0) FROM es_events WHERE stream_name = $1`,
+			streamName.String(),
+		).Scan(&globalPosition)
+		truncateResult = appendResult.From(uint64(globalPosition), uint64(stream.version))
+
+		return err
+	})
+	if err != nil {
+		return nil, utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewTruncateStreamError(err, streamName.String()),
+				"error in truncating stream",
+			),
+		)
+	}
+
+	e.log.Infow(
+		fmt.Sprintf("stream with id %s truncated successfully", streamName.String()),
+		logger.Fields{"TruncatePosition": truncatePosition.Value(), "StreamId": streamName.String()},
+	)
+
+	return truncateResult, nil
+}
+
+func (e *postgresEventStore) DeleteStream(
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	ctx context.Context,
+) error {
+	ctx, span := e.tracer.Start(ctx, "postgresEventStore.DeleteStream")
+	span.SetAttributes(attribute2.String("StreamName", streamName.String()))
+	defer span.End()
+
+	// the events stay in the table for the subscriptions to all, like a soft delete in eventstoredb
+	err := e.updateStream(ctx, streamName, expectedVersion, func(tx *sql.Tx, stream streamRow) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE es_streams SET deleted = TRUE, truncate_before = stream_version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE stream_name = $1`,
+			streamName.String(),
+		)
+
+		return err
+	})
+	if err != nil {
+		return utils.TraceErrStatusFromSpan(
+			span,
+			errors.WithMessage(
+				esErrors.NewDeleteStreamError(err, streamName.String()),
+				"error in deleting stream",
+			),
+		)
+	}
+
+	e.log.Infow(
+		fmt.Sprintf("stream with id %s deleted successfully", streamName.String()),
+		logger.Fields{"StreamId": streamName.String()},
+	)
+
+	return nil
+}
+
+func (e *postgresEventStore) ReadAllEvents(
+	ctx context.Context,
+	position uint64,
+	count int,
+) ([]*AllStreamEvent, error) {
+	rows, err := e.db.QueryContext(
+		ctx,
+		`SELECT `+selectEventColumns+` FROM es_events WHERE global_position > $1 ORDER BY global_position LIMIT $2`,
+		int64(position),
+		count,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*AllStreamEvent
+	for rows.Next() {
+		event, err := e.scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// updateStream runs the update of an existing stream with the expected version check in a transaction
+func (e *postgresEventStore) updateStream(
+	ctx context.Context,
+	streamName streamName.StreamName,
+	expectedVersion expectedStreamVersion.ExpectedStreamVersion,
+	update func(tx *sql.Tx, stream streamRow) error,
+) error {
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stream, err := selectStream(ctx, tx, streamName, true)
+	if err != nil {
+		return err
+	}
+
+	if !expectedVersion.IsSatisfiedBy(stream.currentVersion()) {
+		return errors.New(
+			fmt.Sprintf(
+				"wrong expected version, expected %d but the current version is %d",
+				expectedVersion.Value(),
+				stream.currentVersion(),
+			),
+		)
+	}
+	if stream.currentVersion() < 0 {
+		return errors.Errorf("stream %s doesn't exist", streamName.String())
+	}
+
+	if err := update(tx, stream); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (e *postgresEventStore) readableStream(ctx context.Context, streamName streamName.StreamName) (streamRow, error) {
+	stream, err := selectStream(ctx, e.db, streamName, false)
+	if err != nil {
+		return streamRow{}, errors.WithMessage(esErrors.NewReadStreamError(err), "error in reading stream")
+	}
+
+	if !stream.found || stream.deleted {
+		return streamRow{}, errors.WrapIf(
+			esErrors.NewStreamNotFoundError(nil, streamName.String()),
+			"error in reading stream",
+		)
+	}
+
+	return stream, nil
+}
+
+func (e *postgresEventStore) queryStreamEvents(
+	ctx context.Context,
+	query string,
+	args ...interface{},
+) ([]*models.StreamEvent, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.StreamEvent
+	for rows.Next() {
+		event, err := e.scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event.StreamEvent)
+	}
+
+	return events, rows.Err()
+}
+
+func (e *postgresEventStore) scanEvent(rows *sql.Rows) (*AllStreamEvent, error) {
+	var (
+		globalPosition int64
+		eventId        uuid.UUID
+		stream         string
+		version        int64
+		eventType      string
+		contentType    string
+		data           []byte
+		meta           []byte
+	)
+
+	err := rows.Scan(&globalPosition, &eventId, &stream, &version, &eventType, &contentType, &data, &meta)
+	if err != nil {
+		return nil, errors.WrapIf(err, "error in scanning event")
+	}
+
+	payload, err := e.eventSerializer.Deserialize(data, eventType, contentType)
+	if err != nil {
+		return nil, errors.WrapIff(err, "error in deserializing event `%s`", eventType)
+	}
+
+	domainEvent, ok := payload.(domain.IDomainEvent)
+	if !ok {
+		return nil, errors.New("deserialized event does not implement IDomainEvent")
+	}
+
+	streamEvent := &models.StreamEvent{
+		EventID:  eventId,
+		Event:    domainEvent,
+		Version:  version,
+		Position: globalPosition,
+	}
+
+	if len(meta) > 0 {
+		streamEvent.Metadata, err = e.metadataSerializer.Deserialize(meta)
+		if err != nil {
+			return nil, errors.WrapIf(err, "error in deserializing event metadata")
+		}
+	}
+
+	return &AllStreamEvent{StreamName: streamName.StreamName(stream), StreamEvent: streamEvent}, nil
+}
+
+func selectStream(ctx context.Context, q queryer, streamName streamName.StreamName, forUpdate bool) (streamRow, error) {
+	query := `SELECT stream_version, truncate_before, deleted FROM es_streams WHERE stream_name = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	stream := streamRow{found: true}
+	err := q.QueryRowContext(ctx, query, streamName.String()).
+		Scan(&stream.version, &stream.truncateBefore, &stream.deleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return streamRow{}, nil
+	}
+	if err != nil {
+		return streamRow{}, errors.WrapIf(err, "error in reading stream version")
+	}
+
+	return stream, nil
+}
+
+// limit converts the count of the reads to a postgres limit, the max counts of the reads are bigger than an int64
+func limit(count uint64) int64 {
+	if count > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(count)
+}
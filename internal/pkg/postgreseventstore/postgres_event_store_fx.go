@@ -0,0 +1,79 @@
+package postgreseventstore
+
+import (
+	"context"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/store"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/migration"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/postgreseventstore/config"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// ModuleFunc provided to fxlog, it replaces `eventstroredb.ModuleFunc` in the environments without EventStoreDB.
+	// The `*sql.DB` is provided by `postgresgorm.Module` and the tables are created by the migrations in `migrations`,
+	// they are embedded and registered in the migration runner of the service (`goose.Module` or `gomigrate.Module`)
+	// https://uber-go.github.io/fx/modules.html
+	ModuleFunc = func(projectionBuilderConstructor interface{}) fx.Option { //nolint:gochecknoglobals
+		return fx.Module(
+			"postgreseventstorefx",
+			fx.Provide(projectionBuilderConstructor),
+			postgresEventStoreProviders,
+			postgresEventStoreInvokes,
+		)
+	}
+
+	postgresEventStoreProviders = fx.Options(fx.Provide( //nolint:gochecknoglobals
+		config.ProvideConfig,
+		es.ProvideSnapshotOptions,
+		es.NewSnapshotPolicy,
+		fx.Annotate(
+			NewPostgresEventStore,
+			fx.As(new(store.EventStore)),
+			fx.As(new(PostgresEventStore)),
+		),
+		NewPostgresSubscriptionCheckpointRepository,
+		NewPostgresSubscriptionAllWorker,
+		migration.AsMigrationSource(NewMigrationSource),
+	))
+
+	postgresEventStoreInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
+)
+
+func registerHooks(
+	lc fx.Lifecycle,
+	worker PostgresSubscriptionAllWorker,
+	logger logger.Logger,
+	cfg *config.PostgresEventStoreOptions,
+) {
+	lifetimeCtx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// the OnStart ctx has a short timeout, the subscription needs a context which is alive during the whole app
+			go func() {
+				option := &PostgresSubscriptionToAllOptions{
+					SubscriptionId: cfg.Subscription.SubscriptionId,
+					Prefixes:       cfg.Subscription.Prefix,
+				}
+				if err := worker.SubscribeAll(lifetimeCtx, option); err != nil && lifetimeCtx.Err() == nil {
+					logger.Errorf(
+						"(worker.SubscribeAll) error in running postgres subscription worker: {%v}",
+						err,
+					)
+				}
+			}()
+			logger.Info("postgres subscription worker is listening.")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,232 @@
+package postgreseventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts/projection"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/eventstroredb"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/postgreseventstore/config"
+
+	"emperror.dev/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/mehdihadeli/go-mediatr"
+)
+
+var errListenNotSupported = errors.New("the database driver doesn't support LISTEN") //nolint:gochecknoglobals
+
+type postgresSubscriptionAllWorker struct {
+	log                              logger.Logger
+	db                               *sql.DB
+	cfg                              *config.PostgresEventStoreOptions
+	eventStore                       PostgresEventStore
+	subscriptionCheckpointRepository contracts.SubscriptionCheckpointRepository
+	projectionPublisher              projection.IProjectionPublisher
+}
+
+// PostgresSubscriptionAllWorker publica los eventos del event store de postgres a mediatr y a las proyecciones, revisa
+// la tabla en cada notificacion de LISTEN/NOTIFY y cada `PollingInterval` por si se pierde una notificacion
+type PostgresSubscriptionAllWorker interface {
+	SubscribeAll(ctx context.Context, subscriptionOption *PostgresSubscriptionToAllOptions) error
+}
+
+type PostgresSubscriptionToAllOptions struct {
+	SubscriptionId string
+	// Prefixes filtra los streams por el prefijo de su nombre, sin prefijos se publican todos los streams
+	Prefixes []string
+}
+
+func NewPostgresSubscriptionAllWorker(
+	log logger.Logger,
+	db *sql.DB,
+	cfg *config.PostgresEventStoreOptions,
+	eventStore PostgresEventStore,
+	subscriptionRepository contracts.SubscriptionCheckpointRepository,
+	projectionBuilderFunc eventstroredb.ProjectionBuilderFuc,
+) PostgresSubscriptionAllWorker {
+	builder := eventstroredb.NewProjectionsBuilder()
+	if projectionBuilderFunc != nil {
+		projectionBuilderFunc(builder)
+	}
+	projectionConfigurations := builder.Build()
+	projectionPublisher := es.NewProjectionPublisher(projectionConfigurations.Projections)
+
+	return &postgresSubscriptionAllWorker{
+		log:                              log,
+		db:                               db,
+		cfg:                              cfg,
+		eventStore:                       eventStore,
+		subscriptionCheckpointRepository: subscriptionRepository,
+		projectionPublisher:              projectionPublisher,
+	}
+}
+
+func (s *postgresSubscriptionAllWorker) SubscribeAll(
+	ctx context.Context,
+	subscriptionOption *PostgresSubscriptionToAllOptions,
+) error {
+	if subscriptionOption.SubscriptionId == "" {
+		subscriptionOption.SubscriptionId = "defaultLogger"
+	}
+
+	s.log.Info(fmt.Sprintf("starting postgres subscription to all '%s'.", subscriptionOption.SubscriptionId))
+
+	notifications := make(chan struct{}, 1)
+	go s.listen(ctx, notifications)
+
+	for {
+		count, err := s.catchUp(ctx, subscriptionOption)
+		if err != nil {
+			return err
+		}
+
+		// a full batch means there are more events to read
+		if count == s.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notifications:
+		case <-time.After(s.cfg.PollingInterval):
+		}
+	}
+}
+
+// catchUp publishes a batch of events after the checkpoint and returns the count of the read events
+func (s *postgresSubscriptionAllWorker) catchUp(
+	ctx context.Context,
+	subscriptionOption *PostgresSubscriptionToAllOptions,
+) (int, error) {
+	checkpoint, err := s.subscriptionCheckpointRepository.Load(subscriptionOption.SubscriptionId, ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := s.eventStore.ReadAllEvents(ctx, checkpoint, s.cfg.BatchSize)
+	if err != nil {
+		return 0, errors.WrapIf(err, "failed to read events from the event store")
+	}
+
+	for _, event := range events {
+		if hasPrefix(event.StreamName.String(), subscriptionOption.Prefixes) {
+			if err := s.handleEvent(ctx, event); err != nil {
+				return 0, err
+			}
+		}
+
+		err = s.subscriptionCheckpointRepository.Store(
+			subscriptionOption.SubscriptionId,
+			uint64(event.StreamEvent.Position),
+			ctx,
+		)
+		if err != nil {
+			return 0, errors.WrapIf(err, "failed to store subscription checkpoint")
+		}
+	}
+
+	return len(events), nil
+}
+
+func (s *postgresSubscriptionAllWorker) handleEvent(ctx context.Context, event *AllStreamEvent) error {
+	s.log.Info(
+		fmt.Sprintf(
+			"event appeared in postgres subscription to all. streamId: %s, revision: %d",
+			event.StreamName.String(),
+			event.StreamEvent.Version,
+		),
+	)
+
+	// publish to internal event bus - for handling event and project it manually tp corresponding read model
+	err := mediatr.Publish(ctx, event.StreamEvent)
+	if err != nil {
+		return errors.WrapIf(
+			err,
+			"failed to publish stream event for the mediatr (internal event bus for handling event)",
+		)
+	}
+
+	// publish to projection publisher
+	err = s.projectionPublisher.Publish(ctx, event.StreamEvent)
+	if err != nil {
+		return errors.WrapIf(err, "failed to publish stream event in the handle event")
+	}
+
+	return nil
+}
+
+// listen wakes up the subscription on the notifications of the appends, the polling keeps the subscription working
+// while the listener connection is reconnecting
+func (s *postgresSubscriptionAllWorker) listen(ctx context.Context, notifications chan<- struct{}) {
+	for {
+		err := s.waitForNotifications(ctx, notifications)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errListenNotSupported) {
+			s.log.WarnMsg("postgres subscription to all works only with polling", err)
+			return
+		}
+		s.log.WarnMsg("error in listening the event store notifications, reconnecting", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.PollingInterval):
+		}
+	}
+}
+
+func (s *postgresSubscriptionAllWorker) waitForNotifications(ctx context.Context, notifications chan<- struct{}) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// LISTEN needs a dedicated connection, the notifications are read with the pgx connection of the gorm postgres driver
+	return conn.Raw(func(driverConn interface{}) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.WithMessage(errListenNotSupported, fmt.Sprintf("driver connection %T", driverConn))
+		}
+		pgxConn := stdlibConn.Conn()
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+pgx.Identifier{s.cfg.NotificationChannel}.Sanitize()); err != nil {
+			return errors.WrapIf(err, "error in listening notification channel")
+		}
+
+		for {
+			if _, err := pgxConn.WaitForNotification(ctx); err != nil {
+				return err
+			}
+
+			select {
+			case notifications <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+func hasPrefix(streamName string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(streamName, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package postgreseventstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/es/contracts"
+
+	"emperror.dev/errors"
+)
+
+type postgresSubscriptionCheckpointRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSubscriptionCheckpointRepository guarda la ultima posicion global procesada de cada suscripcion en la tabla
+// `es_subscription_checkpoints`
+func NewPostgresSubscriptionCheckpointRepository(db *sql.DB) contracts.SubscriptionCheckpointRepository {
+	return &postgresSubscriptionCheckpointRepository{db: db}
+}
+
+func (p *postgresSubscriptionCheckpointRepository) Load(subscriptionId string, ctx context.Context) (uint64, error) {
+	var position int64
+
+	err := p.db.QueryRowContext(
+		ctx,
+		`SELECT position FROM es_subscription_checkpoints WHERE subscription_id = $1`,
+		subscriptionId,
+	).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.WrapIf(err, "error in loading subscription checkpoint")
+	}
+
+	return uint64(position), nil
+}
+
+func (p *postgresSubscriptionCheckpointRepository) Store(
+	subscriptionId string,
+	position uint64,
+	ctx context.Context,
+) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		`INSERT INTO es_subscription_checkpoints (subscription_id, position) VALUES ($1, $2)
+		ON CONFLICT (subscription_id) DO UPDATE SET position = EXCLUDED.position, updated_at = CURRENT_TIMESTAMP`,
+		subscriptionId,
+		int64(position),
+	)
+	if err != nil {
+		return errors.WrapIf(err, "error in storing subscription checkpoint")
+	}
+
+	return nil
+}
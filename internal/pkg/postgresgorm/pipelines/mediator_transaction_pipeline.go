@@ -91,8 +91,9 @@ func (m *mediatorTransactionPipeline) Handle(
 	// --- Commit de la Transacción ---
 	m.logger.Infof("committing transaction for request %s", requestName)
 
-	if err := tx.WithContext(ctx).Commit().Error; err != nil {
-		m.logger.Errorf("transaction commit error: ", err)
+	// si el commit falla tambien se pierden los mensajes del outbox, por eso el error se propaga al llamador
+	if err = tx.WithContext(ctx).Commit().Error; err != nil {
+		m.logger.Errorf("transaction commit error: %v", err)
 	}
 
 	if err != nil {
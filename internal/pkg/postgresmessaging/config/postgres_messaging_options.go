@@ -0,0 +1,30 @@
+package config
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+type PostgresMessagingOptions struct {
+	OutboxOptions *persistmessage.OutboxOptions `mapstructure:"outboxOptions"`
+}
+
+func ProvideConfig(environment environment.Environment) (*PostgresMessagingOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[PostgresMessagingOptions]())
+	cfg, err := config.BindConfigKey[PostgresMessagingOptions](optionName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OutboxOptions = persistmessage.NewOutboxOptionsWithDefaults(cfg.OutboxOptions)
+
+	return cfg, nil
+}
+
+func ProvideOutboxOptions(cfg *PostgresMessagingOptions) *persistmessage.OutboxOptions {
+	return cfg.OutboxOptions
+}
@@ -3,47 +3,147 @@ package messagepersistence
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/postgresgorm/contracts"
 
 	"emperror.dev/errors"
 	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const filterBatchSize = 500
+
 type postgresMessagePersistenceService struct {
 	messagingDBContext *PostgresMessagePersistenceDBContext
 	messageSerializer  serializer.MessageSerializer
+	producer           producer.Producer
+	outboxOptions      *persistmessage.OutboxOptions
 	logger             logger.Logger
 }
 
+// Process publica un mensaje del outbox que aun no fue procesado, si la publicacion falla se incrementa su numero de reintentos
 func (m *postgresMessagePersistenceService) Process(messageID string, ctx context.Context) error {
-	// TODO implement me
-	panic("implement me")
+	id, err := uuid.FromString(messageID)
+	if err != nil {
+		return err
+	}
+
+	storeMessage, err := m.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return m.processStoreMessage(ctx, storeMessage)
 }
 
+// ProcessAll publica un lote de mensajes pendientes del outbox. Los mensajes se reservan en una transaccion corta
+// con `SKIP LOCKED` y se publican fuera de ella, asi la publicacion no mantiene bloqueadas las filas y varias
+// instancias del servicio no publican el mismo mensaje
 func (m *postgresMessagePersistenceService) ProcessAll(ctx context.Context) error {
-	// TODO implement me
-	panic("implement me")
+	storeMessages, err := m.lockOutboxMessages(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, storeMessage := range storeMessages {
+		// un mensaje fallido no debe bloquear al resto del lote, se reintenta cuando expire su reserva
+		if err := m.processStoreMessage(ctx, storeMessage); err != nil {
+			m.logger.Errorf(
+				"error in processing outbox message with id: %v, error: %v",
+				storeMessage.ID,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// lockOutboxMessages reserva los mensajes pendientes con `locked_until`, la reserva expira si la instancia se cae
+func (m *postgresMessagePersistenceService) lockOutboxMessages(
+	ctx context.Context,
+) ([]*persistmessage.StoreMessage, error) {
+	var storeMessages []*persistmessage.StoreMessage
+
+	err := m.messagingDBContext.RunInTx(ctx, func(ctx context.Context, _ contracts.GormDBContext) error {
+		dbContext := m.messagingDBContext.WithTxIfExists(ctx)
+		now := time.Now()
+
+		// https://gorm.io/docs/advanced_query.html#Locking
+		result := dbContext.DB().
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(
+				"message_status = ? AND delivery_type = ? AND (locked_until IS NULL OR locked_until < ?)",
+				persistmessage.Stored,
+				persistmessage.Outbox,
+				now,
+			).
+			Order("created_at").
+			Limit(m.outboxOptions.BatchSize).
+			Find(&storeMessages)
+		if result.Error != nil {
+			return customErrors.NewInternalServerErrorWrap(
+				result.Error,
+				"error in fetching outbox messages",
+			)
+		}
+
+		if len(storeMessages) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(storeMessages))
+		for _, storeMessage := range storeMessages {
+			ids = append(ids, storeMessage.ID)
+		}
+
+		lockedUntil := now.Add(m.outboxOptions.LockDuration)
+		result = dbContext.DB().
+			Model(&persistmessage.StoreMessage{}).
+			Where("id IN ?", ids).
+			Update("locked_until", lockedUntil)
+		if result.Error != nil {
+			return customErrors.NewInternalServerErrorWrap(
+				result.Error,
+				"error in locking outbox messages",
+			)
+		}
+
+		for _, storeMessage := range storeMessages {
+			storeMessage.LockedUntil = &lockedUntil
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return storeMessages, nil
 }
 
 func (m *postgresMessagePersistenceService) AddPublishMessage(
 	messageEnvelope types.MessageEnvelope,
 	ctx context.Context,
 ) error {
-	// TODO implement me
-	panic("implement me")
+	return m.AddMessageCore(ctx, messageEnvelope, persistmessage.Outbox)
 }
 
 func (m *postgresMessagePersistenceService) AddReceivedMessage(
 	messageEnvelope types.MessageEnvelope,
 	ctx context.Context,
 ) error {
-	// TODO implement me
-	panic("implement me")
+	return m.AddMessageCore(ctx, messageEnvelope, persistmessage.Inbox)
 }
 
 func (m *postgresMessagePersistenceService) AddMessageCore(
@@ -74,10 +174,16 @@ func (m *postgresMessagePersistenceService) AddMessageCore(
 	if err != nil {
 		return err
 	}
+	// the inbox row is scoped to the consumer and the handler of the context
+	if deliveryType == persistmessage.Inbox {
+		uuidId = persistmessage.InboxMessageId(ctx, uuidId)
+	}
 
 	storeMessage := persistmessage.NewStoreMessage(
 		uuidId,
-		messageEnvelope.Message.GetMessageFullTypeName(),
+		// se guarda el nombre logico del tipo o, si no esta registrado, el nombre completo (ej. `*integrationevents.ProductCreatedV1`)
+		// para poder deserializarlo desde el typemapper
+		typeregistry.GetFullTypeName(messageEnvelope.Message),
 		string(data.Data),
 		deliveryType,
 	)
@@ -89,7 +195,7 @@ func (m *postgresMessagePersistenceService) AddMessageCore(
 
 	m.logger.Infof(
 		"Message with id: %v and delivery type: %v saved in persistence message store",
-		id,
+		uuidId,
 		deliveryType,
 	)
 
@@ -98,14 +204,89 @@ func (m *postgresMessagePersistenceService) AddMessageCore(
 
 func NewPostgresMessageService(
 	postgresMessagePersistenceDBContext *PostgresMessagePersistenceDBContext,
+	messageSerializer serializer.MessageSerializer,
+	producer producer.Producer,
+	outboxOptions *persistmessage.OutboxOptions,
 	l logger.Logger,
 ) persistmessage.MessagePersistenceService {
 	return &postgresMessagePersistenceService{
 		messagingDBContext: postgresMessagePersistenceDBContext,
+		messageSerializer:  messageSerializer,
+		producer:           producer,
+		outboxOptions:      outboxOptions,
 		logger:             l,
 	}
 }
 
+func (m *postgresMessagePersistenceService) processStoreMessage(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	if storeMessage.MessageStatus == persistmessage.Processed {
+		return nil
+	}
+
+	switch storeMessage.DeliveryType {
+	case persistmessage.Outbox:
+		err := m.publishStoreMessage(ctx, storeMessage)
+		if err != nil {
+			storeMessage.IncreaseRetry()
+			if storeMessage.RetryCount >= m.outboxOptions.MaxRetryCount {
+				storeMessage.ChangeState(persistmessage.Failed)
+				m.logger.Errorf(
+					"outbox message with id: %v reached the max retry count and it is marked as failed",
+					storeMessage.ID,
+				)
+			}
+			if updateErr := m.Update(ctx, storeMessage); updateErr != nil {
+				return errors.WrapIf(updateErr, err.Error())
+			}
+
+			return err
+		}
+	case persistmessage.Inbox:
+		// los mensajes del inbox ya fueron manejados por el consumidor, solo se guardan para idempotencia
+	default:
+		return errors.Errorf("delivery type `%v` is not supported", storeMessage.DeliveryType)
+	}
+
+	storeMessage.ChangeState(persistmessage.Processed)
+
+	return m.Update(ctx, storeMessage)
+}
+
+func (m *postgresMessagePersistenceService) publishStoreMessage(
+	ctx context.Context,
+	storeMessage *persistmessage.StoreMessage,
+) error {
+	messageEnvelope, err := m.messageSerializer.DeserializeEnvelop(
+		[]byte(storeMessage.Data),
+		storeMessage.DataType,
+		m.messageSerializer.ContentType(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = m.producer.PublishMessageWithTopicName(
+		ctx,
+		messageEnvelope.Message,
+		metadata.MapToMetadata(messageEnvelope.Headers),
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof(
+		"Message with id: %v and type: %s published from the outbox",
+		storeMessage.ID,
+		storeMessage.DataType,
+	)
+
+	return nil
+}
+
 func (m *postgresMessagePersistenceService) Add(
 	ctx context.Context,
 	storeMessage *persistmessage.StoreMessage,
@@ -162,7 +343,7 @@ func (m *postgresMessagePersistenceService) ChangeState(
 		)
 	}
 
-	storeMessage.MessageStatus = status
+	storeMessage.ChangeState(status)
 	err = m.Update(ctx, storeMessage)
 
 	return err
@@ -173,12 +354,10 @@ func (m *postgresMessagePersistenceService) GetAllActive(
 ) ([]*persistmessage.StoreMessage, error) {
 	var storeMessages []*persistmessage.StoreMessage
 
-	predicate := func(sm *persistmessage.StoreMessage) bool {
-		return sm.MessageStatus == persistmessage.Stored
-	}
-
 	dbContext := m.messagingDBContext.WithTxIfExists(ctx)
-	result := dbContext.DB().Where(predicate).Find(&storeMessages)
+	result := dbContext.DB().
+		Where("message_status = ?", persistmessage.Stored).
+		Find(&storeMessages)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -191,29 +370,41 @@ func (m *postgresMessagePersistenceService) GetByFilter(
 	predicate func(*persistmessage.StoreMessage) bool,
 ) ([]*persistmessage.StoreMessage, error) {
 	var storeMessages []*persistmessage.StoreMessage
+	var filtered []*persistmessage.StoreMessage
 
+	// gorm no puede traducir un predicado de go a sql, por eso el filtro se aplica en memoria por lotes
+	// para no cargar toda la tabla de una vez
+	// https://gorm.io/docs/advanced_query.html#FindInBatches
 	dbContext := m.messagingDBContext.WithTxIfExists(ctx)
-	result := dbContext.DB().Where(predicate).Find(&storeMessages)
-
+	result := dbContext.DB().FindInBatches(&storeMessages, filterBatchSize, func(_ *gorm.DB, _ int) error {
+		for _, storeMessage := range storeMessages {
+			if predicate(storeMessage) {
+				filtered = append(filtered, storeMessage)
+			}
+		}
+
+		return nil
+	})
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	return storeMessages, nil
+	return filtered, nil
 }
 
 func (m *postgresMessagePersistenceService) GetById(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*persistmessage.StoreMessage, error) {
-	var storeMessage *persistmessage.StoreMessage
+	storeMessage := &persistmessage.StoreMessage{}
 
 	// https://gorm.io/docs/query.html#Retrieving-objects-with-primary-key
 	// https://gorm.io/docs/query.html#Struct-amp-Map-Conditions
 	// https://gorm.io/docs/query.html#Inline-Condition
 	// https://gorm.io/docs/advanced_query.html
-	result := m.messagingDBContext.DB().Find(&storeMessage, id)
-	if result.Error != nil {
+	dbContext := m.messagingDBContext.WithTxIfExists(ctx)
+	result := dbContext.DB().First(storeMessage, "id = ?", id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, customErrors.NewNotFoundErrorWrap(
 			result.Error,
 			fmt.Sprintf(
@@ -222,6 +413,15 @@ func (m *postgresMessagePersistenceService) GetById(
 			),
 		)
 	}
+	if result.Error != nil {
+		return nil, customErrors.NewInternalServerErrorWrap(
+			result.Error,
+			fmt.Sprintf(
+				"error in fetching storeMessage with id `%s` from the database",
+				id.String(),
+			),
+		)
+	}
 
 	m.logger.Infof("Number of affected rows are: %d", result.RowsAffected)
 
@@ -263,17 +463,22 @@ func (m *postgresMessagePersistenceService) Remove(
 	return true, nil
 }
 
+// CleanupMessages elimina los mensajes procesados del outbox, los del inbox se conservan durante `InboxRetention`
+// desde que se procesaron porque la deduplicacion de las reentregas depende de ellos
 func (m *postgresMessagePersistenceService) CleanupMessages(
 	ctx context.Context,
 ) error {
-	predicate := func(sm *persistmessage.StoreMessage) bool {
-		return sm.MessageStatus == persistmessage.Processed
-	}
-
 	dbContext := m.messagingDBContext.WithTxIfExists(ctx)
 
 	result := dbContext.DB().
-		Where(predicate).
+		Where(
+			// the rows processed before the processing date was stored are retained from their creation
+			"message_status = ? AND (delivery_type = ? OR (delivery_type = ? AND COALESCE(processed_at, created_at) < ?))",
+			persistmessage.Processed,
+			persistmessage.Outbox,
+			persistmessage.Inbox,
+			time.Now().Add(-m.outboxOptions.InboxRetention),
+		).
 		Delete(&persistmessage.StoreMessage{})
 
 	if result.Error != nil {
@@ -1 +1,313 @@
-package messagepersistence
\ No newline at end of file
+package messagepersistence
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+	"github.com/glebarez/sqlite"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testMessage struct {
+	*types.Message
+	Value string
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&testMessage{}))
+}
+
+func newTestMessage(value string) *testMessage {
+	return &testMessage{Message: types.NewMessage(uuid.NewV4().String()), Value: value}
+}
+
+// fakeProducer solo implementa la publicacion que usa el outbox
+type fakeProducer struct {
+	mu        sync.Mutex
+	published []types.IMessage
+	err       error
+}
+
+func (f *fakeProducer) PublishMessage(ctx context.Context, message types.IMessage) error {
+	return f.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+func (f *fakeProducer) PublishMessageWithTopicName(
+	_ context.Context,
+	message types.IMessage,
+	_ metadata.Metadata,
+	_ string,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, message)
+
+	return nil
+}
+
+func (f *fakeProducer) PublishMessageWithDelay(
+	context.Context,
+	types.IMessage,
+	metadata.Metadata,
+	time.Duration,
+) error {
+	return nil
+}
+
+func (f *fakeProducer) ScheduleMessage(context.Context, types.IMessage, metadata.Metadata, time.Time) error {
+	return nil
+}
+
+func (f *fakeProducer) PublishMessages(context.Context, []types.IMessage, metadata.Metadata, string) error {
+	return nil
+}
+
+func (f *fakeProducer) IsProduced(func(message types.IMessage)) {}
+
+func (f *fakeProducer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.published)
+}
+
+func newTestService(
+	t *testing.T,
+	producer *fakeProducer,
+	outboxOptions *persistmessage.OutboxOptions,
+) (*postgresMessagePersistenceService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	// cada conexion a `:memory:` es una base distinta
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(&persistmessage.StoreMessage{}))
+
+	service := NewPostgresMessageService(
+		NewPostgresMessagePersistenceDBContext(db),
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		producer,
+		persistmessage.NewOutboxOptionsWithDefaults(outboxOptions),
+		defaultlogger.GetLogger(),
+	)
+
+	return service.(*postgresMessagePersistenceService), db
+}
+
+func Test_ProcessAll_Publishes_Stored_Outbox_Messages(t *testing.T) {
+	ctx := context.Background()
+	producer := &fakeProducer{}
+	service, _ := newTestService(t, producer, nil)
+
+	first := newTestMessage("first")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(first, nil), ctx))
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(newTestMessage("second"), nil), ctx))
+	require.NoError(t, service.AddReceivedMessage(*types.NewMessageEnvelope(newTestMessage("inbox"), nil), ctx))
+
+	require.NoError(t, service.ProcessAll(ctx))
+
+	assert.Equal(t, 2, producer.count())
+	assert.Equal(t, "first", producer.published[0].(*testMessage).Value)
+
+	storeMessage, err := service.GetById(ctx, uuid.FromStringOrNil(first.GeMessageId()))
+	require.NoError(t, err)
+	assert.Equal(t, persistmessage.Processed, storeMessage.MessageStatus)
+
+	// los mensajes procesados no se vuelven a publicar
+	require.NoError(t, service.ProcessAll(ctx))
+	assert.Equal(t, 2, producer.count())
+}
+
+func Test_ProcessAll_Skips_Locked_Messages(t *testing.T) {
+	ctx := context.Background()
+	producer := &fakeProducer{}
+	service, db := newTestService(t, producer, nil)
+
+	message := newTestMessage("locked")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(message, nil), ctx))
+	require.NoError(t, db.Model(&persistmessage.StoreMessage{}).
+		Where("id = ?", uuid.FromStringOrNil(message.GeMessageId())).
+		Update("locked_until", time.Now().Add(time.Minute)).Error)
+
+	require.NoError(t, service.ProcessAll(ctx))
+
+	assert.Equal(t, 0, producer.count())
+}
+
+func Test_ProcessAll_Marks_Message_As_Failed_After_Max_Retries(t *testing.T) {
+	ctx := context.Background()
+	producer := &fakeProducer{err: errors.New("broker is down")}
+	service, _ := newTestService(t, producer, &persistmessage.OutboxOptions{
+		MaxRetryCount: 2,
+		LockDuration:  time.Nanosecond,
+	})
+
+	message := newTestMessage("failing")
+	id := uuid.FromStringOrNil(message.GeMessageId())
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(message, nil), ctx))
+
+	require.NoError(t, service.ProcessAll(ctx))
+	storeMessage, err := service.GetById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, persistmessage.Stored, storeMessage.MessageStatus)
+	assert.Equal(t, 1, storeMessage.RetryCount)
+
+	require.NoError(t, service.ProcessAll(ctx))
+	storeMessage, err = service.GetById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, persistmessage.Failed, storeMessage.MessageStatus)
+	assert.Equal(t, 2, storeMessage.RetryCount)
+
+	// un mensaje fallido ya no se selecciona aunque el producer se recupere
+	producer.err = nil
+	require.NoError(t, service.ProcessAll(ctx))
+	assert.Equal(t, 0, producer.count())
+}
+
+func Test_CleanupMessages_Keeps_Inbox_Messages_Inside_Retention(t *testing.T) {
+	ctx := context.Background()
+	producer := &fakeProducer{}
+	service, db := newTestService(t, producer, &persistmessage.OutboxOptions{InboxRetention: time.Hour})
+
+	outboxMessage := newTestMessage("outbox")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(outboxMessage, nil), ctx))
+	require.NoError(t, service.ProcessAll(ctx))
+
+	inbox := pipeline.NewInboxConsumerPipeline(service, defaultlogger.GetLogger())
+	received := newTestMessage("received")
+	handled := 0
+	consume := func() {
+		consumeContext := types.NewMessageConsumeContext(
+			received,
+			metadata.Metadata{},
+			"application/json",
+			"testMessage",
+			time.Now(),
+			0,
+			received.GeMessageId(),
+			"",
+			nil,
+		)
+		require.NoError(t, inbox.Handle(ctx, consumeContext, func(context.Context) error {
+			handled++
+
+			return nil
+		}))
+	}
+
+	oldInboxMessage := newTestMessage("old")
+	require.NoError(t, service.AddReceivedMessage(*types.NewMessageEnvelope(oldInboxMessage, nil), ctx))
+	oldInboxID := persistmessage.InboxMessageId(ctx, uuid.FromStringOrNil(oldInboxMessage.GeMessageId()))
+	require.NoError(t, service.ChangeState(ctx, oldInboxID, persistmessage.Processed))
+	require.NoError(t, db.Model(&persistmessage.StoreMessage{}).
+		Where("id = ?", oldInboxID).
+		Update("processed_at", time.Now().Add(-2*time.Hour)).Error)
+
+	// the retention is counted from the processing, a message received long ago that was just processed is kept
+	lateInboxMessage := newTestMessage("late")
+	require.NoError(t, service.AddReceivedMessage(*types.NewMessageEnvelope(lateInboxMessage, nil), ctx))
+	lateInboxID := persistmessage.InboxMessageId(ctx, uuid.FromStringOrNil(lateInboxMessage.GeMessageId()))
+	require.NoError(t, db.Model(&persistmessage.StoreMessage{}).
+		Where("id = ?", lateInboxID).
+		Update("created_at", time.Now().Add(-2*time.Hour)).Error)
+	require.NoError(t, service.ChangeState(ctx, lateInboxID, persistmessage.Processed))
+
+	consume()
+	require.NoError(t, service.CleanupMessages(ctx))
+
+	_, err := service.GetById(ctx, uuid.FromStringOrNil(outboxMessage.GeMessageId()))
+	assert.True(t, customErrors.IsNotFoundError(err))
+	_, err = service.GetById(ctx, oldInboxID)
+	assert.True(t, customErrors.IsNotFoundError(err))
+	_, err = service.GetById(ctx, lateInboxID)
+	assert.NoError(t, err)
+
+	// la reentrega del broker despues de la limpieza sigue deduplicada
+	consume()
+	assert.Equal(t, 1, handled)
+}
+
+func newTestConsumeContext(message *testMessage) types.MessageConsumeContext {
+	return types.NewMessageConsumeContext(
+		message,
+		metadata.Metadata{},
+		"application/json",
+		"testMessage",
+		time.Now(),
+		0,
+		message.GeMessageId(),
+		"",
+		nil,
+	)
+}
+
+func Test_Inbox_Deduplicates_Each_Consumer_Of_A_Message_Separately(t *testing.T) {
+	producer := &fakeProducer{}
+	service, _ := newTestService(t, producer, nil)
+	inbox := pipeline.NewInboxConsumerPipeline(service, defaultlogger.GetLogger())
+
+	// el mensaje tambien se publico desde este servicio, su fila del outbox esta en la misma tabla
+	message := newTestMessage("shared")
+	require.NoError(t, service.AddPublishMessage(*types.NewMessageEnvelope(message, nil), context.Background()))
+	require.NoError(t, service.ProcessAll(context.Background()))
+	require.Equal(t, 1, producer.count())
+
+	handled := map[string]int{}
+	consume := func(consumerName string, handlerName string) {
+		ctx := consumer.ContextWithConsumeInfo(
+			context.Background(),
+			consumer.ConsumeInfo{ConsumerName: consumerName, HandlerName: handlerName},
+		)
+		require.NoError(t, inbox.Handle(ctx, newTestConsumeContext(message), func(context.Context) error {
+			handled[consumerName+"/"+handlerName]++
+
+			return nil
+		}))
+	}
+
+	consume("orders_consumer", "*handlers.createOrderHandler")
+	consume("orders_consumer", "*handlers.notifyOrderHandler")
+	consume("reports_consumer", "*handlers.createOrderHandler")
+	// las entregas repetidas del broker a cada consumer se deduplican
+	consume("orders_consumer", "*handlers.createOrderHandler")
+	consume("reports_consumer", "*handlers.createOrderHandler")
+
+	assert.Equal(t, map[string]int{
+		"orders_consumer/*handlers.createOrderHandler":  1,
+		"orders_consumer/*handlers.notifyOrderHandler":  1,
+		"reports_consumer/*handlers.createOrderHandler": 1,
+	}, handled)
+
+	inboxMessages, err := service.GetByFilter(context.Background(), func(storeMessage *persistmessage.StoreMessage) bool {
+		return storeMessage.DeliveryType == persistmessage.Inbox
+	})
+	require.NoError(t, err)
+	assert.Len(t, inboxMessages, 3)
+}
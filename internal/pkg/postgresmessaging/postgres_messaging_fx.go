@@ -1,7 +1,10 @@
 package postgresmessaging
 
 import (
+	"context"
+
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/postgresmessaging/config"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/postgresmessaging/messagepersistence"
 
 	"go.uber.org/fx"
@@ -11,14 +14,35 @@ import (
 var Module = fx.Module(
 	"postgresmessagingfx",
 	fx.Provide(
+		config.ProvideConfig,
+		config.ProvideOutboxOptions,
 		messagepersistence.NewPostgresMessagePersistenceDBContext,
 		messagepersistence.NewPostgresMessageService,
+		persistmessage.NewOutboxDispatcher,
 	),
 	fx.Invoke(migrateMessaging),
+	fx.Invoke(registerHooks),
 )
 
 func migrateMessaging(db *gorm.DB) error {
 	err := db.Migrator().AutoMigrate(&persistmessage.StoreMessage{})
 
 	return err
-}
\ No newline at end of file
+}
+
+func registerHooks(
+	lc fx.Lifecycle,
+	dispatcher persistmessage.OutboxDispatcher,
+) {
+	lifetimeCtx := context.Background()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// el ctx de OnStart tiene un timeout corto, el dispatcher necesita un contexto que viva durante toda la aplicacion
+			return dispatcher.Start(lifetimeCtx)
+		},
+		OnStop: func(ctx context.Context) error {
+			return dispatcher.Stop(ctx)
+		},
+	})
+}
@@ -3,8 +3,10 @@ package bus
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"sync"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
@@ -20,6 +22,7 @@ import (
 	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/configurations"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/producercontracts"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/rabbitmqErrors"
+	rabbitmqTypes "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
 	typeMapper "github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
 
 	"github.com/samber/lo"
@@ -27,7 +30,12 @@ import (
 
 type RabbitmqBus interface {
 	bus.Bus
+	producer.AsyncProducer
 	consumerConfigurations.RabbitMQConsumerConnector
+	// RabbitMQConfiguration devuelve la configuracion de producers y consumers con la que se construyo el bus
+	RabbitMQConfiguration() *configurations.RabbitMQConfiguration
+	// DrainStates devuelve el estado del drenado de cada consumer por su nombre
+	DrainStates() map[string]consumer2.DrainState
 }
 
 type rabbitmqBus struct {
@@ -136,6 +144,11 @@ func NewRabbitmqBus(
 	return rabbitBus, nil
 }
 
+// RabbitMQConfiguration returns the built configuration of the producers and consumers
+func (r *rabbitmqBus) RabbitMQConfiguration() *configurations.RabbitMQConfiguration {
+	return r.rabbitmqConfiguration
+}
+
 // IsConsumed adds a notification function to the bus
 func (r *rabbitmqBus) IsConsumed(h func(message types.IMessage)) {
 	r.isConsumedNotifications = append(r.isConsumedNotifications, h)
@@ -242,6 +255,33 @@ func (r *rabbitmqBus) ConnectConsumerHandler(
 	return nil
 }
 
+// ConnectReplyConsumerHandler creates a consumer with an exclusive queue bound to the `replyTo` exchange,
+// each instance has its own queue, so the responses are consumed by the instance that sent the request,
+// the exchange is auto deleted with the queue so the reply addresses of the stopped instances don't pile up in the broker
+func (r *rabbitmqBus) ConnectReplyConsumerHandler(
+	messageType types.IMessage,
+	replyTo string,
+	consumerHandler consumer2.ConsumerHandler,
+) error {
+	return r.ConnectRabbitMQConsumer(
+		messageType,
+		func(builder consumerConfigurations.RabbitMQConsumerConfigurationBuilder) {
+			builder.
+				WithName(fmt.Sprintf("%s_reply_consumer", utils.GetMessageName(messageType))).
+				WithExchangeName(replyTo).
+				WithExchangeType(rabbitmqTypes.ReplyExchangeType).
+				WithDurable(rabbitmqTypes.ReplyExchangeDurable).
+				WithAutoDeleteExchange(rabbitmqTypes.ReplyExchangeAutoDelete).
+				WithQueueName(fmt.Sprintf("%s.%s", replyTo, utils.GetQueueName(messageType))).
+				WithExclusiveQueue(true).
+				WithAutoDeleteQueue(true).
+				WithHandlers(func(handlersBuilder consumer2.ConsumerHandlerConfigurationBuilder) {
+					handlersBuilder.AddHandler(consumerHandler)
+				})
+		},
+	)
+}
+
 // Start starts the bus
 func (r *rabbitmqBus) Start(ctx context.Context) error {
 	r.logger.Infof(
@@ -306,6 +346,13 @@ func (r *rabbitmqBus) Stop() error {
 	}
 	waitGroup.Wait()
 
+	// the producer is closed after the consumers, their handlers can still publish messages while they drain
+	if closer, ok := r.producer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			r.logger.Errorf("error in closing the rabbitmq producer: %v", err)
+		}
+	}
+
 	//err := r.rabbitmqConnection.Close()
 	//if err == amqp091.ErrClosed {
 	//	return nil
@@ -314,6 +361,32 @@ func (r *rabbitmqBus) Stop() error {
 	return nil
 }
 
+// DrainStates returns the drain state of the consumers, the consumers that don't drain are reported as running
+func (r *rabbitmqBus) DrainStates() map[string]consumer2.DrainState {
+	states := map[string]consumer2.DrainState{}
+
+	for _, consumers := range r.messageTypeConsumers {
+		for _, c := range consumers {
+			name := c.GetName()
+			// different consumers can have the same name, e.g. the consumers connected for a message type without a configuration
+			for i := 2; ; i++ {
+				if _, ok := states[name]; !ok {
+					break
+				}
+				name = fmt.Sprintf("%s_%d", c.GetName(), i)
+			}
+
+			state := consumer2.DrainStateRunning
+			if reporter, ok := c.(consumer2.DrainStateReporter); ok {
+				state = reporter.DrainState()
+			}
+			states[name] = state
+		}
+	}
+
+	return states
+}
+
 func (r *rabbitmqBus) PublishMessage(
 	ctx context.Context,
 	message types.IMessage,
@@ -338,3 +411,45 @@ func (r *rabbitmqBus) PublishMessageWithTopicName(
 		topicOrExchangeName,
 	)
 }
+
+func (r *rabbitmqBus) PublishMessageWithDelay(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return r.producer.PublishMessageWithDelay(ctx, message, meta, delay)
+}
+
+func (r *rabbitmqBus) ScheduleMessage(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	return r.producer.ScheduleMessage(ctx, message, meta, at)
+}
+
+func (r *rabbitmqBus) PublishMessages(
+	ctx context.Context,
+	messages []types.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+) error {
+	return r.producer.PublishMessages(ctx, messages, meta, topicOrExchangeName)
+}
+
+func (r *rabbitmqBus) PublishMessageAsync(
+	ctx context.Context,
+	message types.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+	callbacks ...func(err error),
+) (producer.PublishFuture, error) {
+	asyncProducer, ok := r.producer.(producer.AsyncProducer)
+	if !ok {
+		return nil, errors.New("the producer doesn't support asynchronous publishing")
+	}
+
+	return asyncProducer.PublishMessageAsync(ctx, message, meta, topicOrExchangeName, callbacks...)
+}
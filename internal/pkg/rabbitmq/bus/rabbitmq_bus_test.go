@@ -0,0 +1,36 @@
+package bus
+
+import (
+	"reflect"
+	"testing"
+
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// closableProducer registra si el bus cerro el producer
+type closableProducer struct {
+	producer.Producer
+	closed bool
+}
+
+func (p *closableProducer) Close() error {
+	p.closed = true
+
+	return nil
+}
+
+func Test_Stop_Closes_The_Producer(t *testing.T) {
+	mqProducer := &closableProducer{}
+	rabbitBus := &rabbitmqBus{
+		logger:               defaultlogger.GetLogger(),
+		producer:             mqProducer,
+		messageTypeConsumers: map[reflect.Type][]consumer2.Consumer{},
+	}
+
+	assert.NoError(t, rabbitBus.Stop())
+	assert.True(t, mqProducer.closed)
+}
@@ -17,6 +17,13 @@ type RabbitmqOptions struct {
 	AppId               string
 	AutoStart           bool `mapstructure:"autoStart"           default:"true"`
 	Reconnecting        bool `mapstructure:"reconnecting"        default:"true"`
+	// ChannelPoolSize es el numero maximo de channels libres que el producer reutiliza entre publicaciones
+	ChannelPoolSize int `mapstructure:"channelPoolSize"`
+	// DrainTimeout es la espera maxima de los mensajes en curso al detener los consumers, despues se devuelven a la queue.
+	// Debe ser menor que el timeout de apagado de la aplicacion
+	DrainTimeout time.Duration `mapstructure:"drainTimeout"`
+	// Topology es la topologia declarada del broker, es opcional
+	Topology *TopologyOptions `mapstructure:"topology"`
 }
 
 type RabbitmqHostOptions struct {
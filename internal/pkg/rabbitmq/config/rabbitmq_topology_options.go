@@ -0,0 +1,70 @@
+package config
+
+import (
+	"time"
+)
+
+// TopologyOptions declara los exchanges, queues y bindings del broker, se aplican una vez al iniciar el modulo de rabbitmq
+// antes de iniciar los consumers. Con `VerifyOnly` no se modifica el broker, solo se reportan las diferencias
+type TopologyOptions struct {
+	VerifyOnly bool `mapstructure:"verifyOnly"`
+	// FailOnDrift detiene el inicio de la aplicacion si la verificacion encuentra diferencias o si no puede comparar
+	// las definiciones, por ejemplo sin el puerto http del plugin de management
+	FailOnDrift bool                      `mapstructure:"failOnDrift"`
+	Exchanges   []ExchangeTopologyOptions `mapstructure:"exchanges"`
+	Queues      []QueueTopologyOptions    `mapstructure:"queues"`
+	Bindings    []BindingTopologyOptions  `mapstructure:"bindings"`
+}
+
+type ExchangeTopologyOptions struct {
+	Name       string         `mapstructure:"name"`
+	Type       string         `mapstructure:"type"`
+	Durable    bool           `mapstructure:"durable"`
+	AutoDelete bool           `mapstructure:"autoDelete"`
+	Internal   bool           `mapstructure:"internal"`
+	Args       map[string]any `mapstructure:"args"`
+}
+
+type QueueTopologyOptions struct {
+	Name                 string         `mapstructure:"name"`
+	Durable              bool           `mapstructure:"durable"`
+	AutoDelete           bool           `mapstructure:"autoDelete"`
+	Exclusive            bool           `mapstructure:"exclusive"`
+	DeadLetterExchange   string         `mapstructure:"deadLetterExchange"`
+	DeadLetterRoutingKey string         `mapstructure:"deadLetterRoutingKey"`
+	MessageTtl           time.Duration  `mapstructure:"messageTtl"`
+	MaxLength            int            `mapstructure:"maxLength"`
+	Args                 map[string]any `mapstructure:"args"`
+}
+
+// BindingTopologyOptions enlaza el exchange `Source` con la queue `Queue` o con el exchange `DestinationExchange`
+type BindingTopologyOptions struct {
+	Source              string         `mapstructure:"source"`
+	Queue               string         `mapstructure:"queue"`
+	DestinationExchange string         `mapstructure:"destinationExchange"`
+	RoutingKey          string         `mapstructure:"routingKey"`
+	Args                map[string]any `mapstructure:"args"`
+}
+
+// QueueArgs devuelve los argumentos de la queue incluyendo el dead letter exchange, el ttl y la longitud maxima
+func (q *QueueTopologyOptions) QueueArgs() map[string]any {
+	args := map[string]any{}
+	for key, value := range q.Args {
+		args[key] = value
+	}
+
+	if q.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = q.DeadLetterExchange
+	}
+	if q.DeadLetterRoutingKey != "" {
+		args["x-dead-letter-routing-key"] = q.DeadLetterRoutingKey
+	}
+	if q.MessageTtl > 0 {
+		args["x-message-ttl"] = q.MessageTtl.Milliseconds()
+	}
+	if q.MaxLength > 0 {
+		args["x-max-length"] = int64(q.MaxLength)
+	}
+
+	return args
+}
@@ -1,9 +1,13 @@
 package configurations
 
 import (
+	"reflect"
+
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/configurations"
 	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
 	"github.com/samber/lo"
 )
 
@@ -35,6 +39,8 @@ func (r *rabbitMQConfigurationBuilder) AddProducer(
 	producerMessageType types.IMessage,
 	producerBuilderFunc producerConfigurations.RabbitMQProducerConfigurationBuilderFuc,
 ) RabbitMQConfigurationBuilder {
+	registerMessageType(producerMessageType)
+
 	builder := producerConfigurations.NewRabbitMQProducerConfigurationBuilder(producerMessageType)
 	if producerBuilderFunc != nil {
 		producerBuilderFunc(builder)
@@ -49,6 +55,8 @@ func (r *rabbitMQConfigurationBuilder) AddConsumer(
 	consumerMessageType types.IMessage,
 	consumerBuilderFunc consumerConfigurations.RabbitMQConsumerConfigurationBuilderFuc,
 ) RabbitMQConfigurationBuilder {
+	registerMessageType(consumerMessageType)
+
 	builder := consumerConfigurations.NewRabbitMQConsumerConfigurationBuilder(consumerMessageType)
 	if consumerBuilderFunc != nil {
 		consumerBuilderFunc(builder)
@@ -79,3 +87,9 @@ func (r *rabbitMQConfigurationBuilder) Build() *RabbitMQConfiguration {
 
 	return r.rabbitMQConfiguration
 }
+
+// registerMessageType registra el tipo puntero del mensaje en el typemapper para poder deserializarlo por nombre
+// (ej. los mensajes guardados en el outbox o recibidos por el consumidor)
+func registerMessageType(message types.IMessage) {
+	typemapper.RegisterType(reflect.PointerTo(utils.GetMessageBaseReflectType(message)))
+}
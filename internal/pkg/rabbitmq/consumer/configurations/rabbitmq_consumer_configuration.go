@@ -3,13 +3,17 @@ package configurations
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/options"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+
+	"emperror.dev/errors"
 )
 
 type RabbitMQConsumerConfiguration struct {
@@ -27,6 +31,11 @@ type RabbitMQConsumerConfiguration struct {
 	BindingOptions  *options.RabbitMQBindingOptions
 	QueueOptions    *options.RabbitMQQueueOptions
 	ExchangeOptions *options.RabbitMQExchangeOptions
+	// DeadLetterOptions si es nil los mensajes fallidos se devuelven a la queue con un nack
+	DeadLetterOptions *options.RabbitMQDeadLetterOptions
+	RetryPolicy       *consumer2.RetryPolicy
+	// PartitionOptions si es nil todos los workers del consumer leen de una sola queue y no se garantiza el orden
+	PartitionOptions *options.RabbitMQPartitionOptions
 }
 
 func NewDefaultRabbitMQConsumerConfiguration(
@@ -54,5 +63,102 @@ func NewDefaultRabbitMQConsumerConfiguration(
 		},
 		ConsumerMessageType: utils.GetMessageBaseReflectType(messageType),
 		Name:                name,
+		RetryPolicy:         consumer2.NewDefaultRetryPolicy(),
+	}
+}
+
+// GetExchangeName devuelve el exchange del consumer, si no esta configurado se obtiene del tipo del mensaje
+func (c *RabbitMQConsumerConfiguration) GetExchangeName() string {
+	if c.ExchangeOptions != nil && c.ExchangeOptions.Name != "" {
+		return c.ExchangeOptions.Name
 	}
+
+	return utils.GetTopicOrExchangeNameFromType(c.ConsumerMessageType)
+}
+
+// GetRoutingKey devuelve la routing key del binding, si no esta configurada se obtiene del tipo del mensaje
+func (c *RabbitMQConsumerConfiguration) GetRoutingKey() string {
+	if c.BindingOptions != nil && c.BindingOptions.RoutingKey != "" {
+		return c.BindingOptions.RoutingKey
+	}
+
+	return utils.GetRoutingKeyFromType(c.ConsumerMessageType)
+}
+
+// GetQueueName devuelve la queue del consumer, si no esta configurada se obtiene del tipo del mensaje
+func (c *RabbitMQConsumerConfiguration) GetQueueName() string {
+	if c.QueueOptions != nil && c.QueueOptions.Name != "" {
+		return c.QueueOptions.Name
+	}
+
+	return utils.GetQueueNameFromType(c.ConsumerMessageType)
+}
+
+// RetryQueueName devuelve la retry queue con TTL `delay`, sus mensajes expirados vuelven a la queue del consumer
+func (c *RabbitMQConsumerConfiguration) RetryQueueName(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%dms", c.GetQueueName(), delay.Milliseconds())
+}
+
+// DeadLetterNames devuelve el exchange, la queue y la routing key de la dead letter del consumer,
+// los valores vacios de `DeadLetterOptions` se resuelven a partir del exchange y la queue del consumer
+func (c *RabbitMQConsumerConfiguration) DeadLetterNames() (exchange string, queue string, routingKey string) {
+	if c.DeadLetterOptions != nil {
+		exchange = c.DeadLetterOptions.ExchangeName
+		queue = c.DeadLetterOptions.QueueName
+		routingKey = c.DeadLetterOptions.RoutingKey
+	}
+
+	if exchange == "" {
+		exchange = fmt.Sprintf("%s.dlx", c.GetExchangeName())
+	}
+
+	if queue == "" {
+		queue = fmt.Sprintf("%s.dlq", c.GetQueueName())
+	}
+
+	if routingKey == "" {
+		routingKey = queue
+	}
+
+	return exchange, queue, routingKey
+}
+
+// IsPartitioned indica si el consumer reparte sus mensajes en queues particionadas por la partition key
+func (c *RabbitMQConsumerConfiguration) IsPartitioned() bool {
+	return c.PartitionOptions != nil && c.PartitionOptions.Partitions > 0
+}
+
+// Validate rechaza las combinaciones de opciones que el consumer no puede cumplir, con particiones los reintentos deben
+// ser en proceso porque las retry queues devuelven el mensaje a su particion detras de los mensajes mas nuevos de su key
+func (c *RabbitMQConsumerConfiguration) Validate() error {
+	if c.IsPartitioned() && c.RetryPolicy != nil && c.RetryPolicy.DelayedRedelivery {
+		return errors.New(
+			"consumer partitions don't support delayed redelivery, the retry queues break the order of the partition keys",
+		)
+	}
+
+	return nil
+}
+
+// PartitionExchangeName devuelve el exchange de hash consistente que reparte los mensajes entre las particiones
+func (c *RabbitMQConsumerConfiguration) PartitionExchangeName() string {
+	if c.PartitionOptions != nil && c.PartitionOptions.ExchangeName != "" {
+		return c.PartitionOptions.ExchangeName
+	}
+
+	return fmt.Sprintf("%s.partitioned", c.GetQueueName())
+}
+
+// PartitionKeyHeader devuelve el header con el que el exchange calcula la particion de cada mensaje
+func (c *RabbitMQConsumerConfiguration) PartitionKeyHeader() string {
+	if c.PartitionOptions != nil && c.PartitionOptions.PartitionKeyHeader != "" {
+		return c.PartitionOptions.PartitionKeyHeader
+	}
+
+	return messageHeader.PartitionKey
+}
+
+// PartitionQueueName devuelve la queue de la particion `partition`, empezando en cero
+func (c *RabbitMQConsumerConfiguration) PartitionQueueName(partition int) string {
+	return fmt.Sprintf("%s.partition.%d", c.GetQueueName(), partition)
 }
@@ -4,6 +4,7 @@ import (
 	messageConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/options"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
 )
 
@@ -33,6 +34,14 @@ type RabbitMQConsumerConfigurationBuilder interface {
 	WithRoutingKey(routingKey string) RabbitMQConsumerConfigurationBuilder
 	WithBindingArgs(args map[string]any) RabbitMQConsumerConfigurationBuilder
 	WithName(name string) RabbitMQConsumerConfigurationBuilder
+	WithDeadLetter(
+		exchangeName string,
+		queueName string,
+		routingKey string,
+	) RabbitMQConsumerConfigurationBuilder
+	WithRetryPolicy(retryPolicy *messageConsumer.RetryPolicy) RabbitMQConsumerConfigurationBuilder
+	WithPartitions(partitions int) RabbitMQConsumerConfigurationBuilder
+	WithPartitionOptions(partitionOptions *options.RabbitMQPartitionOptions) RabbitMQConsumerConfigurationBuilder
 	Build() *RabbitMQConsumerConfiguration
 }
 
@@ -208,6 +217,46 @@ func (b *rabbitMQConsumerConfigurationBuilder) WithBindingArgs(
 	return b
 }
 
+// WithDeadLetter habilita la dead letter queue, los nombres vacios toman los valores por defecto
+func (b *rabbitMQConsumerConfigurationBuilder) WithDeadLetter(
+	exchangeName string,
+	queueName string,
+	routingKey string,
+) RabbitMQConsumerConfigurationBuilder {
+	b.rabbitmqConsumerConfigurations.DeadLetterOptions = &options.RabbitMQDeadLetterOptions{
+		ExchangeName: exchangeName,
+		QueueName:    queueName,
+		RoutingKey:   routingKey,
+	}
+	return b
+}
+
+// WithRetryPolicy reemplaza la politica de reintentos por defecto del consumer
+func (b *rabbitMQConsumerConfigurationBuilder) WithRetryPolicy(
+	retryPolicy *messageConsumer.RetryPolicy,
+) RabbitMQConsumerConfigurationBuilder {
+	b.rabbitmqConsumerConfigurations.RetryPolicy = retryPolicy
+	return b
+}
+
+// WithPartitions habilita el consumo ordenado por partition key con `partitions` queues y un worker por queue,
+// no se puede combinar con `RetryPolicy.DelayedRedelivery` porque el orden solo se mantiene con los reintentos en proceso
+func (b *rabbitMQConsumerConfigurationBuilder) WithPartitions(
+	partitions int,
+) RabbitMQConsumerConfigurationBuilder {
+	b.rabbitmqConsumerConfigurations.PartitionOptions = &options.RabbitMQPartitionOptions{
+		Partitions: partitions,
+	}
+	return b
+}
+
+func (b *rabbitMQConsumerConfigurationBuilder) WithPartitionOptions(
+	partitionOptions *options.RabbitMQPartitionOptions,
+) RabbitMQConsumerConfigurationBuilder {
+	b.rabbitmqConsumerConfigurations.PartitionOptions = partitionOptions
+	return b
+}
+
 func (b *rabbitMQConsumerConfigurationBuilder) Build() *RabbitMQConsumerConfiguration {
 	if b.pipelinesBuilder != nil {
 		b.rabbitmqConsumerConfigurations.Pipelines = b.pipelinesBuilder.Build().Pipelines
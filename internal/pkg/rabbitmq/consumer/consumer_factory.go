@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
@@ -16,6 +17,7 @@ type consumerFactory struct {
 	eventSerializer serializer.MessageSerializer // the serializer for the event
 	logger          logger.Logger
 	rabbitmqOptions *config.RabbitmqOptions
+	pipelines       []pipeline.ConsumerPipeline // the pipelines of all the consumers, they wrap the pipelines of each consumer
 }
 
 func NewConsumerFactory(
@@ -23,19 +25,38 @@ func NewConsumerFactory(
 	connection types2.IConnection,
 	eventSerializer serializer.MessageSerializer,
 	logger logger.Logger,
+	pipelines ...pipeline.ConsumerPipeline,
 ) consumercontracts.ConsumerFactory {
-	return &consumerFactory{
+	factory := &consumerFactory{
 		rabbitmqOptions: rabbitmqOptions,
 		logger:          logger,
 		eventSerializer: eventSerializer,
 		connection:      connection,
 	}
+
+	for _, p := range pipelines {
+		if p != nil {
+			factory.pipelines = append(factory.pipelines, p)
+		}
+	}
+
+	return factory
 }
 
 func (c *consumerFactory) CreateConsumer(
 	consumerConfiguration *consumerConfigurations.RabbitMQConsumerConfiguration,
 	isConsumedNotifications ...func(message types.IMessage),
 ) (consumer.Consumer, error) {
+	if consumerConfiguration != nil && len(c.pipelines) > 0 {
+		// a copy, so the configuration of the bus doesn't accumulate the factory pipelines
+		configuration := *consumerConfiguration
+		configuration.Pipelines = append(
+			append([]pipeline.ConsumerPipeline{}, c.pipelines...),
+			consumerConfiguration.Pipelines...,
+		)
+		consumerConfiguration = &configuration
+	}
+
 	return NewRabbitMQConsumer(
 		c.rabbitmqOptions,
 		c.connection,
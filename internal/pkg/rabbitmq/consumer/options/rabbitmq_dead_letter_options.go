@@ -0,0 +1,8 @@
+package options
+
+// RabbitMQDeadLetterOptions configura el exchange y la queue donde terminan los mensajes que agotaron sus reintentos
+type RabbitMQDeadLetterOptions struct {
+	ExchangeName string // si esta vacio se usa `<exchange>.dlx`
+	QueueName    string // si esta vacio se usa `<queue>.dlq`
+	RoutingKey   string // si esta vacio se usa el nombre de la dead letter queue
+}
@@ -0,0 +1,11 @@
+package options
+
+// RabbitMQPartitionOptions reparte los mensajes del consumer en `Partitions` queues con un exchange de hash consistente,
+// los mensajes con la misma partition key llegan siempre a la misma queue y cada queue se procesa en orden por un solo worker.
+// El orden solo se mantiene con los reintentos en proceso, que bloquean la particion mientras se reintenta el mensaje,
+// un mensaje que se reproduce desde la dead letter queue vuelve a su particion detras de los mensajes mas nuevos de su key
+type RabbitMQPartitionOptions struct {
+	Partitions         int
+	PartitionKeyHeader string // si esta vacio se usa el header `partition-key` de la metadata del mensaje
+	ExchangeName       string // si esta vacio se usa `<queue>.partitioned`
+}
@@ -7,11 +7,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
 	consumertracing "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/consumer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
-	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
@@ -25,26 +26,12 @@ import (
 	"github.com/ahmetb/go-linq/v3"
 	"github.com/avast/retry-go"
 	"github.com/rabbitmq/amqp091-go"
+	uuid "github.com/satori/go.uuid"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const (
-	retryAttempts = 3                      // the number of times to retry a message
-	retryDelay    = 300 * time.Millisecond // the delay between retry attempts
-)
-
-// retry options for the consumer
-var retryOptions = []retry.Option{
-	retry.Attempts(retryAttempts),       // the number of times to retry a message
-	retry.Delay(retryDelay),             // the delay between retry attempts
-	retry.DelayType(retry.BackOffDelay), // the delay type
-	retry.LastErrorOnly(true),           // only return the last error
-	retry.RetryIf(func(err error) bool {
-		return err != nil
-	}), // retry if the error is not nil
-}
-
 type rabbitMQConsumer struct {
 	rabbitmqConsumerOptions *configurations.RabbitMQConsumerConfiguration
 	connection              types.IConnection
@@ -60,8 +47,21 @@ type rabbitMQConsumer struct {
 	pipelines               []pipeline.ConsumerPipeline
 	handlersLock            sync.Mutex // lock to protect the handlers
 	isConsumedNotifications []func(message messagingTypes.IMessage)
+	deadLetterExchange      string // the resolved dead letter exchange, empty when dead lettering is disabled
+	deadLetterRoutingKey    string // the resolved dead letter routing key
+	retryPolicy             *consumer.RetryPolicy
+	consumerTags            []string // the tags of the consumers of the queue, or of the partition queues
+	queues                  []string // the consumed queues, the queue of the consumer or its partition queues
+	workersPerQueue         int
+	consumeCtx              context.Context // the context of the start, the consumer uses it again when it resumes
+	paused                  bool
+	drainState              consumer.DrainState
+	unacked                 map[uint64]amqp091.Delivery // the deliveries that are not acknowledged yet, they are requeued if the drain times out
+	drainLock               sync.Mutex                  // lock to protect the drain state and the unacknowledged deliveries
 }
 
+const defaultDrainTimeout = 10 * time.Second
+
 // NewRabbitMQConsumer create a new generic RabbitMQ consumer
 func NewRabbitMQConsumer(
 	rabbitmqOptions *config.RabbitmqOptions,
@@ -81,14 +81,29 @@ func NewRabbitMQConsumer(
 		)
 	}
 
+	if err := consumerConfiguration.Validate(); err != nil {
+		return nil, err
+	}
+
 	// create a channel to limit the number of concurrent deliveries
 	// this is used to prevent the consumer from processing more than the concurrency limit
 	// ej: if the concurrency limit is 10, and the consumer is processing 10 messages,
 	// the consumer will not process any more messages until the 10 messages are processed
+	concurrencyLimit := consumerConfiguration.ConcurrencyLimit
+	if consumerConfiguration.IsPartitioned() {
+		// one worker for each partition queue
+		concurrencyLimit = consumerConfiguration.PartitionOptions.Partitions
+	}
 	deliveryRoutines := make(
 		chan struct{},
-		consumerConfiguration.ConcurrencyLimit,
+		concurrencyLimit,
 	)
+
+	retryPolicy := consumerConfiguration.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = consumer.NewDefaultRetryPolicy()
+	}
+
 	cons := &rabbitMQConsumer{
 		messageSerializer:       messageSerializer,
 		rabbitmqOptions:         rabbitmqOptions,
@@ -99,6 +114,9 @@ func NewRabbitMQConsumer(
 		connection:              connection,
 		handlers:                consumerConfiguration.Handlers,
 		pipelines:               consumerConfiguration.Pipelines,
+		retryPolicy:             retryPolicy,
+		drainState:              consumer.DrainStateRunning,
+		unacked:                 map[uint64]amqp091.Delivery{},
 	}
 
 	cons.isConsumedNotifications = isConsumedNotifications
@@ -120,32 +138,9 @@ func (r *rabbitMQConsumer) Start(ctx context.Context) error {
 		return errors.New("connection is required")
 	}
 
-	var exchange string
-	var queue string
-	var routingKey string
-
-	// if the exchange name is not empty, use the exchange name
-	if r.rabbitmqConsumerOptions.ExchangeOptions.Name != "" {
-		exchange = r.rabbitmqConsumerOptions.ExchangeOptions.Name
-	} else { // if the exchange name is empty, use the topic or exchange name from the consumer message type
-		exchange = utils.GetTopicOrExchangeNameFromType(r.rabbitmqConsumerOptions.ConsumerMessageType)
-	}
-
-	// if the routing key is not empty, use the routing key
-	if r.rabbitmqConsumerOptions.BindingOptions.RoutingKey != "" {
-		routingKey = r.rabbitmqConsumerOptions.BindingOptions.RoutingKey
-	} else {
-		// if the routing key is empty, use the routing key from the consumer message type
-		routingKey = utils.GetRoutingKeyFromType(r.rabbitmqConsumerOptions.ConsumerMessageType)
-	}
-
-	// if the queue name is not empty, use the queue name
-	if r.rabbitmqConsumerOptions.QueueOptions.Name != "" {
-		queue = r.rabbitmqConsumerOptions.QueueOptions.Name
-	} else {
-		// if the queue name is empty, use the queue name from the consumer message type
-		queue = utils.GetQueueNameFromType(r.rabbitmqConsumerOptions.ConsumerMessageType)
-	}
+	exchange := r.rabbitmqConsumerOptions.GetExchangeName()
+	routingKey := r.rabbitmqConsumerOptions.GetRoutingKey()
+	queue := r.rabbitmqConsumerOptions.GetQueueName()
 
 	// 2. Prepare the consumer and topology declaration
 	r.reConsumeOnDropConnection(ctx)
@@ -157,9 +152,20 @@ func (r *rabbitMQConsumer) Start(ctx context.Context) error {
 	}
 	r.channel = ch
 
+	r.drainLock.Lock()
+	r.drainState = consumer.DrainStateRunning
+	r.unacked = map[uint64]amqp091.Delivery{}
+	r.drainLock.Unlock()
+
+	// in partitioned mode each partition queue has a single worker, so the messages of a partition are handled in order
+	workersPerQueue := r.rabbitmqConsumerOptions.ConcurrencyLimit
+	if r.rabbitmqConsumerOptions.IsPartitioned() {
+		workersPerQueue = 1
+	}
+
 	// The prefetch count tells the Rabbit connection how many messages to retrieve from the server per request.
 	// ej: if the concurrency limit is 10, and the prefetch count is 10, the consumer will retrieve 100 messages from the server per request.
-	prefetchCount := r.rabbitmqConsumerOptions.ConcurrencyLimit * r.rabbitmqConsumerOptions.PrefetchCount
+	prefetchCount := workersPerQueue * r.rabbitmqConsumerOptions.PrefetchCount
 	if err := r.channel.Qos(prefetchCount, 0, false); err != nil {
 		return err
 	}
@@ -179,114 +185,315 @@ func (r *rabbitMQConsumer) Start(ctx context.Context) error {
 		return err
 	}
 
-	// declare the queue
-	// if the queue already exists, it will not be declared again
-	_, err = r.channel.QueueDeclare(
-		queue, // queue name
-		r.rabbitmqConsumerOptions.QueueOptions.Durable,    // durable
-		r.rabbitmqConsumerOptions.QueueOptions.AutoDelete, // auto delete
-		r.rabbitmqConsumerOptions.QueueOptions.Exclusive,  // exclusive
-		r.rabbitmqConsumerOptions.NoWait,                  // no wait
-		r.rabbitmqConsumerOptions.QueueOptions.Args,
-	) // arguments
-	if err != nil {
-		return err
+	queues := []string{queue}
+	if r.rabbitmqConsumerOptions.IsPartitioned() {
+		queues, err = r.declarePartitions(exchange, routingKey)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := r.declareQueue(queue, routingKey, exchange, r.rabbitmqConsumerOptions.QueueOptions.Args); err != nil {
+			return err
+		}
 	}
 
-	// bind the queue to the exchange
-	err = r.channel.QueueBind(
-		queue, // queue name
-		routingKey,
-		exchange,
-		r.rabbitmqConsumerOptions.NoWait,
-		r.rabbitmqConsumerOptions.BindingOptions.Args, // arguments
-	)
-	if err != nil {
-		return err
+	// declare the dead letter exchange and queue for the messages that exhaust their retries
+	if r.rabbitmqConsumerOptions.DeadLetterOptions != nil {
+		if err := r.declareDeadLetter(); err != nil {
+			return err
+		}
 	}
 
-	// 3. Consume the messages
-	// consume the messages
-	msgs, err := r.channel.Consume(
-		queue,                                // queue name
-		r.rabbitmqConsumerOptions.ConsumerId, // consumer id
-		r.rabbitmqConsumerOptions.AutoAck,    // When autoAck (also known as noAck) is true, the server will acknowledge deliveries to this consumer prior to writing the delivery to the network. When autoAck is true, the consumer should not call Delivery.Ack.
-		r.rabbitmqConsumerOptions.QueueOptions.Exclusive,
-		r.rabbitmqConsumerOptions.NoLocal,
-		r.rabbitmqConsumerOptions.NoWait,
-		nil,
-	)
-	if err != nil {
+	// the copies of the failed deliveries and of the scheduled messages that arrive early are published with publisher confirms,
+	// the original delivery is acknowledged only after the broker confirms its copy
+	if err := r.channel.Confirm(false); err != nil {
 		return err
 	}
 
+	// declare the retry queues, the failed messages wait in them until their TTL expires instead of blocking a consumer goroutine
+	if r.retryPolicy.DelayedRedelivery {
+		if err := r.declareRetryQueues(queue); err != nil {
+			return err
+		}
+	}
+
+	r.queues = queues
+	r.workersPerQueue = workersPerQueue
+	r.consumeCtx = ctx
+
+	r.drainLock.Lock()
+	r.consumerTags = nil
+	r.paused = false
+	r.drainLock.Unlock()
+
+	return r.consume(ctx)
+}
+
+// consume subscribes the workers to the consumer queues, it is called on start and when the consumer resumes
+func (r *rabbitMQConsumer) consume(ctx context.Context) error {
 	// This channel will receive a notification when a channel closed event happens.
 	// https://github.com/streadway/amqp/blob/v1.0.0/channel.go#L447
 	// https://github.com/rabbitmq/amqp091-go/blob/main/example_client_test.go#L75
 	chClosedCh := make(chan *amqp091.Error, 1)
-	ch.NotifyClose(chClosedCh)
-
-	// https://blog.boot.dev/golang/connecting-to-rabbitmq-in-golang/
-	// https://levelup.gitconnected.com/connecting-a-service-in-golang-to-a-rabbitmq-server-835294d8c914
-	// https://medium.com/@dhanushgopinath/automatically-recovering-rabbitmq-connections-in-go-applications-7795a605ca59
-	// https://github.com/rabbitmq/amqp091-go/blob/main/_examples/pubsub/pubsub.go
-	for i := 0; i < r.rabbitmqConsumerOptions.ConcurrencyLimit; i++ {
-		r.logger.Infof("Processing messages on thread %d", i)
-		go func() {
-			for { // infinite loop to consume messages
-				select {
-				// if the context is done, shutdown the consumer
-				case <-ctx.Done():
-					r.logger.Info("shutting down consumer")
-					return
-				case amqErr := <-chClosedCh:
-					// This case handles the event of closed channel e.g. abnormal shutdown
-					r.logger.Errorf("AMQP Channel closed due to: %s", amqErr)
+	r.channel.NotifyClose(chClosedCh)
 
-					// Re-set channel to receive notifications
-					chClosedCh = make(chan *amqp091.Error, 1)
-					ch.NotifyClose(chClosedCh)
+	for partition, consumeQueue := range r.queues {
+		// the consumer tag is required for canceling the consumer on stop, so an empty consumer id gets a unique tag
+		consumerTag := r.rabbitmqConsumerOptions.ConsumerId
+		if consumerTag == "" {
+			consumerTag = fmt.Sprintf("%s.%s", r.GetName(), uuid.NewV4().String())
+		}
+		if r.rabbitmqConsumerOptions.IsPartitioned() {
+			consumerTag = fmt.Sprintf("%s.partition.%d", consumerTag, partition)
+		}
 
-					// if the channel is closed, shutdown the consumer
-					if amqErr != nil {
-						r.logger.Errorf("AMQP Channel closed due to: %s", amqErr)
-					}
+		// 3. Consume the messages
+		// consume the messages
+		msgs, err := r.channel.Consume(
+			consumeQueue,                      // queue name
+			consumerTag,                       // consumer id
+			r.rabbitmqConsumerOptions.AutoAck, // When autoAck (also known as noAck) is true, the server will acknowledge deliveries to this consumer prior to writing the delivery to the network. When autoAck is true, the consumer should not call Delivery.Ack.
+			r.rabbitmqConsumerOptions.QueueOptions.Exclusive,
+			r.rabbitmqConsumerOptions.NoLocal,
+			r.rabbitmqConsumerOptions.NoWait,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
 
-				case msg, ok := <-msgs:
-					if !ok {
-						r.logger.Info("consumer connection dropped")
+		r.drainLock.Lock()
+		r.consumerTags = append(r.consumerTags, consumerTag)
+		r.drainLock.Unlock()
+
+		// https://blog.boot.dev/golang/connecting-to-rabbitmq-in-golang/
+		// https://levelup.gitconnected.com/connecting-a-service-in-golang-to-a-rabbitmq-server-835294d8c914
+		// https://medium.com/@dhanushgopinath/automatically-recovering-rabbitmq-connections-in-go-applications-7795a605ca59
+		// https://github.com/rabbitmq/amqp091-go/blob/main/_examples/pubsub/pubsub.go
+		for i := 0; i < r.workersPerQueue; i++ {
+			r.logger.Infof("Processing messages of queue %s on thread %d", consumeQueue, i)
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+
+				for { // infinite loop to consume messages
+					select {
+					// if the context is done, shutdown the consumer
+					case <-ctx.Done():
+						r.logger.Info("shutting down consumer")
 						return
-					}
+					case amqErr := <-chClosedCh:
+						// This case handles the event of closed channel e.g. abnormal shutdown
+						r.logger.Errorf("AMQP Channel closed due to: %s", amqErr)
 
-					// handle received message and remove message form queue with a manual ack
-					r.handleReceived(ctx, msg)
+						// Re-set channel to receive notifications
+						chClosedCh = make(chan *amqp091.Error, 1)
+						r.channel.NotifyClose(chClosedCh)
 
+						// if the channel is closed, shutdown the consumer
+						if amqErr != nil {
+							r.logger.Errorf("AMQP Channel closed due to: %s", amqErr)
+						}
+
+					case msg, ok := <-msgs:
+						if !ok {
+							r.logger.Info("consumer connection dropped")
+							return
+						}
+
+						// the deliveries prefetched before the cancel are returned to the queue without handling them
+						if r.DrainState() != consumer.DrainStateRunning || r.IsPaused() {
+							r.requeue(msg)
+							continue
+						}
+
+						// handle received message and remove message form queue with a manual ack
+						r.handleReceived(ctx, consumeQueue, msg)
+
+					}
 				}
-			}
-		}()
+			}()
+		}
 	}
 
 	return nil
 }
 
+// Stop drains the consumer, it cancels the consumer tags so the broker stops sending messages, waits for the in-flight
+// handlers until the drain timeout and returns the deliveries that are still unacknowledged to the queue
 func (r *rabbitMQConsumer) Stop() error {
-	// cancel the arrival of new messages
-	if r.channel != nil && !r.channel.IsClosed() {
-		r.channel.Cancel(r.rabbitmqConsumerOptions.ConsumerId, false)
+	r.setDrainState(consumer.DrainStateDraining)
+
+	// 1. cancel the arrival of new messages, the workers finish when the broker closes their deliveries channel
+	r.cancelConsumerTags()
+
+	// 2. wait for the in-flight handlers
+	drainTimeout := defaultDrainTimeout
+	if r.rabbitmqOptions != nil && r.rabbitmqOptions.DrainTimeout > 0 {
+		drainTimeout = r.rabbitmqOptions.DrainTimeout
 	}
 
-	// 2. Esperar a que todas las goroutines terminen
-	// wait all goroutines to finish
-	r.wg.Wait() // block until the wait group is zero
+	drained := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		r.logger.Infof("consumer %s drained its in-flight messages", r.GetName())
+	case <-time.After(drainTimeout):
+		r.logger.Warnf(
+			"consumer %s didn't drain its in-flight messages in %s, requeueing the unacknowledged messages",
+			r.GetName(),
+			drainTimeout,
+		)
+	}
+
+	// 3. the handlers that didn't finish lose their deliveries, the broker delivers them again to other consumer
+	r.requeueUnacked()
 
 	// close the channel (no need defer)
 	if r.channel != nil && !r.channel.IsClosed() {
-		r.channel.Close()
+		if err := r.channel.Close(); err != nil {
+			r.logger.Errorf("error in closing the channel of consumer %s: %v", r.GetName(), err)
+		}
+	}
+
+	r.setDrainState(consumer.DrainStateDrained)
+
+	return nil
+}
+
+// Pause cancels the subscriptions of the consumer, the channel and the in-flight handlers are kept
+func (r *rabbitMQConsumer) Pause() error {
+	r.drainLock.Lock()
+	if r.paused {
+		r.drainLock.Unlock()
+		return nil
+	}
+	r.paused = true
+	r.drainLock.Unlock()
+
+	r.cancelConsumerTags()
+	r.logger.Infof("consumer %s paused", r.GetName())
+
+	return nil
+}
+
+// Resume subscribes the consumer to its queues again, a stopped consumer is not resumed
+func (r *rabbitMQConsumer) Resume() error {
+	r.drainLock.Lock()
+	if !r.paused || r.drainState != consumer.DrainStateRunning {
+		r.drainLock.Unlock()
+		return nil
 	}
+	r.paused = false
+	r.drainLock.Unlock()
+
+	// the consumer subscribes again when the connection is reconnected
+	if r.channel == nil || r.channel.IsClosed() {
+		return rabbitmqErrors.ErrDisconnected
+	}
+
+	if err := r.consume(r.consumeCtx); err != nil {
+		return err
+	}
+	r.logger.Infof("consumer %s resumed", r.GetName())
 
 	return nil
 }
 
+func (r *rabbitMQConsumer) IsPaused() bool {
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	return r.paused
+}
+
+func (r *rabbitMQConsumer) cancelConsumerTags() {
+	r.drainLock.Lock()
+	consumerTags := r.consumerTags
+	r.consumerTags = nil
+	r.drainLock.Unlock()
+
+	if r.channel == nil || r.channel.IsClosed() {
+		return
+	}
+
+	for _, consumerTag := range consumerTags {
+		if err := r.channel.Cancel(consumerTag, false); err != nil {
+			r.logger.Errorf("error in canceling consumer tag `%s`: %v", consumerTag, err)
+		}
+	}
+}
+
+// DrainState returns the drain state of the consumer, it is reported by the rabbitmq consumers health check
+func (r *rabbitMQConsumer) DrainState() consumer.DrainState {
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	return r.drainState
+}
+
+func (r *rabbitMQConsumer) setDrainState(state consumer.DrainState) {
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	r.drainState = state
+}
+
+// track registers the delivery as unacknowledged until its handler settles it
+func (r *rabbitMQConsumer) track(delivery amqp091.Delivery) {
+	if r.rabbitmqConsumerOptions.AutoAck {
+		return
+	}
+
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	r.unacked[delivery.DeliveryTag] = delivery
+}
+
+// settle removes the delivery from the unacknowledged deliveries, it returns false when the drain already requeued it,
+// in that case the delivery must not be acknowledged again
+func (r *rabbitMQConsumer) settle(delivery amqp091.Delivery) bool {
+	if r.rabbitmqConsumerOptions.AutoAck {
+		return true
+	}
+
+	r.drainLock.Lock()
+	defer r.drainLock.Unlock()
+
+	if _, ok := r.unacked[delivery.DeliveryTag]; !ok {
+		return false
+	}
+	delete(r.unacked, delivery.DeliveryTag)
+
+	return true
+}
+
+func (r *rabbitMQConsumer) requeue(delivery amqp091.Delivery) {
+	if r.rabbitmqConsumerOptions.AutoAck {
+		return
+	}
+
+	if err := delivery.Nack(false, true); err != nil {
+		r.logger.Errorf("error in requeueing message with id `%s`: %v", delivery.MessageId, err)
+	}
+}
+
+func (r *rabbitMQConsumer) requeueUnacked() {
+	r.drainLock.Lock()
+	unacked := r.unacked
+	r.unacked = map[uint64]amqp091.Delivery{}
+	r.drainLock.Unlock()
+
+	for _, delivery := range unacked {
+		r.requeue(delivery)
+	}
+}
+
 func (r *rabbitMQConsumer) ConnectionHandler(handler consumer.ConsumerHandler) {
 	r.handlersLock.Lock()
 	defer r.handlersLock.Unlock()
@@ -338,6 +545,7 @@ func (r *rabbitMQConsumer) reConsumeOnDropConnection(ctx context.Context) {
 // it creates a consume context, creates a consumer span, and handles the message
 func (r *rabbitMQConsumer) handleReceived(
 	ctx context.Context,
+	queue string, // the queue of the delivery, the consumer queue or one of its partition queues
 	delivery amqp091.Delivery,
 ) {
 	// for ensuring our handlers execute completely after shutdown
@@ -366,22 +574,37 @@ func (r *rabbitMQConsumer) handleReceived(
 		consumerTraceOption,
 	)
 
+	r.track(delivery)
+
+	// a scheduled message arrives early when its delay is not a delay tier, it waits the remainder in a staging queue
+	// of the consumed queue so the other queues bound to the exchange don't receive it again
+	if remaining := time.Until(messageHeader.GetMessageScheduled(meta)); remaining > types.ScheduledDeliveryTolerance {
+		r.restage(ctx, queue, delivery, remaining, beforeConsumeSpan)
+		return
+	}
+
 	consumeContext, err := r.createConsumeContext(delivery)
 	if err != nil {
 		r.logger.Error(
 			consumertracing.FinishConsumerSpan(beforeConsumeSpan, err),
 		)
+		// the message can't be read by any handler, retrying it would only block the queue
+		r.rejectUnreadable(ctx, queue, delivery, err)
 		return
 	}
 
 	var ack func()
 	var nack func()
+	var reject func()
 
 	// if auto-ack is enabled we should not call Ack method manually it could create some unexpected errors
 	if r.rabbitmqConsumerOptions.AutoAck == false {
 		// ack is a function that acknowledges the message
 		// ack is used to tell the broker that the message was processed correctly
 		ack = func() {
+			if !r.settle(delivery) {
+				return
+			}
 			if err := delivery.Ack(false); err != nil {
 				r.logger.Error(
 					"error sending ACK to RabbitMQ consumer: %v",
@@ -402,6 +625,9 @@ func (r *rabbitMQConsumer) handleReceived(
 		// nack is a function that negatively acknowledges the message
 		// nack is used to tell the broker that the message was not processed correctly
 		nack = func() {
+			if !r.settle(delivery) {
+				return
+			}
 			if err := delivery.Nack(false, true); err != nil {
 				r.logger.Error(
 					"error in sending Nack to RabbitMQ consumer: %v",
@@ -411,9 +637,87 @@ func (r *rabbitMQConsumer) handleReceived(
 			}
 			_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, nil)
 		}
+
+		// reject is a function that removes the message from the queue without requeueing it
+		reject = func() {
+			if !r.settle(delivery) {
+				return
+			}
+			if err := delivery.Nack(false, false); err != nil {
+				r.logger.Error(
+					"error in sending Nack to RabbitMQ consumer: %v",
+					consumertracing.FinishConsumerSpan(beforeConsumeSpan, err),
+				)
+				return
+			}
+			_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, nil)
+		}
 	}
 
-	r.handle(ctx, ack, nack, consumeContext)
+	// the attempts of the previous deliveries through the retry queues
+	previousAttempts := retryAttempt(delivery)
+
+	// deadLetter is a function that sends the failed message to the dead letter exchange with the error details in its headers
+	var deadLetter func(err error, attempts uint)
+	if r.deadLetterExchange != "" {
+		deadLetter = func(handleErr error, attempts uint) {
+			if err := r.publishToDeadLetter(ctx, delivery, queue, handleErr, previousAttempts+attempts); err != nil {
+				r.logger.Errorf(
+					"error in sending message with id `%s` to the dead letter exchange: %v",
+					delivery.MessageId,
+					err,
+				)
+				// the message is requeued, so it is not lost if the dead letter publish fails
+				if nack != nil {
+					nack()
+				}
+				return
+			}
+
+			if ack != nil {
+				ack()
+			}
+		}
+	}
+
+	// redeliver is a function that sends the failed message to the retry queue of its next attempt, it returns false if the message should not be retried
+	var redeliver func(err error) bool
+	if r.retryPolicy.DelayedRedelivery {
+		redeliver = func(handleErr error) bool {
+			nextAttempt := previousAttempts + 1
+			if !r.retryPolicy.IsRetryable(handleErr) || nextAttempt >= r.retryPolicy.MaxAttempts {
+				return false
+			}
+
+			if err := r.publishToRetryQueue(ctx, delivery, nextAttempt); err != nil {
+				r.logger.Errorf(
+					"error in sending message with id `%s` to the retry queue: %v",
+					delivery.MessageId,
+					err,
+				)
+				if nack != nil {
+					nack()
+				}
+				return true
+			}
+
+			if r.rabbitmqConsumerOptions.AutoAck == false && r.settle(delivery) {
+				if err := delivery.Ack(false); err != nil {
+					r.logger.Errorf("error sending ACK to RabbitMQ consumer: %v", err)
+				}
+			}
+			_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, handleErr)
+
+			return true
+		}
+	}
+
+	ctx = consumer.ContextWithConsumeInfo(
+		ctx,
+		consumer.ConsumeInfo{ConsumerName: r.GetName(), Attempt: previousAttempts, Consumer: r},
+	)
+
+	r.handle(ctx, ack, nack, reject, deadLetter, redeliver, consumeContext)
 }
 
 // handle is a function that handles the message
@@ -423,16 +727,64 @@ func (r *rabbitMQConsumer) handle(
 	ctx context.Context,
 	ack func(), // the function to acknowledge the message
 	nack func(), // the function to negatively acknowledge the message
+	reject func(), // the function to remove the message from the queue without requeueing it
+	deadLetter func(err error, attempts uint), // the function to send the message to the dead letter queue, nil if it is disabled
+	redeliver func(err error) bool, // the function to send the message to a retry queue, nil if delayed redelivery is disabled
 	messageConsumeContext messagingTypes.MessageConsumeContext, // the context of the message
 ) {
 	var err error
+	var attempts uint
 	for _, handler := range r.handlers {
-		err = r.runHandlersWithRetry(ctx, handler, messageConsumeContext)
+		attempts, err = r.runHandlersWithRetry(ctx, handler, messageConsumeContext)
 		if err != nil {
 			break // break the loop if there is an error
 		}
 	}
 
+	// the messages that fail while the consumer is paused, e.g. by an open circuit breaker, are requeued without counting the attempt
+	if err != nil && r.IsPaused() && nack != nil && r.rabbitmqConsumerOptions.AutoAck == false {
+		r.logger.Infof(
+			"[rabbitMQConsumer.Handle] message with id `%s` failed while the consumer is paused, it is requeued",
+			messageConsumeContext.MessageId(),
+		)
+		nack()
+
+		return
+	}
+
+	if err != nil && redeliver != nil && redeliver(err) {
+		r.logger.Infof(
+			"[rabbitMQConsumer.Handle] message with id `%s` failed, it was sent to a retry queue",
+			messageConsumeContext.MessageId(),
+		)
+
+		return
+	}
+
+	if err != nil && deadLetter != nil {
+		r.logger.Errorf(
+			"[rabbitMQConsumer.Handle] message with id `%s` exhausted its retries after %d attempts, sending to the dead letter queue",
+			messageConsumeContext.MessageId(),
+			attempts,
+		)
+		deadLetter(err, attempts)
+
+		return
+	}
+
+	// with delayed redelivery a requeued message comes back to the consumer right away, so without a dead letter queue
+	// the message that can't be retried anymore is discarded instead of cycling between the queue and the consumer
+	if err != nil && redeliver != nil && reject != nil && r.rabbitmqConsumerOptions.AutoAck == false {
+		r.logger.Errorf(
+			"[rabbitMQConsumer.Handle] message with id `%s` can't be retried anymore and there is no dead letter queue, it is discarded: %v",
+			messageConsumeContext.MessageId(),
+			err,
+		)
+		reject()
+
+		return
+	}
+
 	if err != nil {
 		r.logger.Error(
 			"[rabbitMQConsumer.Handle] error in handling consume message of RabbitmqMQ, prepare for nacking message",
@@ -449,9 +801,20 @@ func (r *rabbitMQConsumer) runHandlersWithRetry(
 	ctx context.Context,
 	handler consumer.ConsumerHandler,
 	messageConsumeContext messagingTypes.MessageConsumeContext,
-) error {
+) (uint, error) {
+	var attempts uint
+
 	// if there is an error, it will retry the handler
+	info, _ := consumer.ConsumeInfoFromContext(ctx)
+	info.HandlerName = consumer.GetHandlerName(handler)
+	previousAttempts := info.Attempt
+
 	err := retry.Do(func() error {
+		// the in-process retries count as attempts of the message too
+		info.Attempt = previousAttempts + attempts
+		ctx := consumer.ContextWithConsumeInfo(ctx, info)
+		attempts++
+
 		var lastHandler pipeline.ConsumerHandlerFunc
 
 		if r.pipelines != nil && len(r.pipelines) > 0 {
@@ -507,25 +870,448 @@ func (r *rabbitMQConsumer) runHandlersWithRetry(
 			}
 		}
 		return nil
-	}, append(retryOptions, retry.Context(ctx))...)
+	}, r.retryOptions(ctx)...)
+
+	return attempts, err
+}
+
+// retryOptions builds the in-process retry options from the retry policy,
+// with delayed redelivery the handler runs once and the retries are done through the retry queues
+func (r *rabbitMQConsumer) retryOptions(ctx context.Context) []retry.Option {
+	attempts := r.retryPolicy.MaxAttempts
+	if attempts == 0 || r.retryPolicy.DelayedRedelivery {
+		attempts = 1
+	}
+
+	return []retry.Option{
+		retry.Attempts(attempts), // the number of times to run a message handler
+		retry.DelayType(func(n uint, _ error, _ *retry.Config) time.Duration {
+			return r.retryPolicy.DelayWithJitter(n + 1)
+		}), // exponential backoff with jitter
+		retry.LastErrorOnly(true), // only return the last error
+		retry.RetryIf(func(err error) bool {
+			// non retryable errors, e.g. validation errors, and an open circuit fail on the first attempt, and a paused consumer doesn't retry
+			return r.retryPolicy.IsRetryable(err) && !circuitbreaker.IsCircuitOpen(err) && !r.IsPaused()
+		}),
+		retry.Context(ctx),
+	}
+}
+
+// declareQueue declares the consumer queue and binds it to the exchange with the routing key
+func (r *rabbitMQConsumer) declareQueue(queue string, routingKey string, exchange string, args amqp091.Table) error {
+	// declare the queue
+	// if the queue already exists, it will not be declared again
+	_, err := r.channel.QueueDeclare(
+		queue, // queue name
+		r.rabbitmqConsumerOptions.QueueOptions.Durable,    // durable
+		r.rabbitmqConsumerOptions.QueueOptions.AutoDelete, // auto delete
+		r.rabbitmqConsumerOptions.QueueOptions.Exclusive,  // exclusive
+		r.rabbitmqConsumerOptions.NoWait,                  // no wait
+		args,
+	) // arguments
+	if err != nil {
+		return err
+	}
+
+	// bind the queue to the exchange
+	return r.channel.QueueBind(
+		queue, // queue name
+		routingKey,
+		exchange,
+		r.rabbitmqConsumerOptions.NoWait,
+		r.rabbitmqConsumerOptions.BindingOptions.Args, // arguments
+	)
+}
+
+// declarePartitions declares a consistent hash exchange bound to the consumer exchange, and the partition queues bound to it
+// with the same weight, the exchange hashes the partition key header, so the messages of a key always go to the same queue
+func (r *rabbitMQConsumer) declarePartitions(exchange string, routingKey string) ([]string, error) {
+	partitionExchange := r.rabbitmqConsumerOptions.PartitionExchangeName()
+
+	err := r.channel.ExchangeDeclare(
+		partitionExchange,
+		string(types.ExchangeConsistentHash),
+		true,  // durable
+		false, // auto delete
+		false, // internal
+		r.rabbitmqConsumerOptions.NoWait,
+		amqp091.Table{"hash-header": r.rabbitmqConsumerOptions.PartitionKeyHeader()},
+	)
+	if err != nil {
+		return nil, partitionExchangeError(partitionExchange, err)
+	}
+
+	err = r.channel.ExchangeBind(
+		partitionExchange,
+		routingKey,
+		exchange,
+		r.rabbitmqConsumerOptions.NoWait,
+		r.rabbitmqConsumerOptions.BindingOptions.Args,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// with a single active consumer the other instances of the service are standby consumers of the partition,
+	// so the order of the partition is kept when the service is scaled out
+	args := amqp091.Table{"x-single-active-consumer": true}
+	for k, v := range r.rabbitmqConsumerOptions.QueueOptions.Args {
+		args[k] = v
+	}
+
+	var queues []string
+	for partition := 0; partition < r.rabbitmqConsumerOptions.PartitionOptions.Partitions; partition++ {
+		queue := r.rabbitmqConsumerOptions.PartitionQueueName(partition)
+
+		// the routing key of a consistent hash binding is the weight of the queue
+		if err := r.declareQueue(queue, "1", partitionExchange, args); err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+
+	return queues, nil
+}
+
+// partitionExchangeError names the missing plugin when the broker rejects the consistent hash exchange type
+func partitionExchangeError(partitionExchange string, err error) error {
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.CommandInvalid {
+		return errors.WrapIff(
+			err,
+			"the partition exchange `%s` requires the `rabbitmq_consistent_hash_exchange` plugin, enable it with "+
+				"`rabbitmq-plugins enable rabbitmq_consistent_hash_exchange` or disable the partitions of the consumer",
+			partitionExchange,
+		)
+	}
 
 	return err
 }
 
+// declareDeadLetter declares the dead letter exchange and queue, and binds them with the dead letter routing key
+func (r *rabbitMQConsumer) declareDeadLetter() error {
+	deadLetterExchange, deadLetterQueue, deadLetterRoutingKey := r.rabbitmqConsumerOptions.DeadLetterNames()
+
+	err := r.channel.ExchangeDeclare(
+		deadLetterExchange,
+		string(types.ExchangeDirect),
+		true,  // durable
+		false, // auto delete
+		false, // internal
+		r.rabbitmqConsumerOptions.NoWait,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.channel.QueueDeclare(
+		deadLetterQueue,
+		true,  // durable
+		false, // auto delete
+		false, // exclusive
+		r.rabbitmqConsumerOptions.NoWait,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = r.channel.QueueBind(
+		deadLetterQueue,
+		deadLetterRoutingKey,
+		deadLetterExchange,
+		r.rabbitmqConsumerOptions.NoWait,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	r.deadLetterExchange = deadLetterExchange
+	r.deadLetterRoutingKey = deadLetterRoutingKey
+
+	return nil
+}
+
+// declareRetryQueues declares a queue for each delay of the retry policy, the expired messages are dead lettered
+// through the default exchange back to the consumer queue
+func (r *rabbitMQConsumer) declareRetryQueues(queue string) error {
+	for _, delay := range r.retryPolicy.Delays() {
+		// the partitioned consumers reject the delayed redelivery, so the expired messages always go back to the consumer queue
+		args := amqp091.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		}
+
+		_, err := r.channel.QueueDeclare(
+			r.rabbitmqConsumerOptions.RetryQueueName(delay),
+			true,  // durable
+			false, // auto delete
+			false, // exclusive
+			r.rabbitmqConsumerOptions.NoWait,
+			args,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publishToRetryQueue publishes a copy of the failed delivery to the retry queue of the attempt delay through the default exchange
+func (r *rabbitMQConsumer) publishToRetryQueue(
+	ctx context.Context,
+	delivery amqp091.Delivery,
+	attempt uint,
+) error {
+	headers := amqp091.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	headers[types.RetryAttemptHeader] = int64(attempt)
+
+	return r.publishConfirmed(
+		ctx,
+		"",
+		r.rabbitmqConsumerOptions.RetryQueueName(r.retryPolicy.Delay(attempt)),
+		amqp091.Publishing{
+			Headers:       headers,
+			ContentType:   delivery.ContentType,
+			DeliveryMode:  amqp091.Persistent,
+			MessageId:     delivery.MessageId,
+			CorrelationId: delivery.CorrelationId,
+			Timestamp:     delivery.Timestamp,
+			Type:          delivery.Type,
+			Body:          delivery.Body,
+		},
+	)
+}
+
+// rejectUnreadable settles a delivery that can't be turned into a consume context, it is sent to the dead letter exchange
+// when the consumer has one, otherwise it is removed from the queue without requeueing it
+func (r *rabbitMQConsumer) rejectUnreadable(
+	ctx context.Context,
+	queue string,
+	delivery amqp091.Delivery,
+	readErr error,
+) {
+	if r.rabbitmqConsumerOptions.AutoAck || !r.settle(delivery) {
+		return
+	}
+
+	if r.deadLetterExchange != "" {
+		if err := r.publishToDeadLetter(ctx, delivery, queue, readErr, retryAttempt(delivery)); err != nil {
+			r.logger.Errorf(
+				"error in sending message with id `%s` to the dead letter exchange: %v",
+				delivery.MessageId,
+				err,
+			)
+			// the message is requeued, so it is not lost if the dead letter publish fails
+			if err := delivery.Nack(false, true); err != nil {
+				r.logger.Errorf("error in sending Nack to RabbitMQ consumer: %v", err)
+			}
+			return
+		}
+
+		if err := delivery.Ack(false); err != nil {
+			r.logger.Errorf("error sending ACK to RabbitMQ consumer: %v", err)
+		}
+		return
+	}
+
+	if err := delivery.Nack(false, false); err != nil {
+		r.logger.Errorf("error in sending Nack to RabbitMQ consumer: %v", err)
+	}
+}
+
+// restage publishes a copy of the early scheduled delivery to the staging queue of the largest delay tier that fits
+// the remaining delay, the staging queue is declared on demand and the broker removes it when it is not used
+func (r *rabbitMQConsumer) restage(
+	ctx context.Context,
+	queue string,
+	delivery amqp091.Delivery,
+	remaining time.Duration,
+	span trace.Span,
+) {
+	delayMs := types.DelayTier(remaining).Milliseconds()
+	stagingQueue := scheduledStagingQueueName(queue, delayMs)
+
+	_, err := r.channel.QueueDeclare(
+		stagingQueue,
+		true,  // durable
+		false, // auto delete
+		false, // exclusive
+		false, // no wait
+		amqp091.Table{
+			"x-message-ttl":             delayMs,
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+			"x-expires":                 delayMs + types.DelayStagingQueueExpiration.Milliseconds(),
+		},
+	)
+	if err == nil {
+		err = r.publishConfirmed(ctx, "", stagingQueue, amqp091.Publishing{
+			Headers:       delivery.Headers,
+			ContentType:   delivery.ContentType,
+			DeliveryMode:  amqp091.Persistent,
+			MessageId:     delivery.MessageId,
+			CorrelationId: delivery.CorrelationId,
+			Timestamp:     delivery.Timestamp,
+			Type:          delivery.Type,
+			ReplyTo:       delivery.ReplyTo,
+			Body:          delivery.Body,
+		})
+	}
+
+	if err != nil {
+		r.logger.Errorf(
+			"error in staging again the scheduled message with id `%s` for %s: %v",
+			delivery.MessageId,
+			remaining,
+			err,
+		)
+		// the message is requeued, so it is not lost if the staging fails
+		if r.settle(delivery) && !r.rabbitmqConsumerOptions.AutoAck {
+			if err := delivery.Nack(false, true); err != nil {
+				r.logger.Errorf("error in sending Nack to RabbitMQ consumer: %v", err)
+			}
+		}
+		_ = consumertracing.FinishConsumerSpan(span, err)
+
+		return
+	}
+
+	if r.settle(delivery) && !r.rabbitmqConsumerOptions.AutoAck {
+		if err := delivery.Ack(false); err != nil {
+			r.logger.Errorf("error sending ACK to RabbitMQ consumer: %v", err)
+		}
+	}
+	_ = consumertracing.FinishConsumerSpan(span, nil)
+}
+
+// scheduledStagingQueueName is the staging queue of the consumed queue for the delay tier, its expired messages go back to the queue
+func scheduledStagingQueueName(queue string, delayMs int64) string {
+	return fmt.Sprintf("%s.scheduled.%dms", queue, delayMs)
+}
+
+// retryAttempt returns the number of retries already done for the delivery through the retry queues
+func retryAttempt(delivery amqp091.Delivery) uint {
+	switch attempt := delivery.Headers[types.RetryAttemptHeader].(type) {
+	case int64:
+		return uint(attempt)
+	case int32:
+		return uint(attempt)
+	case int:
+		return uint(attempt)
+	}
+
+	return 0
+}
+
+// publishToDeadLetter publishes a copy of the failed delivery to the dead letter exchange,
+// the queue of the delivery is kept in the headers for replaying the message later only to this consumer
+func (r *rabbitMQConsumer) publishToDeadLetter(
+	ctx context.Context,
+	delivery amqp091.Delivery,
+	queue string,
+	handleErr error,
+	attempts uint,
+) error {
+	headers := amqp091.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+
+	headers[types.DeadLetterExceptionMessageHeader] = handleErr.Error()
+	headers[types.DeadLetterExceptionStackTraceHeader] = errorUtils.ErrorsWithStack(handleErr)
+	headers[types.DeadLetterConsumerNameHeader] = r.rabbitmqConsumerOptions.Name
+	headers[types.DeadLetterAttemptsHeader] = int64(attempts)
+	headers[types.DeadLetterOriginalExchangeHeader] = delivery.Exchange
+	headers[types.DeadLetterOriginalRoutingKeyHeader] = delivery.RoutingKey
+	headers[types.DeadLetterOriginalQueueHeader] = queue
+	headers[types.DeadLetterFailedAtHeader] = time.Now().UTC()
+
+	return r.publishConfirmed(
+		ctx,
+		r.deadLetterExchange,
+		r.deadLetterRoutingKey,
+		amqp091.Publishing{
+			Headers:       headers,
+			ContentType:   delivery.ContentType,
+			DeliveryMode:  amqp091.Persistent,
+			MessageId:     delivery.MessageId,
+			CorrelationId: delivery.CorrelationId,
+			Timestamp:     delivery.Timestamp,
+			Type:          delivery.Type,
+			Body:          delivery.Body,
+		},
+	)
+}
+
+// publishConfirmed publishes a copy of a delivery and waits for the broker confirmation, so the caller acknowledges
+// the original delivery only when the broker has stored its copy
+func (r *rabbitMQConsumer) publishConfirmed(
+	ctx context.Context,
+	exchange string,
+	routingKey string,
+	publishing amqp091.Publishing,
+) error {
+	confirmation, err := r.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		routingKey,
+		false,
+		false,
+		publishing,
+	)
+	if err != nil {
+		return err
+	}
+	if confirmation == nil {
+		return errors.New("channel is not in confirm mode")
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errors.Errorf("the broker didn't confirm the message published to exchange `%s`", exchange)
+	}
+
+	return nil
+}
+
 func (r *rabbitMQConsumer) createConsumeContext(
 	delivery amqp091.Delivery,
 ) (messagingTypes.MessageConsumeContext, error) {
-	message := r.deserializeData(
+	message, err := r.deserializeData(
 		delivery.ContentType,
 		delivery.Type,
 		delivery.Body,
 	)
+	if err != nil {
+		return nil, err
+	}
 
 	var meta metadata.Metadata
 	if delivery.Headers != nil {
 		meta = metadata.MapToMetadata(delivery.Headers)
 	}
 
+	// the reply-to property is also set by clients which don't send the metadata headers
+	if delivery.ReplyTo != "" {
+		if meta == nil {
+			meta = metadata.New()
+		}
+		if messageHeader.GetReplyTo(meta) == "" {
+			messageHeader.SetReplyTo(meta, delivery.ReplyTo)
+		}
+	}
+
 	consumeContext := messagingTypes.NewMessageConsumeContext(
 		message,
 		meta,
@@ -535,6 +1321,7 @@ func (r *rabbitMQConsumer) createConsumeContext(
 		delivery.DeliveryTag,
 		delivery.MessageId,
 		delivery.CorrelationId,
+		delivery.Body,
 	)
 	return consumeContext, nil
 }
@@ -546,38 +1333,32 @@ func (r *rabbitMQConsumer) deserializeData(
 	contentType string,
 	eventType string,
 	body []byte,
-) messagingTypes.IMessage {
+) (messagingTypes.IMessage, error) {
 	if contentType == "" {
 		contentType = "application/json"
 	}
 
-	if body == nil || len(body) == 0 {
-		r.logger.Error("message body is nil or empty in the consumer")
-		return nil
+	if len(body) == 0 {
+		return nil, errors.New("message body is nil or empty in the consumer")
 	}
 
-	if contentType == "application/json" {
-		// r.rabbitmqConsumerOptions.ConsumerMessageType --> actual type
-		// deserialize, err := r.messageSerializer.DeserializeType(body, r.rabbitmqConsumerOptions.ConsumerMessageType, contentType)
-		deserialize, err := r.messageSerializer.Deserialize(
-			body,
+	// the message serializer picks the deserializer of the content type, e.g. json or protobuf
+	// r.rabbitmqConsumerOptions.ConsumerMessageType --> actual type
+	// deserialize, err := r.messageSerializer.DeserializeType(body, r.rabbitmqConsumerOptions.ConsumerMessageType, contentType)
+	deserialize, err := r.messageSerializer.Deserialize(
+		body,
+		eventType,
+		contentType,
+	) // or this to explicit type deserialization
+	if err != nil {
+		return nil, errors.WrapIff(
+			err,
+			"error in deserilizng of type '%s' in the consumer",
 			eventType,
-			contentType,
-		) // or this to explicit type deserialization
-		if err != nil {
-			r.logger.Errorf(
-				fmt.Sprintf(
-					"error in deserilizng of type '%s' in the consumer",
-					eventType,
-				),
-			)
-			return nil
-		}
-
-		return deserialize
+		)
 	}
 
-	return nil
+	return deserialize, nil
 }
 
 // reversOrder is a function that reverses the order of the pipelines
@@ -0,0 +1,239 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/options"
+
+	"emperror.dev/errors"
+	"github.com/rabbitmq/amqp091-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingHandler struct {
+	calls int
+	err   error
+}
+
+func (h *failingHandler) Handle(context.Context, messagingTypes.MessageConsumeContext) error {
+	h.calls++
+	if h.err != nil {
+		return h.err
+	}
+
+	return errors.New("handler failed")
+}
+
+// settlements registra como se resolvio la entrega del mensaje
+type settlements struct {
+	acks, nacks, rejects, deadLetters int
+}
+
+func newTestConsumer(handler consumer.ConsumerHandler, retryPolicy *consumer.RetryPolicy) *rabbitMQConsumer {
+	return &rabbitMQConsumer{
+		rabbitmqConsumerOptions: &configurations.RabbitMQConsumerConfiguration{},
+		logger:                  defaultlogger.GetLogger(),
+		handlers:                []consumer.ConsumerHandler{handler},
+		retryPolicy:             retryPolicy,
+		drainState:              consumer.DrainStateRunning,
+		unacked:                 map[uint64]amqp091.Delivery{},
+	}
+}
+
+func newTestConsumeContext() messagingTypes.MessageConsumeContext {
+	message := messagingTypes.NewMessage(uuid.NewV4().String())
+
+	return messagingTypes.NewMessageConsumeContext(
+		message,
+		metadata.Metadata{},
+		"application/json",
+		"testMessage",
+		time.Now(),
+		1,
+		message.GeMessageId(),
+		"",
+		nil,
+	)
+}
+
+func Test_Handle_Rejects_Exhausted_Delayed_Redelivery_Without_Dead_Letter(t *testing.T) {
+	handler := &failingHandler{}
+	c := newTestConsumer(handler, &consumer.RetryPolicy{MaxAttempts: 3, DelayedRedelivery: true})
+	s := &settlements{}
+
+	c.handle(
+		context.Background(),
+		func() { s.acks++ },
+		func() { s.nacks++ },
+		func() { s.rejects++ },
+		nil,
+		func(error) bool { return false },
+		newTestConsumeContext(),
+	)
+
+	assert.Equal(t, 1, handler.calls)
+	assert.Equal(t, settlements{rejects: 1}, *s)
+}
+
+func Test_Handle_Sends_Exhausted_Delayed_Redelivery_To_Dead_Letter(t *testing.T) {
+	c := newTestConsumer(&failingHandler{}, &consumer.RetryPolicy{MaxAttempts: 3, DelayedRedelivery: true})
+	s := &settlements{}
+
+	c.handle(
+		context.Background(),
+		func() { s.acks++ },
+		func() { s.nacks++ },
+		func() { s.rejects++ },
+		func(error, uint) { s.deadLetters++ },
+		func(error) bool { return false },
+		newTestConsumeContext(),
+	)
+
+	assert.Equal(t, settlements{deadLetters: 1}, *s)
+}
+
+func Test_Handle_Leaves_Redelivered_Message_To_Retry_Queue(t *testing.T) {
+	c := newTestConsumer(&failingHandler{}, &consumer.RetryPolicy{MaxAttempts: 3, DelayedRedelivery: true})
+	s := &settlements{}
+
+	c.handle(
+		context.Background(),
+		func() { s.acks++ },
+		func() { s.nacks++ },
+		func() { s.rejects++ },
+		nil,
+		func(error) bool { return true },
+		newTestConsumeContext(),
+	)
+
+	assert.Equal(t, settlements{}, *s)
+}
+
+func Test_Handle_Requeues_Failed_Message_Without_Delayed_Redelivery(t *testing.T) {
+	handler := &failingHandler{}
+	c := newTestConsumer(handler, &consumer.RetryPolicy{MaxAttempts: 2})
+	s := &settlements{}
+
+	c.handle(
+		context.Background(),
+		func() { s.acks++ },
+		func() { s.nacks++ },
+		func() { s.rejects++ },
+		nil,
+		nil,
+		newTestConsumeContext(),
+	)
+
+	assert.Equal(t, 2, handler.calls)
+	assert.Equal(t, settlements{nacks: 1}, *s)
+}
+
+func Test_Handle_Does_Not_Retry_With_The_Circuit_Open(t *testing.T) {
+	handler := &failingHandler{err: errors.WithMessage(circuitbreaker.ErrCircuitOpen, "message of consumer")}
+	c := newTestConsumer(handler, &consumer.RetryPolicy{MaxAttempts: 3})
+	s := &settlements{}
+
+	c.handle(
+		context.Background(),
+		func() { s.acks++ },
+		func() { s.nacks++ },
+		func() { s.rejects++ },
+		nil,
+		nil,
+		newTestConsumeContext(),
+	)
+
+	assert.Equal(t, 1, handler.calls)
+	assert.Equal(t, settlements{nacks: 1}, *s)
+}
+
+func Test_PartitionExchangeError_Names_The_Missing_Plugin(t *testing.T) {
+	brokerErr := &amqp091.Error{
+		Code:   amqp091.CommandInvalid,
+		Reason: "COMMAND_INVALID - invalid exchange type 'x-consistent-hash'",
+	}
+
+	err := partitionExchangeError("products.partitions", brokerErr)
+
+	assert.ErrorContains(t, err, "rabbitmq-plugins enable rabbitmq_consistent_hash_exchange")
+	assert.ErrorIs(t, err, brokerErr)
+}
+
+func Test_PartitionExchangeError_Keeps_Other_Errors(t *testing.T) {
+	brokerErr := &amqp091.Error{Code: amqp091.AccessRefused, Reason: "ACCESS_REFUSED"}
+
+	assert.Equal(t, error(brokerErr), partitionExchangeError("products.partitions", brokerErr))
+}
+
+func Test_NewRabbitMQConsumer_Rejects_Partitions_With_Delayed_Redelivery(t *testing.T) {
+	configuration := configurations.NewDefaultRabbitMQConsumerConfiguration(messagingTypes.NewMessage(uuid.NewV4().String()))
+	configuration.PartitionOptions = &options.RabbitMQPartitionOptions{Partitions: 4}
+	configuration.RetryPolicy = &consumer.RetryPolicy{MaxAttempts: 3, DelayedRedelivery: true}
+
+	_, err := NewRabbitMQConsumer(nil, nil, configuration, nil, defaultlogger.GetLogger())
+	assert.ErrorContains(t, err, "delayed redelivery")
+
+	// the in-process retries keep the order of the partition
+	configuration.RetryPolicy.DelayedRedelivery = false
+	_, err = NewRabbitMQConsumer(nil, nil, configuration, nil, defaultlogger.GetLogger())
+	assert.NoError(t, err)
+}
+
+// fakeAcknowledger registra como el consumer resolvio la entrega en el broker
+type fakeAcknowledger struct {
+	acks, nacks, rejects int
+}
+
+func (a *fakeAcknowledger) Ack(uint64, bool) error {
+	a.acks++
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	if requeue {
+		a.nacks++
+	} else {
+		a.rejects++
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(_ uint64, requeue bool) error {
+	return a.Nack(0, false, requeue)
+}
+
+func Test_HandleReceived_Rejects_Unreadable_Message_Without_Dead_Letter(t *testing.T) {
+	handler := &failingHandler{}
+	c := newTestConsumer(handler, consumer.NewDefaultRetryPolicy())
+	c.rabbitmqConsumerOptions = configurations.NewDefaultRabbitMQConsumerConfiguration(
+		messagingTypes.NewMessage(uuid.NewV4().String()),
+	)
+	c.messageSerializer = json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer())
+	c.deliveryRoutines = make(chan struct{}, 1)
+
+	acknowledger := &fakeAcknowledger{}
+	delivery := amqp091.Delivery{
+		Acknowledger: acknowledger,
+		DeliveryTag:  1,
+		ContentType:  "application/json",
+		Type:         "unknownMessage",
+		Body:         []byte("not json"),
+	}
+
+	c.handleReceived(context.Background(), c.rabbitmqConsumerOptions.GetQueueName(), delivery)
+
+	assert.Equal(t, 0, handler.calls)
+	assert.Equal(t, fakeAcknowledger{rejects: 1}, *acknowledger)
+	// the drain doesn't requeue the rejected delivery again
+	assert.Empty(t, c.unacked)
+}
@@ -0,0 +1,174 @@
+package dlq
+
+import (
+	"net/http"
+
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultListLimit = 50
+
+type listMessagesRequestDto struct {
+	Queue string `param:"queue"`
+	Limit int    `query:"limit"`
+}
+
+type getMessageRequestDto struct {
+	Queue     string `param:"queue"`
+	MessageId string `param:"messageId"`
+	Limit     int    `query:"limit"`
+}
+
+// messagesRequestDto si `MessageIds` esta vacio la operacion aplica a todos los mensajes de la queue
+type messagesRequestDto struct {
+	Queue      string   `param:"queue"`
+	MessageIds []string `json:"messageIds"`
+}
+
+type messagesResponseDto struct {
+	Queue string `json:"queue"`
+	Count int    `json:"count"`
+}
+
+type deadLetterEndpoints struct {
+	manager DeadLetterManager
+}
+
+func newDeadLetterEndpoints(manager DeadLetterManager) *deadLetterEndpoints {
+	return &deadLetterEndpoints{manager: manager}
+}
+
+// MapEndpoints mapea los endpoints de administracion de las dead letter queues
+func (ep *deadLetterEndpoints) MapEndpoints(group *echo.Group) {
+	group.GET("", ep.queues())
+	group.GET("/:queue/messages", ep.listMessages())
+	group.GET("/:queue/messages/:messageId", ep.getMessage())
+	group.POST("/:queue/replay", ep.replay())
+	group.POST("/:queue/purge", ep.purge())
+}
+
+// Queues
+// @Tags Admin
+// @Summary Get dead letter queues
+// @Description Get the dead letter queues of the configured consumers
+// @Produce json
+// @Success 200 {array} string
+// @Router /admin/dlq [get]
+func (ep *deadLetterEndpoints) queues() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, ep.manager.Queues())
+	}
+}
+
+// ListMessages
+// @Tags Admin
+// @Summary List dead letter messages
+// @Description List the messages of a dead letter queue with their error headers, messages are not removed
+// @Produce json
+// @Param queue path string true "Dead letter queue name"
+// @Param limit query int false "Max number of messages"
+// @Success 200 {array} DeadLetterMessage
+// @Router /admin/dlq/{queue}/messages [get]
+func (ep *deadLetterEndpoints) listMessages() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := &listMessagesRequestDto{}
+		if err := c.Bind(request); err != nil {
+			return customErrors.NewBadRequestErrorWrap(err, "error in the binding request")
+		}
+
+		if request.Limit <= 0 {
+			request.Limit = defaultListLimit
+		}
+
+		messages, err := ep.manager.ListMessages(c.Request().Context(), request.Queue, request.Limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, messages)
+	}
+}
+
+// GetMessage
+// @Tags Admin
+// @Summary Get dead letter message
+// @Description Peek a message of a dead letter queue by its id, the message is not removed
+// @Produce json
+// @Param queue path string true "Dead letter queue name"
+// @Param messageId path string true "Message ID"
+// @Param limit query int false "Max number of messages to search"
+// @Success 200 {object} DeadLetterMessage
+// @Router /admin/dlq/{queue}/messages/{messageId} [get]
+func (ep *deadLetterEndpoints) getMessage() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := &getMessageRequestDto{}
+		if err := c.Bind(request); err != nil {
+			return customErrors.NewBadRequestErrorWrap(err, "error in the binding request")
+		}
+
+		if request.Limit <= 0 {
+			request.Limit = defaultListLimit
+		}
+
+		message, err := ep.manager.GetMessage(c.Request().Context(), request.Queue, request.MessageId, request.Limit)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, message)
+	}
+}
+
+// Replay
+// @Tags Admin
+// @Summary Replay dead letter messages
+// @Description Replay the selected messages, or all of them if no ids are sent, to their original exchange and routing key
+// @Accept json
+// @Produce json
+// @Param queue path string true "Dead letter queue name"
+// @Param messagesRequestDto body messagesRequestDto false "Message ids"
+// @Success 200 {object} messagesResponseDto
+// @Router /admin/dlq/{queue}/replay [post]
+func (ep *deadLetterEndpoints) replay() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := &messagesRequestDto{}
+		if err := c.Bind(request); err != nil {
+			return customErrors.NewBadRequestErrorWrap(err, "error in the binding request")
+		}
+
+		count, err := ep.manager.Replay(c.Request().Context(), request.Queue, request.MessageIds)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, &messagesResponseDto{Queue: request.Queue, Count: count})
+	}
+}
+
+// Purge
+// @Tags Admin
+// @Summary Purge dead letter messages
+// @Description Remove the selected messages, or all of them if no ids are sent, from a dead letter queue
+// @Accept json
+// @Produce json
+// @Param queue path string true "Dead letter queue name"
+// @Param messagesRequestDto body messagesRequestDto false "Message ids"
+// @Success 200 {object} messagesResponseDto
+// @Router /admin/dlq/{queue}/purge [post]
+func (ep *deadLetterEndpoints) purge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := &messagesRequestDto{}
+		if err := c.Bind(request); err != nil {
+			return customErrors.NewBadRequestErrorWrap(err, "error in the binding request")
+		}
+
+		count, err := ep.manager.Purge(c.Request().Context(), request.Queue, request.MessageIds)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, &messagesResponseDto{Queue: request.Queue, Count: count})
+	}
+}
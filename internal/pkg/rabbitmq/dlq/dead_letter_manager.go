@@ -0,0 +1,362 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/configurations"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/rabbitmqErrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+
+	"emperror.dev/errors"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/samber/lo"
+)
+
+// headers agregados por el consumer en los reintentos y al enviar el mensaje a la dead letter queue, se eliminan al re-enviar el mensaje
+var deadLetterHeaders = []string{ //nolint:gochecknoglobals
+	types.RetryAttemptHeader,
+	types.DeadLetterExceptionMessageHeader,
+	types.DeadLetterExceptionStackTraceHeader,
+	types.DeadLetterConsumerNameHeader,
+	types.DeadLetterAttemptsHeader,
+	types.DeadLetterOriginalExchangeHeader,
+	types.DeadLetterOriginalRoutingKeyHeader,
+	types.DeadLetterOriginalQueueHeader,
+	types.DeadLetterFailedAtHeader,
+}
+
+// DeadLetterManager permite inspeccionar, re-enviar y eliminar los mensajes de las dead letter queues de los consumers
+type DeadLetterManager interface {
+	// Queues devuelve los nombres de las dead letter queues de los consumers configurados
+	Queues() []string
+	// ListMessages devuelve hasta `limit` mensajes de la queue sin eliminarlos, si `limit` es 0 devuelve todos
+	ListMessages(ctx context.Context, queue string, limit int) ([]*DeadLetterMessage, error)
+	// GetMessage busca un mensaje por su id entre los primeros `limit` mensajes de la queue sin eliminarlo,
+	// si `limit` es 0 busca en toda la queue
+	GetMessage(ctx context.Context, queue string, messageId string, limit int) (*DeadLetterMessage, error)
+	// Replay re-publica los mensajes con el producer en el exchange y con la routing key originales,
+	// si `messageIds` esta vacio re-envia todos. Solo se leen los mensajes que estaban en la queue al empezar,
+	// los que vuelven a fallar durante el replay quedan en la queue
+	Replay(ctx context.Context, queue string, messageIds []string) (int, error)
+	// Purge elimina los mensajes de la queue, si `messageIds` esta vacio elimina todos
+	Purge(ctx context.Context, queue string, messageIds []string) (int, error)
+}
+
+type deadLetterManager struct {
+	connection        types.IConnection
+	producer          producer.Producer
+	messageSerializer serializer.MessageSerializer
+	logger            logger.Logger
+	// consumers configurations by their dead letter queue name
+	consumersConfigurations map[string]*consumerConfigurations.RabbitMQConsumerConfiguration
+}
+
+func NewDeadLetterManager(
+	connection types.IConnection,
+	rabbitmqConfiguration *configurations.RabbitMQConfiguration,
+	producer producer.Producer,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+) DeadLetterManager {
+	consumersConfigurations := make(map[string]*consumerConfigurations.RabbitMQConsumerConfiguration)
+	for _, consumerConfiguration := range rabbitmqConfiguration.ConsumersConfigurations {
+		if consumerConfiguration.DeadLetterOptions == nil {
+			continue
+		}
+
+		_, queue, _ := consumerConfiguration.DeadLetterNames()
+		consumersConfigurations[queue] = consumerConfiguration
+	}
+
+	return &deadLetterManager{
+		connection:              connection,
+		producer:                producer,
+		messageSerializer:       messageSerializer,
+		logger:                  logger,
+		consumersConfigurations: consumersConfigurations,
+	}
+}
+
+func (m *deadLetterManager) Queues() []string {
+	return lo.Keys(m.consumersConfigurations)
+}
+
+func (m *deadLetterManager) ListMessages(
+	ctx context.Context,
+	queue string,
+	limit int,
+) ([]*DeadLetterMessage, error) {
+	if _, err := m.getConsumerConfiguration(queue); err != nil {
+		return nil, err
+	}
+
+	messages := make([]*DeadLetterMessage, 0)
+	err := m.scan(ctx, queue, limit, func(delivery amqp091.Delivery) (bool, bool, error) {
+		messages = append(messages, newDeadLetterMessage(delivery))
+
+		return false, false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (m *deadLetterManager) GetMessage(
+	ctx context.Context,
+	queue string,
+	messageId string,
+	limit int,
+) (*DeadLetterMessage, error) {
+	if _, err := m.getConsumerConfiguration(queue); err != nil {
+		return nil, err
+	}
+
+	var message *DeadLetterMessage
+	err := m.scan(ctx, queue, limit, func(delivery amqp091.Delivery) (bool, bool, error) {
+		if delivery.MessageId != messageId {
+			return false, false, nil
+		}
+		message = newDeadLetterMessage(delivery)
+
+		return false, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if message == nil {
+		return nil, customErrors.NewNotFoundError(
+			fmt.Sprintf("message with id `%s` not found in the dead letter queue `%s`", messageId, queue),
+		)
+	}
+
+	return message, nil
+}
+
+func (m *deadLetterManager) Replay(
+	ctx context.Context,
+	queue string,
+	messageIds []string,
+) (int, error) {
+	consumerConfiguration, err := m.getConsumerConfiguration(queue)
+	if err != nil {
+		return 0, err
+	}
+
+	// the messages that fail again are dead lettered back to the tail of the queue, so only the messages that were
+	// in the queue before the replay are read
+	count, err := m.messageCount(queue)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	replayed := 0
+	err = m.scan(ctx, queue, count, func(delivery amqp091.Delivery) (bool, bool, error) {
+		if len(messageIds) > 0 && !lo.Contains(messageIds, delivery.MessageId) {
+			return false, false, nil
+		}
+
+		if err := m.replayDelivery(ctx, consumerConfiguration, delivery); err != nil {
+			return false, true, err
+		}
+		replayed++
+
+		return true, false, nil
+	})
+
+	m.logger.Infof("replayed %d messages from the dead letter queue `%s`", replayed, queue)
+
+	return replayed, err
+}
+
+func (m *deadLetterManager) Purge(
+	ctx context.Context,
+	queue string,
+	messageIds []string,
+) (int, error) {
+	if _, err := m.getConsumerConfiguration(queue); err != nil {
+		return 0, err
+	}
+
+	if len(messageIds) == 0 {
+		channel, err := m.channel()
+		if err != nil {
+			return 0, err
+		}
+		defer channel.Close()
+
+		purged, err := channel.QueuePurge(queue, false)
+		if err != nil {
+			return 0, errors.WrapIff(err, "error in purging the dead letter queue `%s`", queue)
+		}
+
+		m.logger.Infof("purged %d messages from the dead letter queue `%s`", purged, queue)
+
+		return purged, nil
+	}
+
+	count, err := m.messageCount(queue)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	purged := 0
+	err = m.scan(ctx, queue, count, func(delivery amqp091.Delivery) (bool, bool, error) {
+		if !lo.Contains(messageIds, delivery.MessageId) {
+			return false, false, nil
+		}
+		purged++
+
+		return true, false, nil
+	})
+
+	m.logger.Infof("purged %d messages from the dead letter queue `%s`", purged, queue)
+
+	return purged, err
+}
+
+// replayDelivery re-publica el mensaje con el producer, que espera la confirmacion del broker antes de que se elimine la
+// copia de la dead letter queue, los headers de los reintentos y del error no se re-envian
+func (m *deadLetterManager) replayDelivery(
+	ctx context.Context,
+	consumerConfiguration *consumerConfigurations.RabbitMQConsumerConfiguration,
+	delivery amqp091.Delivery,
+) error {
+	message, err := m.messageSerializer.Deserialize(delivery.Body, delivery.Type, delivery.ContentType)
+	if err != nil {
+		return errors.WrapIff(err, "error in deserializing dead letter message `%s`", delivery.MessageId)
+	}
+
+	exchange, routingKey := replayDestination(consumerConfiguration, delivery)
+
+	// the metadata is a copy, the headers of the delivery are not changed
+	meta := metadata.FromMetadata(metadata.MapToMetadata(delivery.Headers))
+	for _, header := range deadLetterHeaders {
+		delete(meta, header)
+	}
+	messageHeader.SetRoutingKey(meta, routingKey)
+
+	if err := m.producer.PublishMessageWithTopicName(ctx, message, meta, exchange); err != nil {
+		return errors.WrapIff(err, "error in replaying dead letter message `%s`", delivery.MessageId)
+	}
+
+	return nil
+}
+
+// replayDestination devuelve el exchange y la routing key con los que se publico el mensaje, los mensajes que fallaron
+// despues de pasar por una retry queue llegaron por el default exchange y los de una particion por el exchange de las
+// particiones, en esos casos se usan el exchange del consumer y la routing key de su binding
+func replayDestination(
+	consumerConfiguration *consumerConfigurations.RabbitMQConsumerConfiguration,
+	delivery amqp091.Delivery,
+) (string, string) {
+	exchange := headerString(delivery.Headers, types.DeadLetterOriginalExchangeHeader)
+	routingKey := headerString(delivery.Headers, types.DeadLetterOriginalRoutingKeyHeader)
+
+	switch {
+	case exchange == "":
+		// the routing key of the default exchange is the queue name
+		return consumerConfiguration.GetExchangeName(), consumerConfiguration.GetRoutingKey()
+	case consumerConfiguration.IsPartitioned() && exchange == consumerConfiguration.PartitionExchangeName():
+		// the partition exchange keeps the routing key of the message
+		exchange = consumerConfiguration.GetExchangeName()
+	}
+
+	if routingKey == "" {
+		routingKey = consumerConfiguration.GetRoutingKey()
+	}
+
+	return exchange, routingKey
+}
+
+// scan lee los mensajes de la queue sin confirmarlos, `handle` devuelve si el mensaje se confirma (se elimina de la queue)
+// y si se debe detener la lectura, los mensajes no confirmados vuelven a su posicion en la queue al cerrar el canal
+func (m *deadLetterManager) scan(
+	ctx context.Context,
+	queue string,
+	limit int,
+	handle func(delivery amqp091.Delivery) (ack bool, stop bool, err error),
+) error {
+	channel, err := m.channel()
+	if err != nil {
+		return err
+	}
+	defer channel.Close()
+
+	for count := 0; limit <= 0 || count < limit; count++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delivery, ok, err := channel.Get(queue, false)
+		if err != nil {
+			return errors.WrapIff(err, "error in reading the dead letter queue `%s`", queue)
+		}
+		if !ok {
+			return nil
+		}
+
+		ack, stop, err := handle(delivery)
+		if err != nil {
+			return err
+		}
+
+		if ack {
+			if err := delivery.Ack(false); err != nil {
+				return err
+			}
+		}
+
+		if stop {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// messageCount devuelve el numero de mensajes listos de la queue, limita las lecturas que eliminan mensajes
+// a los que estaban en la queue al empezar
+func (m *deadLetterManager) messageCount(queue string) (int, error) {
+	channel, err := m.channel()
+	if err != nil {
+		return 0, err
+	}
+	defer channel.Close()
+
+	deadLetterQueue, err := channel.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return 0, errors.WrapIff(err, "error in inspecting the dead letter queue `%s`", queue)
+	}
+
+	return deadLetterQueue.Messages, nil
+}
+
+func (m *deadLetterManager) channel() (*amqp091.Channel, error) {
+	if m.connection == nil || m.connection.IsClosed() {
+		return nil, rabbitmqErrors.ErrDisconnected
+	}
+
+	return m.connection.Channel()
+}
+
+func (m *deadLetterManager) getConsumerConfiguration(
+	queue string,
+) (*consumerConfigurations.RabbitMQConsumerConfiguration, error) {
+	consumerConfiguration, ok := m.consumersConfigurations[queue]
+	if !ok {
+		return nil, customErrors.NewNotFoundError(fmt.Sprintf("dead letter queue `%s` not found", queue))
+	}
+
+	return consumerConfiguration, nil
+}
@@ -0,0 +1,188 @@
+package dlq
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/options"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/rabbitmq/amqp091-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type productUpdated struct {
+	*messagingTypes.Message
+	Name string
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&productUpdated{}))
+}
+
+// fakeProducer registra los mensajes re-enviados con su metadata y su exchange
+type fakeProducer struct {
+	messages  []messagingTypes.IMessage
+	metadatas []metadata.Metadata
+	exchanges []string
+}
+
+func (f *fakeProducer) PublishMessage(ctx context.Context, message messagingTypes.IMessage) error {
+	return f.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+func (f *fakeProducer) PublishMessageWithTopicName(
+	_ context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+) error {
+	f.messages = append(f.messages, message)
+	f.metadatas = append(f.metadatas, meta)
+	f.exchanges = append(f.exchanges, topicOrExchangeName)
+
+	return nil
+}
+
+func (f *fakeProducer) PublishMessageWithDelay(
+	context.Context,
+	messagingTypes.IMessage,
+	metadata.Metadata,
+	time.Duration,
+) error {
+	return nil
+}
+
+func (f *fakeProducer) ScheduleMessage(context.Context, messagingTypes.IMessage, metadata.Metadata, time.Time) error {
+	return nil
+}
+
+func (f *fakeProducer) PublishMessages(context.Context, []messagingTypes.IMessage, metadata.Metadata, string) error {
+	return nil
+}
+
+func (f *fakeProducer) IsProduced(func(message messagingTypes.IMessage)) {}
+
+func Test_ReplayDestination(t *testing.T) {
+	consumerConfiguration := &consumerConfigurations.RabbitMQConsumerConfiguration{
+		QueueOptions:    &options.RabbitMQQueueOptions{Name: "products_queue"},
+		ExchangeOptions: &options.RabbitMQExchangeOptions{Name: "products"},
+		BindingOptions:  &options.RabbitMQBindingOptions{RoutingKey: "products.updated"},
+	}
+	partitionedConfiguration := &consumerConfigurations.RabbitMQConsumerConfiguration{
+		QueueOptions:     &options.RabbitMQQueueOptions{Name: "products_queue"},
+		ExchangeOptions:  &options.RabbitMQExchangeOptions{Name: "products"},
+		BindingOptions:   &options.RabbitMQBindingOptions{RoutingKey: "products.updated"},
+		PartitionOptions: &options.RabbitMQPartitionOptions{Partitions: 4},
+	}
+
+	tests := []struct {
+		name               string
+		configuration      *consumerConfigurations.RabbitMQConsumerConfiguration
+		headers            amqp091.Table
+		expectedExchange   string
+		expectedRoutingKey string
+	}{
+		{
+			name:          "the original exchange and routing key",
+			configuration: consumerConfiguration,
+			headers: amqp091.Table{
+				types.DeadLetterOriginalQueueHeader:      "products_queue",
+				types.DeadLetterOriginalExchangeHeader:   "catalogs",
+				types.DeadLetterOriginalRoutingKeyHeader: "products.renamed",
+			},
+			expectedExchange:   "catalogs",
+			expectedRoutingKey: "products.renamed",
+		},
+		{
+			name:          "the consumer exchange and binding for messages redelivered through the default exchange",
+			configuration: consumerConfiguration,
+			headers: amqp091.Table{
+				types.DeadLetterOriginalExchangeHeader:   "",
+				types.DeadLetterOriginalRoutingKeyHeader: "products_queue",
+			},
+			expectedExchange:   "products",
+			expectedRoutingKey: "products.updated",
+		},
+		{
+			name:          "the consumer exchange with the original routing key for messages of a partition",
+			configuration: partitionedConfiguration,
+			headers: amqp091.Table{
+				types.DeadLetterOriginalQueueHeader:      "products_queue.partition.2",
+				types.DeadLetterOriginalExchangeHeader:   "products_queue.partitioned",
+				types.DeadLetterOriginalRoutingKeyHeader: "products.renamed",
+			},
+			expectedExchange:   "products",
+			expectedRoutingKey: "products.renamed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exchange, routingKey := replayDestination(test.configuration, amqp091.Delivery{Headers: test.headers})
+
+			assert.Equal(t, test.expectedExchange, exchange)
+			assert.Equal(t, test.expectedRoutingKey, routingKey)
+		})
+	}
+}
+
+func Test_ReplayDelivery_Publishes_Through_The_Producer_Without_The_Dead_Letter_Headers(t *testing.T) {
+	producer := &fakeProducer{}
+	messageSerializer := json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer())
+	manager := &deadLetterManager{
+		producer:          producer,
+		messageSerializer: messageSerializer,
+		logger:            defaultlogger.GetLogger(),
+	}
+
+	message := &productUpdated{Message: messagingTypes.NewMessage(uuid.NewV4().String()), Name: "coffee"}
+	serialized, err := messageSerializer.Serialize(message)
+	require.NoError(t, err)
+
+	delivery := amqp091.Delivery{
+		MessageId:   message.GeMessageId(),
+		ContentType: serialized.ContentType,
+		Type:        typeregistry.GetTypeName(message),
+		Body:        serialized.Data,
+		Headers: amqp091.Table{
+			messageHeader.CorrelationId:              "correlation",
+			types.RetryAttemptHeader:                 int64(2),
+			types.DeadLetterExceptionMessageHeader:   "handler failed",
+			types.DeadLetterOriginalExchangeHeader:   "products",
+			types.DeadLetterOriginalRoutingKeyHeader: "products.updated",
+			types.DeadLetterOriginalQueueHeader:      "products_queue",
+		},
+	}
+	consumerConfiguration := &consumerConfigurations.RabbitMQConsumerConfiguration{
+		QueueOptions: &options.RabbitMQQueueOptions{Name: "products_queue"},
+	}
+
+	require.NoError(t, manager.replayDelivery(context.Background(), consumerConfiguration, delivery))
+
+	require.Len(t, producer.messages, 1)
+	replayed, ok := producer.messages[0].(*productUpdated)
+	require.True(t, ok)
+	assert.Equal(t, message.GeMessageId(), replayed.GeMessageId())
+	assert.Equal(t, "coffee", replayed.Name)
+	assert.Equal(t, "products", producer.exchanges[0])
+
+	meta := producer.metadatas[0]
+	assert.Equal(t, "products.updated", messageHeader.GetRoutingKey(meta))
+	assert.Equal(t, "correlation", messageHeader.GetCorrelationId(meta))
+	for _, header := range deadLetterHeaders {
+		assert.NotContains(t, meta, header)
+	}
+}
@@ -0,0 +1,71 @@
+package dlq
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetterMessage representa un mensaje de una dead letter queue con los detalles del error que lo envio ahi
+type DeadLetterMessage struct {
+	MessageId           string                 `json:"messageId"`
+	CorrelationId       string                 `json:"correlationId"`
+	MessageType         string                 `json:"messageType"`
+	ContentType         string                 `json:"contentType"`
+	ConsumerName        string                 `json:"consumerName"`
+	ExceptionMessage    string                 `json:"exceptionMessage"`
+	ExceptionStackTrace string                 `json:"exceptionStackTrace"`
+	Attempts            int64                  `json:"attempts"`
+	OriginalExchange    string                 `json:"originalExchange"`
+	OriginalRoutingKey  string                 `json:"originalRoutingKey"`
+	FailedAt            time.Time              `json:"failedAt"`
+	Headers             map[string]interface{} `json:"headers"`
+	Body                string                 `json:"body"`
+}
+
+func newDeadLetterMessage(delivery amqp091.Delivery) *DeadLetterMessage {
+	headers := map[string]interface{}{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+
+	message := &DeadLetterMessage{
+		MessageId:           delivery.MessageId,
+		CorrelationId:       delivery.CorrelationId,
+		MessageType:         delivery.Type,
+		ContentType:         delivery.ContentType,
+		ConsumerName:        headerString(delivery.Headers, types.DeadLetterConsumerNameHeader),
+		ExceptionMessage:    headerString(delivery.Headers, types.DeadLetterExceptionMessageHeader),
+		ExceptionStackTrace: headerString(delivery.Headers, types.DeadLetterExceptionStackTraceHeader),
+		OriginalExchange:    headerString(delivery.Headers, types.DeadLetterOriginalExchangeHeader),
+		OriginalRoutingKey:  headerString(delivery.Headers, types.DeadLetterOriginalRoutingKeyHeader),
+		Headers:             headers,
+		Body:                string(delivery.Body),
+	}
+
+	// amqp decodifica los enteros con el tamaño con el que fueron codificados
+	switch attempts := delivery.Headers[types.DeadLetterAttemptsHeader].(type) {
+	case int64:
+		message.Attempts = attempts
+	case int32:
+		message.Attempts = int64(attempts)
+	case int:
+		message.Attempts = int64(attempts)
+	}
+
+	if failedAt, ok := delivery.Headers[types.DeadLetterFailedAtHeader].(time.Time); ok {
+		message.FailedAt = failedAt
+	}
+
+	return message
+}
+
+func headerString(headers amqp091.Table, key string) string {
+	if value, ok := headers[key].(string); ok {
+		return value
+	}
+
+	return ""
+}
@@ -0,0 +1,37 @@
+package dlq
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/http/customecho/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+)
+
+const adminGroupPath = "/admin/dlq"
+
+// Module provided to fxlog
+// https://uber-go.github.io/fx/modules.html
+var Module = fx.Module( //nolint:gochecknoglobals
+	"rabbitmqdlqfx",
+	fx.Provide(NewDeadLetterManager),
+	fx.Invoke(mapEndpoints),
+)
+
+// mapEndpoints registra los endpoints solo si `EnableAdminApi` esta habilitado, permiten re-enviar y eliminar mensajes
+func mapEndpoints(
+	echoServer contracts.EchoHttpServer,
+	manager DeadLetterManager,
+	logger logger.Logger,
+) {
+	if !echoServer.Cfg().EnableAdminApi {
+		return
+	}
+
+	endpoints := newDeadLetterEndpoints(manager)
+	echoServer.RouteBuilder().RegisterGroupFunc(adminGroupPath, func(group *echo.Group) {
+		endpoints.MapEndpoints(group)
+	})
+
+	logger.Infof("dead letter queues admin api is enabled on `%s`", adminGroupPath)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"emperror.dev/errors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/bus"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
 )
 
@@ -27,3 +29,8 @@ func (g *gormHealthChecker) CheckHealth(ctx context.Context) error {
 func (g gormHealthChecker) GetHealthName() string {
 	return "rabbitmq"
 }
+
+// NewRabbitMQConsumersHealthChecker reporta el drenado de cada consumer de rabbitmq
+func NewRabbitMQConsumersHealthChecker(bus bus.RabbitmqBus) contracts.Health {
+	return consumer.NewConsumersHealthChecker(bus, "rabbitmq_consumers")
+}
@@ -3,6 +3,7 @@ package configurations
 import (
 	"reflect"
 
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/options"
@@ -19,6 +20,8 @@ type RabbitMQProducerConfiguration struct {
 	Expiration          string
 	ReplyTo             string
 	ContentEncoding     string
+	// Pipelines se ejecutan antes de publicar cada mensaje, el primero envuelve a los demas
+	Pipelines []pipeline.ProducerPipeline
 }
 
 func NewDefaultRabbitMQProducerConfiguration(
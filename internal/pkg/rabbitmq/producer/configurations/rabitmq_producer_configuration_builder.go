@@ -1,6 +1,7 @@
 package configurations
 
 import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
 )
@@ -18,6 +19,9 @@ type RabbitMQProducerConfigurationBuilder interface {
 	WithExpiration(expiration string) RabbitMQProducerConfigurationBuilder
 	WithReplyTo(replyTo string) RabbitMQProducerConfigurationBuilder
 	WithContentEncoding(contentEncoding string) RabbitMQProducerConfigurationBuilder
+	WithPipelines(
+		pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc,
+	) RabbitMQProducerConfigurationBuilder
 	Build() *RabbitMQProducerConfiguration
 }
 
@@ -117,6 +121,18 @@ func (b *rabbitMQProducerConfigurationBuilder) WithContentEncoding(
 	return b
 }
 
+func (b *rabbitMQProducerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc,
+) RabbitMQProducerConfigurationBuilder {
+	builder := pipeline.NewProducerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.rabbitmqProducerOptions.Pipelines = builder.Build().Pipelines
+
+	return b
+}
+
 func (b *rabbitMQProducerConfigurationBuilder) Build() *RabbitMQProducerConfiguration {
 	return b.rabbitmqProducerOptions
 }
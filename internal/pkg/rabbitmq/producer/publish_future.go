@@ -0,0 +1,24 @@
+package producer
+
+import (
+	"context"
+)
+
+// publishFuture is completed when the broker confirms the message, or when the channel is closed before the confirmation
+type publishFuture struct {
+	done chan struct{}
+	err  error
+}
+
+func (f *publishFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *publishFuture) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,19 +3,23 @@ package producer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
 	producer3 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
 	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/configurations"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+	errorUtils "github.com/DavidReque/go-food-delivery/internal/pkg/utils/errorutils"
 	uuid "github.com/satori/go.uuid"
 
 	"github.com/rabbitmq/amqp091-go"
@@ -30,6 +34,7 @@ type rabbitMQProducer struct {
 	messageSerializer       serializer.MessageSerializer
 	producersConfigurations map[string]*configurations.RabbitMQProducerConfiguration
 	isProducedNotifications []func(message types2.IMessage)
+	channelPool             types.ChannelPool
 }
 
 func NewRabbitMQProducer(
@@ -48,11 +53,25 @@ func NewRabbitMQProducer(
 		producersConfigurations: rabbitmqProducersConfiguration,
 	}
 
+	// the channels are reused between the publishes instead of opening a channel for each message
+	if connection != nil {
+		p.channelPool = types.NewChannelPool(connection, cfg.ChannelPoolSize)
+	}
+
 	p.isProducedNotifications = isProducedNotifications
 
 	return p, nil
 }
 
+// Close closes the pooled channels and stops watching the reconnections, the connection is closed by its owner
+func (r *rabbitMQProducer) Close() error {
+	if r.channelPool == nil {
+		return nil
+	}
+
+	return r.channelPool.Close()
+}
+
 // IsProduced is a method that adds a notification function to the list of produced notifications
 func (r *rabbitMQProducer) IsProduced(h func(message types2.IMessage)) {
 	r.isProducedNotifications = append(r.isProducedNotifications, h)
@@ -82,6 +101,135 @@ func (r *rabbitMQProducer) PublishMessageWithTopicName(
 	meta metadata.Metadata, // The metadata of the message
 	topicOrExchangeName string, // The topic or exchange name
 ) error {
+	return r.publish(ctx, message, meta, topicOrExchangeName, 0)
+}
+
+// PublishMessageWithDelay is a method that publishes a message that is delivered to the exchange after the delay
+func (r *rabbitMQProducer) PublishMessageWithDelay(
+	ctx context.Context,
+	message types2.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return r.ScheduleMessage(ctx, message, meta, time.Now().Add(delay))
+}
+
+// ScheduleMessage is a method that publishes a message that is delivered to the exchange at the given time,
+// the message waits in the staging queue of the largest delay tier that fits the delay and the consumer stages the remainder
+// again, so the message is handled at most the smallest tier after its time
+func (r *rabbitMQProducer) ScheduleMessage(
+	ctx context.Context,
+	message types2.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	if meta == nil {
+		meta = metadata.New()
+	}
+	messageHeader.SetMessageScheduled(meta, at.UTC())
+
+	return r.publish(ctx, message, meta, "", time.Until(at))
+}
+
+// PublishMessages is a method that publishes the messages on a single channel and waits for the confirmations of all of them
+func (r *rabbitMQProducer) PublishMessages(
+	ctx context.Context,
+	messages []types2.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+) error {
+	channel, err := r.getChannel()
+	if err != nil {
+		return err
+	}
+
+	var publishErr error
+	futures := make([]producer.PublishFuture, 0, len(messages))
+	for _, message := range messages {
+		future, err := r.publishDeferred(ctx, channel, message, meta, topicOrExchangeName, 0)
+		if err != nil {
+			publishErr = err
+			break
+		}
+		futures = append(futures, future)
+	}
+	r.channelPool.Put(channel)
+
+	// the confirmations of the published messages are awaited also when a publish of the batch fails
+	for _, future := range futures {
+		publishErr = errors.Join(publishErr, future.Wait(ctx))
+	}
+
+	return publishErr
+}
+
+// PublishMessageAsync is a method that publishes a message without waiting for its confirmation,
+// the callbacks are called when the broker confirms the message
+func (r *rabbitMQProducer) PublishMessageAsync(
+	ctx context.Context,
+	message types2.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+	callbacks ...func(err error),
+) (producer.PublishFuture, error) {
+	channel, err := r.getChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer r.channelPool.Put(channel)
+
+	return r.publishDeferred(ctx, channel, message, meta, topicOrExchangeName, 0, callbacks...)
+}
+
+// publish publishes the message to the exchange and waits for its confirmation
+func (r *rabbitMQProducer) publish(
+	ctx context.Context,
+	message types2.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+	delay time.Duration,
+) error {
+	channel, err := r.getChannel()
+	if err != nil {
+		return err
+	}
+
+	future, err := r.publishDeferred(ctx, channel, message, meta, topicOrExchangeName, delay)
+	// the channel is returned before the confirmation, the pool doesn't close it while it has pending confirmations
+	r.channelPool.Put(channel)
+	if err != nil {
+		return err
+	}
+
+	return future.Wait(ctx)
+}
+
+// getChannel returns a channel in confirm mode from the pool
+func (r *rabbitMQProducer) getChannel() (*amqp091.Channel, error) {
+	// https://github.com/rabbitmq/rabbitmq-tutorials/blob/master/go/publisher_confirms.go
+	if r.connection == nil {
+		return nil, errors.New("connection is nil")
+	}
+
+	if r.connection.IsClosed() {
+		return nil, errors.New("connection is closed, wait for connection alive")
+	}
+
+	return r.channelPool.Get()
+}
+
+// publishDeferred publishes the message on the channel and returns its pending confirmation, when the delay is positive
+// the message is staged in a queue with a TTL and the broker dead letters it to the exchange when the TTL expires,
+// so no delayed message plugin is required
+func (r *rabbitMQProducer) publishDeferred(
+	ctx context.Context,
+	channel *amqp091.Channel,
+	message types2.IMessage,
+	meta metadata.Metadata,
+	topicOrExchangeName string,
+	delay time.Duration,
+	callbacks ...func(err error),
+) (*publishFuture, error) {
 	producerConfiguration := r.getProducerConfigurationByMessage(message) // Get the producer configuration for the message
 
 	if producerConfiguration == nil { // If the producer configuration is not found, create a default one
@@ -101,14 +249,18 @@ func (r *rabbitMQProducer) PublishMessageWithTopicName(
 		exchange = utils.GetTopicOrExchangeName(message)
 	}
 
-	if producerConfiguration != nil && producerConfiguration.RoutingKey != "" {
+	meta = r.getMetadata(message, meta)
+
+	// the routing key of the metadata, e.g. the original routing key of a replayed dead letter message, is not sent as a header
+	if metaRoutingKey := messageHeader.GetRoutingKey(meta); metaRoutingKey != "" {
+		routingKey = metaRoutingKey
+		delete(meta, messageHeader.RoutingKey)
+	} else if producerConfiguration != nil && producerConfiguration.RoutingKey != "" {
 		routingKey = producerConfiguration.RoutingKey
 	} else {
 		routingKey = utils.GetRoutingKey(message)
 	}
 
-	meta = r.getMetadata(message, meta)
-
 	// Create the producer tracing options
 	producerOptions := &producer3.ProducerTracingOptions{
 		MessagingSystem: "rabbitmq",
@@ -122,102 +274,170 @@ func (r *rabbitMQProducer) PublishMessageWithTopicName(
 	// Serialize the message
 	serializedObj, err := r.messageSerializer.Serialize(message)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// the content type depends on the message type, the consumers pick the deserializer with it
+	messageHeader.SetMessageContentType(meta, serializedObj.ContentType)
+	// the serializer headers, like the key id of an encrypted payload, travel with the message
+	for key, value := range serializedObj.Metadata {
+		meta.Set(key, value)
 	}
 
-	// Start the producer span
-	ctx, beforeProduceSpan := producer3.StartProducerSpan(
-		ctx,
-		message,
-		&meta,
-		string(serializedObj.Data),
-		producerOptions,
-	)
+	var future *publishFuture
 
-	// https://github.com/rabbitmq/rabbitmq-tutorials/blob/master/go/publisher_confirms.go
-	if r.connection == nil {
-		return producer3.FinishProducerSpan(
-			beforeProduceSpan,
-			errors.New("connection is nil"),
+	// the publish is the innermost handler, the pipelines can validate or enrich the message before it is sent
+	publishHandler := func(ctx context.Context) error {
+		// Start the producer span
+		ctx, beforeProduceSpan := producer3.StartProducerSpan(
+			ctx,
+			message,
+			&meta,
+			string(serializedObj.Data),
+			producerOptions,
 		)
-	}
 
-	if r.connection.IsClosed() {
-		return producer3.FinishProducerSpan(
-			beforeProduceSpan,
-			errors.New("connection is closed, wait for connection alive"),
+		// ensure the exchange exists, the responses go to the reply exchange of the requester with its own flags
+		var err error
+		if replyTo := messageHeader.GetInReplyTo(meta); replyTo != "" && replyTo == exchange {
+			err = r.ensureReplyExchange(channel, exchange)
+		} else {
+			err = r.ensureExchange(producerConfiguration, channel, exchange)
+		}
+		if err != nil {
+			return producer3.FinishProducerSpan(beforeProduceSpan, err)
+		}
+
+		headers := metadata.MetadataToMap(meta)
+
+		// the delayed messages are published to the delay exchange, it routes them to the staging queue of their delay tier.
+		// The tier is not longer than the delay, the consumer stages again the messages that arrive before their time
+		publishExchange := exchange
+		if delay > 0 {
+			delayMs := types.DelayTier(delay).Milliseconds()
+			publishExchange, err = r.ensureDelayStaging(channel, exchange, delayMs)
+			if err != nil {
+				return producer3.FinishProducerSpan(beforeProduceSpan, err)
+			}
+
+			headers = amqp091.Table{}
+			for k, v := range meta {
+				headers[k] = v
+			}
+			headers[types.DelayHeader] = delayMs
+		}
+
+		replyTo := producerConfiguration.ReplyTo
+		if messageReplyTo := messageHeader.GetReplyTo(meta); messageReplyTo != "" {
+			replyTo = messageReplyTo
+		}
+
+		// create the properties of the message
+		props := amqp091.Publishing{
+			CorrelationId:   messageHeader.GetCorrelationId(meta),
+			MessageId:       message.GeMessageId(),
+			Timestamp:       time.Now(),
+			Headers:         headers,
+			Type:            typeregistry.GetTypeName(message), // the logical name of the message, or its short type name because in other side package name for type could be different
+			ContentType:     serializedObj.ContentType,
+			Body:            serializedObj.Data,
+			DeliveryMode:    producerConfiguration.DeliveryMode,
+			Expiration:      producerConfiguration.Expiration,
+			AppId:           producerConfiguration.AppId,
+			Priority:        producerConfiguration.Priority,
+			ReplyTo:         replyTo,
+			ContentEncoding: producerConfiguration.ContentEncoding,
+		}
+
+		// publish the message, the pooled channels are in confirm mode so the publish returns its deferred confirmation
+		confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+			ctx,
+			publishExchange,
+			routingKey,
+			true,
+			false,
+			props,
 		)
-	}
+		if err != nil {
+			return producer3.FinishProducerSpan(beforeProduceSpan, err)
+		}
+		if confirmation == nil {
+			return producer3.FinishProducerSpan(beforeProduceSpan, errors.New("channel is not in confirm mode"))
+		}
+		// the pool keeps the channel open until the confirmation is resolved, also when the channel is discarded
+		r.channelPool.Track(channel, confirmation)
+
+		future = &publishFuture{done: make(chan struct{})}
+		go func() {
+			defer errorUtils.HandlePanic()
+
+			// wait for the message to be confirmed
+			var confirmErr error
+			<-confirmation.Done()
+			if !confirmation.Acked() {
+				confirmErr = errors.New("ack not confirmed")
+			}
+			future.err = producer3.FinishProducerSpan(beforeProduceSpan, confirmErr)
+
+			// notify the message has been produced
+			if future.err == nil {
+				for _, notification := range r.isProducedNotifications {
+					if notification != nil {
+						notification(message)
+					}
+				}
+			}
+			close(future.done)
 
-	// create a unique channel on the connection and in the end close the channel
-	channel, err := r.connection.Channel()
-	if err != nil {
-		return producer3.FinishProducerSpan(beforeProduceSpan, err)
-	}
-	defer channel.Close()
+			for _, callback := range callbacks {
+				if callback != nil {
+					callback(future.err)
+				}
+			}
+		}()
 
-	// ensure the exchange exists
-	err = r.ensureExchange(producerConfiguration, channel, exchange)
-	if err != nil {
-		return producer3.FinishProducerSpan(beforeProduceSpan, err)
+		return nil
 	}
 
-	// enable publisher confirms
-	if err := channel.Confirm(false); err != nil {
-		return producer3.FinishProducerSpan(beforeProduceSpan, err)
+	producerContext := &pipeline.ProducerContext{
+		Message:     message,
+		Metadata:    meta,
+		Body:        serializedObj.Data,
+		ContentType: serializedObj.ContentType,
 	}
-
-	confirms := make(chan amqp091.Confirmation)
-	channel.NotifyPublish(confirms)
-
-	// create the properties of the message
-	props := amqp091.Publishing{
-		CorrelationId:   messageHeader.GetCorrelationId(meta),
-		MessageId:       message.GeMessageId(),
-		Timestamp:       time.Now(),
-		Headers:         metadata.MetadataToMap(meta),
-		Type:            message.GetMessageTypeName(), // typeMapper.GetTypeName(message) - just message type name not full type name because in other side package name for type could be different
-		ContentType:     serializedObj.ContentType,
-		Body:            serializedObj.Data,
-		DeliveryMode:    producerConfiguration.DeliveryMode,
-		Expiration:      producerConfiguration.Expiration,
-		AppId:           producerConfiguration.AppId,
-		Priority:        producerConfiguration.Priority,
-		ReplyTo:         producerConfiguration.ReplyTo,
-		ContentEncoding: producerConfiguration.ContentEncoding,
+	if err := r.runPipelines(ctx, producerConfiguration.Pipelines, producerContext, publishHandler); err != nil {
+		return nil, err
 	}
 
-	// publish the message
-	err = channel.PublishWithContext(
-		ctx,
-		exchange,
-		routingKey,
-		true,
-		false,
-		props,
-	)
-	if err != nil {
-		return producer3.FinishProducerSpan(beforeProduceSpan, err)
+	// a pipeline skipped the publish, there is no confirmation to wait for
+	if future == nil {
+		future = &publishFuture{done: make(chan struct{})}
+		close(future.done)
+		for _, callback := range callbacks {
+			if callback != nil {
+				callback(nil)
+			}
+		}
 	}
 
-	// wait for the message to be confirmed
-	if confirmed := <-confirms; !confirmed.Ack {
-		return producer3.FinishProducerSpan(
-			beforeProduceSpan,
-			errors.New("ack not confirmed"),
-		)
-	}
+	return future, nil
+}
 
-	// notify the message has been produced
-	if len(r.isProducedNotifications) > 0 {
-		for _, notification := range r.isProducedNotifications {
-			if notification != nil {
-				notification(message)
-			}
+// runPipelines runs the producer pipelines around the publish, the first pipeline is the outermost one
+func (r *rabbitMQProducer) runPipelines(
+	ctx context.Context,
+	pipelines []pipeline.ProducerPipeline,
+	producerContext *pipeline.ProducerContext,
+	publishHandler pipeline.ProducerHandlerFunc,
+) error {
+	next := publishHandler
+	for i := len(pipelines) - 1; i >= 0; i-- {
+		pipe, inner := pipelines[i], next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, producerContext, inner)
 		}
 	}
 
-	return producer3.FinishProducerSpan(beforeProduceSpan, err)
+	return next(ctx)
 }
 
 // getMetadata is a method that returns the metadata of the message
@@ -233,7 +453,7 @@ func (r *rabbitMQProducer) getMetadata(
 	}
 
 	// just message type name not full type name because in other side package name for type could be different
-	messageHeader.SetMessageType(meta, message.GetMessageTypeName())
+	messageHeader.SetMessageType(meta, typeregistry.GetTypeName(message))
 	messageHeader.SetMessageContentType(meta, r.messageSerializer.ContentType())
 
 	// Always set message ID from the message itself
@@ -242,9 +462,11 @@ func (r *rabbitMQProducer) getMetadata(
 	// Always set message created time
 	messageHeader.SetMessageCreated(meta, message.GetCreated())
 
-	// Always generate a new correlation ID
-	cid := uuid.NewV4().String()
-	messageHeader.SetCorrelationId(meta, cid)
+	// keep the correlation ID of the caller, request/reply uses it for matching the responses with their requests
+	if messageHeader.GetCorrelationId(meta) == "" {
+		cid := uuid.NewV4().String()
+		messageHeader.SetCorrelationId(meta, cid)
+	}
 	
 	messageHeader.SetMessageName(meta, utils.GetMessageName(message))
 
@@ -270,4 +492,75 @@ func (r *rabbitMQProducer) ensureExchange(
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ensureReplyExchange declares the reply exchange with the same flags as the reply consumer of the requester,
+// a different declaration of an existing exchange closes the channel
+func (r *rabbitMQProducer) ensureReplyExchange(channel *amqp091.Channel, exchangeName string) error {
+	return channel.ExchangeDeclare(
+		exchangeName,
+		string(types.ReplyExchangeType),
+		types.ReplyExchangeDurable,
+		types.ReplyExchangeAutoDelete,
+		false,
+		false,
+		nil,
+	)
+}
+
+// ensureDelayStaging declares the headers delay exchange of the target exchange and the staging queue of the delay tier,
+// the staging queue dead letters the expired messages to the target exchange with their original routing key
+// and it is removed by the broker when it is not used after the delay
+func (r *rabbitMQProducer) ensureDelayStaging(
+	channel *amqp091.Channel,
+	exchangeName string,
+	delayMs int64,
+) (string, error) {
+	delayExchange := fmt.Sprintf("%s.delay", exchangeName)
+	stagingQueue := fmt.Sprintf("%s.delay.%dms", exchangeName, delayMs)
+
+	err := channel.ExchangeDeclare(
+		delayExchange,
+		string(types.ExchangeHeaders),
+		true,  // durable
+		false, // auto delete
+		false, // internal
+		false, // no wait
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = channel.QueueDeclare(
+		stagingQueue,
+		true,  // durable
+		false, // auto delete
+		false, // exclusive
+		false, // no wait
+		amqp091.Table{
+			"x-message-ttl":          delayMs,
+			"x-dead-letter-exchange": exchangeName,
+			"x-expires":              delayMs + types.DelayStagingQueueExpiration.Milliseconds(),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = channel.QueueBind(
+		stagingQueue,
+		"",
+		delayExchange,
+		false,
+		amqp091.Table{
+			"x-match":         "all",
+			types.DelayHeader: delayMs,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return delayExchange, nil
+}
@@ -0,0 +1,93 @@
+package producer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DelayTier(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay time.Duration
+		want  time.Duration
+	}{
+		{name: "sub second delay", delay: 1500 * time.Microsecond, want: time.Second},
+		{name: "exact tier", delay: 5 * time.Second, want: 5 * time.Second},
+		{name: "between tiers", delay: 16 * time.Minute, want: 15 * time.Minute},
+		{name: "hours between tiers", delay: 13 * time.Hour, want: 12 * time.Hour},
+		{name: "days between tiers", delay: 49 * time.Hour, want: 48 * time.Hour},
+		{name: "longer than the maximum tier", delay: 8 * 24 * time.Hour, want: 7 * 24 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, types.DelayTier(test.delay))
+		})
+	}
+}
+
+// stageUntilDue simula el recorrido de un mensaje programado: espera en la staging queue del tier de su delay y el consumer
+// lo devuelve a la staging queue del tier de lo que le falta hasta que vence, devuelve cuando se entrega y cuantas staging queues uso
+func stageUntilDue(delay time.Duration) (time.Duration, int) {
+	var elapsed time.Duration
+	stages := 0
+	for delay-elapsed > types.ScheduledDeliveryTolerance {
+		elapsed += types.DelayTier(delay - elapsed)
+		stages++
+	}
+
+	return elapsed, stages
+}
+
+func Test_Scheduled_Messages_Are_Delivered_Within_The_Tolerance_Of_Their_Time(t *testing.T) {
+	delays := []time.Duration{
+		300 * time.Millisecond,
+		1500 * time.Millisecond,
+		16 * time.Minute,
+		15*time.Minute + 7*time.Second,
+		13 * time.Hour,
+		49 * time.Hour,
+		8 * 24 * time.Hour,
+	}
+	for delay := time.Millisecond; delay < 24*time.Hour; delay += 7919 * time.Millisecond {
+		delays = append(delays, delay)
+	}
+
+	for _, delay := range delays {
+		delivered, stages := stageUntilDue(delay)
+
+		// the message is never delivered before its tolerance, and at most the smallest tier after its time
+		assert.GreaterOrEqual(t, delivered, delay-types.ScheduledDeliveryTolerance, "delay %s", delay)
+		assert.LessOrEqual(t, delivered, delay+types.DelayTiers[0], "delay %s", delay)
+		assert.LessOrEqual(t, stages, 2*len(types.DelayTiers), "delay %s", delay)
+	}
+}
+
+// fakeConnection solo implementa lo que usa el pool de channels antes de abrir un channel
+type fakeConnection struct {
+	types.IConnection
+}
+
+func (fakeConnection) IsClosed() bool { return false }
+
+func (fakeConnection) NotifyReconnected(receiver chan struct{}) chan struct{} { return receiver }
+
+func Test_Close_Closes_The_Channel_Pool(t *testing.T) {
+	p, err := NewRabbitMQProducer(&config.RabbitmqOptions{}, fakeConnection{}, nil, defaultlogger.GetLogger(), nil)
+	require.NoError(t, err)
+	producer := p.(*rabbitMQProducer)
+
+	require.NoError(t, producer.Close())
+
+	_, err = producer.getChannel()
+	assert.ErrorContains(t, err, "channel pool is closed")
+	// closing twice is a no-op
+	assert.NoError(t, producer.Close())
+}
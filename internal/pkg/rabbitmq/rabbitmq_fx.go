@@ -6,15 +6,21 @@ import (
 	"time"
 
 	bus2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	consumermetrics "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/metrics/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/metrics"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/bus"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/configurations"
 	rabbitmqconsumer "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer"
 	rabbitmqproducer "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/topology"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
 
+	"emperror.dev/errors"
 	"go.uber.org/fx"
 )
 
@@ -40,15 +46,31 @@ var (
 			bus.NewRabbitmqBus,
 			fx.ParamTags(``, ``, ``, `optional:"true"`),
 			fx.As(new(producer.Producer)),
+			fx.As(new(producer.AsyncProducer)),
 			fx.As(new(bus2.Bus)),
 			fx.As(new(bus.RabbitmqBus)),
 		)),
-		fx.Provide(rabbitmqconsumer.NewConsumerFactory),
+		fx.Provide(provideRabbitMQConfiguration),
+		fx.Provide(fx.Annotate(
+			newConsumerMetricsPipeline,
+			fx.ParamTags(`optional:"true"`),
+			fx.ResultTags(`group:"consumer_pipelines"`),
+		)),
+		fx.Provide(fx.Annotate(
+			rabbitmqconsumer.NewConsumerFactory,
+			fx.ParamTags(``, ``, ``, ``, `group:"consumer_pipelines"`),
+		)),
 		fx.Provide(rabbitmqproducer.NewProducerFactory),
+		fx.Provide(topology.NewTopology),
 		fx.Provide(fx.Annotate(
 			NewRabbitMQHealthChecker,
 			fx.As(new(contracts.Health)),
 			fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
+		)),
+		fx.Provide(fx.Annotate(
+			NewRabbitMQConsumersHealthChecker,
+			fx.As(new(contracts.Health)),
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
 		)))
 
 	// - execute after registering all of our provided
@@ -56,10 +78,63 @@ var (
 	// - invokes always execute its func compare to provides that only run when we request for them.
 	// - return value will be discarded and can not be provided
 	rabbitmqInvokes = fx.Options(
+		// the topology hook is registered first, so the topology is ready before the consumers start
+		fx.Invoke(registerTopologyHook),
 		fx.Invoke(registerHooks),
 	) //nolint:gochecknoglobals
 )
 
+// newConsumerMetricsPipeline registra las metricas de todos los consumers, sin el modulo de metricas no se agrega el pipeline
+func newConsumerMetricsPipeline(appMetrics metrics.AppMetrics) (pipeline.ConsumerPipeline, error) {
+	if appMetrics == nil {
+		return nil, nil
+	}
+
+	return consumermetrics.NewConsumerMetricsPipeline(appMetrics)
+}
+
+// provideRabbitMQConfiguration expone la configuracion construida por el bus, la usan otros modulos como el de las dead letter queues
+func provideRabbitMQConfiguration(rabbitmqBus bus.RabbitmqBus) *configurations.RabbitMQConfiguration {
+	return rabbitmqBus.RabbitMQConfiguration()
+}
+
+// registerTopologyHook aplica la topologia declarada, o en modo `VerifyOnly` reporta sus diferencias con el broker
+func registerTopologyHook(
+	lc fx.Lifecycle,
+	brokerTopology topology.Topology,
+	rabbitmqOptions *config.RabbitmqOptions,
+	logger logger.Logger,
+) {
+	options := rabbitmqOptions.Topology
+	if options == nil {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if !options.VerifyOnly {
+				return brokerTopology.Apply(ctx)
+			}
+
+			drifts, err := brokerTopology.Verify(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, drift := range drifts {
+				logger.Errorf("rabbitmq topology drift, %s", drift)
+			}
+			if len(drifts) == 0 {
+				logger.Info("rabbitmq topology verified, the broker matches the declared topology")
+			} else if options.FailOnDrift {
+				return errors.Errorf("rabbitmq topology has %d differences with the broker", len(drifts))
+			}
+
+			return nil
+		},
+	})
+}
+
 func registerHooks(
 	lc fx.Lifecycle,
 	bus bus.RabbitmqBus,
@@ -0,0 +1,144 @@
+package topology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
+
+	"emperror.dev/errors"
+)
+
+// ExchangeDefinition es la definicion de un exchange en el broker
+type ExchangeDefinition struct {
+	Type       string         `json:"type"`
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Internal   bool           `json:"internal"`
+	Arguments  map[string]any `json:"arguments"`
+}
+
+// QueueDefinition es la definicion de una queue en el broker
+type QueueDefinition struct {
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Exclusive  bool           `json:"exclusive"`
+	Arguments  map[string]any `json:"arguments"`
+}
+
+// BindingDefinition es un binding del broker entre un origen y un destino
+type BindingDefinition struct {
+	RoutingKey string         `json:"routing_key"`
+	Arguments  map[string]any `json:"arguments"`
+}
+
+// ManagementReader lee las definiciones del broker, amqp no permite leer los argumentos de una entidad ni los bindings.
+// Las entidades que no existen se devuelven como nil sin error
+type ManagementReader interface {
+	Exchange(ctx context.Context, name string) (*ExchangeDefinition, error)
+	Queue(ctx context.Context, name string) (*QueueDefinition, error)
+	// Bindings devuelve los bindings entre el origen y el destino del binding con cualquier routing key
+	Bindings(ctx context.Context, binding config.BindingTopologyOptions) ([]BindingDefinition, error)
+}
+
+type managementReader struct {
+	hostOptions *config.RabbitmqHostOptions
+	client      *http.Client
+}
+
+// NewManagementReader lee las definiciones con la api http del plugin de management
+func NewManagementReader(hostOptions *config.RabbitmqHostOptions) ManagementReader {
+	return &managementReader{
+		hostOptions: hostOptions,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (m *managementReader) Exchange(ctx context.Context, name string) (*ExchangeDefinition, error) {
+	var definition *ExchangeDefinition
+	err := m.get(ctx, fmt.Sprintf("exchanges/%s/%s", m.vhost(), url.PathEscape(name)), &definition)
+
+	return definition, err
+}
+
+func (m *managementReader) Queue(ctx context.Context, name string) (*QueueDefinition, error) {
+	var definition *QueueDefinition
+	err := m.get(ctx, fmt.Sprintf("queues/%s/%s", m.vhost(), url.PathEscape(name)), &definition)
+
+	return definition, err
+}
+
+func (m *managementReader) Bindings(
+	ctx context.Context,
+	binding config.BindingTopologyOptions,
+) ([]BindingDefinition, error) {
+	destinationKind, destination := "q", binding.Queue
+	if binding.DestinationExchange != "" {
+		destinationKind, destination = "e", binding.DestinationExchange
+	}
+
+	var bindings []BindingDefinition
+	err := m.get(
+		ctx,
+		fmt.Sprintf(
+			"bindings/%s/e/%s/%s/%s",
+			m.vhost(),
+			url.PathEscape(binding.Source),
+			destinationKind,
+			url.PathEscape(destination),
+		),
+		&bindings,
+	)
+
+	return bindings, err
+}
+
+// get decodes the response of the management api, the result is left untouched when the resource doesn't exist
+func (m *managementReader) get(ctx context.Context, path string, result any) error {
+	if m.hostOptions == nil || m.hostOptions.HttpPort == 0 {
+		return errors.New("the management http port is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/api/%s", m.hostOptions.HttpEndPoint(), path),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.hostOptions.UserName, m.hostOptions.Password)
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return errors.WrapIf(err, "error in calling the management api")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("management api responded with status %d", res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return errors.WrapIf(err, "error in decoding the management api response")
+	}
+
+	return nil
+}
+
+func (m *managementReader) vhost() string {
+	vhost := m.hostOptions.VirtualHost
+	if vhost == "" {
+		vhost = "/"
+	}
+
+	return url.PathEscape(vhost)
+}
@@ -0,0 +1,374 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
+
+	"emperror.dev/errors"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const queueTypeArg = "x-queue-type"
+
+// Drift es una diferencia entre la topologia declarada y la del broker
+type Drift struct {
+	Kind   string // exchange, queue or binding
+	Name   string
+	Reason string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s `%s`: %s", d.Kind, d.Name, d.Reason)
+}
+
+// Topology aplica o verifica la topologia declarada en `RabbitmqOptions.Topology`
+type Topology interface {
+	// Apply declara los exchanges, queues y bindings, las declaraciones son idempotentes
+	Apply(ctx context.Context) error
+	// Verify compara la topologia declarada con la del broker sin modificarlo
+	Verify(ctx context.Context) ([]Drift, error)
+}
+
+type topology struct {
+	options    *config.TopologyOptions
+	connection types.IConnection
+	management ManagementReader
+	logger     logger.Logger
+}
+
+func NewTopology(
+	rabbitmqOptions *config.RabbitmqOptions,
+	connection types.IConnection,
+	logger logger.Logger,
+) Topology {
+	options := rabbitmqOptions.Topology
+	if options == nil {
+		options = &config.TopologyOptions{}
+	}
+
+	return &topology{
+		options:    options,
+		connection: connection,
+		management: NewManagementReader(rabbitmqOptions.RabbitmqHostOptions),
+		logger:     logger,
+	}
+}
+
+func (t *topology) Apply(ctx context.Context) error {
+	channel, err := t.connection.Channel()
+	if err != nil {
+		return errors.WrapIf(err, "error in opening a channel for declaring the topology")
+	}
+	defer channel.Close()
+
+	for _, exchange := range t.options.Exchanges {
+		err := channel.ExchangeDeclare(
+			exchange.Name,
+			exchangeType(exchange),
+			exchange.Durable,
+			exchange.AutoDelete,
+			exchange.Internal,
+			false,
+			normalizeArgs(exchange.Args),
+		)
+		if err != nil {
+			return errors.WrapIff(err, "error in declaring exchange `%s`", exchange.Name)
+		}
+	}
+
+	for _, queue := range t.options.Queues {
+		_, err := channel.QueueDeclare(
+			queue.Name,
+			queue.Durable,
+			queue.AutoDelete,
+			queue.Exclusive,
+			false,
+			normalizeArgs(queue.QueueArgs()),
+		)
+		if err != nil {
+			return errors.WrapIff(err, "error in declaring queue `%s`", queue.Name)
+		}
+	}
+
+	for _, binding := range t.options.Bindings {
+		if binding.DestinationExchange != "" {
+			err = channel.ExchangeBind(
+				binding.DestinationExchange,
+				binding.RoutingKey,
+				binding.Source,
+				false,
+				normalizeArgs(binding.Args),
+			)
+		} else {
+			err = channel.QueueBind(
+				binding.Queue,
+				binding.RoutingKey,
+				binding.Source,
+				false,
+				normalizeArgs(binding.Args),
+			)
+		}
+		if err != nil {
+			return errors.WrapIff(err, "error in declaring binding `%s`", bindingName(binding))
+		}
+	}
+
+	t.logger.Infof(
+		"rabbitmq topology applied: %d exchanges, %d queues, %d bindings",
+		len(t.options.Exchanges),
+		len(t.options.Queues),
+		len(t.options.Bindings),
+	)
+
+	return nil
+}
+
+// Verify checks with passive declares that the entities exist, a passive declare never changes the broker, and compares
+// their definitions and the bindings with the management api. The entities that can't be compared, e.g. without the
+// management port, are reported as warnings, or fail the verification when `FailOnDrift` is set
+func (t *topology) Verify(ctx context.Context) ([]Drift, error) {
+	var drifts []Drift
+	var unverifiable []string
+
+	for _, exchange := range t.options.Exchanges {
+		exists, err := t.exists("exchange", exchange.Name, func(channel *amqp091.Channel) error {
+			return channel.ExchangeDeclarePassive(
+				exchange.Name,
+				exchangeType(exchange),
+				exchange.Durable,
+				exchange.AutoDelete,
+				exchange.Internal,
+				false,
+				nil,
+			)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			drifts = append(drifts, Drift{Kind: "exchange", Name: exchange.Name, Reason: "it doesn't exist"})
+			continue
+		}
+
+		definition, err := t.management.Exchange(ctx, exchange.Name)
+		if err != nil {
+			unverifiable = append(unverifiable, fmt.Sprintf("exchange `%s`: %v", exchange.Name, err))
+			continue
+		}
+		if drift := exchangeDrift(exchange, definition); drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+
+	for _, queue := range t.options.Queues {
+		exists, err := t.exists("queue", queue.Name, func(channel *amqp091.Channel) error {
+			_, err := channel.QueueDeclarePassive(queue.Name, queue.Durable, queue.AutoDelete, queue.Exclusive, false, nil)
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			drifts = append(drifts, Drift{Kind: "queue", Name: queue.Name, Reason: "it doesn't exist"})
+			continue
+		}
+
+		definition, err := t.management.Queue(ctx, queue.Name)
+		if err != nil {
+			unverifiable = append(unverifiable, fmt.Sprintf("queue `%s`: %v", queue.Name, err))
+			continue
+		}
+		if drift := queueDrift(queue, definition); drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+
+	// there is no passive bind in amqp, the bindings are read from the management api
+	for _, binding := range t.options.Bindings {
+		definitions, err := t.management.Bindings(ctx, binding)
+		if err != nil {
+			unverifiable = append(unverifiable, fmt.Sprintf("binding `%s`: %v", bindingName(binding), err))
+			continue
+		}
+		if drift := bindingDrift(binding, definitions); drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+
+	if len(unverifiable) > 0 {
+		if t.options.FailOnDrift {
+			return nil, errors.Errorf("rabbitmq topology can't be verified: %s", strings.Join(unverifiable, "; "))
+		}
+		for _, reason := range unverifiable {
+			t.logger.Warnf("rabbitmq topology can't be verified, %s", reason)
+		}
+	}
+
+	return drifts, nil
+}
+
+// exists runs the passive declare on its own channel, because the broker closes the channel when a declare fails.
+// An exclusive queue of another connection is locked, but it exists
+func (t *topology) exists(kind string, name string, declarePassive func(channel *amqp091.Channel) error) (bool, error) {
+	channel, err := t.connection.Channel()
+	if err != nil {
+		return false, errors.WrapIf(err, "error in opening a channel for verifying the topology")
+	}
+
+	err = declarePassive(channel)
+	if !channel.IsClosed() {
+		_ = channel.Close()
+	}
+
+	var amqpErr *amqp091.Error
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound:
+		return false, nil
+	case errors.As(err, &amqpErr) && amqpErr.Code == amqp091.ResourceLocked:
+		return true, nil
+	default:
+		return false, errors.WrapIff(err, "error in verifying %s `%s`", kind, name)
+	}
+}
+
+func exchangeDrift(exchange config.ExchangeTopologyOptions, definition *ExchangeDefinition) *Drift {
+	if definition == nil {
+		return &Drift{Kind: "exchange", Name: exchange.Name, Reason: "it doesn't exist"}
+	}
+
+	var reasons []string
+	if definition.Type != exchangeType(exchange) {
+		reasons = append(reasons, fmt.Sprintf("type is `%s` instead of `%s`", definition.Type, exchangeType(exchange)))
+	}
+	reasons = appendFlagReason(reasons, "durable", exchange.Durable, definition.Durable)
+	reasons = appendFlagReason(reasons, "auto delete", exchange.AutoDelete, definition.AutoDelete)
+	reasons = appendFlagReason(reasons, "internal", exchange.Internal, definition.Internal)
+	reasons = appendArgsReason(reasons, exchange.Args, definition.Arguments)
+
+	return newDrift("exchange", exchange.Name, reasons)
+}
+
+func queueDrift(queue config.QueueTopologyOptions, definition *QueueDefinition) *Drift {
+	if definition == nil {
+		return &Drift{Kind: "queue", Name: queue.Name, Reason: "it doesn't exist"}
+	}
+
+	var reasons []string
+	reasons = appendFlagReason(reasons, "durable", queue.Durable, definition.Durable)
+	reasons = appendFlagReason(reasons, "auto delete", queue.AutoDelete, definition.AutoDelete)
+	reasons = appendFlagReason(reasons, "exclusive", queue.Exclusive, definition.Exclusive)
+	reasons = appendArgsReason(reasons, queue.QueueArgs(), definition.Arguments)
+
+	return newDrift("queue", queue.Name, reasons)
+}
+
+func bindingDrift(binding config.BindingTopologyOptions, definitions []BindingDefinition) *Drift {
+	found := false
+	for _, definition := range definitions {
+		if definition.RoutingKey != binding.RoutingKey {
+			continue
+		}
+		found = true
+
+		// the same routing key can be bound several times with different arguments, e.g. in a headers exchange
+		if argsEqual(binding.Args, definition.Arguments) {
+			return nil
+		}
+	}
+
+	if found {
+		return &Drift{Kind: "binding", Name: bindingName(binding), Reason: "the binding arguments are different"}
+	}
+
+	return &Drift{Kind: "binding", Name: bindingName(binding), Reason: "the binding doesn't exist"}
+}
+
+func appendFlagReason(reasons []string, flag string, declared bool, actual bool) []string {
+	if declared == actual {
+		return reasons
+	}
+
+	return append(reasons, fmt.Sprintf("%s is %t instead of %t", flag, actual, declared))
+}
+
+func appendArgsReason(reasons []string, declared map[string]any, actual map[string]any) []string {
+	if argsEqual(declared, actual) {
+		return reasons
+	}
+
+	return append(reasons, fmt.Sprintf("arguments are %v instead of %v", normalizeArgs(actual), normalizeArgs(declared)))
+}
+
+func newDrift(kind string, name string, reasons []string) *Drift {
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return &Drift{Kind: kind, Name: name, Reason: strings.Join(reasons, ", ")}
+}
+
+// argsEqual compares the arguments after normalizing their numbers, the broker adds `x-queue-type` to the queues
+// declared without it, so it is only compared when it is declared
+func argsEqual(declared map[string]any, actual map[string]any) bool {
+	declaredArgs := normalizeArgs(declared)
+	actualArgs := normalizeArgs(actual)
+	if _, ok := declaredArgs[queueTypeArg]; !ok {
+		delete(actualArgs, queueTypeArg)
+	}
+
+	if len(declaredArgs) != len(actualArgs) {
+		return false
+	}
+	for key, value := range declaredArgs {
+		actualValue, ok := actualArgs[key]
+		if !ok || fmt.Sprint(actualValue) != fmt.Sprint(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func exchangeType(exchange config.ExchangeTopologyOptions) string {
+	if exchange.Type == "" {
+		return string(types.ExchangeTopic)
+	}
+
+	return exchange.Type
+}
+
+func bindingName(binding config.BindingTopologyOptions) string {
+	destination := binding.Queue
+	if binding.DestinationExchange != "" {
+		destination = binding.DestinationExchange
+	}
+
+	return fmt.Sprintf("%s -> %s (%s)", binding.Source, destination, binding.RoutingKey)
+}
+
+// normalizeArgs converts the whole numbers of the configuration to integers, the json numbers are decoded as floats
+// and the broker only accepts integers in arguments like `x-message-ttl`
+func normalizeArgs(args map[string]any) amqp091.Table {
+	if len(args) == 0 {
+		return nil
+	}
+
+	table := amqp091.Table{}
+	for key, value := range args {
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			value = int64(number)
+		}
+		table[strings.TrimSpace(key)] = value
+	}
+
+	return table
+}
@@ -0,0 +1,178 @@
+package topology
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeManagementReader devuelve los bindings configurados, o el error si la api de management no esta disponible
+type fakeManagementReader struct {
+	bindings []BindingDefinition
+	err      error
+}
+
+func (f *fakeManagementReader) Exchange(context.Context, string) (*ExchangeDefinition, error) {
+	return nil, f.err
+}
+
+func (f *fakeManagementReader) Queue(context.Context, string) (*QueueDefinition, error) {
+	return nil, f.err
+}
+
+func (f *fakeManagementReader) Bindings(context.Context, config.BindingTopologyOptions) ([]BindingDefinition, error) {
+	return f.bindings, f.err
+}
+
+func newTestTopology(options *config.TopologyOptions, management ManagementReader) *topology {
+	return &topology{options: options, management: management, logger: defaultlogger.GetLogger()}
+}
+
+func Test_Verify_Bindings(t *testing.T) {
+	binding := config.BindingTopologyOptions{
+		Source:     "orders",
+		Queue:      "orders.created",
+		RoutingKey: "created",
+		Args:       map[string]any{"x-match": "all", "priority": float64(1)},
+	}
+
+	tests := []struct {
+		name     string
+		bindings []BindingDefinition
+		want     []Drift
+	}{
+		{
+			name:     "same binding",
+			bindings: []BindingDefinition{{RoutingKey: "created", Arguments: map[string]any{"x-match": "all", "priority": 1}}},
+		},
+		{
+			name: "same routing key bound twice",
+			bindings: []BindingDefinition{
+				{RoutingKey: "created", Arguments: map[string]any{"x-match": "any"}},
+				{RoutingKey: "created", Arguments: map[string]any{"x-match": "all", "priority": float64(1)}},
+			},
+		},
+		{
+			name:     "missing binding",
+			bindings: []BindingDefinition{{RoutingKey: "updated"}},
+			want: []Drift{{
+				Kind:   "binding",
+				Name:   "orders -> orders.created (created)",
+				Reason: "the binding doesn't exist",
+			}},
+		},
+		{
+			name:     "different arguments",
+			bindings: []BindingDefinition{{RoutingKey: "created", Arguments: map[string]any{"x-match": "any", "priority": 1}}},
+			want: []Drift{{
+				Kind:   "binding",
+				Name:   "orders -> orders.created (created)",
+				Reason: "the binding arguments are different",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			brokerTopology := newTestTopology(
+				&config.TopologyOptions{VerifyOnly: true, Bindings: []config.BindingTopologyOptions{binding}},
+				&fakeManagementReader{bindings: test.bindings},
+			)
+
+			drifts, err := brokerTopology.Verify(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, drifts)
+		})
+	}
+}
+
+func Test_Verify_Fails_On_Drift_When_Bindings_Cant_Be_Verified(t *testing.T) {
+	options := &config.TopologyOptions{
+		VerifyOnly:  true,
+		FailOnDrift: true,
+		Bindings:    []config.BindingTopologyOptions{{Source: "orders", Queue: "orders.created", RoutingKey: "created"}},
+	}
+	management := &fakeManagementReader{err: errors.New("the management http port is not configured")}
+
+	_, err := newTestTopology(options, management).Verify(context.Background())
+	assert.ErrorContains(t, err, "the management http port is not configured")
+
+	// without FailOnDrift the unverifiable bindings are only reported as warnings
+	options.FailOnDrift = false
+	drifts, err := newTestTopology(options, management).Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func Test_ExchangeDrift(t *testing.T) {
+	exchange := config.ExchangeTopologyOptions{Name: "orders", Durable: true, Args: map[string]any{"alternate-exchange": "unrouted"}}
+
+	assert.Nil(t, exchangeDrift(exchange, &ExchangeDefinition{
+		Type:      "topic",
+		Durable:   true,
+		Arguments: map[string]any{"alternate-exchange": "unrouted"},
+	}))
+
+	drift := exchangeDrift(exchange, &ExchangeDefinition{Type: "direct", Durable: false})
+	require.NotNil(t, drift)
+	assert.Contains(t, drift.Reason, "type is `direct` instead of `topic`")
+	assert.Contains(t, drift.Reason, "durable is false instead of true")
+	assert.Contains(t, drift.Reason, "arguments are")
+
+	assert.Equal(t, "it doesn't exist", exchangeDrift(exchange, nil).Reason)
+}
+
+func Test_QueueDrift(t *testing.T) {
+	queue := config.QueueTopologyOptions{Name: "orders.created", Durable: true, DeadLetterExchange: "orders.dlx", MaxLength: 100}
+
+	tests := []struct {
+		name       string
+		definition *QueueDefinition
+		wantReason string
+	}{
+		{
+			name: "same queue with the default queue type of the broker",
+			definition: &QueueDefinition{Durable: true, Arguments: map[string]any{
+				"x-dead-letter-exchange": "orders.dlx",
+				"x-max-length":           float64(100),
+				"x-queue-type":           "classic",
+			}},
+		},
+		{
+			name: "different max length",
+			definition: &QueueDefinition{Durable: true, Arguments: map[string]any{
+				"x-dead-letter-exchange": "orders.dlx",
+				"x-max-length":           float64(10),
+			}},
+			wantReason: "arguments are",
+		},
+		{
+			name: "exclusive queue",
+			definition: &QueueDefinition{Durable: true, Exclusive: true, Arguments: map[string]any{
+				"x-dead-letter-exchange": "orders.dlx",
+				"x-max-length":           float64(100),
+			}},
+			wantReason: "exclusive is true instead of false",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			drift := queueDrift(queue, test.definition)
+			if test.wantReason == "" {
+				assert.Nil(t, drift)
+				return
+			}
+
+			require.NotNil(t, drift)
+			assert.Contains(t, drift.Reason, test.wantReason)
+		})
+	}
+}
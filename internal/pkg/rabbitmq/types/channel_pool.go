@@ -0,0 +1,228 @@
+package types
+
+import (
+	"sync"
+
+	errorUtils "github.com/DavidReque/go-food-delivery/internal/pkg/utils/errorutils"
+
+	"emperror.dev/errors"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const defaultChannelPoolSize = 10
+
+// ChannelPool reutiliza channels en modo confirm entre publicaciones, los channels se descartan cuando la conexion se reconecta
+type ChannelPool interface {
+	// Get devuelve un channel libre del pool o abre uno nuevo si no hay ninguno
+	Get() (*amqp091.Channel, error)
+	// Put devuelve el channel al pool, los channels cerrados o de una conexion anterior se descartan
+	Put(channel *amqp091.Channel)
+	// Track registra una confirmacion pendiente del channel, un channel descartado se cierra cuando se resuelven
+	// todas sus confirmaciones porque al cerrarlo las confirmaciones pendientes se darian como no confirmadas
+	Track(channel *amqp091.Channel, confirmation *amqp091.DeferredConfirmation)
+	// Invalidate cierra los channels libres, los channels en uso se descartan cuando vuelven al pool
+	Invalidate()
+	Close() error
+}
+
+type channelPool struct {
+	connection IConnection
+	channels   chan *amqp091.Channel
+	states     map[*amqp091.Channel]*pooledChannel
+	generation uint64
+	lock       sync.Mutex
+	closed     bool
+	done       chan struct{}
+}
+
+type pooledChannel struct {
+	generation uint64         // the pool generation when the channel was opened
+	pending    sync.WaitGroup // the confirmations of the channel that are not resolved yet
+}
+
+func NewChannelPool(connection IConnection, size int) ChannelPool {
+	if size <= 0 {
+		size = defaultChannelPoolSize
+	}
+
+	pool := &channelPool{
+		connection: connection,
+		channels:   make(chan *amqp091.Channel, size),
+		states:     map[*amqp091.Channel]*pooledChannel{},
+		done:       make(chan struct{}),
+	}
+
+	go pool.invalidateOnReconnect(connection.NotifyReconnected(make(chan struct{}, 1)))
+
+	return pool
+}
+
+func (p *channelPool) Get() (*amqp091.Channel, error) {
+	for {
+		select {
+		case channel := <-p.channels:
+			if channel.IsClosed() || p.isStale(channel) {
+				p.discard(channel)
+				continue
+			}
+
+			return channel, nil
+		default:
+			return p.open()
+		}
+	}
+}
+
+func (p *channelPool) Put(channel *amqp091.Channel) {
+	if channel == nil {
+		return
+	}
+
+	if p.isStale(channel) || channel.IsClosed() {
+		p.discard(channel)
+		return
+	}
+
+	select {
+	case p.channels <- channel:
+	default:
+		// the pool is full
+		p.discard(channel)
+	}
+}
+
+func (p *channelPool) Track(channel *amqp091.Channel, confirmation *amqp091.DeferredConfirmation) {
+	if channel == nil || confirmation == nil {
+		return
+	}
+
+	p.lock.Lock()
+	state, ok := p.states[channel]
+	if ok {
+		state.pending.Add(1)
+	}
+	p.lock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	go func() {
+		defer errorUtils.HandlePanic()
+
+		// the confirmation is resolved by the broker, or as not acked when the channel or the connection is closed
+		<-confirmation.Done()
+		state.pending.Done()
+	}()
+}
+
+func (p *channelPool) Invalidate() {
+	p.lock.Lock()
+	p.generation++
+	p.lock.Unlock()
+
+	p.drain()
+}
+
+func (p *channelPool) Close() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.lock.Unlock()
+
+	p.drain()
+
+	return nil
+}
+
+func (p *channelPool) open() (*amqp091.Channel, error) {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil, errors.New("channel pool is closed")
+	}
+	generation := p.generation
+	p.lock.Unlock()
+
+	channel, err := p.connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	// the pooled channels are always in confirm mode, the publishers wait for their deferred confirmations
+	if err := channel.Confirm(false); err != nil {
+		_ = channel.Close()
+		return nil, err
+	}
+
+	p.lock.Lock()
+	p.states[channel] = &pooledChannel{generation: generation}
+	p.lock.Unlock()
+
+	return channel, nil
+}
+
+func (p *channelPool) drain() {
+	for {
+		select {
+		case channel := <-p.channels:
+			p.discard(channel)
+		default:
+			return
+		}
+	}
+}
+
+// discard removes the channel from the pool and closes it when its pending confirmations are resolved, closing it before
+// would report the messages the broker may have routed as not confirmed
+func (p *channelPool) discard(channel *amqp091.Channel) {
+	p.lock.Lock()
+	state, ok := p.states[channel]
+	delete(p.states, channel)
+	p.lock.Unlock()
+
+	if !ok {
+		closeChannel(channel)
+		return
+	}
+
+	go func() {
+		defer errorUtils.HandlePanic()
+
+		state.pending.Wait()
+		closeChannel(channel)
+	}()
+}
+
+func closeChannel(channel *amqp091.Channel) {
+	if !channel.IsClosed() {
+		_ = channel.Close()
+	}
+}
+
+// isStale checks if the channel was opened before the last invalidation, e.g. on the connection before a reconnect
+func (p *channelPool) isStale(channel *amqp091.Channel) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	state, ok := p.states[channel]
+
+	return !ok || p.closed || state.generation != p.generation
+}
+
+func (p *channelPool) invalidateOnReconnect(reconnected chan struct{}) {
+	defer errorUtils.HandlePanic()
+
+	for {
+		select {
+		case <-reconnected:
+			p.Invalidate()
+		case <-p.done:
+			return
+		}
+	}
+}
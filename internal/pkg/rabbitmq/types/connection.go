@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/config"
@@ -18,6 +19,9 @@ type internalConnection struct {
 	errConnectionChan chan error
 	errChannelChan    chan error
 	reconnectedChan   chan struct{}
+	// reconnectedReceivers are notified on each reconnect, unlike `reconnectedChan` every receiver gets the notification
+	reconnectedReceivers []chan struct{}
+	receiversLock        sync.Mutex
 }
 
 type IConnection interface {
@@ -31,6 +35,8 @@ type IConnection interface {
 	Raw() *amqp091.Connection
 	ErrorConnectionChannel() chan error
 	ReconnectedChannel() chan struct{}
+	// NotifyReconnected registra un receptor que se notifica en cada reconexion, las notificaciones se descartan si el receptor esta lleno
+	NotifyReconnected(receiver chan struct{}) chan struct{}
 }
 
 func NewRabbitMQConnection(cfg *config.RabbitmqOptions) (IConnection, error) {
@@ -74,6 +80,27 @@ func (c *internalConnection) ReconnectedChannel() chan struct{} {
 	return c.reconnectedChan
 }
 
+func (c *internalConnection) NotifyReconnected(receiver chan struct{}) chan struct{} {
+	c.receiversLock.Lock()
+	defer c.receiversLock.Unlock()
+
+	c.reconnectedReceivers = append(c.reconnectedReceivers, receiver)
+
+	return receiver
+}
+
+func (c *internalConnection) notifyReconnected() {
+	c.receiversLock.Lock()
+	defer c.receiversLock.Unlock()
+
+	for _, receiver := range c.reconnectedReceivers {
+		select {
+		case receiver <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (c *internalConnection) ReConnect() error {
 	if c.Connection.IsClosed() == false {
 		return nil
@@ -155,6 +182,7 @@ func (c *internalConnection) handleReconnecting() {
 				defaultlogger.GetLogger().
 					Info("Rabbitmq Connection Reconnected")
 				c.isConnected = true
+				c.notifyReconnected()
 				c.reconnectedChan <- struct{}{}
 				continue
 			}
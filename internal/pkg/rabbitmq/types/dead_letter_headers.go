@@ -0,0 +1,13 @@
+package types
+
+// headers que se agregan a los mensajes enviados a la dead letter queue, sirven para inspeccionar y re-enviar los mensajes fallidos
+const (
+	DeadLetterExceptionMessageHeader    = "x-exception-message"
+	DeadLetterExceptionStackTraceHeader = "x-exception-stacktrace"
+	DeadLetterConsumerNameHeader        = "x-consumer-name"
+	DeadLetterAttemptsHeader            = "x-attempts"
+	DeadLetterOriginalExchangeHeader    = "x-original-exchange"
+	DeadLetterOriginalRoutingKeyHeader  = "x-original-routing-key"
+	DeadLetterOriginalQueueHeader       = "x-original-queue"
+	DeadLetterFailedAtHeader            = "x-failed-at"
+)
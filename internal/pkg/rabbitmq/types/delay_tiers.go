@@ -0,0 +1,48 @@
+package types
+
+import "time"
+
+// ScheduledDeliveryTolerance es lo que un mensaje programado puede llegar antes de su hora sin volver a una staging queue,
+// cubre la diferencia de reloj entre el producer, el broker y el consumer
+const ScheduledDeliveryTolerance = 100 * time.Millisecond
+
+// DelayStagingQueueExpiration es lo que vive una staging queue sin uso despues de su TTL, asi todos sus mensajes
+// expiran antes de que el broker la elimine
+const DelayStagingQueueExpiration = time.Minute
+
+// DelayTiers son los TTL de las staging queues de los mensajes programados, cada exchange o queue tiene como maximo
+// una staging queue por tier
+var DelayTiers = []time.Duration{ //nolint:gochecknoglobals
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+	48 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// DelayTier devuelve el tier mas grande que no es mayor a la espera restante, asi el mensaje nunca sale de su staging queue
+// despues de su hora. Si llega antes, el consumer lo devuelve a la staging queue del tier de lo que le falta.
+// Una espera menor al primer tier usa el primer tier, por eso un mensaje puede llegar como maximo un tier minimo tarde
+func DelayTier(remaining time.Duration) time.Duration {
+	tier := DelayTiers[0]
+	for _, t := range DelayTiers {
+		if t > remaining {
+			break
+		}
+		tier = t
+	}
+
+	return tier
+}
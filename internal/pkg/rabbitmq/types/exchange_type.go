@@ -5,7 +5,10 @@ import "github.com/rabbitmq/amqp091-go"
 type ExchangeType string
 
 const (
-	ExchangeFanout ExchangeType = amqp091.ExchangeFanout
-	ExchangeDirect              = amqp091.ExchangeDirect
-	ExchangeTopic               = amqp091.ExchangeTopic
+	ExchangeFanout  ExchangeType = amqp091.ExchangeFanout
+	ExchangeDirect               = amqp091.ExchangeDirect
+	ExchangeTopic                = amqp091.ExchangeTopic
+	ExchangeHeaders              = amqp091.ExchangeHeaders
+	// ExchangeConsistentHash requires the `rabbitmq_consistent_hash_exchange` plugin, it is shipped with rabbitmq but disabled by default
+	ExchangeConsistentHash ExchangeType = "x-consistent-hash"
 )
@@ -0,0 +1,7 @@
+package types
+
+// RetryAttemptHeader es el numero de reintentos que lleva un mensaje re-enviado por las retry queues
+const RetryAttemptHeader = "x-retry-attempt"
+
+// DelayHeader es la espera en milisegundos de un mensaje programado, el exchange de delay lo usa para enviarlo a su staging queue
+const DelayHeader = "x-delay-ms"
@@ -0,0 +1,9 @@
+package types
+
+// el exchange de las respuestas de request/reply pertenece al cliente que las espera, no es durable y el broker lo elimina
+// junto con la queue exclusiva del cliente, el consumer de respuestas y el producer que responde lo declaran con los mismos flags
+const (
+	ReplyExchangeType       = ExchangeTopic
+	ReplyExchangeDurable    = false
+	ReplyExchangeAutoDelete = true
+)
@@ -0,0 +1,109 @@
+package bus
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/configurations"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/consumercontracts"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/producercontracts"
+)
+
+type RedisStreamsBus interface {
+	bus.Bus
+	consumerConfigurations.RedisStreamsConsumerConnector
+	// RedisStreamsConfiguration devuelve la configuracion de producers y consumers con la que se construyo el bus
+	RedisStreamsConfiguration() *configurations.RedisStreamsConfiguration
+	// DrainStates devuelve el estado del drenado de cada consumer por su nombre
+	DrainStates() map[string]consumer2.DrainState
+}
+
+type redisStreamsBus struct {
+	*bus.BrokerBus
+	streamsConfiguration *configurations.RedisStreamsConfiguration
+	consumerFactory      consumercontracts.ConsumerFactory
+	producerFactory      producercontracts.ProducerFactory
+}
+
+func NewRedisStreamsBus(
+	logger logger.Logger,
+	consumerFactory consumercontracts.ConsumerFactory,
+	producerFactory producercontracts.ProducerFactory,
+	streamsBuilderFunc configurations.RedisStreamsConfigurationBuilderFuc,
+) (RedisStreamsBus, error) {
+	builder := configurations.NewRedisStreamsConfigurationBuilder()
+	if streamsBuilderFunc != nil {
+		streamsBuilderFunc(builder)
+	}
+
+	b := &redisStreamsBus{
+		BrokerBus:            bus.NewBrokerBus(logger),
+		streamsConfiguration: builder.Build(),
+		consumerFactory:      consumerFactory,
+		producerFactory:      producerFactory,
+	}
+
+	producersConfigurationMap := make(map[string]*producerConfigurations.RedisStreamsProducerConfiguration)
+	for _, config := range b.streamsConfiguration.ProducersConfigurations {
+		producersConfigurationMap[config.ProducerMessageType.String()] = config
+	}
+
+	for _, consumerConfiguration := range b.streamsConfiguration.ConsumersConfigurations {
+		redisStreamsConsumer, err := consumerFactory.CreateConsumer(consumerConfiguration, b.NotifyConsumed)
+		if err != nil {
+			return nil, err
+		}
+
+		b.AddConsumer(consumerConfiguration.ConsumerMessageType, redisStreamsConsumer)
+	}
+
+	redisStreamsProducer, err := producerFactory.CreateProducer(producersConfigurationMap, b.NotifyProduced)
+	if err != nil {
+		return nil, err
+	}
+	b.SetProducer(redisStreamsProducer)
+
+	return b, nil
+}
+
+// RedisStreamsConfiguration returns the built configuration of the producers and consumers
+func (r *redisStreamsBus) RedisStreamsConfiguration() *configurations.RedisStreamsConfiguration {
+	return r.streamsConfiguration
+}
+
+// ConnectRedisStreamsConsumer Add a new consumer to existing message type consumers. if there is no consumer, will create a new consumer for the message type
+func (r *redisStreamsBus) ConnectRedisStreamsConsumer(
+	messageType types.IMessage,
+	consumerBuilderFunc consumerConfigurations.RedisStreamsConsumerConfigurationBuilderFuc,
+) error {
+	builder := consumerConfigurations.NewRedisStreamsConsumerConfigurationBuilder(messageType)
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+
+	redisStreamsConsumer, err := r.consumerFactory.CreateConsumer(builder.Build(), r.NotifyConsumed)
+	if err != nil {
+		return err
+	}
+
+	return r.ConnectConsumer(messageType, redisStreamsConsumer)
+}
+
+// ConnectConsumerHandler Add handler to existing consumer. creates new consumer if not exist
+func (r *redisStreamsBus) ConnectConsumerHandler(
+	messageType types.IMessage,
+	consumerHandler consumer2.ConsumerHandler,
+) error {
+	if r.ConnectHandlerToConsumers(messageType, consumerHandler) {
+		return nil
+	}
+
+	return r.ConnectRedisStreamsConsumer(messageType, func(builder consumerConfigurations.RedisStreamsConsumerConfigurationBuilder) {
+		builder.WithHandlers(func(handlersBuilder consumer2.ConsumerHandlerConfigurationBuilder) {
+			handlersBuilder.AddHandler(consumerHandler)
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"github.com/iancoleman/strcase"
+)
+
+type RedisStreamsOptions struct {
+	AutoStart bool `mapstructure:"autoStart" default:"true"`
+	// ConsumerName identifica a la instancia dentro de los consumer groups, si esta vacio se usa el hostname
+	ConsumerName string `mapstructure:"consumerName"`
+	// MaxLen es el largo aproximado maximo de los streams, las entradas mas viejas se recortan en cada XADD, 0 no recorta
+	MaxLen int64 `mapstructure:"maxLen"`
+	// BlockTimeout es la espera maxima de XREADGROUP cuando el stream no tiene entradas nuevas
+	BlockTimeout time.Duration `mapstructure:"blockTimeout"`
+	// ClaimInterval es cada cuanto se buscan entradas pendientes abandonadas con XAUTOCLAIM
+	ClaimInterval time.Duration `mapstructure:"claimInterval"`
+	// ScheduleInterval es cada cuanto se devuelven al stream las entradas programadas que ya vencieron
+	ScheduleInterval time.Duration `mapstructure:"scheduleInterval"`
+	// DrainTimeout es la espera maxima de los mensajes en curso al detener los consumers
+	DrainTimeout time.Duration `mapstructure:"drainTimeout"`
+}
+
+func ProvideConfig(environment environment.Environment) (*RedisStreamsOptions, error) {
+	optionName := strcase.ToLowerCamel(typemapper.GetGenericTypeNameByT[RedisStreamsOptions]())
+	cfg, err := config.BindConfigKey[RedisStreamsOptions](optionName)
+
+	return cfg, err
+}
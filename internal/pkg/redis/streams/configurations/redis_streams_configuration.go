@@ -0,0 +1,11 @@
+package configurations
+
+import (
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+)
+
+type RedisStreamsConfiguration struct {
+	ProducersConfigurations []*producerConfigurations.RedisStreamsProducerConfiguration
+	ConsumersConfigurations []*consumerConfigurations.RedisStreamsConsumerConfiguration
+}
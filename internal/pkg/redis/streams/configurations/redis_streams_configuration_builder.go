@@ -0,0 +1,65 @@
+package configurations
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+)
+
+type RedisStreamsConfigurationBuilder interface {
+	AddProducer(
+		producerMessageType types.IMessage,
+		producerBuilderFunc producerConfigurations.RedisStreamsProducerConfigurationBuilderFuc,
+	) RedisStreamsConfigurationBuilder
+	AddConsumer(
+		consumerMessageType types.IMessage,
+		consumerBuilderFunc consumerConfigurations.RedisStreamsConsumerConfigurationBuilderFuc,
+	) RedisStreamsConfigurationBuilder
+	Build() *RedisStreamsConfiguration
+}
+
+type streamsConfigurationBuilder struct {
+	builder bus.BrokerConfigurationBuilder[
+		*producerConfigurations.RedisStreamsProducerConfiguration,
+		*consumerConfigurations.RedisStreamsConsumerConfiguration,
+	]
+}
+
+func NewRedisStreamsConfigurationBuilder() RedisStreamsConfigurationBuilder {
+	return &streamsConfigurationBuilder{}
+}
+
+func (b *streamsConfigurationBuilder) AddProducer(
+	producerMessageType types.IMessage,
+	producerBuilderFunc producerConfigurations.RedisStreamsProducerConfigurationBuilderFuc,
+) RedisStreamsConfigurationBuilder {
+	builder := producerConfigurations.NewRedisStreamsProducerConfigurationBuilder(producerMessageType)
+	if producerBuilderFunc != nil {
+		producerBuilderFunc(builder)
+	}
+
+	b.builder.AddProducer(producerMessageType, builder.Build)
+
+	return b
+}
+
+func (b *streamsConfigurationBuilder) AddConsumer(
+	consumerMessageType types.IMessage,
+	consumerBuilderFunc consumerConfigurations.RedisStreamsConsumerConfigurationBuilderFuc,
+) RedisStreamsConfigurationBuilder {
+	builder := consumerConfigurations.NewRedisStreamsConsumerConfigurationBuilder(consumerMessageType)
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+
+	b.builder.AddConsumer(consumerMessageType, builder.Build)
+
+	return b
+}
+
+func (b *streamsConfigurationBuilder) Build() *RedisStreamsConfiguration {
+	producers, consumers := b.builder.Build()
+
+	return &RedisStreamsConfiguration{ProducersConfigurations: producers, ConsumersConfigurations: consumers}
+}
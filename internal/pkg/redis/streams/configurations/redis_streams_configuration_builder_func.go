@@ -0,0 +1,3 @@
+package configurations
+
+type RedisStreamsConfigurationBuilderFuc func(builder RedisStreamsConfigurationBuilder)
@@ -0,0 +1,3 @@
+package configurations
+
+type RedisStreamsConsumerConfigurationBuilderFuc func(builder RedisStreamsConsumerConfigurationBuilder)
@@ -0,0 +1,15 @@
+package configurations
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+)
+
+type RedisStreamsConsumerConnector interface {
+	consumer.ConsumerConnector
+	// ConnectRedisStreamsConsumer Add a new consumer to existing message type consumers. if there is no consumer, will create a new consumer for the message type
+	ConnectRedisStreamsConsumer(
+		messageType types.IMessage,
+		consumerBuilderFunc RedisStreamsConsumerConfigurationBuilderFuc,
+	) error
+}
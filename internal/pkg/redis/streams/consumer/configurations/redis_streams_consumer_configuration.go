@@ -0,0 +1,66 @@
+package configurations
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	consumer2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+)
+
+type RedisStreamsConsumerConfiguration struct {
+	Name                string
+	ConsumerMessageType reflect.Type
+	Pipelines           []pipeline.ConsumerPipeline
+	Handlers            []consumer2.ConsumerHandler
+	*consumer2.ConsumerOptions
+	Stream string
+	// Group es el consumer group del stream, las instancias con el mismo group se reparten las entradas
+	Group string
+	// ConcurrencyLimit es la cantidad de workers que leen del stream, cada worker es un consumer del group
+	ConcurrencyLimit int
+	// PrefetchCount es la cantidad maxima de entradas que lee cada worker en un XREADGROUP
+	PrefetchCount int
+	RetryAttempts uint          // numero total de intentos del handler en cada entrega, incluyendo el primero
+	RetryDelay    time.Duration // espera antes del primer reintento, se duplica en cada reintento
+	// MaxDeliveries es la cantidad de entregas de una entrada antes de moverla al dead letter stream
+	MaxDeliveries int64
+	// ClaimMinIdle es el tiempo que una entrada pendiente tiene que estar sin ack para que otro worker la reclame
+	ClaimMinIdle     time.Duration
+	DeadLetterStream string
+	// ScheduledKey es el sorted set donde esperan las entradas programadas que todavia no vencen
+	ScheduledKey string
+}
+
+func NewDefaultRedisStreamsConsumerConfiguration(messageType types2.IMessage) *RedisStreamsConsumerConfiguration {
+	name := fmt.Sprintf("%s_consumer", utils.GetMessageName(messageType))
+	stream := utils.GetTopicOrExchangeName(messageType)
+
+	return &RedisStreamsConsumerConfiguration{
+		Name:                name,
+		ConsumerMessageType: utils.GetMessageBaseReflectType(messageType),
+		ConsumerOptions:     &consumer2.ConsumerOptions{ExitOnError: false, ConsumerId: ""},
+		Stream:              stream,
+		Group:               utils.GetQueueName(messageType),
+		ConcurrencyLimit:    1,
+		PrefetchCount:       4,
+		RetryAttempts:       3,
+		RetryDelay:          300 * time.Millisecond,
+		MaxDeliveries:       5,
+		ClaimMinIdle:        time.Minute,
+		DeadLetterStream:    fmt.Sprintf("%s.dlq", stream),
+	}
+}
+
+// GetScheduledKey returns the sorted set of the scheduled entries of the group, by default `{<stream>}:<group>:scheduled`.
+// The hash tag keeps the sorted set in the slot of the stream, so a redis cluster can move the entries between them in a script
+func (c *RedisStreamsConsumerConfiguration) GetScheduledKey() string {
+	if c.ScheduledKey != "" {
+		return c.ScheduledKey
+	}
+
+	return fmt.Sprintf("{%s}:%s:scheduled", c.Stream, c.Group)
+}
@@ -0,0 +1,146 @@
+package configurations
+
+import (
+	"fmt"
+	"time"
+
+	messageConsumer "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+)
+
+type RedisStreamsConsumerConfigurationBuilder interface {
+	WithHandlers(consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc) RedisStreamsConsumerConfigurationBuilder
+	WithPipelines(pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc) RedisStreamsConsumerConfigurationBuilder
+	WithName(name string) RedisStreamsConsumerConfigurationBuilder
+	WithStream(stream string) RedisStreamsConsumerConfigurationBuilder
+	WithGroup(group string) RedisStreamsConsumerConfigurationBuilder
+	WithConcurrencyLimit(limit int) RedisStreamsConsumerConfigurationBuilder
+	WithPrefetchCount(count int) RedisStreamsConsumerConfigurationBuilder
+	WithRetry(attempts uint, delay time.Duration) RedisStreamsConsumerConfigurationBuilder
+	// WithMaxDeliveries mueve las entradas al dead letter stream despues de `maxDeliveries` entregas fallidas,
+	// las entregas pendientes se reclaman despues de `claimMinIdle`
+	WithMaxDeliveries(maxDeliveries int64, claimMinIdle time.Duration) RedisStreamsConsumerConfigurationBuilder
+	// WithDeadLetter cambia el dead letter stream, si esta vacio se usa `<stream>.dlq`
+	WithDeadLetter(stream string) RedisStreamsConsumerConfigurationBuilder
+	// WithScheduledKey cambia el sorted set de las entradas programadas, tiene que estar en el slot del stream
+	WithScheduledKey(key string) RedisStreamsConsumerConfigurationBuilder
+	Build() *RedisStreamsConsumerConfiguration
+}
+
+type redisStreamsConsumerConfigurationBuilder struct {
+	redisStreamsConsumerConfigurations *RedisStreamsConsumerConfiguration
+	pipelinesBuilder                   pipeline.ConsumerPipelineConfigurationBuilder
+	handlersBuilder                    messageConsumer.ConsumerHandlerConfigurationBuilder
+	deadLetterStream                   string
+}
+
+func NewRedisStreamsConsumerConfigurationBuilder(messageType types.IMessage) RedisStreamsConsumerConfigurationBuilder {
+	return &redisStreamsConsumerConfigurationBuilder{
+		redisStreamsConsumerConfigurations: NewDefaultRedisStreamsConsumerConfiguration(messageType),
+	}
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithHandlers(
+	consumerBuilderFunc messageConsumer.ConsumerHandlerConfigurationBuilderFunc,
+) RedisStreamsConsumerConfigurationBuilder {
+	builder := messageConsumer.NewConsumerHandlersConfigurationBuilder()
+	if consumerBuilderFunc != nil {
+		consumerBuilderFunc(builder)
+	}
+	b.handlersBuilder = builder
+
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ConsumerPipelineConfigurationBuilderFunc,
+) RedisStreamsConsumerConfigurationBuilder {
+	builder := pipeline.NewConsumerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.pipelinesBuilder = builder
+
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithName(name string) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.Name = name
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithStream(stream string) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.Stream = stream
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithGroup(group string) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.Group = group
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithConcurrencyLimit(
+	limit int,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.ConcurrencyLimit = limit
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithPrefetchCount(
+	count int,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.PrefetchCount = count
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithRetry(
+	attempts uint,
+	delay time.Duration,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.RetryAttempts = attempts
+	b.redisStreamsConsumerConfigurations.RetryDelay = delay
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithMaxDeliveries(
+	maxDeliveries int64,
+	claimMinIdle time.Duration,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.MaxDeliveries = maxDeliveries
+	b.redisStreamsConsumerConfigurations.ClaimMinIdle = claimMinIdle
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithDeadLetter(
+	stream string,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.deadLetterStream = stream
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) WithScheduledKey(
+	key string,
+) RedisStreamsConsumerConfigurationBuilder {
+	b.redisStreamsConsumerConfigurations.ScheduledKey = key
+	return b
+}
+
+func (b *redisStreamsConsumerConfigurationBuilder) Build() *RedisStreamsConsumerConfiguration {
+	if b.pipelinesBuilder != nil {
+		b.redisStreamsConsumerConfigurations.Pipelines = b.pipelinesBuilder.Build().Pipelines
+	}
+	if b.handlersBuilder != nil {
+		b.redisStreamsConsumerConfigurations.Handlers = b.handlersBuilder.Build().Handlers
+	}
+	// the default dead letter stream depends on the stream, which can be changed after the defaults
+	b.redisStreamsConsumerConfigurations.DeadLetterStream = fmt.Sprintf(
+		"%s.dlq",
+		b.redisStreamsConsumerConfigurations.Stream,
+	)
+	if b.deadLetterStream != "" {
+		b.redisStreamsConsumerConfigurations.DeadLetterStream = b.deadLetterStream
+	}
+
+	return b.redisStreamsConsumerConfigurations
+}
@@ -0,0 +1,69 @@
+package consumer
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	consumerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/consumercontracts"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type consumerFactory struct {
+	client            redis.UniversalClient
+	messageSerializer serializer.MessageSerializer
+	logger            logger.Logger
+	streamsOptions    *config.RedisStreamsOptions
+	pipelines         []pipeline.ConsumerPipeline // the pipelines of all the consumers, they wrap the pipelines of each consumer
+}
+
+func NewConsumerFactory(
+	streamsOptions *config.RedisStreamsOptions,
+	client redis.UniversalClient,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+	pipelines ...pipeline.ConsumerPipeline,
+) consumercontracts.ConsumerFactory {
+	factory := &consumerFactory{
+		streamsOptions:    streamsOptions,
+		logger:            logger,
+		messageSerializer: messageSerializer,
+		client:            client,
+	}
+
+	for _, p := range pipelines {
+		if p != nil {
+			factory.pipelines = append(factory.pipelines, p)
+		}
+	}
+
+	return factory
+}
+
+func (c *consumerFactory) CreateConsumer(
+	consumerConfiguration *consumerConfigurations.RedisStreamsConsumerConfiguration,
+	isConsumedNotifications ...func(message types.IMessage),
+) (consumer.Consumer, error) {
+	if consumerConfiguration != nil && len(c.pipelines) > 0 {
+		// a copy, so the configuration of the bus doesn't accumulate the factory pipelines
+		configuration := *consumerConfiguration
+		configuration.Pipelines = append(
+			append([]pipeline.ConsumerPipeline{}, c.pipelines...),
+			consumerConfiguration.Pipelines...,
+		)
+		consumerConfiguration = &configuration
+	}
+
+	return NewRedisStreamsConsumer(
+		c.streamsOptions,
+		c.client,
+		consumerConfiguration,
+		c.messageSerializer,
+		c.logger,
+		isConsumedNotifications...,
+	)
+}
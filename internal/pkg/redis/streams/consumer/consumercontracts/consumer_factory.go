@@ -0,0 +1,15 @@
+package consumercontracts
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+)
+
+// ConsumerFactory is a factory for creating consumers
+type ConsumerFactory interface {
+	CreateConsumer(
+		consumerConfiguration *configurations.RedisStreamsConsumerConfiguration,
+		isConsumedNotifications ...func(message messagingTypes.IMessage),
+	) (consumer.Consumer, error)
+}
@@ -0,0 +1,784 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	consumertracing "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+	errorUtils "github.com/DavidReque/go-food-delivery/internal/pkg/utils/errorutils"
+
+	"emperror.dev/errors"
+	"github.com/avast/retry-go"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const (
+	defaultDrainTimeout  = 10 * time.Second
+	defaultBlockTimeout  = 2 * time.Second
+	defaultClaimInterval = 30 * time.Second
+	// resumePollInterval is how often a worker of a paused consumer checks if the consumer was resumed
+	resumePollInterval = 500 * time.Millisecond
+	// defaultScheduleInterval is how often the due scheduled entries are moved back to the stream
+	defaultScheduleInterval = time.Second
+	// scheduleBatchSize is the max number of due entries moved back to the stream in each run of the script
+	scheduleBatchSize = 100
+)
+
+// releaseScheduledScript moves the due entries of the sorted set (KEYS[1]) back to the stream (KEYS[2]), the script
+// is atomic, so the instances of the group don't add twice the same entry
+var releaseScheduledScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, member in ipairs(due) do
+	local fields = {}
+	for key, value in pairs(cjson.decode(member)) do
+		table.insert(fields, key)
+		table.insert(fields, value)
+	end
+	redis.call('XADD', KEYS[2], '*', unpack(fields))
+	redis.call('ZREM', KEYS[1], member)
+end
+return #due
+`)
+
+type redisStreamsConsumer struct {
+	streamsOptions          *config.RedisStreamsOptions
+	client                  redis.UniversalClient
+	consumerConfiguration   *configurations.RedisStreamsConsumerConfiguration
+	messageSerializer       serializer.MessageSerializer
+	logger                  logger.Logger
+	handlers                []consumer.ConsumerHandler
+	handlersLock            sync.Mutex
+	pipelines               []pipeline.ConsumerPipeline
+	isConsumedNotifications []func(message messagingTypes.IMessage)
+	cancel                  context.CancelFunc
+	consumeDone             chan struct{}
+	lock                    sync.Mutex
+	drainState              consumer.DrainState
+	paused                  bool
+}
+
+// NewRedisStreamsConsumer crea un consumer que lee el stream de su configuracion con un consumer group, cada entrada
+// se confirma con XACK despues de que sus handlers terminan sin error, las entradas sin ack se reclaman con XAUTOCLAIM
+// y despues de `MaxDeliveries` entregas se mueven al dead letter stream
+func NewRedisStreamsConsumer(
+	streamsOptions *config.RedisStreamsOptions,
+	client redis.UniversalClient,
+	consumerConfiguration *configurations.RedisStreamsConsumerConfiguration,
+	messageSerializer serializer.MessageSerializer,
+	logger logger.Logger,
+	isConsumedNotifications ...func(message messagingTypes.IMessage),
+) (consumer.Consumer, error) {
+	if consumerConfiguration == nil {
+		return nil, errors.New("consumer configuration is required")
+	}
+
+	if consumerConfiguration.ConsumerMessageType == nil {
+		return nil, errors.New("consumer message type is required")
+	}
+
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+
+	return &redisStreamsConsumer{
+		streamsOptions:          streamsOptions,
+		client:                  client,
+		consumerConfiguration:   consumerConfiguration,
+		messageSerializer:       messageSerializer,
+		logger:                  logger,
+		handlers:                consumerConfiguration.Handlers,
+		pipelines:               consumerConfiguration.Pipelines,
+		isConsumedNotifications: isConsumedNotifications,
+		drainState:              consumer.DrainStateRunning,
+	}, nil
+}
+
+func (r *redisStreamsConsumer) IsConsumed(h func(message messagingTypes.IMessage)) {
+	r.isConsumedNotifications = append(r.isConsumedNotifications, h)
+}
+
+func (r *redisStreamsConsumer) ConnectionHandler(handler consumer.ConsumerHandler) {
+	r.handlersLock.Lock()
+	defer r.handlersLock.Unlock()
+
+	r.handlers = append(r.handlers, handler)
+}
+
+// GetName returns the name of the consumer
+func (r *redisStreamsConsumer) GetName() string {
+	return r.consumerConfiguration.Name
+}
+
+// Start creates the consumer group if it doesn't exist and starts the workers, the claimer of the pending entries and
+// the scheduler of the scheduled entries, they read the stream in the background until the consumer is stopped
+func (r *redisStreamsConsumer) Start(ctx context.Context) error {
+	stream, group := r.consumerConfiguration.Stream, r.consumerConfiguration.Group
+
+	// the group starts at the first entry of the stream, so the entries added before the first start are consumed
+	err := r.client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return errors.WrapIff(err, "error in creating the group `%s` of stream `%s`", group, stream)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	consumeDone := make(chan struct{})
+
+	r.lock.Lock()
+	r.cancel = cancel
+	r.consumeDone = consumeDone
+	r.drainState = consumer.DrainStateRunning
+	r.paused = false
+	r.lock.Unlock()
+
+	workers := r.consumerConfiguration.ConcurrencyLimit
+	if workers < 1 {
+		workers = 1
+	}
+
+	consumerName := r.consumerName()
+	waitGroup := sync.WaitGroup{}
+
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func(name string) {
+			defer errorUtils.HandlePanic()
+			defer waitGroup.Done()
+
+			r.read(ctx, name)
+		}(fmt.Sprintf("%s-%d", consumerName, i))
+	}
+
+	waitGroup.Add(1)
+	go func() {
+		defer errorUtils.HandlePanic()
+		defer waitGroup.Done()
+
+		r.claim(ctx, fmt.Sprintf("%s-claimer", consumerName))
+	}()
+
+	waitGroup.Add(1)
+	go func() {
+		defer errorUtils.HandlePanic()
+		defer waitGroup.Done()
+
+		r.releaseScheduled(ctx)
+	}()
+
+	go func() {
+		waitGroup.Wait()
+		close(consumeDone)
+	}()
+
+	r.logger.Infof(
+		"consumer %s joined the group `%s` of stream `%s` with %d workers",
+		r.GetName(),
+		group,
+		stream,
+		workers,
+	)
+
+	return nil
+}
+
+// Stop stops reading the stream after the in-flight entries finish, the entries read and not acked before the drain timeout
+// stay pending in the group and they are claimed by other instance
+func (r *redisStreamsConsumer) Stop() error {
+	r.lock.Lock()
+	cancel, consumeDone := r.cancel, r.consumeDone
+	r.lock.Unlock()
+
+	if cancel == nil {
+		r.setDrainState(consumer.DrainStateDrained)
+		return nil
+	}
+
+	r.setDrainState(consumer.DrainStateDraining)
+	cancel()
+
+	drainTimeout := defaultDrainTimeout
+	if r.streamsOptions != nil && r.streamsOptions.DrainTimeout > 0 {
+		drainTimeout = r.streamsOptions.DrainTimeout
+	}
+
+	select {
+	case <-consumeDone:
+	case <-time.After(drainTimeout):
+		r.logger.Errorf("consumer %s didn't drain its in-flight messages in %s", r.GetName(), drainTimeout)
+	}
+
+	r.setDrainState(consumer.DrainStateDrained)
+
+	return nil
+}
+
+// Pause stops reading new entries, the workers wait until the consumer is resumed
+func (r *redisStreamsConsumer) Pause() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.paused || r.cancel == nil {
+		return nil
+	}
+	r.paused = true
+	r.logger.Infof("consumer %s paused", r.GetName())
+
+	return nil
+}
+
+func (r *redisStreamsConsumer) Resume() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.paused {
+		return nil
+	}
+	r.paused = false
+	r.logger.Infof("consumer %s resumed", r.GetName())
+
+	return nil
+}
+
+func (r *redisStreamsConsumer) IsPaused() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.paused
+}
+
+// DrainState returns the drain state of the consumer
+func (r *redisStreamsConsumer) DrainState() consumer.DrainState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.drainState
+}
+
+func (r *redisStreamsConsumer) setDrainState(state consumer.DrainState) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.drainState = state
+}
+
+// consumerName is the name of the instance in the consumer groups, each worker adds its index to it
+func (r *redisStreamsConsumer) consumerName() string {
+	name := ""
+	if r.streamsOptions != nil {
+		name = r.streamsOptions.ConsumerName
+	}
+
+	if name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "consumer"
+		}
+		name = hostname
+	}
+
+	return fmt.Sprintf("%s-%s", name, r.GetName())
+}
+
+// read reads the new entries of the stream with XREADGROUP, the entries of a read are handled one by one
+func (r *redisStreamsConsumer) read(ctx context.Context, consumerName string) {
+	blockTimeout := defaultBlockTimeout
+	if r.streamsOptions != nil && r.streamsOptions.BlockTimeout > 0 {
+		blockTimeout = r.streamsOptions.BlockTimeout
+	}
+
+	for ctx.Err() == nil {
+		if r.IsPaused() && !r.waitResume(ctx) {
+			return
+		}
+
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.consumerConfiguration.Group,
+			Consumer: consumerName,
+			Streams:  []string{r.consumerConfiguration.Stream, ">"},
+			Count:    int64(r.consumerConfiguration.PrefetchCount),
+			Block:    blockTimeout,
+		}).Result()
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			r.logger.Errorf(
+				"error in reading stream `%s` by consumer %s: %v",
+				r.consumerConfiguration.Stream,
+				r.GetName(),
+				err,
+			)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				// the entries that are not handled because the consumer stopped stay pending and they are claimed later
+				if !r.handleEntry(ctx, entry, 1) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// claim takes the pending entries that are idle more than `ClaimMinIdle` with XAUTOCLAIM, e.g. the entries of a stopped
+// instance or the entries whose handlers failed, and handles them again
+func (r *redisStreamsConsumer) claim(ctx context.Context, consumerName string) {
+	claimInterval := defaultClaimInterval
+	if r.streamsOptions != nil && r.streamsOptions.ClaimInterval > 0 {
+		claimInterval = r.streamsOptions.ClaimInterval
+	}
+
+	ticker := time.NewTicker(claimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if r.IsPaused() {
+			continue
+		}
+
+		start := "0-0"
+		for {
+			entries, next, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   r.consumerConfiguration.Stream,
+				Group:    r.consumerConfiguration.Group,
+				MinIdle:  r.consumerConfiguration.ClaimMinIdle,
+				Start:    start,
+				Count:    int64(r.consumerConfiguration.PrefetchCount),
+				Consumer: consumerName,
+			}).Result()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				r.logger.Errorf(
+					"error in claiming the pending entries of stream `%s` by consumer %s: %v",
+					r.consumerConfiguration.Stream,
+					r.GetName(),
+					err,
+				)
+				break
+			}
+
+			for _, entry := range entries {
+				if !r.handleEntry(ctx, entry, r.deliveries(ctx, entry.ID)) {
+					return
+				}
+			}
+
+			if next == "0-0" || next == "" {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// deliveries returns how many times the pending entry was delivered, XAUTOCLAIM counts the claim as a delivery
+func (r *redisStreamsConsumer) deliveries(ctx context.Context, id string) int64 {
+	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: r.consumerConfiguration.Stream,
+		Group:  r.consumerConfiguration.Group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 1
+	}
+
+	return pending[0].RetryCount
+}
+
+// handleEntry returns false if the entry was not handled because the consumer stopped
+func (r *redisStreamsConsumer) handleEntry(ctx context.Context, entry redis.XMessage, deliveries int64) bool {
+	// the entries trimmed from the stream are still pending, they have no values
+	if len(entry.Values) == 0 {
+		r.ack(ctx, entry.ID)
+		return true
+	}
+
+	body, meta := types.FromValues(entry.Values)
+
+	// the entries of a scheduled message that are moved back to the stream are handled only by the group that scheduled them
+	if group, ok := entry.Values[types.ScheduledGroupField]; ok && group != r.consumerConfiguration.Group {
+		r.ack(ctx, entry.ID)
+		return true
+	}
+
+	// the streams have no delayed delivery, the scheduled entries wait in a sorted set out of the pending entries,
+	// so they are not claimed or sent to the dead letter stream while they wait
+	if scheduled := messageHeader.GetMessageScheduled(meta); time.Now().Before(scheduled) {
+		if err := r.schedule(ctx, entry, scheduled); err != nil {
+			// the entry stays pending, so it is claimed and scheduled again
+			r.logger.Errorf("error in scheduling entry `%s` of stream `%s`: %v", entry.ID, r.consumerConfiguration.Stream, err)
+		}
+
+		return true
+	}
+
+	readCtx := ctx
+	// the in-flight handler is not canceled on stop, it is drained
+	ctx = context.WithoutCancel(ctx)
+
+	consumerTraceOption := &consumertracing.ConsumerTracingOptions{
+		MessagingSystem: "redis",
+		DestinationKind: "stream",
+		Destination:     r.consumerConfiguration.Stream,
+		OtherAttributes: []attribute.KeyValue{
+			semconv.MessagingMessageID(entry.ID),
+			attribute.String("messaging.redis.consumer_group", r.consumerConfiguration.Group),
+			attribute.Int64("messaging.redis.deliveries", deliveries),
+		},
+	}
+	ctx, beforeConsumeSpan := consumertracing.StartConsumerSpan(ctx, &meta, string(body), consumerTraceOption)
+
+	consumeContext := r.createConsumeContext(entry, body, meta, deliveries)
+	ctx = consumer.ContextWithConsumeInfo(ctx, consumer.ConsumeInfo{ConsumerName: r.GetName(), Consumer: r})
+
+	for {
+		attempts, err := r.runHandlers(ctx, consumeContext)
+		if err == nil {
+			r.ack(ctx, entry.ID)
+			_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, nil)
+
+			for _, notification := range r.isConsumedNotifications {
+				if notification != nil {
+					notification(consumeContext.Message())
+				}
+			}
+
+			return true
+		}
+
+		// while the consumer is paused, e.g. by an open circuit breaker, the entry waits in the worker
+		// and it is handled again when the consumer is resumed
+		if r.IsPaused() {
+			if !r.waitResume(readCtx) {
+				_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, err)
+				return false
+			}
+			continue
+		}
+
+		r.logger.Errorf(
+			"[redisStreamsConsumer.Handle] message with id `%s` failed after %d attempts in delivery %d: %v",
+			consumeContext.MessageId(),
+			attempts,
+			deliveries,
+			err,
+		)
+
+		maxDeliveries := r.consumerConfiguration.MaxDeliveries
+		if maxDeliveries > 0 && deliveries >= maxDeliveries {
+			if deadLetterErr := r.publishToDeadLetter(ctx, entry, err, deliveries); deadLetterErr != nil {
+				// the entry stays pending, so it is claimed and sent to the dead letter stream again
+				r.logger.Errorf(
+					"error in sending message with id `%s` to the dead letter stream: %v",
+					consumeContext.MessageId(),
+					deadLetterErr,
+				)
+			} else {
+				r.ack(ctx, entry.ID)
+			}
+		}
+
+		// without ack the entry stays pending and it is delivered again by the claimer after `ClaimMinIdle`
+		_ = consumertracing.FinishConsumerSpan(beforeConsumeSpan, err)
+
+		return true
+	}
+}
+
+// schedule adds the entry to the sorted set of the scheduled entries with its time as score and acks it in a transaction
+func (r *redisStreamsConsumer) schedule(ctx context.Context, entry redis.XMessage, scheduled time.Time) error {
+	values := make(map[string]interface{}, len(entry.Values)+2)
+	for key, value := range entry.Values {
+		values[key] = value
+	}
+	values[types.ScheduledGroupField] = r.consumerConfiguration.Group
+	// the id of the first entry keeps the member unique, also when the entry is scheduled again after a claim
+	if _, ok := values[types.ScheduledOriginalIdField]; !ok {
+		values[types.ScheduledOriginalIdField] = entry.ID
+	}
+
+	member, err := json.Marshal(values)
+	if err != nil {
+		return errors.WrapIf(err, "error in marshaling the scheduled entry")
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, r.consumerConfiguration.GetScheduledKey(), redis.Z{
+			Score:  float64(scheduled.UnixMilli()),
+			Member: string(member),
+		})
+		pipe.XAck(ctx, r.consumerConfiguration.Stream, r.consumerConfiguration.Group, entry.ID)
+
+		return nil
+	})
+
+	return errors.WrapIff(err, "error in adding to the sorted set `%s`", r.consumerConfiguration.GetScheduledKey())
+}
+
+// releaseScheduled moves the due scheduled entries back to the stream, where the group reads them as new entries
+func (r *redisStreamsConsumer) releaseScheduled(ctx context.Context) {
+	scheduleInterval := defaultScheduleInterval
+	if r.streamsOptions != nil && r.streamsOptions.ScheduleInterval > 0 {
+		scheduleInterval = r.streamsOptions.ScheduleInterval
+	}
+
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			released, err := releaseScheduledScript.Run(
+				ctx,
+				r.client,
+				[]string{r.consumerConfiguration.GetScheduledKey(), r.consumerConfiguration.Stream},
+				time.Now().UnixMilli(),
+				scheduleBatchSize,
+			).Int()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				r.logger.Errorf(
+					"error in releasing the scheduled entries of stream `%s` by consumer %s: %v",
+					r.consumerConfiguration.Stream,
+					r.GetName(),
+					err,
+				)
+				break
+			}
+
+			if released < scheduleBatchSize {
+				break
+			}
+		}
+	}
+}
+
+func (r *redisStreamsConsumer) ack(ctx context.Context, id string) {
+	err := r.client.XAck(ctx, r.consumerConfiguration.Stream, r.consumerConfiguration.Group, id).Err()
+	if err != nil {
+		r.logger.Errorf("error in acknowledging entry `%s` of stream `%s`: %v", id, r.consumerConfiguration.Stream, err)
+	}
+}
+
+// waitResume waits until the consumer is resumed, it returns false if the consumer stopped before
+func (r *redisStreamsConsumer) waitResume(ctx context.Context) bool {
+	ticker := time.NewTicker(resumePollInterval)
+	defer ticker.Stop()
+
+	for r.IsPaused() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
+
+// runHandlers runs the handlers with their pipelines and retries, it returns the attempts of the failed handler
+func (r *redisStreamsConsumer) runHandlers(
+	ctx context.Context,
+	consumeContext messagingTypes.MessageConsumeContext,
+) (uint, error) {
+	r.handlersLock.Lock()
+	handlers := r.handlers
+	r.handlersLock.Unlock()
+
+	info, _ := consumer.ConsumeInfoFromContext(ctx)
+
+	for _, handler := range handlers {
+		info.HandlerName = consumer.GetHandlerName(handler)
+
+		var attempts uint
+		err := retry.Do(func() error {
+			info.Attempt = attempts
+			ctx := consumer.ContextWithConsumeInfo(ctx, info)
+			attempts++
+
+			return r.runPipelines(ctx, handler, consumeContext)
+		}, r.retryOptions(ctx)...)
+		if err != nil {
+			return attempts, err
+		}
+	}
+
+	return 0, nil
+}
+
+// runPipelines runs the consumer pipelines around the handler, the first pipeline is the outermost one
+func (r *redisStreamsConsumer) runPipelines(
+	ctx context.Context,
+	handler consumer.ConsumerHandler,
+	consumeContext messagingTypes.MessageConsumeContext,
+) error {
+	var next pipeline.ConsumerHandlerFunc = func(ctx context.Context) error {
+		return handler.Handle(ctx, consumeContext)
+	}
+
+	for i := len(r.pipelines) - 1; i >= 0; i-- {
+		pipe, inner := r.pipelines[i], next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, consumeContext, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+func (r *redisStreamsConsumer) retryOptions(ctx context.Context) []retry.Option {
+	attempts := r.consumerConfiguration.RetryAttempts
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	return []retry.Option{
+		retry.Attempts(attempts),
+		retry.Delay(r.consumerConfiguration.RetryDelay),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retry.RetryIf(func(err error) bool {
+			// the validation errors and an open circuit fail on the first attempt, and a paused consumer doesn't retry
+			return !customErrors.IsValidationError(err) && !circuitbreaker.IsCircuitOpen(err) && !r.IsPaused()
+		}),
+		retry.Context(ctx),
+	}
+}
+
+func (r *redisStreamsConsumer) createConsumeContext(
+	entry redis.XMessage,
+	body []byte,
+	meta metadata.Metadata,
+	deliveries int64,
+) messagingTypes.MessageConsumeContext {
+	contentType := messageHeader.GetMessageContentType(meta)
+	// the consumer only receives messages of its type, the type header has the short name of the message
+	// that can be ambiguous between packages
+	messageType := typemapper.GetFullTypeNameByType(reflect.PointerTo(r.consumerConfiguration.ConsumerMessageType))
+
+	return messagingTypes.NewMessageConsumeContext(
+		r.deserializeData(contentType, messageType, body),
+		meta,
+		contentType,
+		messageType,
+		entryTime(entry.ID),
+		uint64(deliveries),
+		messageHeader.GetMessageId(meta),
+		messageHeader.GetCorrelationId(meta),
+		body,
+	)
+}
+
+// deserializeData deserializes the body of the entry with the serializer of its content type
+func (r *redisStreamsConsumer) deserializeData(
+	contentType string,
+	messageType string,
+	body []byte,
+) messagingTypes.IMessage {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	if len(body) == 0 {
+		r.logger.Error("message body is nil or empty in the consumer")
+		return nil
+	}
+
+	message, err := r.messageSerializer.Deserialize(body, messageType, contentType)
+	if err != nil {
+		r.logger.Errorf("error in deserializing of type '%s' in the consumer: %v", messageType, err)
+		return nil
+	}
+
+	return message
+}
+
+// publishToDeadLetter adds a copy of the failed entry to the dead letter stream with the error details in its fields
+func (r *redisStreamsConsumer) publishToDeadLetter(
+	ctx context.Context,
+	entry redis.XMessage,
+	handleErr error,
+	deliveries int64,
+) error {
+	values := make(map[string]interface{}, len(entry.Values)+6)
+	for key, value := range entry.Values {
+		values[key] = value
+	}
+
+	values[types.DeadLetterExceptionMessageField] = handleErr.Error()
+	values[types.DeadLetterConsumerNameField] = r.GetName()
+	values[types.DeadLetterDeliveriesField] = strconv.FormatInt(deliveries, 10)
+	values[types.DeadLetterOriginalStreamField] = r.consumerConfiguration.Stream
+	values[types.DeadLetterOriginalIdField] = entry.ID
+	values[types.DeadLetterFailedAtField] = time.Now().UTC().Format(time.RFC3339Nano)
+
+	err := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.consumerConfiguration.DeadLetterStream,
+		Values: values,
+	}).Err()
+	if err != nil {
+		return errors.WrapIff(
+			err,
+			"error in publishing to the dead letter stream `%s`",
+			r.consumerConfiguration.DeadLetterStream,
+		)
+	}
+
+	return nil
+}
+
+// entryTime returns the time of the entry from its id, the ids are `<unix milliseconds>-<sequence>`
+func entryTime(id string) time.Time {
+	millis, _, _ := strings.Cut(id, "-")
+
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+
+	return time.UnixMilli(ms)
+}
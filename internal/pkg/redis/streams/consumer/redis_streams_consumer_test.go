@@ -0,0 +1,231 @@
+package consumer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer/json"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger/defaultlogger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer/configurations"
+	redisProducer "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
+
+	"emperror.dev/errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPlaced struct {
+	*messagingTypes.Message
+	OrderId string
+}
+
+func newOrderPlaced(orderId string) *orderPlaced {
+	return &orderPlaced{Message: messagingTypes.NewMessage(uuid.NewV4().String()), OrderId: orderId}
+}
+
+// recordingHandler envia al canal los mensajes que recibe y falla con `err` si no es nil
+type recordingHandler struct {
+	received chan *orderPlaced
+	err      error
+}
+
+func newRecordingHandler(err error) *recordingHandler {
+	return &recordingHandler{received: make(chan *orderPlaced, 100), err: err}
+}
+
+func (h *recordingHandler) Handle(_ context.Context, consumeContext messagingTypes.MessageConsumeContext) error {
+	if message, ok := consumeContext.Message().(*orderPlaced); ok {
+		h.received <- message
+	}
+
+	return h.err
+}
+
+func (h *recordingHandler) next(t *testing.T) *orderPlaced {
+	t.Helper()
+
+	select {
+	case message := <-h.received:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not delivered")
+
+		return nil
+	}
+}
+
+func init() {
+	typemapper.RegisterType(reflect.TypeOf(&orderPlaced{}))
+}
+
+var testOptions = &config.RedisStreamsOptions{ //nolint:gochecknoglobals
+	BlockTimeout:     100 * time.Millisecond,
+	ClaimInterval:    100 * time.Millisecond,
+	ScheduleInterval: 100 * time.Millisecond,
+	DrainTimeout:     time.Second,
+}
+
+func newTestClient(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func startTestConsumer(
+	t *testing.T,
+	client redis.UniversalClient,
+	handler consumer.ConsumerHandler,
+	configure func(configuration *configurations.RedisStreamsConsumerConfiguration),
+) *configurations.RedisStreamsConsumerConfiguration {
+	t.Helper()
+
+	configuration := configurations.NewDefaultRedisStreamsConsumerConfiguration(&orderPlaced{})
+	configuration.Handlers = []consumer.ConsumerHandler{handler}
+	configuration.RetryAttempts = 1
+	if configure != nil {
+		configure(configuration)
+	}
+
+	c, err := NewRedisStreamsConsumer(
+		testOptions,
+		client,
+		configuration,
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+		defaultlogger.GetLogger(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background()))
+	t.Cleanup(func() { _ = c.Stop() })
+
+	return configuration
+}
+
+func newTestProducer(t *testing.T, client redis.UniversalClient) producer.Producer {
+	t.Helper()
+
+	p, err := redisProducer.NewRedisStreamsProducer(
+		testOptions,
+		client,
+		nil,
+		defaultlogger.GetLogger(),
+		json.NewDefaultMessageJsonSerializer(json.NewDefaultJsonSerializer()),
+	)
+	require.NoError(t, err)
+
+	return p
+}
+
+func pendingCount(t *testing.T, client redis.UniversalClient, configuration *configurations.RedisStreamsConsumerConfiguration) int64 {
+	t.Helper()
+
+	pending, err := client.XPending(context.Background(), configuration.Stream, configuration.Group).Result()
+	require.NoError(t, err)
+
+	return pending.Count
+}
+
+func Test_Consumer_Acks_The_Entry_After_The_Handler_Succeeds(t *testing.T) {
+	client := newTestClient(t)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, client, handler, nil)
+
+	require.NoError(t, newTestProducer(t, client).PublishMessage(context.Background(), newOrderPlaced("order-1")))
+
+	assert.Equal(t, "order-1", handler.next(t).OrderId)
+	assert.Eventually(t, func() bool {
+		return pendingCount(t, client, configuration) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Consumer_Keeps_Scheduled_Entries_Out_Of_The_Pending_Entries(t *testing.T) {
+	client := newTestClient(t)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, client, handler, func(configuration *configurations.RedisStreamsConsumerConfiguration) {
+		configuration.ClaimMinIdle = 50 * time.Millisecond
+		configuration.MaxDeliveries = 1
+	})
+	p := newTestProducer(t, client)
+
+	require.NoError(t, p.ScheduleMessage(context.Background(), newOrderPlaced("order-1"), nil, time.Now().Add(time.Hour)))
+	require.NoError(t, p.PublishMessage(context.Background(), newOrderPlaced("order-2")))
+
+	// the entry after the scheduled one is not blocked by it
+	assert.Equal(t, "order-2", handler.next(t).OrderId)
+	assert.Eventually(t, func() bool {
+		return pendingCount(t, client, configuration) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// the scheduled entry waits in the sorted set, it is not claimed or sent to the dead letter stream
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int64(1), client.ZCard(context.Background(), configuration.GetScheduledKey()).Val())
+	assert.Equal(t, int64(0), client.XLen(context.Background(), configuration.DeadLetterStream).Val())
+}
+
+func Test_Consumer_Handles_Scheduled_Entries_When_They_Are_Due(t *testing.T) {
+	client := newTestClient(t)
+	handler := newRecordingHandler(nil)
+	configuration := startTestConsumer(t, client, handler, nil)
+
+	due := time.Now().Add(300 * time.Millisecond)
+	require.NoError(t, newTestProducer(t, client).ScheduleMessage(context.Background(), newOrderPlaced("order-1"), nil, due))
+
+	assert.Equal(t, "order-1", handler.next(t).OrderId)
+	assert.False(t, time.Now().Before(due))
+	assert.Equal(t, int64(0), client.ZCard(context.Background(), configuration.GetScheduledKey()).Val())
+}
+
+func Test_Scheduled_Entries_Are_Handled_Once_By_Each_Group(t *testing.T) {
+	client := newTestClient(t)
+	orders := newRecordingHandler(nil)
+	startTestConsumer(t, client, orders, nil)
+	notifications := newRecordingHandler(nil)
+	startTestConsumer(t, client, notifications, func(configuration *configurations.RedisStreamsConsumerConfiguration) {
+		configuration.Group = "notifications"
+	})
+
+	require.NoError(t, newTestProducer(t, client).PublishMessageWithDelay(context.Background(), newOrderPlaced("order-1"), nil, 200*time.Millisecond))
+
+	assert.Equal(t, "order-1", orders.next(t).OrderId)
+	assert.Equal(t, "order-1", notifications.next(t).OrderId)
+	// the entries moved back to the stream for a group are skipped by the other group
+	assert.Never(t, func() bool {
+		return len(orders.received) > 0 || len(notifications.received) > 0
+	}, time.Second, 50*time.Millisecond)
+}
+
+func Test_Consumer_Moves_The_Entry_To_The_Dead_Letter_Stream_After_Max_Deliveries(t *testing.T) {
+	client := newTestClient(t)
+	handler := newRecordingHandler(errors.New("handler failed"))
+	configuration := startTestConsumer(t, client, handler, func(configuration *configurations.RedisStreamsConsumerConfiguration) {
+		configuration.ClaimMinIdle = 50 * time.Millisecond
+		configuration.MaxDeliveries = 2
+	})
+
+	require.NoError(t, newTestProducer(t, client).PublishMessage(context.Background(), newOrderPlaced("order-1")))
+	handler.next(t)
+	handler.next(t)
+
+	assert.Eventually(t, func() bool {
+		return client.XLen(context.Background(), configuration.DeadLetterStream).Val() == 1 &&
+			pendingCount(t, client, configuration) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	entries := client.XRange(context.Background(), configuration.DeadLetterStream, "-", "+").Val()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "handler failed", entries[0].Values[types.DeadLetterExceptionMessageField])
+	assert.Equal(t, "2", entries[0].Values[types.DeadLetterDeliveriesField])
+}
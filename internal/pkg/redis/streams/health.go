@@ -0,0 +1,12 @@
+package streams
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/bus"
+)
+
+// NewRedisStreamsConsumersHealthChecker reporta el drenado de cada consumer de los streams, la conexion la reporta el health de redis
+func NewRedisStreamsConsumersHealthChecker(bus bus.RedisStreamsBus) contracts.Health {
+	return consumer.NewConsumersHealthChecker(bus, "redis_streams_consumers")
+}
@@ -0,0 +1,3 @@
+package configurations
+
+type RedisStreamsProducerConfigurationBuilderFuc func(builder RedisStreamsProducerConfigurationBuilder)
@@ -0,0 +1,24 @@
+package configurations
+
+import (
+	"reflect"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+)
+
+type RedisStreamsProducerConfiguration struct {
+	ProducerMessageType reflect.Type
+	// Stream es el stream de los mensajes del tipo, por defecto el nombre del exchange del tipo en rabbitmq
+	Stream string
+	// Pipelines se ejecutan antes de publicar cada mensaje, el primero envuelve a los demas
+	Pipelines []pipeline.ProducerPipeline
+}
+
+func NewDefaultRedisStreamsProducerConfiguration(messageType types2.IMessage) *RedisStreamsProducerConfiguration {
+	return &RedisStreamsProducerConfiguration{
+		ProducerMessageType: utils.GetMessageBaseReflectType(messageType),
+		Stream:              utils.GetTopicOrExchangeName(messageType),
+	}
+}
@@ -0,0 +1,43 @@
+package configurations
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+)
+
+type RedisStreamsProducerConfigurationBuilder interface {
+	WithStream(stream string) RedisStreamsProducerConfigurationBuilder
+	WithPipelines(pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc) RedisStreamsProducerConfigurationBuilder
+	Build() *RedisStreamsProducerConfiguration
+}
+
+type redisStreamsProducerConfigurationBuilder struct {
+	redisStreamsProducerConfigurations *RedisStreamsProducerConfiguration
+}
+
+func NewRedisStreamsProducerConfigurationBuilder(messageType types.IMessage) RedisStreamsProducerConfigurationBuilder {
+	return &redisStreamsProducerConfigurationBuilder{
+		redisStreamsProducerConfigurations: NewDefaultRedisStreamsProducerConfiguration(messageType),
+	}
+}
+
+func (b *redisStreamsProducerConfigurationBuilder) WithStream(stream string) RedisStreamsProducerConfigurationBuilder {
+	b.redisStreamsProducerConfigurations.Stream = stream
+	return b
+}
+
+func (b *redisStreamsProducerConfigurationBuilder) WithPipelines(
+	pipelineBuilderFunc pipeline.ProducerPipelineConfigurationBuilderFunc,
+) RedisStreamsProducerConfigurationBuilder {
+	builder := pipeline.NewProducerPipelineConfigurationBuilder()
+	if pipelineBuilderFunc != nil {
+		pipelineBuilderFunc(builder)
+	}
+	b.redisStreamsProducerConfigurations.Pipelines = builder.Build().Pipelines
+
+	return b
+}
+
+func (b *redisStreamsProducerConfigurationBuilder) Build() *RedisStreamsProducerConfiguration {
+	return b.redisStreamsProducerConfigurations
+}
@@ -0,0 +1,48 @@
+package producer
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/producercontracts"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type producerFactory struct {
+	client            redis.UniversalClient
+	logger            logger.Logger
+	messageSerializer serializer.MessageSerializer
+	streamsOptions    *config.RedisStreamsOptions
+}
+
+func NewProducerFactory(
+	streamsOptions *config.RedisStreamsOptions,
+	client redis.UniversalClient,
+	messageSerializer serializer.MessageSerializer,
+	l logger.Logger,
+) producercontracts.ProducerFactory {
+	return &producerFactory{
+		streamsOptions:    streamsOptions,
+		logger:            l,
+		client:            client,
+		messageSerializer: messageSerializer,
+	}
+}
+
+func (p *producerFactory) CreateProducer(
+	redisStreamsProducersConfiguration map[string]*producerConfigurations.RedisStreamsProducerConfiguration,
+	isProducedNotifications ...func(message types.IMessage),
+) (producer.Producer, error) {
+	return NewRedisStreamsProducer(
+		p.streamsOptions,
+		p.client,
+		redisStreamsProducersConfiguration,
+		p.logger,
+		p.messageSerializer,
+		isProducedNotifications...,
+	)
+}
@@ -0,0 +1,14 @@
+package producercontracts
+
+import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	types2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+)
+
+type ProducerFactory interface {
+	CreateProducer(
+		redisStreamsProducersConfiguration map[string]*configurations.RedisStreamsProducerConfiguration,
+		isProducedNotifications ...func(message types2.IMessage),
+	) (producer.Producer, error)
+}
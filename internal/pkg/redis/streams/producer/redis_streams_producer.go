@@ -0,0 +1,301 @@
+package producer
+
+import (
+	"context"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	producertracing "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/tracing/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	messagingTypes "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/serializer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/types"
+
+	"emperror.dev/errors"
+	"github.com/redis/go-redis/v9"
+	uuid "github.com/satori/go.uuid"
+)
+
+type redisStreamsProducer struct {
+	logger                  logger.Logger
+	streamsOptions          *config.RedisStreamsOptions
+	client                  redis.UniversalClient
+	messageSerializer       serializer.MessageSerializer
+	producersConfigurations map[string]*configurations.RedisStreamsProducerConfiguration
+	isProducedNotifications []func(message messagingTypes.IMessage)
+}
+
+// pendingEntry is a serialized message with its producer span, the span is finished when redis adds the entry
+type pendingEntry struct {
+	message    messagingTypes.IMessage
+	args       *redis.XAddArgs
+	finishSpan func(err error) error
+}
+
+func NewRedisStreamsProducer(
+	cfg *config.RedisStreamsOptions,
+	client redis.UniversalClient,
+	redisStreamsProducersConfiguration map[string]*configurations.RedisStreamsProducerConfiguration,
+	logger logger.Logger,
+	messageSerializer serializer.MessageSerializer,
+	isProducedNotifications ...func(message messagingTypes.IMessage),
+) (producer.Producer, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+
+	return &redisStreamsProducer{
+		logger:                  logger,
+		streamsOptions:          cfg,
+		client:                  client,
+		messageSerializer:       messageSerializer,
+		producersConfigurations: redisStreamsProducersConfiguration,
+		isProducedNotifications: isProducedNotifications,
+	}, nil
+}
+
+// IsProduced is a method that adds a notification function to the list of produced notifications
+func (r *redisStreamsProducer) IsProduced(h func(message messagingTypes.IMessage)) {
+	r.isProducedNotifications = append(r.isProducedNotifications, h)
+}
+
+// PublishMessage adds the message to the stream of its type
+func (r *redisStreamsProducer) PublishMessage(ctx context.Context, message messagingTypes.IMessage) error {
+	return r.PublishMessageWithTopicName(ctx, message, nil, "")
+}
+
+// PublishMessageWithTopicName adds the message to the stream `topicName`, if it is empty the stream of the message type is used
+func (r *redisStreamsProducer) PublishMessageWithTopicName(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	return r.PublishMessages(ctx, []messagingTypes.IMessage{message}, meta, topicName)
+}
+
+// PublishMessageWithDelay publishes a message that is handled by the consumers after the delay
+func (r *redisStreamsProducer) PublishMessageWithDelay(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	delay time.Duration,
+) error {
+	return r.ScheduleMessage(ctx, message, meta, time.Now().Add(delay))
+}
+
+// ScheduleMessage adds the message right away with its scheduled time in the metadata, the streams have no delayed
+// delivery so each consumer group keeps the entry in a sorted set and adds it back to the stream at the scheduled time
+func (r *redisStreamsProducer) ScheduleMessage(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	at time.Time,
+) error {
+	if meta == nil {
+		meta = metadata.New()
+	}
+	messageHeader.SetMessageScheduled(meta, at.UTC())
+
+	return r.PublishMessageWithTopicName(ctx, message, meta, "")
+}
+
+// PublishMessages adds the messages in a single round trip with a redis pipeline
+func (r *redisStreamsProducer) PublishMessages(
+	ctx context.Context,
+	messages []messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) error {
+	pending := make([]*pendingEntry, 0, len(messages))
+	for _, message := range messages {
+		p, err := r.prepare(ctx, message, meta, topicName)
+		if err != nil {
+			for _, prepared := range pending {
+				_ = prepared.finishSpan(err)
+			}
+			return err
+		}
+		// a pipeline skipped the publish
+		if p == nil {
+			continue
+		}
+		pending = append(pending, p)
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(pending))
+	_, _ = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, p := range pending {
+			cmds[i] = pipe.XAdd(ctx, p.args)
+		}
+		return nil
+	})
+
+	var publishErr error
+	for i, p := range pending {
+		err := cmds[i].Err()
+		if err != nil {
+			err = errors.WrapIff(err, "error in adding the message to the stream `%s`", p.args.Stream)
+		}
+
+		if err = p.finishSpan(err); err != nil {
+			publishErr = errors.Append(publishErr, err)
+			continue
+		}
+
+		for _, notification := range r.isProducedNotifications {
+			if notification != nil {
+				notification(p.message)
+			}
+		}
+	}
+
+	return publishErr
+}
+
+// prepare runs the pipelines of the message and returns its entry, it returns nil if a pipeline skipped the publish
+func (r *redisStreamsProducer) prepare(
+	ctx context.Context,
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+	topicName string,
+) (*pendingEntry, error) {
+	producerConfiguration := r.getProducerConfigurationByMessage(message)
+	if producerConfiguration == nil {
+		producerConfiguration = configurations.NewDefaultRedisStreamsProducerConfiguration(message)
+	}
+
+	stream := topicName
+	if stream == "" {
+		stream = producerConfiguration.Stream
+	}
+
+	// each message of a batch gets its own metadata, the message id and type are different
+	meta = r.getMetadata(message, copyMetadata(meta))
+
+	producerOptions := &producertracing.ProducerTracingOptions{
+		MessagingSystem: "redis",
+		DestinationKind: "stream",
+		Destination:     stream,
+	}
+
+	serializedObj, err := r.messageSerializer.Serialize(message)
+	if err != nil {
+		return nil, err
+	}
+	messageHeader.SetMessageContentType(meta, serializedObj.ContentType)
+	// the serializer headers, like the key id of an encrypted payload, travel with the message
+	for key, value := range serializedObj.Metadata {
+		meta.Set(key, value)
+	}
+
+	var pending *pendingEntry
+
+	// building the entry is the innermost handler, the pipelines can validate or enrich the message before it is added
+	publishHandler := func(ctx context.Context) error {
+		_, beforeProduceSpan := producertracing.StartProducerSpan(
+			ctx,
+			message,
+			&meta,
+			string(serializedObj.Data),
+			producerOptions,
+		)
+
+		args := &redis.XAddArgs{
+			Stream: stream,
+			Values: types.ToValues(serializedObj.Data, meta),
+		}
+		if r.streamsOptions != nil && r.streamsOptions.MaxLen > 0 {
+			args.MaxLen = r.streamsOptions.MaxLen
+			args.Approx = true
+		}
+
+		pending = &pendingEntry{
+			message: message,
+			args:    args,
+			finishSpan: func(err error) error {
+				return producertracing.FinishProducerSpan(beforeProduceSpan, err)
+			},
+		}
+
+		return nil
+	}
+
+	producerContext := &pipeline.ProducerContext{
+		Message:     message,
+		Metadata:    meta,
+		Body:        serializedObj.Data,
+		ContentType: serializedObj.ContentType,
+	}
+	if err := r.runPipelines(ctx, producerConfiguration.Pipelines, producerContext, publishHandler); err != nil {
+		if pending != nil {
+			_ = pending.finishSpan(err)
+		}
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// runPipelines runs the producer pipelines around the publish, the first pipeline is the outermost one
+func (r *redisStreamsProducer) runPipelines(
+	ctx context.Context,
+	pipelines []pipeline.ProducerPipeline,
+	producerContext *pipeline.ProducerContext,
+	publishHandler pipeline.ProducerHandlerFunc,
+) error {
+	next := publishHandler
+	for i := len(pipelines) - 1; i >= 0; i-- {
+		pipe, inner := pipelines[i], next
+		next = func(ctx context.Context) error {
+			return pipe.Handle(ctx, producerContext, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+func (r *redisStreamsProducer) getProducerConfigurationByMessage(
+	message messagingTypes.IMessage,
+) *configurations.RedisStreamsProducerConfiguration {
+	messageType := utils.GetMessageBaseReflectType(message)
+	return r.producersConfigurations[messageType.String()]
+}
+
+// getMetadata sets the headers of the message, the consumers pick the type and the deserializer of the message with them
+func (r *redisStreamsProducer) getMetadata(
+	message messagingTypes.IMessage,
+	meta metadata.Metadata,
+) metadata.Metadata {
+	messageHeader.SetMessageType(meta, typeregistry.GetTypeName(message))
+	messageHeader.SetMessageContentType(meta, r.messageSerializer.ContentType())
+	messageHeader.SetMessageId(meta, message.GeMessageId())
+	messageHeader.SetMessageCreated(meta, message.GetCreated())
+	messageHeader.SetMessageName(meta, utils.GetMessageName(message))
+
+	if messageHeader.GetCorrelationId(meta) == "" {
+		messageHeader.SetCorrelationId(meta, uuid.NewV4().String())
+	}
+
+	return meta
+}
+
+func copyMetadata(meta metadata.Metadata) metadata.Metadata {
+	copied := metadata.New()
+	for key, value := range meta {
+		copied[key] = value
+	}
+
+	return copied
+}
@@ -0,0 +1,103 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+
+	bus2 "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/bus"
+	consumermetrics "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/otel/metrics/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/health/contracts"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/metrics"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/bus"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/config"
+	streamsconsumer "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/consumer"
+	streamsproducer "github.com/DavidReque/go-food-delivery/internal/pkg/redis/streams/producer"
+
+	"go.uber.org/fx"
+)
+
+var (
+	// ModuleFunc provided to fxlog, it replaces the rabbitmq module in the deployments that only run redis,
+	// the redis client is provided by the `redis.Module`
+	// https://uber-go.github.io/fx/modules.html
+	ModuleFunc = func(streamsConfigurationConstructor interface{}) fx.Option { //nolint:gochecknoglobals
+		return fx.Module(
+			"redisstreamsfx",
+			fx.Provide(streamsConfigurationConstructor),
+			streamsProviders,
+			streamsInvokes,
+		)
+	}
+
+	streamsProviders = fx.Options( //nolint:gochecknoglobals
+		fx.Provide(config.ProvideConfig),
+		fx.Provide(fx.Annotate(
+			bus.NewRedisStreamsBus,
+			fx.ParamTags(``, ``, ``, `optional:"true"`),
+			fx.As(new(producer.Producer)),
+			fx.As(new(bus2.Bus)),
+			fx.As(new(bus.RedisStreamsBus)),
+		)),
+		fx.Provide(fx.Annotate(
+			newConsumerMetricsPipeline,
+			fx.ParamTags(`optional:"true"`),
+			fx.ResultTags(`group:"consumer_pipelines"`),
+		)),
+		fx.Provide(fx.Annotate(
+			streamsconsumer.NewConsumerFactory,
+			fx.ParamTags(``, ``, ``, ``, `group:"consumer_pipelines"`),
+		)),
+		fx.Provide(streamsproducer.NewProducerFactory),
+		fx.Provide(fx.Annotate(
+			NewRedisStreamsConsumersHealthChecker,
+			fx.As(new(contracts.Health)),
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, "healths")),
+		)))
+
+	streamsInvokes = fx.Options(fx.Invoke(registerHooks)) //nolint:gochecknoglobals
+)
+
+// newConsumerMetricsPipeline registra las metricas de los consumers de los streams, sin el modulo de metricas no se agrega el pipeline
+func newConsumerMetricsPipeline(appMetrics metrics.AppMetrics) (pipeline.ConsumerPipeline, error) {
+	if appMetrics == nil {
+		return nil, nil
+	}
+
+	return consumermetrics.NewConsumerMetricsPipeline(appMetrics)
+}
+
+func registerHooks(
+	lc fx.Lifecycle,
+	bus bus.RedisStreamsBus,
+	streamsOptions *config.RedisStreamsOptions,
+	logger logger.Logger,
+) {
+	if !streamsOptions.AutoStart {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// the start ctx is canceled after the start callbacks, the consumers live until the bus is stopped
+			if err := bus.Start(context.Background()); err != nil {
+				return err
+			}
+			logger.Info("redis streams consumers are listening.")
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// the hooks are stopped in reverse order, so the consumers drain before the redis module closes the client
+			if err := bus.Stop(); err != nil {
+				logger.Errorf("error shutting down redis streams consumers: %v", err)
+			} else {
+				logger.Info("redis streams consumers shutdown gracefully")
+			}
+
+			return nil
+		},
+	})
+}
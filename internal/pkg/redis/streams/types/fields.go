@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/metadata"
+)
+
+// BodyField es el campo de la entrada del stream con el mensaje serializado, los demas campos son la metadata
+const BodyField = "body"
+
+// campos que se agregan a las entradas enviadas al dead letter stream, sirven para inspeccionar y re-enviar las entradas fallidas
+const (
+	DeadLetterExceptionMessageField = "x-exception-message"
+	DeadLetterConsumerNameField     = "x-consumer-name"
+	DeadLetterDeliveriesField       = "x-deliveries"
+	DeadLetterOriginalStreamField   = "x-original-stream"
+	DeadLetterOriginalIdField       = "x-original-id"
+	DeadLetterFailedAtField         = "x-failed-at"
+)
+
+// campos que se agregan a las entradas programadas que se devuelven al stream cuando vencen, solo las maneja
+// el group que las programo
+const (
+	ScheduledGroupField      = "x-scheduled-group"
+	ScheduledOriginalIdField = "x-scheduled-original-id"
+)
+
+// timeFields son los campos de la metadata que se leen como `time.Time`
+var timeFields = map[string]bool{ //nolint:gochecknoglobals
+	messageHeader.Created:   true,
+	messageHeader.Scheduled: true,
+	DeadLetterFailedAtField: true,
+}
+
+// ToValues convierte el mensaje serializado y su metadata en los campos de la entrada, los tiempos se escriben en RFC3339
+func ToValues(body []byte, meta metadata.Metadata) map[string]interface{} {
+	values := make(map[string]interface{}, len(meta)+1)
+
+	for key, value := range meta {
+		if value == nil || key == BodyField {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case []byte:
+			values[key] = string(v)
+		case time.Time:
+			values[key] = v.UTC().Format(time.RFC3339Nano)
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	values[BodyField] = string(body)
+
+	return values
+}
+
+// FromValues devuelve el mensaje serializado y la metadata de los campos de la entrada
+func FromValues(values map[string]interface{}) ([]byte, metadata.Metadata) {
+	meta := metadata.New()
+	var body []byte
+
+	for key, value := range values {
+		data := fmt.Sprint(value)
+
+		if key == BodyField {
+			body = []byte(data)
+			continue
+		}
+
+		if timeFields[key] {
+			if t, err := time.Parse(time.RFC3339Nano, data); err == nil {
+				meta.Set(key, t)
+				continue
+			}
+		}
+
+		meta.Set(key, data)
+	}
+
+	return body, meta
+}
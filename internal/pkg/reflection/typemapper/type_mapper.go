@@ -35,8 +35,8 @@ func discoverTypes() {
 }
 
 func RegisterType(typ reflect.Type) {
-	types[GetFullTypeName(typ)] = append(types[GetFullTypeName(typ)], typ)
-	types[GetTypeName(typ)] = append(types[GetTypeName(typ)], typ)
+	types[GetFullTypeNameByType(typ)] = append(types[GetFullTypeNameByType(typ)], typ)
+	types[GetTypeNameByType(typ)] = append(types[GetTypeNameByType(typ)], typ)
 }
 
 func RegisterTypeWithKey(key string, typ reflect.Type) {
@@ -321,6 +321,11 @@ func InstanceByPackageName(pkgPath string, name string) interface{} {
 }
 
 func getInstanceFromType(typ reflect.Type) interface{} {
+	// el tipo no esta registrado
+	if typ == nil {
+		return nil
+	}
+
 	if typ.Kind() == reflect.Ptr {
 		res := reflect.New(typ.Elem()).Interface()
 		return res
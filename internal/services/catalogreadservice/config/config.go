@@ -4,11 +4,13 @@ import (
 	"strings"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/config/environment"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	AppOptions AppOptions `mapstructure:"appOptions" env:"AppOptions"`
+	AppOptions       AppOptions       `mapstructure:"appOptions"       env:"AppOptions"`
+	ConsumersOptions ConsumersOptions `mapstructure:"consumersOptions" env:"ConsumersOptions"`
 }
 
 func NewConfig(env environment.Environment) (*Config, error) {
@@ -25,6 +27,15 @@ type AppOptions struct {
 	ServiceName  string `mapstructure:"serviceName"  env:"serviceName"`
 }
 
+// ConsumersOptions configura los consumers de rabbitmq de los eventos de productos
+type ConsumersOptions struct {
+	// ProductUpdatedPartitions reparte las actualizaciones de productos en queues por id de producto para aplicarlas en orden,
+	// 0 las consume de una sola queue. Requiere el plugin `rabbitmq_consistent_hash_exchange` en el broker
+	ProductUpdatedPartitions int `mapstructure:"productUpdatedPartitions"`
+	// CircuitBreaker son los umbrales del circuit breaker de cada consumer, el probe de mongo y redis se agrega en el codigo
+	CircuitBreaker circuitbreaker.CircuitBreakerOptions `mapstructure:"circuitBreaker"`
+}
+
 func (cfg *AppOptions) GetMicroserviceNameUpper() string {
 	return strings.ToUpper(cfg.ServiceName)
 }
@@ -1,16 +1,29 @@
 package rabbitmq
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/consumer"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/utils"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mongodb"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing"
 	rabbitmqConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/configurations"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/consumer/configurations"
+	redis2 "github.com/DavidReque/go-food-delivery/internal/pkg/redis"
+	"github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/config"
 	createProductExternalEventV1 "github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/internal/products/features/creating_product/v1/events/integrationevents/externalevents"
 	deleteProductExternalEventV1 "github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/internal/products/features/deleting_products/v1/events/integration_events/external_events"
 	updateProductExternalEventsV1 "github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/internal/products/features/updating_products/v1/events/integration_events/external_events"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func ConfigProductsRabbitMQ(
@@ -18,7 +31,41 @@ func ConfigProductsRabbitMQ(
 	logger logger.Logger,
 	validator *validator.Validate,
 	tracer tracing.AppTracer,
+	schemaRegistry schema.SchemaRegistry,
+	circuitBreakers circuitbreaker.CircuitBreakerRegistry,
+	mongoClient *mongo.Client,
+	redisClient *redis.Client,
+	consumersOptions *config.ConsumersOptions,
 ) {
+	// the payloads are validated against the schema of our copy of each event, an incompatible change in the
+	// write service goes to the dead letter queue instead of being applied with missing fields
+	schemaRegistry.Register(&createProductExternalEventV1.ProductCreatedV1{})
+	schemaRegistry.Register(&deleteProductExternalEventV1.ProductDeletedV1{})
+	schemaRegistry.Register(&updateProductExternalEventsV1.ProductUpdatedV1{})
+
+	// the handlers write to mongo and redis, while one of them is down the consumers are paused instead of
+	// sending every message to the dead letter queue
+	probe := func(ctx context.Context) error {
+		if err := mongodb.NewMongoHealthChecker(mongoClient).CheckHealth(ctx); err != nil {
+			return err
+		}
+
+		return redis2.NewRedisHealthChecker(redisClient).CheckHealth(ctx)
+	}
+
+	// los umbrales vienen de la configuracion, los valores en cero usan los defaults del circuit breaker
+	circuitBreakerOptions := consumersOptions.CircuitBreaker
+	circuitBreakerOptions.Probe = probe
+
+	pipelines := func(message types.IMessage) pipeline.ConsumerPipelineConfigurationBuilderFunc {
+		consumerName := fmt.Sprintf("%s_consumer", utils.GetMessageName(message))
+
+		return func(pipelinesBuilder pipeline.ConsumerPipelineConfigurationBuilder) {
+			pipelinesBuilder.AddPipeline(circuitBreakers.NewCircuitBreakerPipeline(consumerName, &circuitBreakerOptions))
+			pipelinesBuilder.AddPipeline(schema.NewConsumerSchemaValidationPipeline(schemaRegistry, logger))
+		}
+	}
+
 	// add custom message type mappings
 	// utils.RegisterCustomMessageTypesToRegistrty(map[string]types.IMessage{"productCreatedV1": &creatingProductIntegration.ProductCreatedV1{}})
 
@@ -27,7 +74,8 @@ func ConfigProductsRabbitMQ(
 		AddConsumer(
 			createProductExternalEventV1.ProductCreatedV1{},
 			func(builder configurations.RabbitMQConsumerConfigurationBuilder) {
-				builder.WithHandlers(
+				// los mensajes que agotan sus reintentos terminan en `<queue>.dlq`
+				builder.WithDeadLetter("", "", "").WIthPipelines(pipelines(createProductExternalEventV1.ProductCreatedV1{})).WithHandlers(
 					func(handlersBuilder consumer.ConsumerHandlerConfigurationBuilder) {
 						handlersBuilder.AddHandler(
 							createProductExternalEventV1.NewProductCreatedConsumer(
@@ -43,7 +91,7 @@ func ConfigProductsRabbitMQ(
 		AddConsumer(
 			deleteProductExternalEventV1.ProductDeletedV1{},
 			func(builder configurations.RabbitMQConsumerConfigurationBuilder) {
-				builder.WithHandlers(
+				builder.WithDeadLetter("", "", "").WIthPipelines(pipelines(deleteProductExternalEventV1.ProductDeletedV1{})).WithHandlers(
 					func(handlersBuilder consumer.ConsumerHandlerConfigurationBuilder) {
 						handlersBuilder.AddHandler(
 							deleteProductExternalEventV1.NewProductDeletedConsumer(
@@ -64,7 +112,12 @@ func ConfigProductsRabbitMQ(
 		AddConsumer(
 			updateProductExternalEventsV1.ProductUpdatedV1{},
 			func(builder configurations.RabbitMQConsumerConfigurationBuilder) {
-				builder.WithHandlers(
+				// con particiones las actualizaciones de un mismo producto se aplican en orden, la partition key es el id del producto
+				// y requiere el plugin `rabbitmq_consistent_hash_exchange` en el broker, por eso se habilitan desde la configuracion
+				if consumersOptions.ProductUpdatedPartitions > 0 {
+					builder.WithPartitions(consumersOptions.ProductUpdatedPartitions)
+				}
+				builder.WithDeadLetter("", "", "").WIthPipelines(pipelines(updateProductExternalEventsV1.ProductUpdatedV1{})).WithHandlers(
 					func(handlersBuilder consumer.ConsumerHandlerConfigurationBuilder) {
 						handlersBuilder.AddHandler(
 							updateProductExternalEventsV1.NewProductUpdatedConsumer(
@@ -2,6 +2,8 @@ package infrastructure
 
 import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/circuitbreaker"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/grpc"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/health"
 	customEcho "github.com/DavidReque/go-food-delivery/internal/pkg/http/customecho"
@@ -11,9 +13,13 @@ import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/configurations"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/dlq"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/redis"
+	"github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/config"
 	rabbitmq2 "github.com/DavidReque/go-food-delivery/internal/services/catalogreadservice/internal/products/configurations/rabbitmq"
 	"github.com/go-playground/validator/v10"
+	redis2 "github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/fx"
 )
 
@@ -27,12 +33,32 @@ var Module = fx.Module(
 	mongodb.Module,
 	redis.Module,
 	rabbitmq.ModuleFunc(
-		func(v *validator.Validate, l logger.Logger, tracer tracing.AppTracer) configurations.RabbitMQConfigurationBuilderFuc {
+		func(
+			v *validator.Validate,
+			l logger.Logger,
+			tracer tracing.AppTracer,
+			schemaRegistry schema.SchemaRegistry,
+			circuitBreakers circuitbreaker.CircuitBreakerRegistry,
+			mongoClient *mongo.Client,
+			redisClient *redis2.Client,
+			cfg *config.Config,
+		) configurations.RabbitMQConfigurationBuilderFuc {
 			return func(builder configurations.RabbitMQConfigurationBuilder) {
-				rabbitmq2.ConfigProductsRabbitMQ(builder, l, v, tracer)
+				rabbitmq2.ConfigProductsRabbitMQ(
+					builder,
+					l,
+					v,
+					tracer,
+					schemaRegistry,
+					circuitBreakers,
+					mongoClient,
+					redisClient,
+					&cfg.ConsumersOptions,
+				)
 			}
 		},
 	),
+	dlq.Module,
 	health.Module,
 	tracing.Module,
 	metrics.Module,
@@ -1,6 +1,8 @@
 package rabbitmq
 
 import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/pipeline"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/configurations"
 	producerConfigurations "github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/producer/configurations"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/rabbitmq/types"
@@ -12,7 +14,17 @@ import (
 // ConfigProductsRabbitMQ configures the rabbitmq for the products
 func ConfigProductsRabbitMQ(
 	builder configurations.RabbitMQConfigurationBuilder,
+	schemaRegistry schema.SchemaRegistry,
 ) {
+	// the schemas of the events are the contract with the consumers, the events that don't match them are not published
+	schemaRegistry.Register(&creatingproductevents.ProductCreatedV1{})
+	schemaRegistry.Register(&updatingproductevents.ProductUpdatedV1{})
+	schemaRegistry.Register(&deletingproductevents.ProductDeletedV1{})
+
+	validateSchema := func(pipelinesBuilder pipeline.ProducerPipelineConfigurationBuilder) {
+		pipelinesBuilder.AddPipeline(schema.NewProducerSchemaValidationPipeline(schemaRegistry))
+	}
+
 	// Add producer for the product created event
 	builder.AddProducer(
 		creatingproductevents.ProductCreatedV1{},
@@ -21,6 +33,7 @@ func ConfigProductsRabbitMQ(
 										WithExchangeType(types.ExchangeTopic). // Exchange type
 										WithRoutingKey("products.created").    // Routing key
 										WithDurable(true)                      // Durable
+			builder.WithPipelines(validateSchema)
 		},
 	)
 
@@ -32,6 +45,7 @@ func ConfigProductsRabbitMQ(
 										WithExchangeType(types.ExchangeTopic). // Exchange type
 										WithRoutingKey("products.updated").    // Routing key
 										WithDurable(true)                      // Durable
+			builder.WithPipelines(validateSchema)
 		},
 	)
 
@@ -43,6 +57,7 @@ func ConfigProductsRabbitMQ(
 										WithExchangeType(types.ExchangeTopic). // Exchange type
 										WithRoutingKey("products.deleted").    // Routing key
 										WithDurable(true)                      // Durable
+			builder.WithPipelines(validateSchema)
 		},
 	)
 }
@@ -1,6 +1,7 @@
 package fxparams
 
 import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/persistmessage"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/producer"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/otel/tracing"
@@ -26,5 +27,8 @@ type ProductHandlerParams struct {
 	// Se utiliza para publicar mensajes en la cola de RabbitMQ
 	RabbitmqProducer producer.Producer
 
+	// MessagePersistenceService guarda los eventos de integracion en el outbox dentro de la transaccion del handler
+	MessagePersistenceService persistmessage.MessagePersistenceService
+
 	Tracer tracing.AppTracer
 }
@@ -11,7 +11,8 @@ import (
 )
 
 type CreateProduct struct {
-	cqrs.Command
+	// TxCommand habilita la transaccion del pipeline del mediator, el evento se guarda en el outbox dentro de la misma transaccion
+	cqrs.TxCommand
 	ProductID   uuid.UUID
 	Name        string
 	Description string
@@ -39,7 +40,7 @@ func NewCreateProduct(
 	price float64,
 ) *CreateProduct {
 	command := &CreateProduct{
-		Command:     cqrs.NewCommandByT[CreateProduct](),
+		TxCommand:   cqrs.NewTxCommandByT[CreateProduct](),
 		ProductID:   uuid.NewV4(),
 		Name:        name,
 		Description: description,
@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/cqrs"
-	// customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
+	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/postgresgorm/gormdbcontext"
 	"github.com/DavidReque/go-food-delivery/internal/services/catalogwriteservice/internal/products/data/datamodels"
@@ -78,22 +80,24 @@ func (c *createProductHandler) Handle(
 	// Create the product creation event
 	productCreated := integrationevents.NewProductCreatedV1(productDto)
 
-	// Publish the product creation event to RabbitMQ
-	err = c.RabbitmqProducer.PublishMessage(ctx, productCreated)
+	// Save the product creation event in the outbox within the same transaction as the product,
+	// the outbox dispatcher publishes it to RabbitMQ after the commit
+	err = c.MessagePersistenceService.AddPublishMessage(
+		*types.NewMessageEnvelope(productCreated, map[string]interface{}{messageHeader.PartitionKey: productDto.Id.String()}),
+		ctx,
+	)
 	if err != nil {
-		c.Log.Errorw(
-			fmt.Sprintf("error in publishing ProductCreated integration_events event: %v", err),
-			logger.Fields{"ProductId": productCreated.Id},
-		)
-		// Log error but don't fail the request - this follows the pattern from the reference repo
-		// The product was successfully created, event publishing is secondary
-	} else {
-		c.Log.Infow(
-			fmt.Sprintf("ProductCreated message with messageId `%s` published to the rabbitmq broker", productCreated.MessageId),
-			logger.Fields{"MessageId": productCreated.MessageId},
+		return nil, customErrors.NewApplicationErrorWrap(
+			err,
+			"error in saving 'ProductCreated' message in the outbox",
 		)
 	}
 
+	c.Log.Infow(
+		fmt.Sprintf("ProductCreated message with messageId `%s` saved in the outbox", productCreated.MessageId),
+		logger.Fields{"MessageId": productCreated.MessageId},
+	)
+
 	// Log the product creation
 	c.Log.Infow(
 		fmt.Sprintf(
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/cqrs"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -9,12 +10,17 @@ import (
 )
 
 type DeleteProduct struct {
+	// TxCommand habilita la transaccion del pipeline del mediator, el evento se guarda en el outbox dentro de la misma transaccion
+	cqrs.TxCommand
 	ProductID uuid.UUID
 }
 
 // NewDeleteProduct delete a product
 func NewDeleteProduct(productID uuid.UUID) *DeleteProduct {
-	command := &DeleteProduct{ProductID: productID}
+	command := &DeleteProduct{
+		TxCommand: cqrs.NewTxCommandByT[DeleteProduct](),
+		ProductID: productID,
+	}
 
 	return command
 }
@@ -27,10 +33,6 @@ func NewDeleteProductWithValidation(productID uuid.UUID) (*DeleteProduct, error)
 	return command, err
 }
 
-// IsTxRequest for enabling transactions on the mediatr pipeline
-func (c *DeleteProduct) isTxRequest() {
-}
-
 // Validate validates the delete product command
 func (c *DeleteProduct) Validate() error {
 	// Validate the product id
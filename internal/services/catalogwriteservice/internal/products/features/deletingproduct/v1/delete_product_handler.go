@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/cqrs"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/postgresgorm/gormdbcontext"
@@ -34,10 +36,6 @@ func (c *deleteProductHandler) RegisterHandler() error {
 	)
 }
 
-// IsTxRequest for enabling transactions on the mediatr pipeline
-func (c *deleteProductHandler) isTxRequest() {
-}
-
 // Handle handles the delete product command
 func (c *deleteProductHandler) Handle(
 	ctx context.Context,
@@ -54,18 +52,22 @@ func (c *deleteProductHandler) Handle(
 		command.ProductID.String(),
 	)
 
-	// Publish the product deletion event to RabbitMQ
-	if err = c.RabbitmqProducer.PublishMessage(ctx, productDeleted); err != nil {
+	// Save the product deletion event in the outbox, it will be published to RabbitMQ after the transaction commit
+	err = c.MessagePersistenceService.AddPublishMessage(
+		*types.NewMessageEnvelope(productDeleted, map[string]interface{}{messageHeader.PartitionKey: command.ProductID.String()}),
+		ctx,
+	)
+	if err != nil {
 		return nil, customErrors.NewApplicationErrorWrap(
 			err,
-			"error in publishing 'ProductDeleted' message",
+			"error in saving 'ProductDeleted' message in the outbox",
 		)
 	}
 
 	// Log the product deletion event
 	c.Log.Infow(
 		fmt.Sprintf(
-			"ProductDeleted message with messageId '%s' published to the rabbitmq broker",
+			"ProductDeleted message with messageId '%s' saved in the outbox",
 			productDeleted.MessageId,
 		),
 		logger.Fields{"MessageId": productDeleted.MessageId},
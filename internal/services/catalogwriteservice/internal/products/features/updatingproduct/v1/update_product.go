@@ -3,6 +3,7 @@ package v1
 import (
 	"time"
 
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/cqrs"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/google/uuid"
@@ -10,6 +11,8 @@ import (
 
 // UpdateProduct es la estructura para actualizar un producto
 type UpdateProduct struct {
+	// TxCommand habilita la transaccion del pipeline del mediator, el evento se guarda en el outbox dentro de la misma transaccion
+	cqrs.TxCommand
 	ProductID   uuid.UUID
 	Name        string
 	Description string
@@ -25,6 +28,7 @@ func NewUpdateProduct(
 	price float64,
 ) *UpdateProduct {
 	command := &UpdateProduct{
+		TxCommand:   cqrs.NewTxCommandByT[UpdateProduct](),
 		ProductID:   productID,
 		Name:        name,
 		Description: description,
@@ -50,10 +54,6 @@ func NewUpdateProductWithValidation(
 	return command, err
 }
 
-// IsTxRequest for enabling transactions on the mediatr pipeline
-func (c *UpdateProduct) isTxRequest() {
-}
-
 // Validate valida la estructura para actualizar un producto
 func (c *UpdateProduct) Validate() error {
 	err := validation.ValidateStruct(
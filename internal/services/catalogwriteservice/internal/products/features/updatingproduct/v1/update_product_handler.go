@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/cqrs"
+	messageHeader "github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/messageheader"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/types"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/logger"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/mapper"
@@ -39,10 +41,6 @@ func (c *updateProductHandler) RegisterHandler() error {
 	)
 }
 
-// IsTxRequest for enabling transactions on the mediatr pipeline
-func (c *updateProductHandler) isTxRequest() {
-}
-
 // Handle maneja la solicitud para actualizar un producto
 func (c *updateProductHandler) Handle(
 	ctx context.Context,
@@ -98,12 +96,16 @@ func (c *updateProductHandler) Handle(
 	// Crear el evento de actualización de un producto
 	productUpdated := integrationevents.NewProductUpdatedV1(productDto)
 
-	// Publicar el evento de actualización de un producto
-	err = c.RabbitmqProducer.PublishMessage(ctx, productUpdated)
+	// Guardar el evento en el outbox, se publica en RabbitMQ despues del commit de la transaccion,
+	// el id del producto es la partition key para que sus actualizaciones se consuman en orden
+	err = c.MessagePersistenceService.AddPublishMessage(
+		*types.NewMessageEnvelope(productUpdated, map[string]interface{}{messageHeader.PartitionKey: productDto.Id.String()}),
+		ctx,
+	)
 	if err != nil {
 		return nil, customErrors.NewApplicationErrorWrap(
 			err,
-			"error in publishing 'ProductUpdated' message",
+			"error in saving 'ProductUpdated' message in the outbox",
 		)
 	}
 
@@ -117,7 +119,7 @@ func (c *updateProductHandler) Handle(
 
 	c.Log.Infow(
 		fmt.Sprintf(
-			"ProductUpdated message with messageId `%s` published to the rabbitmq broker",
+			"ProductUpdated message with messageId `%s` saved in the outbox",
 			productUpdated.MessageId,
 		),
 		logger.Fields{"MessageId": productUpdated.MessageId},
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/messaging/schema"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/grpc"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/health"
 	"github.com/DavidReque/go-food-delivery/internal/pkg/migration/goose"
@@ -31,9 +32,9 @@ var Module = fx.Module(
 	postgresmessaging.Module,
 	goose.Module,
 	rabbitmq.ModuleFunc(
-		func() configurations.RabbitMQConfigurationBuilderFuc {
+		func(schemaRegistry schema.SchemaRegistry) configurations.RabbitMQConfigurationBuilderFuc {
 			return func(builder configurations.RabbitMQConfigurationBuilder) {
-				rabbitmq2.ConfigProductsRabbitMQ(builder)
+				rabbitmq2.ConfigProductsRabbitMQ(builder, schemaRegistry)
 			}
 		},
 	),
@@ -4,13 +4,18 @@ import (
 	"time"
 
 	"github.com/DavidReque/go-food-delivery/internal/pkg/core/domain"
+	"github.com/DavidReque/go-food-delivery/internal/pkg/core/typeregistry"
 	customErrors "github.com/DavidReque/go-food-delivery/internal/pkg/http/httperrors/customerrors"
-	"github.com/DavidReque/go-food-delivery/internal/pkg/reflection/typemapper"
 	dtosV1 "github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/dtos/v1"
 	domainExceptions "github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/exceptions/domain_exceptions"
 	"github.com/google/uuid"
 )
 
+// el nombre logico del evento en el event store, `*OrderCreatedV1` es el nombre de los eventos guardados antes del registry
+func init() {
+	_ = typeregistry.Register[OrderCreatedV1]("orders.order-created.v1", "*OrderCreatedV1")
+}
+
 type OrderCreatedV1 struct {
 	*domain.DomainEvent
 	OrderId         uuid.UUID             `json:"order_id"`
@@ -57,7 +62,7 @@ func NewOrderCreatedEventV1(
 		DeliveredTime:   deliveredTime,
 	}
 
-	eventData.DomainEvent = domain.NewDomainEvent(typemapper.GetTypeName(eventData))
+	eventData.DomainEvent = domain.NewDomainEvent(typeregistry.GetTypeName(eventData))
 
 	return eventData, nil
 }
@@ -55,6 +55,7 @@ func NewOrder(
 	createdAt time.Time,
 ) (*Order, error) {
 	order := &Order{}
+	order.NewEmptyAggregate()
 	order.SetId(utils.ConvertGoogleUUIDToSatoriUUID(id))
 	// order.SetId(id)
 
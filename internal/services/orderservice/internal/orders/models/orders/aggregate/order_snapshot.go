@@ -0,0 +1,89 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/pkg/mapper"
+	dtosV1 "github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/dtos/v1"
+	"github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/models/orders/value_objects"
+
+	"github.com/google/uuid"
+	uuid2 "github.com/satori/go.uuid"
+)
+
+// orderSnapshotSchemaVersion se incrementa cuando cambia orderSnapshot, los snapshots anteriores se ignoran y la orden se carga desde sus eventos
+const orderSnapshotSchemaVersion = 1
+
+// orderSnapshot es el estado de la orden que se guarda en los snapshots, tiene el email y la direccion del cliente y el
+// snapshot store lo cifra con las opciones de cifrado de los mensajes (`es.NewSnapshotStoreWithEncryption`)
+type orderSnapshot struct {
+	Id              uuid2.UUID            `json:"id"`
+	ShopItems       []*dtosV1.ShopItemDto `json:"shopItems"`
+	AccountEmail    string                `json:"accountEmail"`
+	DeliveryAddress string                `json:"deliveryAddress"`
+	CancelReason    string                `json:"cancelReason"`
+	DeliveredTime   time.Time             `json:"deliveredTime"`
+	Paid            bool                  `json:"paid"`
+	Submitted       bool                  `json:"submitted"`
+	Completed       bool                  `json:"completed"`
+	Canceled        bool                  `json:"canceled"`
+	PaymentId       uuid.UUID             `json:"paymentId"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+}
+
+func (o *Order) SnapshotSchemaVersion() int {
+	return orderSnapshotSchemaVersion
+}
+
+func (o *Order) CreateSnapshot() ([]byte, error) {
+	itemsDto, err := mapper.Map[[]*dtosV1.ShopItemDto](o.shopItems)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&orderSnapshot{
+		Id:              o.Id(),
+		ShopItems:       itemsDto,
+		AccountEmail:    o.accountEmail,
+		DeliveryAddress: o.deliveryAddress,
+		CancelReason:    o.cancelReason,
+		DeliveredTime:   o.deliveredTime,
+		Paid:            o.paid,
+		Submitted:       o.submitted,
+		Completed:       o.completed,
+		Canceled:        o.canceled,
+		PaymentId:       o.paymentId,
+		CreatedAt:       o.createdAt,
+		UpdatedAt:       o.updatedAt,
+	})
+}
+
+func (o *Order) RestoreSnapshot(state []byte) error {
+	snapshot := &orderSnapshot{}
+	if err := json.Unmarshal(state, snapshot); err != nil {
+		return err
+	}
+
+	items, err := mapper.Map[[]*value_objects.ShopItem](snapshot.ShopItems)
+	if err != nil {
+		return err
+	}
+
+	o.SetId(snapshot.Id)
+	o.shopItems = items
+	o.accountEmail = snapshot.AccountEmail
+	o.deliveryAddress = snapshot.DeliveryAddress
+	o.cancelReason = snapshot.CancelReason
+	o.deliveredTime = snapshot.DeliveredTime
+	o.paid = snapshot.Paid
+	o.submitted = snapshot.Submitted
+	o.completed = snapshot.Completed
+	o.canceled = snapshot.Canceled
+	o.paymentId = snapshot.PaymentId
+	o.createdAt = snapshot.CreatedAt
+	o.updatedAt = snapshot.UpdatedAt
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package aggregate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/configurations/mappings"
+	"github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/models/orders/aggregate"
+	"github.com/DavidReque/go-food-delivery/internal/services/orderservice/internal/orders/models/orders/value_objects"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	if err := mappings.ConfigureOrdersMappings(); err != nil {
+		panic(err)
+	}
+}
+
+func newTestOrder(t *testing.T) *aggregate.Order {
+	t.Helper()
+
+	order, err := aggregate.NewOrder(
+		uuid.New(),
+		[]*value_objects.ShopItem{
+			value_objects.CreateNewShopItem("pizza", "pepperoni pizza", 2, 10.5),
+			value_objects.CreateNewShopItem("soda", "orange soda", 1, 2),
+		},
+		"john@example.com",
+		"street 1",
+		time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	return order
+}
+
+func Test_Order_RestoreSnapshot_Restores_The_State_Of_CreateSnapshot(t *testing.T) {
+	order := newTestOrder(t)
+
+	state, err := order.CreateSnapshot()
+	require.NoError(t, err)
+
+	restored := &aggregate.Order{}
+	restored.NewEmptyAggregate()
+	require.NoError(t, restored.RestoreSnapshot(state))
+
+	assert.Equal(t, order.Id(), restored.Id())
+	assert.Equal(t, order.AccountEmail(), restored.AccountEmail())
+	assert.Equal(t, order.DeliveryAddress(), restored.DeliveryAddress())
+	assert.Equal(t, order.DeliveredTime(), restored.DeliveredTime())
+	assert.Equal(t, order.CreatedAt(), restored.CreatedAt())
+	assert.Equal(t, order.TotalPrice(), restored.TotalPrice())
+	assert.Equal(t, order.Paid(), restored.Paid())
+	assert.Equal(t, order.Submitted(), restored.Submitted())
+	assert.Equal(t, order.Completed(), restored.Completed())
+	assert.Equal(t, order.Canceled(), restored.Canceled())
+	assert.Equal(t, order.CancelReason(), restored.CancelReason())
+	assert.Equal(t, order.PaymentId(), restored.PaymentId())
+
+	require.Len(t, restored.ShopItems(), 2)
+	for i, item := range order.ShopItems() {
+		assert.Equal(t, item.Title(), restored.ShopItems()[i].Title())
+		assert.Equal(t, item.Description(), restored.ShopItems()[i].Description())
+		assert.Equal(t, item.Quantity(), restored.ShopItems()[i].Quantity())
+		assert.Equal(t, item.Price(), restored.ShopItems()[i].Price())
+	}
+}
+
+func Test_Order_SnapshotSchemaVersion(t *testing.T) {
+	assert.Equal(t, 1, newTestOrder(t).SnapshotSchemaVersion())
+}
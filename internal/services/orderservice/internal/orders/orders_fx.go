@@ -23,7 +23,7 @@ var Module = fx.Module(
 	fx.Provide(fx.Annotate(repositories.NewMongoOrderReadRepository)),
 	fx.Provide(repositories.NewElasticOrderReadRepository),
 
-	fx.Provide(eventstroredb.NewEventStoreAggregateStore[*aggregate.Order]),
+	fx.Provide(eventstroredb.NewEventStoreAggregateStoreWithSnapshots[*aggregate.Order]),
 	fx.Provide(fx.Annotate(func(catalogsServer echocontracts.EchoHttpServer) *echo.Group {
 		var g *echo.Group
 		catalogsServer.RouteBuilder().RegisterGroupFunc("/api/v1", func(v1 *echo.Group) {
@@ -36,6 +36,7 @@ var Module = fx.Module(
 			}
 		},
 	),
+	eventstroredb.SnapshotModule,
 	rabbitmq.ModuleFunc(
 		func() configurations.RabbitMQConfigurationBuilderFuc {
 			return func(builder configurations.RabbitMQConfigurationBuilder) {